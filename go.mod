@@ -11,6 +11,7 @@ require (
 	github.com/charmbracelet/x/ansi v0.11.7
 	github.com/google/uuid v1.6.0
 	github.com/lithammer/fuzzysearch v1.1.8
+	go.yaml.in/yaml/v3 v3.0.5
 	golang.org/x/term v0.45.0
 	modernc.org/sqlite v1.53.0
 )
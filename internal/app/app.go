@@ -21,6 +21,8 @@ var (
 	resolvePaneContentsFn = resurrect.ResolvePaneContents
 	resolveSaveDirFn      = resurrect.ResolveDir
 	latestSaveFn          = resurrect.LatestSave
+	loadUserMenusFn       = menu.LoadUserMenus
+	loadFrecencyFn        = menu.LoadFrecency
 )
 
 // Config describes user-provided application options.
@@ -64,6 +66,16 @@ func Run(cfg Config) error {
 		watcher.Wait()
 		tmux.Shutdown()
 	}()
+	// a broken user menu must not keep the popup from opening.
+	userMenus, err := loadUserMenusFn(socketPath)
+	if err != nil {
+		logging.Error(err)
+	}
+	// likewise an unreadable frecency store just means tmux's own order.
+	usage, sortMode, err := loadFrecencyFn(socketPath)
+	if err != nil {
+		logging.Error(err)
+	}
 	model := ui.NewModel(ui.ModelConfig{
 		SocketPath:  socketPath,
 		Width:       cfg.Width,
@@ -76,6 +88,9 @@ func Run(cfg Config) error {
 		MenuArgs:    cfg.MenuArgs,
 		ClientID:    clientID,
 		SessionName: strings.TrimSpace(cfg.SessionName),
+		UserMenus:   userMenus,
+		Frecency:    usage,
+		SortMode:    sortMode,
 	})
 	if cfg.ResurrectOp != "" {
 		model.SetResurrectInit(buildResurrectStart(cfg, socketPath, clientID))
@@ -1,6 +1,6 @@
-// Package clipboard copies text to the host's native system clipboard,
-// dispatching on the running OS. it is consumer-agnostic: no tmux, bubbletea,
-// or menu imports.
+// Package clipboard copies text to and reads text from the host's native
+// system clipboard, dispatching on the running OS. it has no tmux,
+// bubbletea, or menu imports.
 package clipboard
 
 import (
@@ -65,3 +65,55 @@ func copyForOS(goos string, text string, run runner) error {
 		return fmt.Errorf("%w: %s", errUnsupportedOS, goos)
 	}
 }
+
+// reader runs the named command and returns its standard output. like runner
+// it is a package var so tests can stub the os/exec invocation.
+type reader = func(name string, args ...string) (string, error)
+
+var readClipboardCommand reader = func(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	return string(out), err
+}
+
+// Read returns the system clipboard's text using the native tool for the
+// running OS.
+func Read() (string, error) {
+	return readForOS(runtime.GOOS, readClipboardCommand)
+}
+
+// readForOS dispatches to the native clipboard reader for goos. on linux it
+// tries wl-paste, then xclip, then xsel, first working tool wins, mirroring
+// copyForOS. wl-paste gets --no-newline so it returns the clipboard verbatim
+// instead of appending a newline of its own.
+func readForOS(goos string, run reader) (string, error) {
+	switch goos {
+	case "darwin":
+		return run("pbpaste")
+	case "windows":
+		return run("powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw")
+	case "linux":
+		attempts := []struct {
+			name string
+			args []string
+		}{
+			{"wl-paste", []string{"--no-newline"}},
+			{"xclip", []string{"-selection", "clipboard", "-o"}},
+			{"xsel", []string{"--clipboard", "--output"}},
+		}
+		var lastErr error
+		for _, a := range attempts {
+			text, err := run(a.name, a.args...)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return text, nil
+		}
+		if lastErr != nil {
+			return "", lastErr
+		}
+		return "", errUnsupportedOS
+	default:
+		return "", fmt.Errorf("%w: %s", errUnsupportedOS, goos)
+	}
+}
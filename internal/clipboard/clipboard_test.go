@@ -135,3 +135,84 @@ func TestCopyForOSUnknownReturnsError(t *testing.T) {
 		t.Fatalf("calls = %d, want 0 (unsupported os must not invoke the stub)", len(calls))
 	}
 }
+
+// newStubReader returns a reader that records every call in calls, failing
+// with errFor(name) or returning out otherwise.
+func newStubReader(calls *[]stubCall, out string, errFor map[string]error) reader {
+	return func(name string, args ...string) (string, error) {
+		*calls = append(*calls, stubCall{name: name, args: args})
+		if err := errFor[name]; err != nil {
+			return "", err
+		}
+		return out, nil
+	}
+}
+
+func TestReadForOSDarwinUsesPbpaste(t *testing.T) {
+	var calls []stubCall
+	stub := newStubReader(&calls, "hello\n", nil)
+
+	got, err := readForOS("darwin", stub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "hello\n" {
+		t.Fatalf("text = %q, want the tool output verbatim", got)
+	}
+	if len(calls) != 1 || calls[0].name != "pbpaste" {
+		t.Fatalf("calls = %+v, want a single pbpaste", calls)
+	}
+}
+
+func TestReadForOSLinuxFallsThroughToXsel(t *testing.T) {
+	var calls []stubCall
+	stub := newStubReader(&calls, "text", map[string]error{
+		"wl-paste": errBoom,
+		"xclip":    errBoom,
+	})
+
+	got, err := readForOS("linux", stub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "text" {
+		t.Fatalf("text = %q, want text", got)
+	}
+	if len(calls) != 3 || calls[0].name != "wl-paste" || calls[1].name != "xclip" || calls[2].name != "xsel" {
+		t.Fatalf("call order = %+v, want wl-paste, xclip, xsel", calls)
+	}
+	if got := calls[0].args; len(got) != 1 || got[0] != "--no-newline" {
+		t.Fatalf("wl-paste args = %v, want [--no-newline]", got)
+	}
+	if got := calls[1].args; len(got) != 3 || got[2] != "-o" {
+		t.Fatalf("xclip args = %v, want [-selection clipboard -o]", got)
+	}
+	if got := calls[2].args; len(got) != 2 || got[1] != "--output" {
+		t.Fatalf("xsel args = %v, want [--clipboard --output]", got)
+	}
+}
+
+func TestReadForOSLinuxAllFailReturnsError(t *testing.T) {
+	var calls []stubCall
+	stub := newStubReader(&calls, "", map[string]error{
+		"wl-paste": errBoom,
+		"xclip":    errBoom,
+		"xsel":     errBoom,
+	})
+
+	if _, err := readForOS("linux", stub); !errors.Is(err, errBoom) {
+		t.Fatalf("err = %v, want the last tool's error", err)
+	}
+}
+
+func TestReadForOSUnknownReturnsError(t *testing.T) {
+	var calls []stubCall
+	stub := newStubReader(&calls, "x", nil)
+
+	if _, err := readForOS("plan9", stub); !errors.Is(err, errUnsupportedOS) {
+		t.Fatalf("err = %v, want errUnsupportedOS", err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("calls = %d, want 0 (unsupported os must not invoke the stub)", len(calls))
+	}
+}
@@ -0,0 +1,224 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// OSC52Mode places the OSC 52 backend relative to the native clipboard tool.
+type OSC52Mode int
+
+const (
+	// OSC52Off never emits OSC 52.
+	OSC52Off OSC52Mode = iota
+	// OSC52Fallback emits OSC 52 only when the native tool fails (the
+	// default: a remote box without xclip still reaches the laptop).
+	OSC52Fallback
+	// OSC52Primary emits OSC 52 first and only tries the native tool when
+	// that fails.
+	OSC52Primary
+)
+
+func (m OSC52Mode) String() string {
+	switch m {
+	case OSC52Off:
+		return "off"
+	case OSC52Primary:
+		return "primary"
+	default:
+		return "fallback"
+	}
+}
+
+// Passthrough is the wrapping an OSC 52 sequence needs to get through a
+// terminal multiplexer sitting between the tty and the real terminal.
+type Passthrough int
+
+const (
+	PassthroughNone Passthrough = iota
+	// PassthroughTmux wraps the sequence in tmux's DCS passthrough, for a
+	// client whose own terminal is another tmux (local tmux → ssh → remote
+	// tmux). The outer tmux needs allow-passthrough on.
+	PassthroughTmux
+	// PassthroughScreen wraps the sequence in screen's DCS passthrough,
+	// chunked because screen drops DCS strings longer than 768 bytes.
+	PassthroughScreen
+)
+
+func (p Passthrough) String() string {
+	switch p {
+	case PassthroughTmux:
+		return "tmux"
+	case PassthroughScreen:
+		return "screen"
+	default:
+		return "none"
+	}
+}
+
+// OSC52MaxBytes is the largest text OSC 52 will carry. Terminals cap the
+// sequence (xterm and hterm at ~100 000 bytes of base64); beyond that they
+// silently drop it, so refuse up front instead.
+const OSC52MaxBytes = 74994
+
+// osc52WriteChunk bounds each write to the tty so a large sequence is never
+// truncated by a short write on a busy pty.
+const osc52WriteChunk = 4096
+
+// screenChunk is the payload per DCS string under screen passthrough.
+const screenChunk = 76
+
+// errOSC52TooLarge is returned for text beyond OSC52MaxBytes.
+var errOSC52TooLarge = errors.New("clipboard: text too large for OSC 52")
+
+// OSC52Sequence returns the escape sequence that sets the terminal's
+// clipboard to text, wrapped for pt.
+func OSC52Sequence(text string, pt Passthrough) (string, error) {
+	if len(text) > OSC52MaxBytes {
+		return "", fmt.Errorf("%w: %d bytes (max %d)", errOSC52TooLarge, len(text), OSC52MaxBytes)
+	}
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch pt {
+	case PassthroughTmux:
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\", nil
+	case PassthroughScreen:
+		var b strings.Builder
+		for len(seq) > 0 {
+			n := min(screenChunk, len(seq))
+			b.WriteString("\x1bP" + seq[:n] + "\x1b\\")
+			seq = seq[n:]
+		}
+		return b.String(), nil
+	default:
+		return seq, nil
+	}
+}
+
+// writeOSC52 writes seq to w in osc52WriteChunk pieces.
+func writeOSC52(w io.Writer, seq string) error {
+	for len(seq) > 0 {
+		n := min(osc52WriteChunk, len(seq))
+		if _, err := io.WriteString(w, seq[:n]); err != nil {
+			return err
+		}
+		seq = seq[n:]
+	}
+	return nil
+}
+
+// openTTY opens a tty for writing. it is a package var so tests can capture
+// the sequence instead of writing to a real terminal.
+var openTTY = func(path string) (io.WriteCloser, error) {
+	return os.OpenFile(path, os.O_WRONLY, 0)
+}
+
+// CopyOSC52 sets the clipboard of the terminal behind tty to text by writing
+// an OSC 52 sequence to it.
+func CopyOSC52(tty, text string, pt Passthrough) error {
+	if strings.TrimSpace(tty) == "" {
+		return errors.New("clipboard: no tty for OSC 52")
+	}
+	seq, err := OSC52Sequence(text, pt)
+	if err != nil {
+		return err
+	}
+	w, err := openTTY(tty)
+	if err != nil {
+		return fmt.Errorf("clipboard: open %s: %w", tty, err)
+	}
+	if err := writeOSC52(w, seq); err != nil {
+		w.Close()
+		return fmt.Errorf("clipboard: write %s: %w", tty, err)
+	}
+	return w.Close()
+}
+
+// Strategy couples the native tool with an OSC 52 writer in the order mode
+// asks for. OSC52 is injected so callers decide how the sequence reaches the
+// terminal (direct tty write, or tmux's own set-clipboard forwarding).
+type Strategy struct {
+	Mode   OSC52Mode
+	Native func(string) error
+	OSC52  func(string) error
+}
+
+// Copy runs the primary backend, falling back to the other on failure. When
+// both fail the error names both causes.
+func (s Strategy) Copy(text string) error {
+	native := s.Native
+	if native == nil {
+		native = Copy
+	}
+	if s.Mode == OSC52Off || s.OSC52 == nil {
+		return native(text)
+	}
+	first, second := native, s.OSC52
+	if s.Mode == OSC52Primary {
+		first, second = s.OSC52, native
+	}
+	firstErr := first(text)
+	if firstErr == nil {
+		return nil
+	}
+	if err := second(text); err != nil {
+		return errors.Join(firstErr, err)
+	}
+	return nil
+}
+
+const (
+	envOSC52         = "TMUX_POPUP_CONTROL_CLIPBOARD_OSC52"
+	envOSC52Passthru = "TMUX_POPUP_CONTROL_CLIPBOARD_OSC52_PASSTHROUGH"
+	optOSC52         = "@tmux-popup-control-clipboard-osc52"
+	optOSC52Passthru = "@tmux-popup-control-clipboard-osc52-passthrough"
+)
+
+// OSC52Settings configure the OSC 52 backend. AutoPassthrough means the
+// passthrough wrapping is picked from the client's terminal name.
+type OSC52Settings struct {
+	Mode            OSC52Mode
+	Passthrough     Passthrough
+	AutoPassthrough bool
+}
+
+// ResolveOSC52Settings reads each setting from the environment first, then
+// the tmux option, then the default.
+func ResolveOSC52Settings(getenv, option func(string) string) OSC52Settings {
+	lookup := func(envKey, optKey string) string {
+		if v := strings.TrimSpace(getenv(envKey)); v != "" {
+			return strings.ToLower(v)
+		}
+		return strings.ToLower(strings.TrimSpace(option(optKey)))
+	}
+	s := OSC52Settings{Mode: OSC52Fallback, AutoPassthrough: true}
+	switch lookup(envOSC52, optOSC52) {
+	case "off", "0", "false", "no":
+		s.Mode = OSC52Off
+	case "primary", "on", "1", "true", "yes":
+		s.Mode = OSC52Primary
+	}
+	switch lookup(envOSC52Passthru, optOSC52Passthru) {
+	case "none", "off":
+		s.AutoPassthrough = false
+	case "tmux":
+		s.AutoPassthrough, s.Passthrough = false, PassthroughTmux
+	case "screen":
+		s.AutoPassthrough, s.Passthrough = false, PassthroughScreen
+	}
+	return s
+}
+
+// PassthroughFor picks the wrapping for a client whose terminal reports
+// termname. screen* maps to tmux passthrough too: tmux's default-terminal is
+// "screen", so a screen termname almost always means a nested tmux. GNU
+// screen users select PassthroughScreen explicitly.
+func PassthroughFor(termname string) Passthrough {
+	if strings.HasPrefix(termname, "tmux") || strings.HasPrefix(termname, "screen") {
+		return PassthroughTmux
+	}
+	return PassthroughNone
+}
@@ -0,0 +1,167 @@
+package clipboard
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestOSC52SequenceEncodesText(t *testing.T) {
+	got, err := OSC52Sequence("hello", PassthroughNone)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "\x1b]52;c;aGVsbG8=\a"; got != want {
+		t.Fatalf("sequence = %q, want %q", got, want)
+	}
+}
+
+func TestOSC52SequenceTmuxPassthroughDoublesEscapes(t *testing.T) {
+	got, err := OSC52Sequence("hello", PassthroughTmux)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\a\x1b\\"; got != want {
+		t.Fatalf("sequence = %q, want %q", got, want)
+	}
+}
+
+func TestOSC52SequenceScreenPassthroughChunks(t *testing.T) {
+	text := strings.Repeat("x", 300)
+	got, err := OSC52Sequence(text, PassthroughScreen)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := OSC52Sequence(text, PassthroughNone)
+	chunks := strings.Split(strings.TrimSuffix(got, "\x1b\\"), "\x1b\\")
+	if want := (len(raw) + screenChunk - 1) / screenChunk; len(chunks) != want {
+		t.Fatalf("chunks = %d, want %d", len(chunks), want)
+	}
+	var joined strings.Builder
+	for _, c := range chunks {
+		if !strings.HasPrefix(c, "\x1bP") || len(c) > screenChunk+2 {
+			t.Fatalf("malformed chunk %q", c)
+		}
+		joined.WriteString(strings.TrimPrefix(c, "\x1bP"))
+	}
+	if joined.String() != raw {
+		t.Fatal("chunks do not reassemble into the raw sequence")
+	}
+}
+
+func TestOSC52SequenceRejectsOversizedText(t *testing.T) {
+	_, err := OSC52Sequence(strings.Repeat("x", OSC52MaxBytes+1), PassthroughNone)
+	if !errors.Is(err, errOSC52TooLarge) {
+		t.Fatalf("err = %v, want errOSC52TooLarge", err)
+	}
+}
+
+// recordingTTY captures every write made to a stubbed tty.
+type recordingTTY struct {
+	writes []string
+	closed bool
+}
+
+func (r *recordingTTY) Write(p []byte) (int, error) {
+	r.writes = append(r.writes, string(p))
+	return len(p), nil
+}
+
+func (r *recordingTTY) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestCopyOSC52WritesInChunks(t *testing.T) {
+	tty := &recordingTTY{}
+	var openedPath string
+	orig := openTTY
+	openTTY = func(path string) (io.WriteCloser, error) {
+		openedPath = path
+		return tty, nil
+	}
+	defer func() { openTTY = orig }()
+
+	text := strings.Repeat("y", 10000)
+	if err := CopyOSC52("/dev/pts/9", text, PassthroughNone); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := OSC52Sequence(text, PassthroughNone)
+	if openedPath != "/dev/pts/9" || !tty.closed {
+		t.Fatalf("opened %q closed=%v", openedPath, tty.closed)
+	}
+	if strings.Join(tty.writes, "") != want {
+		t.Fatal("written bytes differ from the sequence")
+	}
+	for _, w := range tty.writes {
+		if len(w) > osc52WriteChunk {
+			t.Fatalf("write of %d bytes exceeds chunk size", len(w))
+		}
+	}
+	if err := CopyOSC52("", "x", PassthroughNone); err == nil {
+		t.Fatal("expected an error without a tty")
+	}
+}
+
+func TestStrategyOrdersBackends(t *testing.T) {
+	var calls []string
+	backend := func(name string, err error) func(string) error {
+		return func(string) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	calls = nil
+	s := Strategy{Mode: OSC52Fallback, Native: backend("native", nil), OSC52: backend("osc52", nil)}
+	if err := s.Copy("x"); err != nil || strings.Join(calls, ",") != "native" {
+		t.Fatalf("fallback with working native: calls=%v err=%v", calls, err)
+	}
+
+	calls = nil
+	s.Native = backend("native", errBoom)
+	if err := s.Copy("x"); err != nil || strings.Join(calls, ",") != "native,osc52" {
+		t.Fatalf("fallback with failing native: calls=%v err=%v", calls, err)
+	}
+
+	calls = nil
+	s = Strategy{Mode: OSC52Primary, Native: backend("native", nil), OSC52: backend("osc52", nil)}
+	if err := s.Copy("x"); err != nil || strings.Join(calls, ",") != "osc52" {
+		t.Fatalf("primary: calls=%v err=%v", calls, err)
+	}
+
+	calls = nil
+	s = Strategy{Mode: OSC52Off, Native: backend("native", errBoom), OSC52: backend("osc52", nil)}
+	if err := s.Copy("x"); !errors.Is(err, errBoom) || strings.Join(calls, ",") != "native" {
+		t.Fatalf("off: calls=%v err=%v", calls, err)
+	}
+}
+
+func TestResolveOSC52Settings(t *testing.T) {
+	env := map[string]string{envOSC52: "primary"}
+	opts := map[string]string{optOSC52: "off", optOSC52Passthru: "screen"}
+	s := ResolveOSC52Settings(func(k string) string { return env[k] }, func(k string) string { return opts[k] })
+	if s.Mode != OSC52Primary || s.AutoPassthrough || s.Passthrough != PassthroughScreen {
+		t.Fatalf("unexpected settings %#v", s)
+	}
+
+	s = ResolveOSC52Settings(func(string) string { return "" }, func(string) string { return "" })
+	if s.Mode != OSC52Fallback || !s.AutoPassthrough {
+		t.Fatalf("unexpected defaults %#v", s)
+	}
+}
+
+func TestPassthroughFor(t *testing.T) {
+	cases := map[string]Passthrough{
+		"xterm-256color":  PassthroughNone,
+		"tmux-256color":   PassthroughTmux,
+		"screen-256color": PassthroughTmux,
+		"alacritty":       PassthroughNone,
+	}
+	for termname, want := range cases {
+		if got := PassthroughFor(termname); got != want {
+			t.Fatalf("PassthroughFor(%q) = %v, want %v", termname, got, want)
+		}
+	}
+}
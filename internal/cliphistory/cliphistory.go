@@ -0,0 +1,320 @@
+// Package cliphistory persists a bounded history of copied text in the
+// session storage directory, so copies outlive both the popup and the tmux
+// server. it is consumer-agnostic: no tmux, bubbletea, or menu imports.
+package cliphistory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/statefile"
+)
+
+// Sources recorded alongside each entry.
+const (
+	SourceExtract = "extract"
+	SourceBuffer  = "buffer"
+)
+
+// Entry is one remembered copy. ID is derived from the text, so copying the
+// same text twice refreshes a single entry instead of adding a duplicate.
+type Entry struct {
+	ID      string    `json:"id"`
+	Text    string    `json:"text"`
+	Source  string    `json:"source"`
+	Created time.Time `json:"created"`
+	Pinned  bool      `json:"pinned,omitempty"`
+}
+
+// Limits bound the unpinned part of the history. Pinned entries are never
+// pruned. A zero field disables that limit.
+type Limits struct {
+	MaxEntries int
+	MaxBytes   int
+	MaxAge     time.Duration
+}
+
+// DefaultLimits applies when no option overrides them.
+var DefaultLimits = Limits{
+	MaxEntries: 200,
+	MaxBytes:   4 << 20,
+	MaxAge:     30 * 24 * time.Hour,
+}
+
+// historyFile is the on-disk layout. BufferMark is the creation time of the
+// newest tmux buffer imported so far, so polling only picks up new buffers.
+type historyFile struct {
+	Entries    []Entry   `json:"entries"`
+	BufferMark time.Time `json:"buffer_mark,omitzero"`
+}
+
+// nowFn is stubbed in tests to make pruning deterministic.
+var nowFn = time.Now
+
+func historyPath(dir string) string {
+	return filepath.Join(dir, ".clipboard-history")
+}
+
+func historyLockPath(dir string) string {
+	return filepath.Join(dir, ".clipboard-history.lock")
+}
+
+// EntryID returns the stable identifier for text.
+func EntryID(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Load returns the stored entries, pinned first, then newest first. A missing
+// history file is an empty history.
+func Load(dir string) ([]Entry, error) {
+	hf, err := readHistory(dir)
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(hf.Entries)
+	return hf.Entries, nil
+}
+
+// Find returns the entry with id.
+func Find(dir, id string) (Entry, bool, error) {
+	hf, err := readHistory(dir)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	for _, e := range hf.Entries {
+		if e.ID == id {
+			return e, true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// Record adds text to the history, or moves an existing identical entry to
+// the top, then prunes to limits. Blank text is ignored.
+func Record(dir, text, source string, limits Limits) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	if limits.MaxBytes > 0 && len(text) > limits.MaxBytes {
+		return fmt.Errorf("clipboard history: entry of %d bytes exceeds the %d byte limit", len(text), limits.MaxBytes)
+	}
+	return update(dir, func(hf *historyFile) {
+		now := nowFn()
+		id := EntryID(text)
+		pinned := false
+		hf.Entries = slices.DeleteFunc(hf.Entries, func(e Entry) bool {
+			if e.ID == id {
+				pinned = e.Pinned
+				return true
+			}
+			return false
+		})
+		hf.Entries = append([]Entry{{ID: id, Text: text, Source: source, Created: now, Pinned: pinned}}, hf.Entries...)
+		sortEntries(hf.Entries)
+		hf.Entries = Prune(hf.Entries, limits, now)
+	})
+}
+
+// ImportedBuffer is a tmux paste buffer offered to ImportBuffers.
+type ImportedBuffer struct {
+	Created time.Time
+	Text    string
+}
+
+// ImportBuffers records buffers created at or after the stored buffer mark
+// that are not already in the history, then advances the mark. Unlike Record
+// it never reorders existing entries, so repeated polls are idempotent.
+func ImportBuffers(dir string, buffers []ImportedBuffer, limits Limits) (int, error) {
+	added := 0
+	err := update(dir, func(hf *historyFile) {
+		known := make(map[string]bool, len(hf.Entries))
+		for _, e := range hf.Entries {
+			known[e.ID] = true
+		}
+		mark := hf.BufferMark
+		for _, buf := range buffers {
+			if buf.Created.Before(hf.BufferMark) {
+				continue
+			}
+			if buf.Created.After(mark) {
+				mark = buf.Created
+			}
+			id := EntryID(buf.Text)
+			if known[id] || strings.TrimSpace(buf.Text) == "" {
+				continue
+			}
+			if limits.MaxBytes > 0 && len(buf.Text) > limits.MaxBytes {
+				continue
+			}
+			known[id] = true
+			hf.Entries = append(hf.Entries, Entry{ID: id, Text: buf.Text, Source: SourceBuffer, Created: buf.Created})
+			added++
+		}
+		hf.BufferMark = mark
+		sortEntries(hf.Entries)
+		hf.Entries = Prune(hf.Entries, limits, nowFn())
+	})
+	return added, err
+}
+
+// BufferMark returns the creation time of the newest imported tmux buffer,
+// so pollers can skip fetching the content of buffers already seen.
+func BufferMark(dir string) (time.Time, error) {
+	hf, err := readHistory(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return hf.BufferMark, nil
+}
+
+// SetPinned pins or unpins the entry with id.
+func SetPinned(dir, id string, pinned bool) error {
+	found := false
+	err := update(dir, func(hf *historyFile) {
+		for i := range hf.Entries {
+			if hf.Entries[i].ID == id {
+				hf.Entries[i].Pinned = pinned
+				found = true
+			}
+		}
+	})
+	if err == nil && !found {
+		return fmt.Errorf("clipboard history: no entry %s", id)
+	}
+	return err
+}
+
+// Delete removes the entries with the given ids.
+func Delete(dir string, ids []string) error {
+	return update(dir, func(hf *historyFile) {
+		hf.Entries = slices.DeleteFunc(hf.Entries, func(e Entry) bool {
+			return slices.Contains(ids, e.ID)
+		})
+	})
+}
+
+// Prune drops unpinned entries older than MaxAge, then the oldest unpinned
+// entries until both MaxEntries and MaxBytes hold. entries must be newest
+// first within each pinned/unpinned group; the result keeps that order.
+func Prune(entries []Entry, limits Limits, now time.Time) []Entry {
+	kept := make([]Entry, 0, len(entries))
+	count, size := 0, 0
+	full := false
+	for _, e := range entries {
+		if e.Pinned {
+			kept = append(kept, e)
+			continue
+		}
+		if full || (limits.MaxAge > 0 && now.Sub(e.Created) > limits.MaxAge) {
+			continue
+		}
+		if (limits.MaxEntries > 0 && count >= limits.MaxEntries) ||
+			(limits.MaxBytes > 0 && size+len(e.Text) > limits.MaxBytes) {
+			full = true
+			continue
+		}
+		count++
+		size += len(e.Text)
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// sortEntries orders pinned entries first, then newest first.
+func sortEntries(entries []Entry) {
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		if a.Pinned != b.Pinned {
+			if a.Pinned {
+				return -1
+			}
+			return 1
+		}
+		return b.Created.Compare(a.Created)
+	})
+}
+
+func readHistory(dir string) (historyFile, error) {
+	return statefile.Load[historyFile](historyPath(dir), "clipboard history")
+}
+
+// update applies fn to the stored history; the popup and a polling
+// status-line job both write through it.
+func update(dir string, fn func(*historyFile)) error {
+	return statefile.Update(historyLockPath(dir), historyPath(dir), "clipboard history", fn)
+}
+
+// Settings configure recording and polling.
+type Settings struct {
+	Enabled     bool
+	PollBuffers bool
+	Limits      Limits
+}
+
+const (
+	envEnabled    = "TMUX_POPUP_CONTROL_CLIPBOARD_HISTORY"
+	envPoll       = "TMUX_POPUP_CONTROL_CLIPBOARD_HISTORY_POLL"
+	envMaxEntries = "TMUX_POPUP_CONTROL_CLIPBOARD_HISTORY_MAX"
+	envMaxBytes   = "TMUX_POPUP_CONTROL_CLIPBOARD_HISTORY_MAX_BYTES"
+	envMaxAgeDays = "TMUX_POPUP_CONTROL_CLIPBOARD_HISTORY_MAX_AGE_DAYS"
+	optEnabled    = "@tmux-popup-control-clipboard-history"
+	optPoll       = "@tmux-popup-control-clipboard-history-poll"
+	optMaxEntries = "@tmux-popup-control-clipboard-history-max"
+	optMaxBytes   = "@tmux-popup-control-clipboard-history-max-bytes"
+	optMaxAgeDays = "@tmux-popup-control-clipboard-history-max-age-days"
+)
+
+// ResolveSettings reads each setting from the environment first, then the
+// tmux option, then the default.
+func ResolveSettings(getenv, option func(string) string) Settings {
+	lookup := func(envKey, optKey string) string {
+		if v := strings.TrimSpace(getenv(envKey)); v != "" {
+			return v
+		}
+		return strings.TrimSpace(option(optKey))
+	}
+	s := Settings{Enabled: true, Limits: DefaultLimits}
+	if v := lookup(envEnabled, optEnabled); v != "" {
+		s.Enabled = parseBool(v)
+	}
+	if v := lookup(envPoll, optPoll); v != "" {
+		s.PollBuffers = parseBool(v)
+	}
+	if n, ok := parseNonNegative(lookup(envMaxEntries, optMaxEntries)); ok {
+		s.Limits.MaxEntries = n
+	}
+	if n, ok := parseNonNegative(lookup(envMaxBytes, optMaxBytes)); ok {
+		s.Limits.MaxBytes = n
+	}
+	if n, ok := parseNonNegative(lookup(envMaxAgeDays, optMaxAgeDays)); ok {
+		s.Limits.MaxAge = time.Duration(n) * 24 * time.Hour
+	}
+	return s
+}
+
+func parseBool(s string) bool {
+	switch strings.ToLower(s) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseNonNegative accepts an integer >= 0; 0 disables the limit.
+func parseNonNegative(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
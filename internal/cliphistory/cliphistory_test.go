@@ -0,0 +1,168 @@
+package cliphistory
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withNow(t *testing.T, now time.Time) {
+	t.Helper()
+	orig := nowFn
+	nowFn = func() time.Time { return now }
+	t.Cleanup(func() { nowFn = orig })
+}
+
+func texts(entries []Entry) string {
+	parts := make([]string, len(entries))
+	for i, e := range entries {
+		parts[i] = e.Text
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestRecordDedupesAndOrdersNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, text := range []string{"one", "two", "one"} {
+		withNow(t, base.Add(time.Duration(i)*time.Minute))
+		if err := Record(dir, text, SourceExtract, DefaultLimits); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Record(dir, "   ", SourceExtract, DefaultLimits); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(entries); got != "one,two" {
+		t.Fatalf("entries = %s, want one,two", got)
+	}
+	info, err := os.Stat(historyPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("history mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestPinnedSurvivePruningAndSortFirst(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limits := Limits{MaxEntries: 2}
+	withNow(t, base)
+	if err := Record(dir, "keep", SourceExtract, limits); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetPinned(dir, EntryID("keep"), true); err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range []string{"a", "b", "c"} {
+		withNow(t, base.Add(time.Duration(i+1)*time.Minute))
+		if err := Record(dir, text, SourceExtract, limits); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(entries); got != "keep,c,b" {
+		t.Fatalf("entries = %s, want keep,c,b", got)
+	}
+	// re-recording a pinned entry keeps the pin
+	if err := Record(dir, "keep", SourceExtract, limits); err != nil {
+		t.Fatal(err)
+	}
+	entry, ok, err := Find(dir, EntryID("keep"))
+	if err != nil || !ok || !entry.Pinned {
+		t.Fatalf("expected keep to stay pinned, got %#v %v %v", entry, ok, err)
+	}
+	if err := SetPinned(dir, "missing", true); err == nil {
+		t.Fatal("expected error pinning an unknown entry")
+	}
+}
+
+func TestPruneAgeAndBytes(t *testing.T) {
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Text: "newest", Created: now},
+		{Text: "middle-entry", Created: now.Add(-time.Hour)},
+		{Text: "old", Created: now.Add(-48 * time.Hour)},
+		{Text: "pinned-and-ancient", Created: now.Add(-1000 * time.Hour), Pinned: true},
+	}
+	got := Prune(entries, Limits{MaxAge: 24 * time.Hour}, now)
+	if texts(got) != "newest,middle-entry,pinned-and-ancient" {
+		t.Fatalf("age prune = %s", texts(got))
+	}
+	got = Prune(entries, Limits{MaxBytes: 10}, now)
+	if texts(got) != "newest,pinned-and-ancient" {
+		t.Fatalf("byte prune = %s", texts(got))
+	}
+}
+
+func TestRecordRejectsOversizedEntry(t *testing.T) {
+	if err := Record(t.TempDir(), "too long", SourceExtract, Limits{MaxBytes: 3}); err == nil {
+		t.Fatal("expected oversized entry to be rejected")
+	}
+}
+
+func TestImportBuffersOnlyTakesNewBuffers(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	withNow(t, t0.Add(time.Hour))
+	added, err := ImportBuffers(dir, []ImportedBuffer{
+		{Created: t0, Text: "first"},
+		{Created: t0.Add(time.Minute), Text: "second"},
+	}, DefaultLimits)
+	if err != nil || added != 2 {
+		t.Fatalf("first import: added=%d err=%v", added, err)
+	}
+	if err := Delete(dir, []string{EntryID("first")}); err != nil {
+		t.Fatal(err)
+	}
+	added, err = ImportBuffers(dir, []ImportedBuffer{
+		{Created: t0, Text: "first"},
+		{Created: t0.Add(time.Minute), Text: "second"},
+		{Created: t0.Add(2 * time.Minute), Text: "third"},
+	}, DefaultLimits)
+	if err != nil || added != 1 {
+		t.Fatalf("second import: added=%d err=%v", added, err)
+	}
+	entries, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := texts(entries); got != "third,second" {
+		t.Fatalf("entries = %s, want third,second (deleted buffer must not return)", got)
+	}
+	if entries[0].Source != SourceBuffer {
+		t.Fatalf("source = %q", entries[0].Source)
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	env := map[string]string{envMaxEntries: "50"}
+	opts := map[string]string{
+		optMaxEntries: "10",
+		optPoll:       "on",
+		optMaxAgeDays: "0",
+		optEnabled:    "off",
+	}
+	s := ResolveSettings(func(k string) string { return env[k] }, func(k string) string { return opts[k] })
+	if s.Enabled || !s.PollBuffers {
+		t.Fatalf("unexpected switches %#v", s)
+	}
+	if s.Limits.MaxEntries != 50 || s.Limits.MaxAge != 0 || s.Limits.MaxBytes != DefaultLimits.MaxBytes {
+		t.Fatalf("unexpected limits %#v", s.Limits)
+	}
+
+	s = ResolveSettings(func(string) string { return "" }, func(string) string { return "" })
+	if !s.Enabled || s.PollBuffers || s.Limits != DefaultLimits {
+		t.Fatalf("unexpected defaults %#v", s)
+	}
+}
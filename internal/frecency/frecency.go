@@ -0,0 +1,151 @@
+// Package frecency remembers how often and how recently menu items are
+// picked, keyed by registry node ID plus item ID, so switch menus can rank
+// the session used twenty times a day above the one used once last month.
+// it is consumer-agnostic: no tmux, bubbletea, or menu imports.
+package frecency
+
+import (
+	"cmp"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/statefile"
+)
+
+// HalfLife is how long a pick takes to lose half its weight.
+const HalfLife = 72 * time.Hour
+
+// minScore is the decayed score below which a record is forgotten: a single
+// pick lasts a little over two weeks.
+const minScore = 0.02
+
+// maxPerNode caps the records kept per node; the lowest scores go first.
+const maxPerNode = 256
+
+// Record is the usage of one item: Score is the decayed pick count as of
+// Last, and At decays it to any later instant.
+type Record struct {
+	Score float64   `json:"score"`
+	Last  time.Time `json:"last"`
+}
+
+// At returns the record's score decayed to now.
+func (r Record) At(now time.Time) float64 {
+	elapsed := now.Sub(r.Last)
+	if elapsed <= 0 {
+		return r.Score
+	}
+	return r.Score * math.Exp2(-float64(elapsed)/float64(HalfLife))
+}
+
+// Store holds every node's records.
+type Store struct {
+	Nodes map[string]map[string]Record `json:"nodes"`
+}
+
+// Bump counts one pick of item under node at now.
+func (s *Store) Bump(node, item string, now time.Time) {
+	if s.Nodes == nil {
+		s.Nodes = make(map[string]map[string]Record)
+	}
+	records := s.Nodes[node]
+	if records == nil {
+		records = make(map[string]Record)
+		s.Nodes[node] = records
+	}
+	records[item] = Record{Score: records[item].At(now) + 1, Last: now}
+}
+
+// Scores returns the decayed score of every remembered item under node.
+func (s Store) Scores(node string, now time.Time) map[string]float64 {
+	records := s.Nodes[node]
+	if len(records) == 0 {
+		return nil
+	}
+	scores := make(map[string]float64, len(records))
+	for item, r := range records {
+		scores[item] = r.At(now)
+	}
+	return scores
+}
+
+// prune forgets records that decayed below minScore and caps each node at
+// maxPerNode.
+func (s *Store) prune(now time.Time) {
+	for node, records := range s.Nodes {
+		for item, r := range records {
+			if r.At(now) < minScore {
+				delete(records, item)
+			}
+		}
+		if len(records) > maxPerNode {
+			items := make([]string, 0, len(records))
+			for item := range records {
+				items = append(items, item)
+			}
+			slices.SortFunc(items, func(a, b string) int {
+				return cmp.Compare(records[b].At(now), records[a].At(now))
+			})
+			for _, item := range items[maxPerNode:] {
+				delete(records, item)
+			}
+		}
+		if len(records) == 0 {
+			delete(s.Nodes, node)
+		}
+	}
+}
+
+func storePath(dir string) string {
+	return filepath.Join(dir, ".frecency")
+}
+
+func storeLockPath(dir string) string {
+	return filepath.Join(dir, ".frecency.lock")
+}
+
+// Load reads the store from dir. A missing file is an empty store.
+func Load(dir string) (Store, error) {
+	return statefile.Load[Store](storePath(dir), "frecency")
+}
+
+// Pick counts one pick of each item under node and saves the store.
+func Pick(dir, node string, items []string, now time.Time) error {
+	return statefile.Update(storeLockPath(dir), storePath(dir), "frecency", func(s *Store) {
+		for _, item := range items {
+			s.Bump(node, item, now)
+		}
+		s.prune(now)
+	})
+}
+
+// SortMode orders the items of switch menus.
+type SortMode int
+
+const (
+	// SortTmux keeps tmux's own order (the default).
+	SortTmux SortMode = iota
+	// SortFrecency puts the most frecent items first.
+	SortFrecency
+)
+
+const (
+	envSort = "TMUX_POPUP_CONTROL_SORT"
+	optSort = "@tmux-popup-control-sort"
+)
+
+// ResolveSortMode reads the sort mode from the environment first, then the
+// tmux option.
+func ResolveSortMode(getenv, option func(string) string) SortMode {
+	v := strings.TrimSpace(getenv(envSort))
+	if v == "" {
+		v = strings.TrimSpace(option(optSort))
+	}
+	if strings.EqualFold(v, "frecency") {
+		return SortFrecency
+	}
+	return SortTmux
+}
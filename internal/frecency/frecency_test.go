@@ -0,0 +1,115 @@
+package frecency
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRecordDecaysByHalfLife(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := Record{Score: 4, Last: now}
+	if got := r.At(now.Add(-time.Hour)); got != 4 {
+		t.Fatalf("score before Last = %v, want 4", got)
+	}
+	if got := r.At(now.Add(2 * HalfLife)); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("score after two half-lives = %v, want 1", got)
+	}
+}
+
+func TestBumpAddsToDecayedScore(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var s Store
+	s.Bump("session:switch", "work", now)
+	s.Bump("session:switch", "work", now.Add(HalfLife))
+	s.Bump("session:switch", "play", now.Add(HalfLife))
+	scores := s.Scores("session:switch", now.Add(HalfLife))
+	if math.Abs(scores["work"]-1.5) > 1e-9 || scores["play"] != 1 {
+		t.Fatalf("unexpected scores %v", scores)
+	}
+	if s.Scores("window:switch", now) != nil {
+		t.Fatal("expected no scores for an unused node")
+	}
+}
+
+func TestPickPersistsAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := Pick(dir, "pane:switch", []string{"stale"}, now); err != nil {
+		t.Fatal(err)
+	}
+	later := now.Add(30 * 24 * time.Hour)
+	if err := Pick(dir, "pane:switch", []string{"%1", "%2"}, later); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := s.Scores("pane:switch", later)
+	if len(scores) != 2 || scores["%1"] != 1 || scores["%2"] != 1 {
+		t.Fatalf("expected stale record pruned, got %v", scores)
+	}
+}
+
+func TestPruneCapsRecordsPerNode(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var s Store
+	for i := range maxPerNode + 10 {
+		s.Bump("command", fmt.Sprint(i), now.Add(time.Duration(i)*time.Minute))
+	}
+	end := now.Add(time.Duration(maxPerNode+10) * time.Minute)
+	s.prune(end)
+	scores := s.Scores("command", end)
+	if len(scores) != maxPerNode {
+		t.Fatalf("kept %d records, want %d", len(scores), maxPerNode)
+	}
+	if _, ok := scores["0"]; ok {
+		t.Fatal("expected the oldest record dropped first")
+	}
+}
+
+func TestLoadMissingFileIsEmpty(t *testing.T) {
+	s, err := Load(t.TempDir())
+	if err != nil || len(s.Nodes) != 0 {
+		t.Fatalf("got %#v %v", s, err)
+	}
+}
+
+func TestResolveSortMode(t *testing.T) {
+	none := func(string) string { return "" }
+	env := func(k string) string {
+		if k == envSort {
+			return "Frecency"
+		}
+		return ""
+	}
+	opt := func(k string) string {
+		if k == optSort {
+			return "frecency"
+		}
+		return ""
+	}
+	tmuxEnv := func(k string) string {
+		if k == envSort {
+			return "tmux"
+		}
+		return ""
+	}
+	cases := []struct {
+		name        string
+		getenv, opt func(string) string
+		want        SortMode
+	}{
+		{"default", none, none, SortTmux},
+		{"env", env, none, SortFrecency},
+		{"option", none, opt, SortFrecency},
+		{"env wins", tmuxEnv, opt, SortTmux},
+	}
+	for _, tc := range cases {
+		if got := ResolveSortMode(tc.getenv, tc.opt); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
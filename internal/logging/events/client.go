@@ -0,0 +1,11 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type ClientTracer struct{}
+
+var Client = ClientTracer{}
+
+func (ClientTracer) Action(op string, clients []string) {
+	logging.Trace("client.action", map[string]any{"op": op, "clients": clients})
+}
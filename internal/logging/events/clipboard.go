@@ -0,0 +1,73 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type ClipboardTracer struct{}
+
+var Clipboard = ClipboardTracer{}
+
+func (ClipboardTracer) BufferPaste(name, target string) {
+	logging.Trace("clipboard.buffer.paste", map[string]any{"name": name, "target": target})
+}
+
+func (ClipboardTracer) BufferDelete(names []string) {
+	logging.Trace("clipboard.buffer.delete", map[string]any{"names": names})
+}
+
+func (ClipboardTracer) BufferPrompt(action, name string) {
+	logging.Trace("clipboard.buffer.prompt", map[string]any{"action": action, "name": name})
+}
+
+func (ClipboardTracer) BufferRename(name, newName string) {
+	logging.Trace("clipboard.buffer.rename", map[string]any{"name": name, "new_name": newName})
+}
+
+func (ClipboardTracer) BufferSave(name, path string) {
+	logging.Trace("clipboard.buffer.save", map[string]any{"name": name, "path": path})
+}
+
+func (ClipboardTracer) BufferLoad(name, path string) {
+	logging.Trace("clipboard.buffer.load", map[string]any{"name": name, "path": path})
+}
+
+func (ClipboardTracer) BufferEdit(name, editor string) {
+	logging.Trace("clipboard.buffer.edit", map[string]any{"name": name, "editor": editor})
+}
+
+func (ClipboardTracer) HistoryRecord(source string, size int) {
+	logging.Trace("clipboard.history.record", map[string]any{"source": source, "size": size})
+}
+
+func (ClipboardTracer) HistoryPoll(added int) {
+	logging.Trace("clipboard.history.poll", map[string]any{"added": added})
+}
+
+func (ClipboardTracer) HistoryInsert(id, target string) {
+	logging.Trace("clipboard.history.insert", map[string]any{"id": id, "target": target})
+}
+
+func (ClipboardTracer) HistoryCopy(id string) {
+	logging.Trace("clipboard.history.copy", map[string]any{"id": id})
+}
+
+func (ClipboardTracer) HistoryPin(id string, pinned bool) {
+	logging.Trace("clipboard.history.pin", map[string]any{"id": id, "pinned": pinned})
+}
+
+func (ClipboardTracer) HistoryDelete(ids []string) {
+	logging.Trace("clipboard.history.delete", map[string]any{"ids": ids})
+}
+
+func (ClipboardTracer) SystemPaste(target string, size int, bracketed, stripNewline bool) {
+	logging.Trace("clipboard.system.paste", map[string]any{
+		"target": target, "size": size, "bracketed": bracketed, "strip_newline": stripNewline,
+	})
+}
+
+func (ClipboardTracer) OSC52(tty string, size int, passthrough string) {
+	logging.Trace("clipboard.osc52.write", map[string]any{"tty": tty, "size": size, "passthrough": passthrough})
+}
+
+func (ClipboardTracer) OSC52Forward(size int) {
+	logging.Trace("clipboard.osc52.forward", map[string]any{"size": size})
+}
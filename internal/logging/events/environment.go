@@ -0,0 +1,11 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type EnvironmentTracer struct{}
+
+var Environment = EnvironmentTracer{}
+
+func (EnvironmentTracer) Change(op, id string) {
+	logging.Trace("environment.change", map[string]any{"op": op, "id": id})
+}
@@ -72,3 +72,39 @@ func (PaneTracer) CaptureCancel(reason paneReason) {
 func (PaneTracer) CaptureSubmit(filePath string) {
 	logging.Trace("pane.capture.submit", map[string]any{"file": filePath})
 }
+
+func (PaneTracer) Broadcast(targets []string, keys bool, failed int) {
+	logging.Trace("pane.broadcast", map[string]any{"targets": targets, "keys": keys, "failed": failed})
+}
+
+func (PaneTracer) LogStart(targets []string, file string, strip bool, failed int) {
+	logging.Trace("pane.log.start", map[string]any{"targets": targets, "file": file, "strip": strip, "failed": failed})
+}
+
+func (PaneTracer) LogStop(targets []string) {
+	logging.Trace("pane.log.stop", map[string]any{"targets": targets})
+}
+
+func (PaneTracer) ResizeMode(target string) {
+	logging.Trace("pane.resize.mode", map[string]any{"target": target})
+}
+
+func (PaneTracer) Equalize(target, layout string) {
+	logging.Trace("pane.resize.equalize", map[string]any{"target": target, "layout": layout})
+}
+
+func (PaneTracer) Zoom(target string) {
+	logging.Trace("pane.resize.zoom", map[string]any{"target": target})
+}
+
+func (PaneTracer) Split(target, placement string, percent int) {
+	logging.Trace("pane.split", map[string]any{"target": target, "placement": placement, "percent": percent})
+}
+
+func (PaneTracer) Respawn(target string, kill bool) {
+	logging.Trace("pane.respawn", map[string]any{"target": target, "kill": kill})
+}
+
+func (PaneTracer) HistoryError(err error) {
+	logging.Trace("pane.spawn.history.error", map[string]any{"error": err.Error()})
+}
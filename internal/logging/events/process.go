@@ -0,0 +1,19 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type ProcessTracer struct{}
+
+var Process = ProcessTracer{}
+
+func (ProcessTracer) Display(pid int) {
+	logging.Trace("process.display", map[string]any{"pid": pid})
+}
+
+func (ProcessTracer) Switch(pid int, pane string) {
+	logging.Trace("process.switch", map[string]any{"pid": pid, "pane": pane})
+}
+
+func (ProcessTracer) Signal(pids []int, signal string) {
+	logging.Trace("process.signal", map[string]any{"pids": pids, "signal": signal})
+}
@@ -0,0 +1,19 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type SearchTracer struct{}
+
+var Search = SearchTracer{}
+
+func (SearchTracer) Run(scope, query string, regex bool, panes, hits int) {
+	logging.Trace("search.run", map[string]any{"scope": scope, "query": query, "regex": regex, "panes": panes, "hits": hits})
+}
+
+func (SearchTracer) Error(pane string, err error) {
+	logging.Trace("search.error", map[string]any{"pane": pane, "error": err.Error()})
+}
+
+func (SearchTracer) Jump(pane string, line, scroll int) {
+	logging.Trace("search.jump", map[string]any{"pane": pane, "line": line, "scroll": scroll})
+}
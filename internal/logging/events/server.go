@@ -0,0 +1,19 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type ServerTracer struct{}
+
+var Server = ServerTracer{}
+
+func (ServerTracer) Reload(files []string, failed int) {
+	logging.Trace("server.reload", map[string]any{"files": files, "failed": failed})
+}
+
+func (ServerTracer) Toggle(option, value string) {
+	logging.Trace("server.toggle", map[string]any{"option": option, "value": value})
+}
+
+func (ServerTracer) Kill(socket string) {
+	logging.Trace("server.kill", map[string]any{"socket": socket})
+}
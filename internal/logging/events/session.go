@@ -46,6 +46,12 @@ func (SessionTracer) Create(name string) {
 	logging.Trace("session.new.create", map[string]any{"name": name})
 }
 
+// CreateFrom records a session created from another one, grouped with it
+// or cloned from it.
+func (SessionTracer) CreateFrom(action, source, name string) {
+	logging.Trace("session.new.from", map[string]any{"action": action, "source": source, "name": name})
+}
+
 // Rename records a session rename from target to name.
 func (SessionTracer) Rename(target, name string) {
 	logging.Trace("session.rename", map[string]any{"target": target, "name": name})
@@ -70,3 +76,41 @@ func (SessionTracer) SubmitRename(target, name string) {
 func (SessionTracer) SubmitNew(name string) {
 	logging.Trace("session.new.submit", map[string]any{"name": name})
 }
+
+// TemplatePrompt records that the template form was opened for id.
+func (SessionTracer) TemplatePrompt(id string) {
+	logging.Trace("session.template.prompt", map[string]any{"template": id})
+}
+
+// TemplateCreate records a session built from template id.
+func (SessionTracer) TemplateCreate(id, name, root string) {
+	logging.Trace("session.template.create", map[string]any{"template": id, "name": name, "root": root})
+}
+
+// TemplateLoadError records templates that failed to load.
+func (SessionTracer) TemplateLoadError(err error) {
+	logging.Trace("session.template.load_error", map[string]any{"error": err.Error()})
+}
+
+// ImportStart records that an imported project file was opened in the
+// template form.
+func (SessionTracer) ImportStart(path string) {
+	logging.Trace("session.import.start", map[string]any{"path": path})
+}
+
+// ImportConvert records project files converted into templates.
+func (SessionTracer) ImportConvert(paths []string) {
+	logging.Trace("session.import.convert", map[string]any{"paths": paths})
+}
+
+// OpenProject records a project directory being opened, either in an
+// existing session or a new one.
+func (SessionTracer) OpenProject(dir, name string, created bool) {
+	logging.Trace("session.project.open", map[string]any{"dir": dir, "session": name, "created": created})
+}
+
+// ProjectLoadError records a project root or zoxide database that could not
+// be read.
+func (SessionTracer) ProjectLoadError(err error) {
+	logging.Trace("session.project.load_error", map[string]any{"error": err.Error()})
+}
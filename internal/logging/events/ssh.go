@@ -0,0 +1,15 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type SSHTracer struct{}
+
+var SSH = SSHTracer{}
+
+func (SSHTracer) Open(hosts []string, as string) {
+	logging.Trace("ssh.open", map[string]any{"hosts": hosts, "as": as})
+}
+
+func (SSHTracer) LoadError(err error) {
+	logging.Trace("ssh.load_error", map[string]any{"error": err.Error()})
+}
@@ -0,0 +1,15 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type TaskTracer struct{}
+
+var Task = TaskTracer{}
+
+func (TaskTracer) Run(dir, task, place string) {
+	logging.Trace("task.run", map[string]any{"dir": dir, "task": task, "place": place})
+}
+
+func (TaskTracer) Error(dir string, err error) {
+	logging.Trace("task.error", map[string]any{"dir": dir, "error": err.Error()})
+}
@@ -0,0 +1,23 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type TrashTracer struct{}
+
+var Trash = TrashTracer{}
+
+func (TrashTracer) Capture(kind string, targets []string) {
+	logging.Trace("trash.capture", map[string]any{"kind": kind, "targets": targets})
+}
+
+func (TrashTracer) CaptureError(kind string, targets []string, err error) {
+	logging.Trace("trash.capture_error", map[string]any{"kind": kind, "targets": targets, "error": err.Error()})
+}
+
+func (TrashTracer) Restore(path string, newSession bool) {
+	logging.Trace("trash.restore", map[string]any{"path": path, "new_session": newSession})
+}
+
+func (TrashTracer) Delete(paths []string) {
+	logging.Trace("trash.delete", map[string]any{"paths": paths})
+}
@@ -0,0 +1,15 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type UndoTracer struct{}
+
+var Undo = UndoTracer{}
+
+func (UndoTracer) Record(action, label string) {
+	logging.Trace("undo.record", map[string]any{"action": action, "label": label})
+}
+
+func (UndoTracer) Replay(action, label string) {
+	logging.Trace("undo.replay", map[string]any{"action": action, "label": label})
+}
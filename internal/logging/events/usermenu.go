@@ -0,0 +1,19 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type UserMenuTracer struct{}
+
+var UserMenu = UserMenuTracer{}
+
+func (UserMenuTracer) Load(menus int) {
+	logging.Trace("usermenu.load", map[string]any{"menus": menus})
+}
+
+func (UserMenuTracer) Clash(id string) {
+	logging.Trace("usermenu.clash", map[string]any{"id": id})
+}
+
+func (UserMenuTracer) Run(kind, command string) {
+	logging.Trace("usermenu.run", map[string]any{"kind": kind, "command": command})
+}
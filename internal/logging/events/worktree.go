@@ -0,0 +1,19 @@
+package events
+
+import "github.com/atomicstack/tmux-popup-control/internal/logging"
+
+type WorktreeTracer struct{}
+
+var Worktree = WorktreeTracer{}
+
+func (WorktreeTracer) Open(path, as string, created bool) {
+	logging.Trace("worktree.open", map[string]any{"path": path, "as": as, "created": created})
+}
+
+func (WorktreeTracer) Add(main, branch, path string) {
+	logging.Trace("worktree.add", map[string]any{"main": main, "branch": branch, "path": path})
+}
+
+func (WorktreeTracer) Remove(paths []string) {
+	logging.Trace("worktree.remove", map[string]any{"paths": paths})
+}
@@ -0,0 +1,221 @@
+package menu
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	listClientsFn  = tmux.ListClients
+	clientSwitchFn = tmux.SwitchClient
+)
+
+func loadClientMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{
+		"detach",
+		"detach-kill",
+		"switch",
+		"read-only",
+		"refresh",
+		"lock",
+	}), nil
+}
+
+// loadClientListMenu lists the attached clients as a table keyed by client
+// name, the popup's own client last so a multi-select never cuts the popup
+// off before the others are handled.
+func loadClientListMenu(ctx Context) ([]Item, error) {
+	clients, err := listClientsFn(ctx.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) == 0 {
+		return nil, nil
+	}
+	clients = popupClientLast(ctx, clients)
+	now := time.Now()
+	cells := make([][]string, 0, len(clients)+1)
+	cells = append(cells, []string{"tty", "session", "size", "term", "activity", "flags"})
+	for _, c := range clients {
+		activity := ""
+		if !c.Activity.IsZero() {
+			activity = resurrect.RelativeTime(c.Activity, now)
+		}
+		cells = append(cells, []string{
+			c.Tty,
+			c.Session,
+			fmt.Sprintf("%dx%d", c.Width, c.Height),
+			c.Termname,
+			activity,
+			clientFlags(ctx, c),
+		})
+	}
+	aligned := table.Format(cells, []table.Alignment{
+		table.AlignLeft, table.AlignLeft, table.AlignRight, table.AlignLeft, table.AlignLeft, table.AlignLeft,
+	})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: clients[i].Name, Label: label})
+	}
+	return items, nil
+}
+
+func popupClientLast(ctx Context, clients []tmux.Client) []tmux.Client {
+	own := strings.TrimSpace(ctx.ClientID)
+	out := slices.Clone(clients)
+	slices.SortStableFunc(out, func(a, b tmux.Client) int {
+		switch {
+		case a.Name == own && b.Name != own:
+			return 1
+		case b.Name == own && a.Name != own:
+			return -1
+		}
+		return 0
+	})
+	return out
+}
+
+func clientFlags(ctx Context, c tmux.Client) string {
+	var flags []string
+	if c.Name == strings.TrimSpace(ctx.ClientID) {
+		flags = append(flags, "this popup")
+	}
+	if c.ReadOnly {
+		flags = append(flags, "read-only")
+	}
+	if c.IgnoreSize {
+		flags = append(flags, "ignore-size")
+	}
+	return strings.Join(flags, ", ")
+}
+
+// ClientPaneID returns the active pane of the client's current window, for
+// the preview capture.
+func ClientPaneID(ctx Context, client string) (string, error) {
+	out, err := tmuxOutput(ctx.SocketPath, "display-message", "-c", client, "-p", "#{pane_id}")
+	if err != nil {
+		return "", err
+	}
+	pane := strings.TrimSpace(out)
+	if pane == "" {
+		return "", fmt.Errorf("no active pane for client %s", client)
+	}
+	return pane, nil
+}
+
+// ClientDetachAction detaches the selected clients.
+func ClientDetachAction(ctx Context, item Item) tea.Cmd {
+	return clientCommandAction(ctx, item, "detach", "Detached", "detach-client", "-t")
+}
+
+// ClientDetachKillAction detaches the selected clients and sends SIGHUP to
+// their parent processes, closing the shells or ssh sessions they ran in.
+func ClientDetachKillAction(ctx Context, item Item) tea.Cmd {
+	return clientCommandAction(ctx, item, "detach-kill", "Detached and hung up", "detach-client", "-P", "-t")
+}
+
+// ClientRefreshAction redraws the selected clients.
+func ClientRefreshAction(ctx Context, item Item) tea.Cmd {
+	return clientCommandAction(ctx, item, "refresh", "Refreshed", "refresh-client", "-t")
+}
+
+// ClientLockAction locks the selected clients with lock-command.
+func ClientLockAction(ctx Context, item Item) tea.Cmd {
+	return clientCommandAction(ctx, item, "lock", "Locked", "lock-client", "-t")
+}
+
+// clientCommandAction runs args followed by each selected client's name.
+func clientCommandAction(ctx Context, item Item, op, verb string, args ...string) tea.Cmd {
+	names := splitSelectionIDs(item.ID)
+	if len(names) == 0 {
+		return failCmd("no client selected")
+	}
+	return func() tea.Msg {
+		events.Client.Action(op, names)
+		for _, name := range names {
+			if _, err := tmuxOutput(ctx.SocketPath, append(slices.Clone(args), name)...); err != nil {
+				return ActionResult{Err: err}
+			}
+		}
+		if len(names) == 1 {
+			return ActionResult{Info: fmt.Sprintf("%s %s", verb, names[0])}
+		}
+		return ActionResult{Info: fmt.Sprintf("%s %d clients", verb, len(names))}
+	}
+}
+
+// ClientReadOnlyAction flips the read-only flag of the selected client.
+// A read-only client can only detach or switch sessions.
+func ClientReadOnlyAction(ctx Context, item Item) tea.Cmd {
+	name := strings.TrimSpace(item.ID)
+	if name == "" {
+		return failCmd("no client selected")
+	}
+	return func() tea.Msg {
+		clients, err := listClientsFn(ctx.SocketPath)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		i := slices.IndexFunc(clients, func(c tmux.Client) bool { return c.Name == name })
+		if i < 0 {
+			return ActionResult{Err: fmt.Errorf("client %s is no longer attached", name)}
+		}
+		flag, state := "read-only", "read-only"
+		if clients[i].ReadOnly {
+			flag, state = "!read-only", "writable"
+		}
+		events.Client.Action("read-only", []string{name})
+		if _, err := tmuxOutput(ctx.SocketPath, "refresh-client", "-t", name, "-f", flag); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Made %s %s", name, state)}
+	}
+}
+
+// ClientSwitchPrompt asks which session to move Client to.
+type ClientSwitchPrompt struct {
+	Context Context
+	Client  Item
+	Session string
+}
+
+// ClientSwitchAction picks the client to move; the session comes from the
+// follow-up list.
+func ClientSwitchAction(ctx Context, item Item) tea.Cmd {
+	name := strings.TrimSpace(item.ID)
+	if name == "" {
+		return failCmd("no client selected")
+	}
+	return func() tea.Msg {
+		clients, err := listClientsFn(ctx.SocketPath)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		i := slices.IndexFunc(clients, func(c tmux.Client) bool { return c.Name == name })
+		if i < 0 {
+			return ActionResult{Err: fmt.Errorf("client %s is no longer attached", name)}
+		}
+		return ClientSwitchPrompt{Context: ctx, Client: Item{ID: name, Label: clients[i].Tty}, Session: clients[i].Session}
+	}
+}
+
+// ClientSwitchCommand moves client to session.
+func ClientSwitchCommand(ctx Context, client Item, session Item) tea.Cmd {
+	return func() tea.Msg {
+		events.Client.Action("switch", []string{client.ID})
+		if err := clientSwitchFn(ctx.SocketPath, client.ID, session.ID); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Switched %s to %s", client.Label, session.ID)}
+	}
+}
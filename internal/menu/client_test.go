@@ -0,0 +1,87 @@
+package menu
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+func stubClients(t *testing.T) *[]string {
+	t.Helper()
+	t.Cleanup(withPaneStub(&listClientsFn, func(string) ([]tmux.Client, error) {
+		return []tmux.Client{
+			{Name: "/dev/pts/1", Tty: "/dev/pts/1", Session: "work", Termname: "xterm-256color", Width: 200, Height: 50, Activity: time.Now()},
+			{Name: "/dev/pts/3", Tty: "/dev/pts/3", Session: "ops", Termname: "tmux-256color", Width: 80, Height: 24, ReadOnly: true, IgnoreSize: true},
+		}, nil
+	}))
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		return nil, nil
+	}))
+	return &calls
+}
+
+func TestLoadClientListMenuPutsPopupClientLast(t *testing.T) {
+	stubClients(t)
+	items, err := loadClientListMenu(Context{ClientID: "/dev/pts/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[0].Header || items[1].ID != "/dev/pts/3" || items[2].ID != "/dev/pts/1" {
+		t.Fatalf("unexpected items %#v", items)
+	}
+	if !strings.Contains(items[1].Label, "read-only, ignore-size") || !strings.Contains(items[1].Label, "80x24") {
+		t.Fatalf("expected flags and size, got %q", items[1].Label)
+	}
+	if !strings.Contains(items[2].Label, "this popup") || !strings.Contains(items[2].Label, "just now") {
+		t.Fatalf("expected popup client marked, got %q", items[2].Label)
+	}
+}
+
+func TestClientActions(t *testing.T) {
+	calls := stubClients(t)
+	ctx := Context{}
+	if res := ClientDetachKillAction(ctx, Item{ID: "/dev/pts/3\n/dev/pts/1"})().(ActionResult); res.Err != nil || res.Info != "Detached and hung up 2 clients" {
+		t.Fatalf("unexpected result %#v", res)
+	}
+	if res := ClientReadOnlyAction(ctx, Item{ID: "/dev/pts/3"})().(ActionResult); res.Err != nil || res.Info != "Made /dev/pts/3 writable" {
+		t.Fatalf("unexpected result %#v", res)
+	}
+	if res := ClientReadOnlyAction(ctx, Item{ID: "/dev/pts/1"})().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if res := ClientLockAction(ctx, Item{ID: "/dev/pts/1"})().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []string{
+		"detach-client -P -t /dev/pts/3",
+		"detach-client -P -t /dev/pts/1",
+		"refresh-client -t /dev/pts/3 -f !read-only",
+		"refresh-client -t /dev/pts/1 -f read-only",
+		"lock-client -t /dev/pts/1",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestClientSwitchActionPromptsWithCurrentSession(t *testing.T) {
+	stubClients(t)
+	prompt, ok := ClientSwitchAction(Context{}, Item{ID: "/dev/pts/3"})().(ClientSwitchPrompt)
+	if !ok || prompt.Session != "ops" || prompt.Client.ID != "/dev/pts/3" {
+		t.Fatalf("unexpected prompt %#v", prompt)
+	}
+	var got [2]string
+	t.Cleanup(withPaneStub(&clientSwitchFn, func(_, client, target string) error {
+		got = [2]string{client, target}
+		return nil
+	}))
+	res := ClientSwitchCommand(Context{}, prompt.Client, Item{ID: "work"})().(ActionResult)
+	if res.Err != nil || got != [2]string{"/dev/pts/3", "work"} {
+		t.Fatalf("unexpected switch %v %#v", got, res)
+	}
+}
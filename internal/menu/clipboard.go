@@ -1,13 +1,513 @@
 package menu
 
-import "os/exec"
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/clipboard"
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	listBuffersFn   = tmux.ListBuffers
+	showBufferFn    = tmux.ShowBuffer
+	pasteBufferFn   = tmux.PasteBuffer
+	deleteBuffersFn = tmux.DeleteBuffers
+	renameBufferFn  = tmux.RenameBuffer
+	saveBufferFn    = tmux.SaveBuffer
+	loadBufferFn    = tmux.LoadBuffer
+	bufferEditorFn  = bufferEditorCommand
+	systemReadFn    = clipboard.Read
+	systemPasteFn   = tmux.PasteText
+	nativeCopyFn    = clipboard.Copy
+	osc52OptionFn   = tmux.ShowOption
+	osc52TerminalFn = tmux.ClientTerminal
+	osc52WriteFn    = clipboard.CopyOSC52
+	osc52ForwardFn  = tmux.ForwardToClipboard
+)
+
+// CopyToSystemClipboard puts text on the system clipboard, combining the
+// native tool with OSC 52 as @tmux-popup-control-clipboard-osc52 asks, so a
+// copy made on a remote box over ssh still reaches the local terminal.
+func CopyToSystemClipboard(socketPath, text string) error {
+	option := func(opt string) string { return osc52OptionFn(socketPath, opt) }
+	settings := clipboard.ResolveOSC52Settings(os.Getenv, option)
+	strategy := clipboard.Strategy{
+		Mode:   settings.Mode,
+		Native: nativeCopyFn,
+		OSC52: func(text string) error {
+			return copyOSC52(socketPath, text, settings)
+		},
+	}
+	return strategy.Copy(text)
+}
+
+// copyOSC52 writes the OSC 52 sequence straight to the originating client's
+// tty. When that is impossible (no client, or the tty belongs to another
+// user) it hands the text to tmux with set-buffer -w, which emits OSC 52
+// itself unless set-clipboard is off.
+func copyOSC52(socketPath, text string, settings clipboard.OSC52Settings) error {
+	tty, termname, err := osc52TerminalFn(socketPath)
+	if err == nil {
+		pt := settings.Passthrough
+		if settings.AutoPassthrough {
+			pt = clipboard.PassthroughFor(termname)
+		}
+		events.Clipboard.OSC52(tty, len(text), pt.String())
+		if err = osc52WriteFn(tty, text, pt); err == nil {
+			return nil
+		}
+	}
+	if osc52OptionFn(socketPath, "set-clipboard") == "off" {
+		return err
+	}
+	events.Clipboard.OSC52Forward(len(text))
+	if fwdErr := osc52ForwardFn(socketPath, text); fwdErr != nil {
+		return errors.Join(err, fwdErr)
+	}
+	return nil
+}
 
 func loadClipboardMenu(Context) ([]Item, error) {
 	items := []Item{
+		{ID: "system", Label: "System Clipboard"},
 		{ID: "buffer", Label: "Tmux Buffers"},
+		{ID: "history", Label: "Clipboard History"},
+	}
+	return items, nil
+}
+
+// systemPasteMode is one way of pasting the host clipboard, keyed by the
+// clipboard:system item ID.
+type systemPasteMode struct {
+	bracketed    bool
+	stripNewline bool
+}
+
+var systemPasteModes = map[string]systemPasteMode{
+	"paste":   {bracketed: true},
+	"trimmed": {bracketed: true, stripNewline: true},
+	"raw":     {},
+}
+
+func loadClipboardSystemMenu(Context) ([]Item, error) {
+	return []Item{
+		{ID: "paste", Label: "paste"},
+		{ID: "trimmed", Label: "paste without trailing newline"},
+		{ID: "raw", Label: "paste without bracketed paste"},
+	}, nil
+}
+
+// systemClipboardText reads the host clipboard and applies mode.
+func systemClipboardText(mode systemPasteMode) (string, error) {
+	text, err := systemReadFn()
+	if err != nil {
+		return "", fmt.Errorf("reading system clipboard: %w", err)
+	}
+	if mode.stripNewline {
+		text = strings.TrimRight(text, "\r\n")
+	}
+	if text == "" {
+		return "", fmt.Errorf("system clipboard is empty")
+	}
+	return text, nil
+}
+
+// SystemClipboardPreviewLines shows exactly what the highlighted paste mode
+// would send: the host clipboard after newline stripping, under a title
+// describing the mode.
+func SystemClipboardPreviewLines(_ Context, id string) ([]string, error) {
+	mode, ok := systemPasteModes[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown paste mode %q", id)
+	}
+	text, err := systemClipboardText(mode)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := exec.LookPath("copyq"); err == nil {
-		items = append([]Item{{ID: "system", Label: "System Clipboard (copyq)"}}, items...)
+	title := humanizeSaveSize(int64(len(text)))
+	if mode.bracketed {
+		title += ", bracketed paste"
+	}
+	if strings.HasSuffix(text, "\n") {
+		title += ", ends with newline"
+	}
+	lines := []string{title, ""}
+	return append(lines, splitLines(text)...), nil
+}
+
+// systemPasteCommand pastes the host clipboard into the originating pane in
+// the paste mode keyed by id.
+func systemPasteCommand(ctx Context, id string) tea.Cmd {
+	mode := systemPasteModes[id]
+	target := bufferPasteTarget(ctx)
+	return func() tea.Msg {
+		text, err := systemClipboardText(mode)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Clipboard.SystemPaste(target, len(text), mode.bracketed, mode.stripNewline)
+		if err := systemPasteFn(ctx.SocketPath, target, text, mode.bracketed); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: "Pasted system clipboard"}
+	}
+}
+
+func ClipboardSystemPasteAction(ctx Context, _ Item) tea.Cmd {
+	return systemPasteCommand(ctx, "paste")
+}
+
+func ClipboardSystemPasteTrimmedAction(ctx Context, _ Item) tea.Cmd {
+	return systemPasteCommand(ctx, "trimmed")
+}
+
+func ClipboardSystemPasteRawAction(ctx Context, _ Item) tea.Cmd {
+	return systemPasteCommand(ctx, "raw")
+}
+
+func loadClipboardBufferMenu(Context) ([]Item, error) {
+	items := []string{
+		"paste",
+		"rename",
+		"delete",
+		"save",
+		"load",
+		"edit",
+	}
+	return menuItemsFromIDs(items), nil
+}
+
+// loadBufferListMenu lists the server's paste buffers as a table with a
+// header row. Item IDs are buffer names.
+func loadBufferListMenu(ctx Context) ([]Item, error) {
+	buffers, err := listBuffersFn(ctx.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(buffers) == 0 {
+		return nil, nil
+	}
+	cells := make([][]string, 0, len(buffers)+1)
+	cells = append(cells, []string{"name", "size", "sample"})
+	for _, buf := range buffers {
+		cells = append(cells, []string{buf.Name, humanizeSaveSize(int64(buf.Size)), buf.Sample})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignRight, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: buffers[i].Name, Label: label})
 	}
 	return items, nil
 }
+
+// BufferPreviewLines returns the full content of the named buffer for the
+// preview panel, under a title line naming the buffer and its size.
+func BufferPreviewLines(ctx Context, name string) ([]string, error) {
+	content, err := showBufferFn(ctx.SocketPath, name)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{fmt.Sprintf("%s (%s)", name, humanizeSaveSize(int64(len(content)))), ""}
+	return append(lines, splitLines(content)...), nil
+}
+
+// bufferPasteTarget is the pane that launched the popup, falling back to the
+// current pane when the popup was started outside main.sh.
+func bufferPasteTarget(ctx Context) string {
+	return cmp.Or(tmux.OriginPaneID(), strings.TrimSpace(ctx.CurrentPaneID))
+}
+
+// ClipboardBufferPasteAction pastes the selected buffer into the originating
+// pane.
+func ClipboardBufferPasteAction(ctx Context, item Item) tea.Cmd {
+	name := strings.TrimSpace(item.ID)
+	if name == "" {
+		return failCmd("no buffer selected")
+	}
+	target := bufferPasteTarget(ctx)
+	return runAction(
+		func() { events.Clipboard.BufferPaste(name, target) },
+		func() error { return pasteBufferFn(ctx.SocketPath, name, target) },
+		fmt.Sprintf("Pasted %s", name),
+	)
+}
+
+// ClipboardBufferDeleteAction deletes every selected buffer.
+func ClipboardBufferDeleteAction(ctx Context, item Item) tea.Cmd {
+	names := splitSelectionIDs(item.ID)
+	if len(names) == 0 {
+		return failCmd("no buffer selected")
+	}
+	okMsg := fmt.Sprintf("Deleted %s", names[0])
+	if len(names) > 1 {
+		okMsg = fmt.Sprintf("Deleted %d buffers", len(names))
+	}
+	return runAction(
+		func() { events.Clipboard.BufferDelete(names) },
+		func() error { return deleteBuffersFn(ctx.SocketPath, names) },
+		okMsg,
+	)
+}
+
+// BufferPrompt asks the UI to show the buffer form for Action, one of
+// clipboard:buffer:rename, :save or :load.
+type BufferPrompt struct {
+	Context Context
+	Action  string
+	Buffer  string
+	Initial string
+}
+
+// BufferRequest carries a submitted buffer form.
+type BufferRequest struct {
+	Context Context
+	Action  string
+	Buffer  string
+	Value   string
+}
+
+func bufferPromptCmd(ctx Context, action, name, initial string) tea.Cmd {
+	return func() tea.Msg {
+		events.Clipboard.BufferPrompt(action, name)
+		return BufferPrompt{Context: ctx, Action: action, Buffer: name, Initial: initial}
+	}
+}
+
+func ClipboardBufferRenameAction(ctx Context, item Item) tea.Cmd {
+	name := strings.TrimSpace(item.ID)
+	if name == "" {
+		return failCmd("no buffer selected")
+	}
+	return bufferPromptCmd(ctx, "clipboard:buffer:rename", name, name)
+}
+
+func ClipboardBufferSaveAction(ctx Context, item Item) tea.Cmd {
+	name := strings.TrimSpace(item.ID)
+	if name == "" {
+		return failCmd("no buffer selected")
+	}
+	return bufferPromptCmd(ctx, "clipboard:buffer:save", name, "~/"+name+".txt")
+}
+
+func ClipboardBufferLoadAction(ctx Context, _ Item) tea.Cmd {
+	return bufferPromptCmd(ctx, "clipboard:buffer:load", "", "~/")
+}
+
+// BufferCommand runs the operation behind a submitted buffer form.
+func BufferCommand(req BufferRequest) tea.Cmd {
+	return func() tea.Msg {
+		socket := req.Context.SocketPath
+		value := strings.TrimSpace(req.Value)
+		if value == "" {
+			return ActionResult{Err: fmt.Errorf("value required")}
+		}
+		switch req.Action {
+		case "clipboard:buffer:rename":
+			events.Clipboard.BufferRename(req.Buffer, value)
+			if err := renameBufferFn(socket, req.Buffer, value); err != nil {
+				return ActionResult{Err: err}
+			}
+			return ActionResult{Info: fmt.Sprintf("Renamed %s to %s", req.Buffer, value)}
+		case "clipboard:buffer:save":
+			path, err := bufferFilePath(value)
+			if err != nil {
+				return ActionResult{Err: err}
+			}
+			// owner-only (0700) like pane:capture: buffers routinely hold
+			// copied secrets.
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+				return ActionResult{Err: fmt.Errorf("create directory %s: %w", filepath.Dir(path), err)}
+			}
+			events.Clipboard.BufferSave(req.Buffer, path)
+			if err := saveBufferFn(socket, req.Buffer, path); err != nil {
+				return ActionResult{Err: err}
+			}
+			return ActionResult{Info: fmt.Sprintf("Saved %s to %s", req.Buffer, path)}
+		case "clipboard:buffer:load":
+			path, err := bufferFilePath(value)
+			if err != nil {
+				return ActionResult{Err: err}
+			}
+			events.Clipboard.BufferLoad(req.Buffer, path)
+			if err := loadBufferFn(socket, req.Buffer, path); err != nil {
+				return ActionResult{Err: err}
+			}
+			return ActionResult{Info: fmt.Sprintf("Loaded %s", path)}
+		default:
+			return ActionResult{Err: fmt.Errorf("unknown buffer action %q", req.Action)}
+		}
+	}
+}
+
+// bufferFilePath expands a leading ~/ and makes path absolute: tmux resolves
+// relative paths against the client's cwd, not the popup's.
+func bufferFilePath(path string) (string, error) {
+	return filepath.Abs(expandTilde(path))
+}
+
+// bufferEditorCommand builds the editor invocation for path from $VISUAL or
+// $EDITOR, falling back to vi. The variable may carry arguments ("code -w").
+func bufferEditorCommand(path string) *exec.Cmd {
+	editor := strings.Fields(cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR")))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
+
+// ClipboardBufferEditAction saves the selected buffer to a temp file, hands
+// the terminal to the editor and loads the file back into the same buffer
+// once the editor exits.
+func ClipboardBufferEditAction(ctx Context, item Item) tea.Cmd {
+	name := strings.TrimSpace(item.ID)
+	if name == "" {
+		return failCmd("no buffer selected")
+	}
+	return func() tea.Msg {
+		file, err := os.CreateTemp("", "tmux-buffer-*.txt")
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		path := file.Name()
+		file.Close()
+		if err := saveBufferFn(ctx.SocketPath, name, path); err != nil {
+			os.Remove(path)
+			return ActionResult{Err: err}
+		}
+		cmd := bufferEditorFn(path)
+		events.Clipboard.BufferEdit(name, cmd.Path)
+		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return finishBufferEdit(ctx, name, path, err)
+		})()
+	}
+}
+
+// finishBufferEdit writes the edited file back into the buffer and removes
+// it. An editor that exits non-zero leaves the buffer untouched.
+func finishBufferEdit(ctx Context, name, path string, editErr error) ActionResult {
+	defer os.Remove(path)
+	if editErr != nil {
+		return ActionResult{Err: fmt.Errorf("editor: %w", editErr)}
+	}
+	if err := loadBufferFn(ctx.SocketPath, name, path); err != nil {
+		return ActionResult{Err: err}
+	}
+	return ActionResult{Info: fmt.Sprintf("Updated %s", name)}
+}
+
+// BufferForm is the single-line form behind buffer rename, save and load.
+type BufferForm struct {
+	input  textinput.Model
+	ctx    Context
+	action string
+	buffer string
+	title  string
+	help   string
+}
+
+func NewBufferForm(prompt BufferPrompt) *BufferForm {
+	ti := textinput.New()
+	styleFormInput(&ti)
+	ti.CharLimit = 256
+	ti.SetWidth(60)
+	var title, help string
+	switch prompt.Action {
+	case "clipboard:buffer:rename":
+		ti.Placeholder = "buffer name"
+		ti.CharLimit = 128
+		ti.SetWidth(40)
+		title = fmt.Sprintf("Rename %s", prompt.Buffer)
+		help = "Press Enter to rename. Esc to cancel."
+	case "clipboard:buffer:save":
+		ti.Placeholder = "file path"
+		title = fmt.Sprintf("Save %s to file", prompt.Buffer)
+		help = "Press Enter to save. Esc to cancel."
+	default:
+		ti.Placeholder = "file path"
+		title = "Load buffer from file"
+		help = "Press Enter to load. Esc to cancel."
+	}
+	if prompt.Initial != "" {
+		ti.SetValue(prompt.Initial)
+		ti.CursorEnd()
+	}
+	ti.Focus()
+	return &BufferForm{
+		input:  ti,
+		ctx:    prompt.Context,
+		action: prompt.Action,
+		buffer: prompt.Buffer,
+		title:  title,
+		help:   help,
+	}
+}
+
+func (f *BufferForm) Context() Context    { return f.ctx }
+func (f *BufferForm) Target() string      { return f.buffer }
+func (f *BufferForm) Title() string       { return f.title }
+func (f *BufferForm) Help() string        { return f.help }
+func (f *BufferForm) Value() string       { return strings.TrimSpace(f.input.Value()) }
+func (f *BufferForm) InputView() string   { return f.input.View() }
+func (f *BufferForm) Cursor() *tea.Cursor { return f.input.Cursor() }
+func (f *BufferForm) FocusCmd() tea.Cmd   { return f.input.Focus() }
+func (f *BufferForm) ActionID() string    { return f.action }
+
+func (f *BufferForm) PendingLabel() string {
+	value := f.Value()
+	switch {
+	case value == "":
+		return f.action
+	case f.buffer == "":
+		return value
+	default:
+		return fmt.Sprintf("%s → %s", f.buffer, value)
+	}
+}
+
+func (f *BufferForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch m.String() {
+		case "ctrl+u":
+			if f.input.Value() != "" {
+				f.input.SetValue("")
+				f.input.CursorStart()
+			}
+			return nil, false, false
+		case "esc":
+			return nil, false, true
+		case "enter":
+			value := f.Value()
+			if value == "" {
+				return nil, false, true
+			}
+			if strings.ContainsAny(value, "\n\r") {
+				return nil, false, false
+			}
+			return BufferCommand(BufferRequest{
+				Context: f.ctx,
+				Action:  f.action,
+				Buffer:  f.buffer,
+				Value:   value,
+			}), true, false
+		}
+	}
+	updated, cmd := f.input.Update(msg)
+	f.input = updated
+	return cmd, false, false
+}
+
+func (f *BufferForm) SyncContext(ctx Context) {
+	f.ctx = ctx
+}
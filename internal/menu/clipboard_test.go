@@ -0,0 +1,374 @@
+package menu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/clipboard"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+func TestLoadBufferListMenu(t *testing.T) {
+	restore := withPaneStub(&listBuffersFn, func(string) ([]tmux.Buffer, error) {
+		return []tmux.Buffer{
+			{Name: "buffer1", Size: 2048, Sample: "git push"},
+			{Name: "notes", Size: 5, Sample: "hello"},
+		}, nil
+	})
+	defer restore()
+
+	items, err := loadBufferListMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[0].Header {
+		t.Fatalf("expected header + 2 buffers, got %#v", items)
+	}
+	if items[1].ID != "buffer1" || !strings.Contains(items[1].Label, "2 KB") || !strings.Contains(items[1].Label, "git push") {
+		t.Fatalf("unexpected first row %#v", items[1])
+	}
+	if items[2].ID != "notes" {
+		t.Fatalf("unexpected second row %#v", items[2])
+	}
+}
+
+func TestLoadBufferListMenuEmpty(t *testing.T) {
+	restore := withPaneStub(&listBuffersFn, func(string) ([]tmux.Buffer, error) { return nil, nil })
+	defer restore()
+
+	items, err := loadBufferListMenu(Context{})
+	if err != nil || items != nil {
+		t.Fatalf("expected no items, got %#v, %v", items, err)
+	}
+}
+
+func TestBufferPreviewLinesShowsFullContent(t *testing.T) {
+	restore := withPaneStub(&showBufferFn, func(_, name string) (string, error) {
+		if name != "notes" {
+			t.Fatalf("unexpected buffer %q", name)
+		}
+		return "line one\nline two", nil
+	})
+	defer restore()
+
+	lines, err := BufferPreviewLines(Context{}, "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"notes (17 B)", "", "line one", "line two"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestClipboardBufferPasteTargetsOriginPane(t *testing.T) {
+	t.Setenv("TMUX_POPUP_CONTROL_PANE_ID", "%7")
+	var gotName, gotTarget string
+	restore := withPaneStub(&pasteBufferFn, func(_, name, target string) error {
+		gotName, gotTarget = name, target
+		return nil
+	})
+	defer restore()
+
+	res := ClipboardBufferPasteAction(Context{CurrentPaneID: "%1"}, Item{ID: "buffer3"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	if gotName != "buffer3" || gotTarget != "%7" {
+		t.Fatalf("paste(%q, %q), want buffer3 into %%7", gotName, gotTarget)
+	}
+}
+
+func TestClipboardBufferDeleteMultiple(t *testing.T) {
+	var got []string
+	restore := withPaneStub(&deleteBuffersFn, func(_ string, names []string) error {
+		got = names
+		return nil
+	})
+	defer restore()
+
+	res := ClipboardBufferDeleteAction(Context{}, Item{ID: "a\nb"})().(ActionResult)
+	if res.Err != nil || res.Info != "Deleted 2 buffers" {
+		t.Fatalf("unexpected result %#v", res)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("deleted %q", got)
+	}
+}
+
+func TestClipboardBufferPromptActions(t *testing.T) {
+	prompt := ClipboardBufferSaveAction(Context{}, Item{ID: "notes"})().(BufferPrompt)
+	if prompt.Action != "clipboard:buffer:save" || prompt.Buffer != "notes" || prompt.Initial != "~/notes.txt" {
+		t.Fatalf("unexpected save prompt %#v", prompt)
+	}
+	prompt = ClipboardBufferRenameAction(Context{}, Item{ID: "notes"})().(BufferPrompt)
+	if prompt.Action != "clipboard:buffer:rename" || prompt.Initial != "notes" {
+		t.Fatalf("unexpected rename prompt %#v", prompt)
+	}
+	prompt = ClipboardBufferLoadAction(Context{}, Item{ID: "load"})().(BufferPrompt)
+	if prompt.Action != "clipboard:buffer:load" || prompt.Buffer != "" {
+		t.Fatalf("unexpected load prompt %#v", prompt)
+	}
+}
+
+func TestBufferCommandSaveResolvesPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	var gotPath string
+	restore := withPaneStub(&saveBufferFn, func(_, _, path string) error {
+		gotPath = path
+		return nil
+	})
+	defer restore()
+
+	res := BufferCommand(BufferRequest{Action: "clipboard:buffer:save", Buffer: "notes", Value: "~/out/notes.txt"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	want := filepath.Join(home, "out", "notes.txt")
+	if gotPath != want {
+		t.Fatalf("path = %q, want %q", gotPath, want)
+	}
+	if _, err := os.Stat(filepath.Dir(want)); err != nil {
+		t.Fatalf("expected parent directory to be created: %v", err)
+	}
+}
+
+func TestBufferCommandRename(t *testing.T) {
+	var got []string
+	restore := withPaneStub(&renameBufferFn, func(_, name, newName string) error {
+		got = []string{name, newName}
+		return nil
+	})
+	defer restore()
+
+	res := BufferCommand(BufferRequest{Action: "clipboard:buffer:rename", Buffer: "buffer0", Value: " keep "})().(ActionResult)
+	if res.Err != nil || !reflect.DeepEqual(got, []string{"buffer0", "keep"}) {
+		t.Fatalf("unexpected rename %#v %q", res, got)
+	}
+}
+
+func TestBufferFormSubmitsRequest(t *testing.T) {
+	var gotPath string
+	restore := withPaneStub(&loadBufferFn, func(_, _, path string) error {
+		gotPath = path
+		return nil
+	})
+	defer restore()
+
+	form := NewBufferForm(BufferPrompt{Action: "clipboard:buffer:load", Initial: "/tmp/in.txt"})
+	cmd, done, cancel := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !done || cancel || cmd == nil {
+		t.Fatalf("expected submit, got done=%v cancel=%v", done, cancel)
+	}
+	if res := cmd().(ActionResult); res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	if gotPath != "/tmp/in.txt" {
+		t.Fatalf("loaded %q", gotPath)
+	}
+}
+
+func TestFinishBufferEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "buf.txt")
+	if err := os.WriteFile(path, []byte("edited"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var loaded []string
+	restore := withPaneStub(&loadBufferFn, func(_, name, p string) error {
+		loaded = []string{name, p}
+		return nil
+	})
+	defer restore()
+
+	res := finishBufferEdit(Context{}, "notes", path, nil)
+	if res.Err != nil || !reflect.DeepEqual(loaded, []string{"notes", path}) {
+		t.Fatalf("unexpected result %#v, loaded %q", res, loaded)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected temp file to be removed")
+	}
+
+	loaded = nil
+	res = finishBufferEdit(Context{}, "notes", path, errors.New("exit status 1"))
+	if res.Err == nil || loaded != nil {
+		t.Fatalf("editor failure must not write back: %#v %q", res, loaded)
+	}
+}
+
+func TestBufferEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code -w")
+	cmd := bufferEditorCommand("/tmp/x")
+	if !reflect.DeepEqual(cmd.Args, []string{"code", "-w", "/tmp/x"}) {
+		t.Fatalf("args = %q", cmd.Args)
+	}
+	t.Setenv("EDITOR", "")
+	if cmd := bufferEditorCommand("/tmp/x"); cmd.Args[0] != "vi" {
+		t.Fatalf("expected vi fallback, got %q", cmd.Args)
+	}
+}
+
+func TestClipboardBufferRegistry(t *testing.T) {
+	reg := BuildRegistry()
+	if node, ok := reg.Find("clipboard:buffer:delete"); !ok || !node.MultiSelect {
+		t.Fatal("expected clipboard:buffer:delete to be multi-select")
+	}
+	for _, id := range []string{"clipboard:buffer:paste", "clipboard:buffer:rename", "clipboard:buffer:save", "clipboard:buffer:edit"} {
+		node, ok := reg.Find(id)
+		if !ok || node.Loader == nil || node.Action == nil {
+			t.Fatalf("registry missing loader/action for %s", id)
+		}
+	}
+	if node, ok := reg.Find("clipboard:buffer:load"); !ok || node.Action == nil || node.Loader != nil {
+		t.Fatal("expected clipboard:buffer:load to be a leaf action")
+	}
+}
+
+func TestSystemClipboardPreviewAppliesMode(t *testing.T) {
+	restore := withPaneStub(&systemReadFn, func() (string, error) { return "make deploy\n", nil })
+	defer restore()
+
+	lines, err := SystemClipboardPreviewLines(Context{}, "paste")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"12 B, bracketed paste, ends with newline", "", "make deploy"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("paste preview = %q, want %q", lines, want)
+	}
+	lines, err = SystemClipboardPreviewLines(Context{}, "trimmed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines[0] != "11 B, bracketed paste" {
+		t.Fatalf("trimmed preview title = %q", lines[0])
+	}
+}
+
+func TestClipboardSystemPasteModes(t *testing.T) {
+	t.Setenv("TMUX_POPUP_CONTROL_PANE_ID", "%3")
+	restoreRead := withPaneStub(&systemReadFn, func() (string, error) { return "ls -la\n", nil })
+	defer restoreRead()
+	type call struct {
+		target, text string
+		bracketed    bool
+	}
+	var got call
+	restorePaste := withPaneStub(&systemPasteFn, func(_, target, text string, bracketed bool) error {
+		got = call{target, text, bracketed}
+		return nil
+	})
+	defer restorePaste()
+
+	cases := []struct {
+		action Action
+		want   call
+	}{
+		{ClipboardSystemPasteAction, call{"%3", "ls -la\n", true}},
+		{ClipboardSystemPasteTrimmedAction, call{"%3", "ls -la", true}},
+		{ClipboardSystemPasteRawAction, call{"%3", "ls -la\n", false}},
+	}
+	for _, tc := range cases {
+		got = call{}
+		if res := tc.action(Context{}, Item{})().(ActionResult); res.Err != nil {
+			t.Fatalf("unexpected error: %v", res.Err)
+		}
+		if got != tc.want {
+			t.Fatalf("paste = %#v, want %#v", got, tc.want)
+		}
+	}
+}
+
+func TestClipboardSystemPasteEmptyClipboard(t *testing.T) {
+	restoreRead := withPaneStub(&systemReadFn, func() (string, error) { return "\n", nil })
+	defer restoreRead()
+	restorePaste := withPaneStub(&systemPasteFn, func(string, string, string, bool) error {
+		t.Fatal("empty clipboard must not be pasted")
+		return nil
+	})
+	defer restorePaste()
+
+	res := ClipboardSystemPasteTrimmedAction(Context{}, Item{})().(ActionResult)
+	if res.Err == nil {
+		t.Fatal("expected an error for an empty clipboard")
+	}
+}
+
+// stubSystemCopy replaces every system clipboard backend, records the calls
+// and serves tmux options from opts.
+func stubSystemCopy(t *testing.T, opts map[string]string, nativeErr, ttyErr error) *[]string {
+	t.Helper()
+	var calls []string
+	restores := []func(){
+		withPaneStub(&osc52OptionFn, func(_, opt string) string { return opts[opt] }),
+		withPaneStub(&nativeCopyFn, func(string) error {
+			calls = append(calls, "native")
+			return nativeErr
+		}),
+		withPaneStub(&osc52TerminalFn, func(string) (string, string, error) {
+			return "/dev/pts/3", "tmux-256color", nil
+		}),
+		withPaneStub(&osc52WriteFn, func(tty, _ string, pt clipboard.Passthrough) error {
+			calls = append(calls, "osc52:"+tty+":"+pt.String())
+			return ttyErr
+		}),
+		withPaneStub(&osc52ForwardFn, func(string, string) error {
+			calls = append(calls, "forward")
+			return nil
+		}),
+	}
+	t.Cleanup(func() {
+		for _, restore := range restores {
+			restore()
+		}
+	})
+	return &calls
+}
+
+func TestCopyToSystemClipboardFallsBackToOSC52(t *testing.T) {
+	calls := stubSystemCopy(t, nil, errors.New("no xclip"), nil)
+	if err := CopyToSystemClipboard("", "text"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"native", "osc52:/dev/pts/3:tmux"}; !reflect.DeepEqual(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestCopyToSystemClipboardPrimaryWithExplicitPassthrough(t *testing.T) {
+	calls := stubSystemCopy(t, map[string]string{
+		"@tmux-popup-control-clipboard-osc52":             "primary",
+		"@tmux-popup-control-clipboard-osc52-passthrough": "none",
+	}, nil, nil)
+	if err := CopyToSystemClipboard("", "text"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"osc52:/dev/pts/3:none"}; !reflect.DeepEqual(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestCopyToSystemClipboardForwardsThroughTmux(t *testing.T) {
+	calls := stubSystemCopy(t, map[string]string{"@tmux-popup-control-clipboard-osc52": "primary"}, nil, os.ErrPermission)
+	if err := CopyToSystemClipboard("", "text"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"osc52:/dev/pts/3:tmux", "forward"}; !reflect.DeepEqual(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+
+	calls = stubSystemCopy(t, map[string]string{"set-clipboard": "off"}, errors.New("no xclip"), os.ErrPermission)
+	if err := CopyToSystemClipboard("", "text"); !errors.Is(err, os.ErrPermission) {
+		t.Fatalf("expected permission error with set-clipboard off, got %v", err)
+	}
+	if want := []string{"native", "osc52:/dev/pts/3:tmux"}; !reflect.DeepEqual(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
@@ -0,0 +1,288 @@
+package menu
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/cliphistory"
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	historyDirFn       = resurrect.ResolveDir
+	historyOptionFn    = tmux.ShowOption
+	historyPasteFn     = tmux.PasteText
+	historyClipboardFn = CopyToSystemClipboard
+)
+
+// historySampleWidth caps the sample column of the history listing.
+const historySampleWidth = 60
+
+func loadClipboardHistoryMenu(Context) ([]Item, error) {
+	items := []string{
+		"insert",
+		"copy",
+		"pin",
+		"delete",
+	}
+	return menuItemsFromIDs(items), nil
+}
+
+// ClipboardHistorySettings resolves the history settings for socketPath from
+// the environment and the @tmux-popup-control-clipboard-history-* options.
+func ClipboardHistorySettings(socketPath string) cliphistory.Settings {
+	return cliphistory.ResolveSettings(os.Getenv, func(opt string) string {
+		return historyOptionFn(socketPath, opt)
+	})
+}
+
+// RecordClipboardHistory stores text in the clipboard history unless history
+// is disabled. Callers treat failures as non-fatal: the copy itself already
+// happened.
+func RecordClipboardHistory(socketPath, text, source string) error {
+	settings := ClipboardHistorySettings(socketPath)
+	if !settings.Enabled {
+		return nil
+	}
+	dir, err := historyDirFn(socketPath)
+	if err != nil {
+		return err
+	}
+	events.Clipboard.HistoryRecord(source, len(text))
+	return cliphistory.Record(dir, text, source, settings.Limits)
+}
+
+// PollClipboardBuffers imports tmux paste buffers created since the last
+// poll into the history. It only fetches the content of new buffers, so it
+// is cheap enough to run from a hook.
+func PollClipboardBuffers(socketPath string) (int, error) {
+	settings := ClipboardHistorySettings(socketPath)
+	if !settings.Enabled {
+		return 0, nil
+	}
+	dir, err := historyDirFn(socketPath)
+	if err != nil {
+		return 0, err
+	}
+	return pollClipboardBuffers(socketPath, dir, settings.Limits)
+}
+
+func pollClipboardBuffers(socketPath, dir string, limits cliphistory.Limits) (int, error) {
+	mark, err := cliphistory.BufferMark(dir)
+	if err != nil {
+		return 0, err
+	}
+	buffers, err := listBuffersFn(socketPath)
+	if err != nil {
+		return 0, err
+	}
+	var fresh []cliphistory.ImportedBuffer
+	for _, buf := range buffers {
+		if buf.Created.IsZero() || buf.Created.Before(mark) || buf.Name == tmux.PasteTextBuffer {
+			continue
+		}
+		text, err := showBufferFn(socketPath, buf.Name)
+		if err != nil {
+			continue // deleted between list and show
+		}
+		fresh = append(fresh, cliphistory.ImportedBuffer{Created: buf.Created, Text: text})
+	}
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+	added, err := cliphistory.ImportBuffers(dir, fresh, limits)
+	if err == nil {
+		events.Clipboard.HistoryPoll(added)
+	}
+	return added, err
+}
+
+// loadClipboardHistoryListMenu lists the history as a table with a header
+// row, pinned entries first. Item IDs are entry IDs. When buffer polling is
+// enabled, new tmux buffers are imported first.
+func loadClipboardHistoryListMenu(ctx Context) ([]Item, error) {
+	settings := ClipboardHistorySettings(ctx.SocketPath)
+	dir, err := historyDirFn(ctx.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Enabled && settings.PollBuffers {
+		// a failed poll still leaves the stored history worth showing.
+		if _, err := pollClipboardBuffers(ctx.SocketPath, dir, settings.Limits); err != nil {
+			logging.Error(err)
+		}
+	}
+	entries, err := cliphistory.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	cells := make([][]string, 0, len(entries)+1)
+	cells = append(cells, []string{"", "age", "source", "size", "sample"})
+	for _, e := range entries {
+		pin := ""
+		if e.Pinned {
+			pin = "*"
+		}
+		cells = append(cells, []string{
+			pin,
+			resurrect.RelativeTime(e.Created, now),
+			e.Source,
+			humanizeSaveSize(int64(len(e.Text))),
+			historySample(e.Text),
+		})
+	}
+	aligned := table.Format(cells, []table.Alignment{
+		table.AlignLeft, table.AlignLeft, table.AlignLeft, table.AlignRight, table.AlignLeft,
+	})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: entries[i].ID, Label: label})
+	}
+	return items, nil
+}
+
+// historySample flattens text to one line for the listing: newlines and tabs
+// become visible markers and the result is cut at historySampleWidth runes.
+func historySample(text string) string {
+	sample := strings.NewReplacer("\r\n", "↵", "\n", "↵", "\t", " ").Replace(strings.TrimSpace(text))
+	runes := []rune(sample)
+	if len(runes) > historySampleWidth {
+		return string(runes[:historySampleWidth-1]) + "…"
+	}
+	return sample
+}
+
+// ClipboardHistoryPreviewLines returns the full text of a history entry for
+// the preview panel, under a title line with its source, age and size.
+func ClipboardHistoryPreviewLines(ctx Context, id string) ([]string, error) {
+	entry, err := findHistoryEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	title := fmt.Sprintf("%s, %s (%s)", entry.Source, resurrect.RelativeTime(entry.Created, time.Now()), humanizeSaveSize(int64(len(entry.Text))))
+	if entry.Pinned {
+		title += ", pinned"
+	}
+	lines := []string{title, ""}
+	return append(lines, splitLines(entry.Text)...), nil
+}
+
+func findHistoryEntry(ctx Context, id string) (cliphistory.Entry, error) {
+	dir, err := historyDirFn(ctx.SocketPath)
+	if err != nil {
+		return cliphistory.Entry{}, err
+	}
+	entry, ok, err := cliphistory.Find(dir, id)
+	if err != nil {
+		return cliphistory.Entry{}, err
+	}
+	if !ok {
+		return cliphistory.Entry{}, fmt.Errorf("clipboard history entry %s not found", id)
+	}
+	return entry, nil
+}
+
+// ClipboardHistoryInsertAction pastes the selected entry into the originating
+// pane.
+func ClipboardHistoryInsertAction(ctx Context, item Item) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no history entry selected")
+	}
+	target := bufferPasteTarget(ctx)
+	return func() tea.Msg {
+		entry, err := findHistoryEntry(ctx, id)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Clipboard.HistoryInsert(id, target)
+		if err := historyPasteFn(ctx.SocketPath, target, entry.Text, true); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: "Inserted history entry"}
+	}
+}
+
+// ClipboardHistoryCopyAction puts the selected entry back on the system
+// clipboard.
+func ClipboardHistoryCopyAction(ctx Context, item Item) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no history entry selected")
+	}
+	return func() tea.Msg {
+		entry, err := findHistoryEntry(ctx, id)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Clipboard.HistoryCopy(id)
+		if err := historyClipboardFn(ctx.SocketPath, entry.Text); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: "Copied history entry to the clipboard"}
+	}
+}
+
+// ClipboardHistoryPinAction toggles the pin on the selected entry. Pinned
+// entries sort first and are exempt from the size and age limits.
+func ClipboardHistoryPinAction(ctx Context, item Item) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no history entry selected")
+	}
+	return func() tea.Msg {
+		entry, err := findHistoryEntry(ctx, id)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		dir, err := historyDirFn(ctx.SocketPath)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		pinned := !entry.Pinned
+		events.Clipboard.HistoryPin(id, pinned)
+		if err := cliphistory.SetPinned(dir, id, pinned); err != nil {
+			return ActionResult{Err: err}
+		}
+		if pinned {
+			return ActionResult{Info: "Pinned history entry"}
+		}
+		return ActionResult{Info: "Unpinned history entry"}
+	}
+}
+
+// ClipboardHistoryDeleteAction removes every selected entry.
+func ClipboardHistoryDeleteAction(ctx Context, item Item) tea.Cmd {
+	ids := splitSelectionIDs(item.ID)
+	if len(ids) == 0 {
+		return failCmd("no history entry selected")
+	}
+	okMsg := "Deleted history entry"
+	if len(ids) > 1 {
+		okMsg = fmt.Sprintf("Deleted %d history entries", len(ids))
+	}
+	return runAction(
+		func() { events.Clipboard.HistoryDelete(ids) },
+		func() error {
+			dir, err := historyDirFn(ctx.SocketPath)
+			if err != nil {
+				return err
+			}
+			return cliphistory.Delete(dir, ids)
+		},
+		okMsg,
+	)
+}
@@ -0,0 +1,152 @@
+package menu
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/cliphistory"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+// withHistoryDir points the history at a fresh temp dir and stubs the tmux
+// option lookup so settings come from opts alone.
+func withHistoryDir(t *testing.T, opts map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	restoreDir := withPaneStub(&historyDirFn, func(string) (string, error) { return dir, nil })
+	restoreOpt := withPaneStub(&historyOptionFn, func(_, opt string) string { return opts[opt] })
+	t.Cleanup(func() {
+		restoreOpt()
+		restoreDir()
+	})
+	return dir
+}
+
+func TestRecordClipboardHistoryRespectsDisable(t *testing.T) {
+	dir := withHistoryDir(t, map[string]string{"@tmux-popup-control-clipboard-history": "off"})
+	if err := RecordClipboardHistory("", "secret", cliphistory.SourceExtract); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := cliphistory.Load(dir)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected nothing recorded, got %#v %v", entries, err)
+	}
+}
+
+func TestLoadClipboardHistoryListMenu(t *testing.T) {
+	dir := withHistoryDir(t, nil)
+	if err := RecordClipboardHistory("", "first\nsecond line", cliphistory.SourceExtract); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordClipboardHistory("", "pinned", cliphistory.SourceExtract); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordClipboardHistory("", "newest", cliphistory.SourceExtract); err != nil {
+		t.Fatal(err)
+	}
+	if err := cliphistory.SetPinned(dir, cliphistory.EntryID("pinned"), true); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := loadClipboardHistoryListMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 || !items[0].Header {
+		t.Fatalf("expected header + 3 entries, got %#v", items)
+	}
+	if items[1].ID != cliphistory.EntryID("pinned") || !strings.HasPrefix(items[1].Label, "*") {
+		t.Fatalf("expected pinned entry first, got %#v", items[1])
+	}
+	if items[2].ID != cliphistory.EntryID("newest") {
+		t.Fatalf("expected newest unpinned entry second, got %#v", items[2])
+	}
+	if !strings.Contains(items[3].Label, "first↵second line") {
+		t.Fatalf("expected flattened sample, got %q", items[3].Label)
+	}
+}
+
+func TestPollClipboardBuffersImportsNewBuffers(t *testing.T) {
+	dir := withHistoryDir(t, nil)
+	created := time.Now().Truncate(time.Second)
+	restoreList := withPaneStub(&listBuffersFn, func(string) ([]tmux.Buffer, error) {
+		return []tmux.Buffer{
+			{Name: "buffer0", Created: created},
+			{Name: tmux.PasteTextBuffer, Created: created},
+		}, nil
+	})
+	defer restoreList()
+	var shown []string
+	restoreShow := withPaneStub(&showBufferFn, func(_, name string) (string, error) {
+		shown = append(shown, name)
+		return "from tmux", nil
+	})
+	defer restoreShow()
+
+	added, err := PollClipboardBuffers("")
+	if err != nil || added != 1 {
+		t.Fatalf("added=%d err=%v", added, err)
+	}
+	if !reflect.DeepEqual(shown, []string{"buffer0"}) {
+		t.Fatalf("shown = %q, scratch paste buffer must be skipped", shown)
+	}
+	entry, ok, err := cliphistory.Find(dir, cliphistory.EntryID("from tmux"))
+	if err != nil || !ok || entry.Source != cliphistory.SourceBuffer {
+		t.Fatalf("unexpected entry %#v %v %v", entry, ok, err)
+	}
+}
+
+func TestClipboardHistoryInsertPastesIntoOriginPane(t *testing.T) {
+	withHistoryDir(t, nil)
+	t.Setenv("TMUX_POPUP_CONTROL_PANE_ID", "%4")
+	if err := RecordClipboardHistory("", "echo hi\n", cliphistory.SourceExtract); err != nil {
+		t.Fatal(err)
+	}
+	var gotTarget, gotText string
+	restore := withPaneStub(&historyPasteFn, func(_, target, text string, _ bool) error {
+		gotTarget, gotText = target, text
+		return nil
+	})
+	defer restore()
+
+	res := ClipboardHistoryInsertAction(Context{}, Item{ID: cliphistory.EntryID("echo hi\n")})().(ActionResult)
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	if gotTarget != "%4" || gotText != "echo hi\n" {
+		t.Fatalf("paste(%q, %q)", gotTarget, gotText)
+	}
+}
+
+func TestClipboardHistoryPinToggles(t *testing.T) {
+	dir := withHistoryDir(t, nil)
+	if err := RecordClipboardHistory("", "keep me", cliphistory.SourceExtract); err != nil {
+		t.Fatal(err)
+	}
+	id := cliphistory.EntryID("keep me")
+	for _, want := range []string{"Pinned history entry", "Unpinned history entry"} {
+		res := ClipboardHistoryPinAction(Context{}, Item{ID: id})().(ActionResult)
+		if res.Err != nil || res.Info != want {
+			t.Fatalf("got %#v, want %q", res, want)
+		}
+	}
+	entry, _, _ := cliphistory.Find(dir, id)
+	if entry.Pinned {
+		t.Fatal("expected entry to end unpinned")
+	}
+}
+
+func TestClipboardHistoryRegistry(t *testing.T) {
+	reg := BuildRegistry()
+	if node, ok := reg.Find("clipboard:history:delete"); !ok || !node.MultiSelect {
+		t.Fatal("expected clipboard:history:delete to be multi-select")
+	}
+	for _, id := range []string{"clipboard:history:insert", "clipboard:history:copy", "clipboard:history:pin"} {
+		node, ok := reg.Find(id)
+		if !ok || node.Loader == nil || node.Action == nil {
+			t.Fatalf("registry missing loader/action for %s", id)
+		}
+	}
+}
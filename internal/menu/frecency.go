@@ -0,0 +1,41 @@
+package menu
+
+import (
+	"os"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/frecency"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	frecencyDirFn    = resurrect.ResolveDir
+	frecencyOptionFn = tmux.ShowOption
+)
+
+// LoadFrecency returns the stored usage for socketPath and the configured
+// sort mode (TMUX_POPUP_CONTROL_SORT / @tmux-popup-control-sort).
+func LoadFrecency(socketPath string) (frecency.Store, frecency.SortMode, error) {
+	mode := frecency.ResolveSortMode(os.Getenv, func(opt string) string {
+		return frecencyOptionFn(socketPath, opt)
+	})
+	dir, err := frecencyDirFn(socketPath)
+	if err != nil {
+		return frecency.Store{}, mode, err
+	}
+	store, err := frecency.Load(dir)
+	return store, mode, err
+}
+
+// RecordFrecency counts one pick of each item under the registry node.
+func RecordFrecency(socketPath, nodeID string, itemIDs []string) error {
+	if nodeID == "" || len(itemIDs) == 0 {
+		return nil
+	}
+	dir, err := frecencyDirFn(socketPath)
+	if err != nil {
+		return err
+	}
+	return frecency.Pick(dir, nodeID, itemIDs, time.Now())
+}
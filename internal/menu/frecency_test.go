@@ -0,0 +1,52 @@
+package menu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/frecency"
+)
+
+func TestRecordAndLoadFrecency(t *testing.T) {
+	dir := t.TempDir()
+	restoreDir := withPaneStub(&frecencyDirFn, func(string) (string, error) { return dir, nil })
+	defer restoreDir()
+	restoreOpt := withPaneStub(&frecencyOptionFn, func(_, opt string) string {
+		if opt == "@tmux-popup-control-sort" {
+			return "frecency"
+		}
+		return ""
+	})
+	defer restoreOpt()
+	t.Setenv("TMUX_POPUP_CONTROL_SORT", "")
+
+	if err := RecordFrecency("", "session:switch", []string{"work", "play"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordFrecency("", "session:switch", []string{"work"}); err != nil {
+		t.Fatal(err)
+	}
+	store, mode, err := LoadFrecency("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != frecency.SortFrecency {
+		t.Fatalf("mode = %v, want frecency", mode)
+	}
+	scores := store.Scores("session:switch", time.Now())
+	if scores["work"] <= scores["play"] || scores["play"] == 0 {
+		t.Fatalf("unexpected scores %v", scores)
+	}
+}
+
+func TestFrecencyRegistry(t *testing.T) {
+	reg := BuildRegistry()
+	for _, id := range []string{"session:switch", "window:switch", "pane:switch", "command"} {
+		if node, ok := reg.Find(id); !ok || !node.Frecency {
+			t.Fatalf("expected %s to be ranked by frecency", id)
+		}
+	}
+	if node, ok := reg.Find("session:kill"); ok && node.Frecency {
+		t.Fatal("session:kill should keep tmux order")
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"os"
 	"testing"
 
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
 	"github.com/atomicstack/tmux-popup-control/internal/testutil"
 )
 
 func TestMain(m *testing.M) {
+	// kills must never snapshot tmux into the developer's real trash; tests
+	// that care stub the capture themselves and call trashKilled directly.
+	trashKilledFn = func(Context, resurrect.TrashKind, []string) {}
 	code := m.Run()
 	testutil.ShutdownSharedServer()
 	os.Exit(code)
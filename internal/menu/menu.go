@@ -41,6 +41,9 @@ type Context struct {
 	PaneIncludeCurrent   bool
 	ExtractCategory      extract.Category
 	ExtractGrabArea      extract.GrabArea
+	SearchScope          string
+	SearchQuery          string
+	SearchRegex          bool
 }
 
 // WindowEntry represents a tmux window reference for menu loaders.
@@ -67,16 +70,19 @@ type PaneEntry struct {
 	Current   bool
 	Title     string
 	Command   string
+	PID       int
 }
 
 // SessionEntry represents a tmux session reference for menu loaders.
 type SessionEntry struct {
 	Name     string
 	Label    string
+	Path     string
 	Attached bool
 	Current  bool
 	Clients  []string
 	Windows  int
+	Group    string
 }
 
 // Loader populates submenu entries on demand.
@@ -111,16 +117,26 @@ type SessionRequest struct {
 func RootItems() []Item {
 	return []Item{
 		{ID: "extract", Label: "extract"},
-		// "process" and "clipboard" are temporarily hidden from the root menu
-		// while their submenus remain unimplemented. Their loaders stay wired
-		// in CategoryLoaders so re-enabling is just restoring these entries.
+		// search and server are labelled apart from "session", which "se"
+		// must keep landing on; their IDs still match "sea" and "ser".
+		{ID: "search", Label: "scrollback-search"},
+		{ID: "palette", Label: "palette"},
+		{ID: "process", Label: "process"},
+		{ID: "clipboard", Label: "clipboard"},
 		{ID: "customize-mode", Label: "customize-mode"},
 		{ID: "keybinding", Label: "keybinding"},
 		{ID: "command", Label: "command"},
 		{ID: "pane", Label: "pane"},
 		{ID: "window", Label: "window"},
+		{ID: "worktree", Label: "worktree"},
+		{ID: "ssh", Label: "ssh"},
+		{ID: "task", Label: "task"},
+		{ID: "client", Label: "client"},
+		{ID: "server", Label: "tmux-server"},
 		{ID: "plugins", Label: "plugins"},
 		{ID: "resurrect", Label: "resurrect"},
+		{ID: "trash", Label: "trash"},
+		{ID: "undo", Label: "undo"},
 		{ID: "session", Label: "session"},
 	}
 }
@@ -129,90 +145,231 @@ func RootItems() []Item {
 func CategoryLoaders() map[string]Loader {
 	return map[string]Loader{
 		"extract":    loadExtractMenu,
+		"search":     loadSearchMenu,
 		"process":    loadProcessMenu,
 		"clipboard":  loadClipboardMenu,
 		"keybinding": loadKeybindingMenu,
 		"command":    loadCommandMenu,
 		"pane":       loadPaneMenu,
 		"window":     loadWindowMenu,
+		"worktree":   loadWorktreeMenu,
+		"ssh":        loadSSHMenu,
+		"task":       loadTaskMenu,
+		"client":     loadClientMenu,
+		"server":     loadServerMenu,
 		"session":    loadSessionMenu,
 		"plugins":    loadPluginsMenu,
 		"resurrect":  loadResurrectMenu,
+		"trash":      loadTrashMenu,
+		"undo":       loadUndoMenu,
 	}
 }
 
 // ActionHandlers maps submenu identifiers to their execution logic.
 func ActionHandlers() map[string]Action {
 	return map[string]Action{
-		"customize-mode":           CustomizeModeAction,
-		"session:new":              SessionNewAction,
-		"session:switch":           SessionSwitchAction,
-		"session:rename":           SessionRenameAction,
-		"session:detach":           SessionDetachAction,
-		"session:kill":             SessionKillAction,
-		"session:tree":             SessionTreeAction,
-		"resurrect:save":           ResurrectSaveAction,
-		"resurrect:save-as":        ResurrectSaveAsAction,
-		"resurrect:restore":        ResurrectRestoreAction,
-		"resurrect:restore-from":   ResurrectRestoreFromAction,
-		"resurrect:delete-saved":   ResurrectDeleteSavedAction,
-		"window:switch":            WindowSwitchAction,
-		"window:link":              WindowLinkAction,
-		"window:pull-from-session": WindowPullFromSessionAction,
-		"window:push-to-session":   WindowPushToSessionAction,
-		"window:swap":              WindowSwapAction,
-		"window:rename":            WindowRenameAction,
-		"window:kill":              WindowKillAction,
-		"window:layout":            WindowLayoutAction,
-		"keybinding":               KeybindingAction,
-		"pane:switch":              PaneSwitchAction,
-		"pane:break":               PaneBreakAction,
-		"pane:join":                PaneJoinAction,
-		"pane:swap":                PaneSwapAction,
-		"pane:kill":                PaneKillAction,
-		"pane:rename":              PaneRenameAction,
-		"pane:capture":             PaneCaptureAction,
-		"pane:resize:left":         PaneResizeLeftAction,
-		"pane:resize:right":        PaneResizeRightAction,
-		"pane:resize:up":           PaneResizeUpAction,
-		"pane:resize:down":         PaneResizeDownAction,
-		"plugins:install":          PluginsInstallAction,
-		"plugins:update":           PluginsUpdateAction,
-		"plugins:uninstall":        PluginsUninstallAction,
+		"customize-mode":                     CustomizeModeAction,
+		"session:new":                        SessionNewAction,
+		"session:new-from-template":          SessionTemplateAction,
+		"session:open-project":               SessionOpenProjectAction,
+		"session:new-grouped":                SessionNewGroupedAction,
+		"session:clone":                      SessionCloneAction,
+		"session:environment:new":            SessionEnvNewAction,
+		"session:environment:set":            SessionEnvSetAction,
+		"session:environment:unset":          SessionEnvUnsetAction,
+		"session:environment:remove":         SessionEnvRemoveAction,
+		"session:environment:copy-to-global": SessionEnvCopyGlobalAction,
+		"session:environment:refresh-panes":  SessionEnvRefreshPanesAction,
+		"session:import:start":               SessionImportStartAction,
+		"session:import:convert":             SessionImportConvertAction,
+		"session:switch":                     SessionSwitchAction,
+		"session:rename":                     SessionRenameAction,
+		"session:detach":                     SessionDetachAction,
+		"session:kill":                       SessionKillAction,
+		"session:tree":                       SessionTreeAction,
+		"resurrect:save":                     ResurrectSaveAction,
+		"resurrect:save-as":                  ResurrectSaveAsAction,
+		"resurrect:restore":                  ResurrectRestoreAction,
+		"resurrect:restore-from":             ResurrectRestoreFromAction,
+		"resurrect:delete-saved":             ResurrectDeleteSavedAction,
+		"window:switch":                      WindowSwitchAction,
+		"window:link":                        WindowLinkAction,
+		"window:pull-from-session":           WindowPullFromSessionAction,
+		"window:push-to-session":             WindowPushToSessionAction,
+		"window:swap":                        WindowSwapAction,
+		"window:rename":                      WindowRenameAction,
+		"window:kill":                        WindowKillAction,
+		"window:layout":                      WindowLayoutAction,
+		"keybinding":                         KeybindingAction,
+		"pane:switch":                        PaneSwitchAction,
+		"pane:break":                         PaneBreakAction,
+		"pane:join":                          PaneJoinAction,
+		"pane:swap":                          PaneSwapAction,
+		"pane:kill":                          PaneKillAction,
+		"pane:rename":                        PaneRenameAction,
+		"pane:capture":                       PaneCaptureAction,
+		"pane:broadcast":                     PaneBroadcastAction,
+		"pane:log:start":                     PaneLogStartAction,
+		"pane:split":                         PaneSplitAction,
+		"pane:respawn":                       PaneRespawnAction,
+		"pane:log:stop":                      PaneLogStopAction,
+		"pane:resize:left":                   PaneResizeLeftAction,
+		"pane:resize:right":                  PaneResizeRightAction,
+		"pane:resize:up":                     PaneResizeUpAction,
+		"pane:resize:down":                   PaneResizeDownAction,
+		"pane:resize:interactive":            PaneResizeInteractiveAction,
+		"plugins:install":                    PluginsInstallAction,
+		"plugins:update":                     PluginsUpdateAction,
+		"plugins:uninstall":                  PluginsUninstallAction,
+		"process:display":                    ProcessDisplayAction,
+		"process:tree":                       ProcessTreeAction,
+		"process:terminate":                  ProcessTerminateAction,
+		"process:kill":                       ProcessKillAction,
+		"process:interrupt":                  ProcessInterruptAction,
+		"process:continue":                   ProcessContinueAction,
+		"process:stop":                       ProcessStopAction,
+		"process:quit":                       ProcessQuitAction,
+		"process:hangup":                     ProcessHangupAction,
+		"clipboard:system:paste":             ClipboardSystemPasteAction,
+		"clipboard:system:trimmed":           ClipboardSystemPasteTrimmedAction,
+		"clipboard:system:raw":               ClipboardSystemPasteRawAction,
+		"clipboard:buffer:paste":             ClipboardBufferPasteAction,
+		"clipboard:buffer:rename":            ClipboardBufferRenameAction,
+		"clipboard:buffer:delete":            ClipboardBufferDeleteAction,
+		"clipboard:buffer:save":              ClipboardBufferSaveAction,
+		"clipboard:buffer:load":              ClipboardBufferLoadAction,
+		"clipboard:buffer:edit":              ClipboardBufferEditAction,
+		"clipboard:history:insert":           ClipboardHistoryInsertAction,
+		"clipboard:history:copy":             ClipboardHistoryCopyAction,
+		"clipboard:history:pin":              ClipboardHistoryPinAction,
+		"clipboard:history:delete":           ClipboardHistoryDeleteAction,
+		"trash:restore":                      TrashRestoreAction,
+		"trash:restore-new":                  TrashRestoreNewAction,
+		"trash:delete":                       TrashDeleteAction,
+		"undo:last":                          UndoLastAction,
+		"undo:history":                       UndoHistoryAction,
+		"worktree:window":                    WorktreeWindowAction,
+		"worktree:session":                   WorktreeSessionAction,
+		"worktree:add":                       WorktreeAddAction,
+		"worktree:remove":                    WorktreeRemoveAction,
+		"ssh:window":                         SSHWindowAction,
+		"ssh:split":                          SSHSplitAction,
+		"ssh:tiled":                          SSHTiledAction,
+		"ssh:tiled-sync":                     SSHTiledSyncAction,
+		"task:split":                         TaskSplitAction,
+		"task:window":                        TaskWindowAction,
+		"task:pane":                          TaskPaneAction,
+		"task:rerun":                         TaskRerunAction,
+		"client:detach":                      ClientDetachAction,
+		"client:detach-kill":                 ClientDetachKillAction,
+		"client:switch":                      ClientSwitchAction,
+		"client:read-only":                   ClientReadOnlyAction,
+		"client:refresh":                     ClientRefreshAction,
+		"client:lock":                        ClientLockAction,
+		"server:reload":                      ServerReloadAction,
+		"server:info":                        ServerInfoAction,
+		"server:exit-empty":                  ServerExitEmptyAction,
+		"server:exit-unattached":             ServerExitUnattachedAction,
+		"server:kill-server":                 ServerKillAction,
+		"search:server":                      SearchAction,
+		"search:session":                     SearchAction,
+		"search:window":                      SearchAction,
+		"search:results":                     SearchJumpAction,
 	}
 }
 
 // ActionLoaders enumerates loaders for nested submenu actions.
 func ActionLoaders() map[string]Loader {
 	return map[string]Loader{
-		"session:switch":           loadSessionSwitchMenu,
-		"session:rename":           loadSessionRenameMenu,
-		"session:detach":           loadSessionDetachMenu,
-		"session:kill":             loadSessionKillMenu,
-		"session:tree":             loadSessionTreeMenu,
-		"resurrect:restore-from":   loadResurrectRestoreFromMenu,
-		"resurrect:delete-saved":   loadResurrectDeleteSavedMenu,
-		"window:switch":            loadWindowSwitchMenu,
-		"window:link":              loadWindowLinkMenu,
-		"window:pull-from-session": loadWindowPullFromSessionMenu,
-		"window:push-to-session":   loadWindowPushToSessionMenu,
-		"window:swap":              loadWindowSwapMenu,
-		"window:rename":            loadWindowRenameMenu,
-		"window:kill":              loadWindowKillMenu,
-		"window:layout":            loadWindowLayoutMenu,
-		"pane:switch":              loadPaneSwitchMenu,
-		"pane:break":               loadPaneBreakMenu,
-		"pane:join":                loadPaneJoinMenu,
-		"pane:swap":                loadPaneSwapMenu,
-		"pane:kill":                loadPaneKillMenu,
-		"pane:rename":              loadPaneRenameMenu,
-		"pane:resize":              loadPaneResizeMenu,
-		"pane:resize:left":         loadPaneResizeLeftMenu,
-		"pane:resize:right":        loadPaneResizeRightMenu,
-		"pane:resize:up":           loadPaneResizeUpMenu,
-		"pane:resize:down":         loadPaneResizeDownMenu,
-		"plugins:update":           loadPluginsUpdateMenu,
-		"plugins:uninstall":        loadPluginsUninstallMenu,
+		"session:switch":                     loadSessionSwitchMenu,
+		"session:new-from-template":          loadSessionTemplateMenu,
+		"session:open-project":               loadSessionProjectMenu,
+		"session:new-grouped":                loadSessionSourceMenu,
+		"session:clone":                      loadSessionSourceMenu,
+		"session:environment":                loadSessionEnvMenu,
+		"session:environment:set":            loadSessionEnvListMenu,
+		"session:environment:unset":          loadSessionEnvListMenu,
+		"session:environment:remove":         loadSessionEnvListMenu,
+		"session:environment:copy-to-global": loadSessionEnvLocalMenu,
+		"session:environment:refresh-panes":  loadSessionEnvStaleMenu,
+		"session:import":                     loadSessionImportMenu,
+		"session:import:start":               loadSessionImportListMenu,
+		"session:import:convert":             loadSessionImportListMenu,
+		"session:rename":                     loadSessionRenameMenu,
+		"session:detach":                     loadSessionDetachMenu,
+		"session:kill":                       loadSessionKillMenu,
+		"session:tree":                       loadSessionTreeMenu,
+		"resurrect:restore-from":             loadResurrectRestoreFromMenu,
+		"resurrect:delete-saved":             loadResurrectDeleteSavedMenu,
+		"window:switch":                      loadWindowSwitchMenu,
+		"window:link":                        loadWindowLinkMenu,
+		"window:pull-from-session":           loadWindowPullFromSessionMenu,
+		"window:push-to-session":             loadWindowPushToSessionMenu,
+		"window:swap":                        loadWindowSwapMenu,
+		"window:rename":                      loadWindowRenameMenu,
+		"window:kill":                        loadWindowKillMenu,
+		"window:layout":                      loadWindowLayoutMenu,
+		"pane:switch":                        loadPaneSwitchMenu,
+		"pane:break":                         loadPaneBreakMenu,
+		"pane:join":                          loadPaneJoinMenu,
+		"pane:swap":                          loadPaneSwapMenu,
+		"pane:kill":                          loadPaneKillMenu,
+		"pane:rename":                        loadPaneRenameMenu,
+		"pane:broadcast":                     loadPaneBroadcastMenu,
+		"pane:log":                           loadPaneLogMenu,
+		"pane:split":                         loadPaneSplitMenu,
+		"pane:respawn":                       loadPaneRespawnMenu,
+		"pane:log:start":                     loadPaneLogStartMenu,
+		"pane:log:stop":                      loadPaneLogStopMenu,
+		"pane:resize":                        loadPaneResizeMenu,
+		"pane:resize:left":                   loadPaneResizeLeftMenu,
+		"pane:resize:right":                  loadPaneResizeRightMenu,
+		"pane:resize:up":                     loadPaneResizeUpMenu,
+		"pane:resize:down":                   loadPaneResizeDownMenu,
+		"plugins:update":                     loadPluginsUpdateMenu,
+		"plugins:uninstall":                  loadPluginsUninstallMenu,
+		"process:display":                    loadProcessDisplayMenu,
+		"process:tree":                       loadProcessTreeMenu,
+		"process:terminate":                  loadProcessSignalMenu,
+		"process:kill":                       loadProcessSignalMenu,
+		"process:interrupt":                  loadProcessSignalMenu,
+		"process:continue":                   loadProcessSignalMenu,
+		"process:stop":                       loadProcessSignalMenu,
+		"process:quit":                       loadProcessSignalMenu,
+		"process:hangup":                     loadProcessSignalMenu,
+		"clipboard:system":                   loadClipboardSystemMenu,
+		"clipboard:buffer":                   loadClipboardBufferMenu,
+		"clipboard:buffer:paste":             loadBufferListMenu,
+		"clipboard:buffer:rename":            loadBufferListMenu,
+		"clipboard:buffer:delete":            loadBufferListMenu,
+		"clipboard:buffer:save":              loadBufferListMenu,
+		"clipboard:buffer:edit":              loadBufferListMenu,
+		"clipboard:history":                  loadClipboardHistoryMenu,
+		"clipboard:history:insert":           loadClipboardHistoryListMenu,
+		"clipboard:history:copy":             loadClipboardHistoryListMenu,
+		"clipboard:history:pin":              loadClipboardHistoryListMenu,
+		"clipboard:history:delete":           loadClipboardHistoryListMenu,
+		"trash:restore":                      loadTrashListMenu,
+		"trash:restore-new":                  loadTrashListMenu,
+		"trash:delete":                       loadTrashListMenu,
+		"undo:history":                       loadUndoHistoryMenu,
+		"worktree:window":                    loadWorktreeOpenMenu,
+		"worktree:session":                   loadWorktreeOpenMenu,
+		"worktree:remove":                    loadWorktreeRemoveMenu,
+		"ssh:window":                         loadSSHHostMenu,
+		"ssh:split":                          loadSSHHostMenu,
+		"ssh:tiled":                          loadSSHHostMenu,
+		"ssh:tiled-sync":                     loadSSHHostMenu,
+		"task:split":                         loadTaskListMenu,
+		"task:window":                        loadTaskListMenu,
+		"task:pane":                          loadTaskListMenu,
+		"client:detach":                      loadClientListMenu,
+		"client:detach-kill":                 loadClientListMenu,
+		"client:switch":                      loadClientListMenu,
+		"client:read-only":                   loadClientListMenu,
+		"client:refresh":                     loadClientListMenu,
+		"client:lock":                        loadClientListMenu,
+		"search:results":                     loadSearchResultsMenu,
 	}
 }
 
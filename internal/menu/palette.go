@@ -0,0 +1,79 @@
+package menu
+
+import (
+	"slices"
+	"strings"
+)
+
+// PaletteID is the registry node of the action palette: every other node of
+// the registry, plus the live items of the switch menus, flattened into one
+// filterable list.
+const PaletteID = "palette"
+
+// paletteLiveNodes are the switch menus whose items join the palette, so a
+// session, window or pane is one pick away too.
+var paletteLiveNodes = []string{"session:switch", "window:switch", "pane:switch"}
+
+// paletteCrumb separates the breadcrumb segments of a palette label.
+const paletteCrumb = " → "
+
+// paletteItems builds the palette for the registry. A node entry's ID is the
+// node ID; a live entry's ID is the node ID and the item ID joined by a tab
+// (see PaletteTarget).
+func (r *Registry) paletteItems(ctx Context) ([]Item, error) {
+	ids := make([]string, 0, len(r.nodes))
+	for id, node := range r.nodes {
+		if id == "root" || id == PaletteID {
+			continue
+		}
+		if node.Loader == nil && node.Action == nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	items := make([]Item, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, Item{ID: id, Label: paletteLabel(id)})
+	}
+	for _, id := range paletteLiveNodes {
+		node, ok := r.nodes[id]
+		if !ok || node.Loader == nil || node.Action == nil {
+			continue
+		}
+		live, err := node.Loader(ctx)
+		if err != nil {
+			return items, err
+		}
+		for _, item := range live {
+			if item.Header {
+				continue
+			}
+			items = append(items, Item{
+				ID:    id + "\t" + item.ID,
+				Label: paletteLabel(id) + paletteCrumb + strings.Join(strings.Fields(item.Label), " "),
+			})
+		}
+	}
+	return items, nil
+}
+
+// PaletteTarget splits a palette item ID into the registry node it stands for
+// and, for a live entry, the item to run that node's action on.
+func PaletteTarget(id string) (nodeID, itemID string) {
+	nodeID, itemID, _ = strings.Cut(id, "\t")
+	return nodeID, itemID
+}
+
+// PaletteLabel returns the palette label of a live entry without its
+// breadcrumb, for action messages such as "Switched to …".
+func PaletteLabel(label string) string {
+	if idx := strings.LastIndex(label, paletteCrumb); idx >= 0 {
+		return label[idx+len(paletteCrumb):]
+	}
+	return label
+}
+
+func paletteLabel(id string) string {
+	return strings.ReplaceAll(id, ":", paletteCrumb)
+}
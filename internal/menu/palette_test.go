@@ -0,0 +1,71 @@
+package menu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaletteListsRegistryNodesWithBreadcrumbs(t *testing.T) {
+	reg := BuildRegistry()
+	node, ok := reg.Find(PaletteID)
+	if !ok || node.Loader == nil {
+		t.Fatal("expected a palette node with a loader")
+	}
+	items, err := node.Loader(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := make(map[string]string, len(items))
+	for _, item := range items {
+		labels[item.ID] = item.Label
+	}
+	if got := labels["pane:resize:left"]; got != "pane → resize → left" {
+		t.Fatalf("pane:resize:left label = %q", got)
+	}
+	if _, ok := labels["session:new"]; !ok {
+		t.Fatal("expected action-only nodes in the palette")
+	}
+	for _, id := range []string{"root", PaletteID} {
+		if _, ok := labels[id]; ok {
+			t.Fatalf("palette must not list %q", id)
+		}
+	}
+}
+
+func TestPaletteIncludesLiveSwitchItems(t *testing.T) {
+	reg := BuildRegistry()
+	node, _ := reg.Find(PaletteID)
+	items, err := node.Loader(Context{
+		IncludeCurrent: true,
+		Sessions:       []SessionEntry{{Name: "work", Label: "work"}},
+		Panes:          []PaneEntry{{ID: "%3", Label: "work:1.0  vim"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session, pane *Item
+	for i := range items {
+		switch nodeID, itemID := PaletteTarget(items[i].ID); {
+		case nodeID == "session:switch" && itemID == "work":
+			session = &items[i]
+		case nodeID == "pane:switch" && itemID == "%3":
+			pane = &items[i]
+		}
+	}
+	if session == nil || !strings.HasPrefix(session.Label, "session → switch → work") {
+		t.Fatalf("expected live session entry, got %#v", session)
+	}
+	if pane == nil || pane.Label != "pane → switch → work:1.0 vim" {
+		t.Fatalf("expected flattened live pane entry, got %#v", pane)
+	}
+	if got := PaletteLabel(pane.Label); got != "work:1.0 vim" {
+		t.Fatalf("PaletteLabel = %q", got)
+	}
+}
+
+func TestPaletteTargetOfNodeEntry(t *testing.T) {
+	nodeID, itemID := PaletteTarget("window:layout")
+	if nodeID != "window:layout" || itemID != "" {
+		t.Fatalf("got %q %q", nodeID, itemID)
+	}
+}
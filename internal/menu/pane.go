@@ -13,6 +13,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 
 	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
 	"github.com/atomicstack/tmux-popup-control/internal/tmux"
 )
 
@@ -36,6 +37,10 @@ func loadPaneMenu(Context) ([]Item, error) {
 		"join",
 		"break",
 		"capture",
+		"broadcast",
+		"log",
+		"split",
+		"respawn",
 		"switch",
 		// ^^^ do NOT reorder these! ^^^
 	}
@@ -101,8 +106,8 @@ func loadPaneRenameMenu(ctx Context) ([]Item, error) {
 }
 
 func loadPaneResizeMenu(Context) ([]Item, error) {
-	directions := []string{"left", "right", "up", "down"}
-	return menuItemsFromIDs(directions), nil
+	items := []string{"left", "right", "up", "down", "interactive"}
+	return menuItemsFromIDs(items), nil
 }
 
 func loadPaneResizeAmountMenu(direction string) ([]Item, error) {
@@ -143,6 +148,7 @@ func PaneKillAction(ctx Context, item Item) tea.Cmd {
 	label := item.Label
 	return func() tea.Msg {
 		events.Pane.Kill(sorted)
+		trashKilledFn(ctx, resurrect.TrashPane, sorted)
 		if err := killPanesFn(ctx.SocketPath, sorted); err != nil {
 			return ActionResult{Err: err}
 		}
@@ -308,6 +314,7 @@ func PaneEntriesFromTmux(panes []tmux.Pane) []PaneEntry {
 			Current:   p.Current,
 			Title:     p.Title,
 			Command:   p.Command,
+			PID:       p.PID,
 		})
 	}
 	return entries
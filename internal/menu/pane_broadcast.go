@@ -0,0 +1,151 @@
+package menu
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+)
+
+// loadPaneBroadcastMenu lists every pane on the server, for marking the
+// ones to send input to.
+func loadPaneBroadcastMenu(ctx Context) ([]Item, error) {
+	return PaneEntriesToItems(ctx.Panes), nil
+}
+
+// PaneBroadcastPrompt asks for the input to send to Targets.
+type PaneBroadcastPrompt struct {
+	Context Context
+	Targets []string
+}
+
+// PaneBroadcastAction opens the input form for the selected panes.
+func PaneBroadcastAction(ctx Context, item Item) tea.Cmd {
+	targets := splitSelectionIDs(item.ID)
+	if len(targets) == 0 {
+		return failCmd("no pane selected")
+	}
+	return func() tea.Msg {
+		return PaneBroadcastPrompt{Context: ctx, Targets: targets}
+	}
+}
+
+// PaneBroadcastCommand sends input to every target and reports how each one
+// went. In literal mode the text is typed as is and followed by Enter; in
+// key mode input is a space-separated list of key names, such as "C-c" or
+// "q Enter", sent without anything added.
+func PaneBroadcastCommand(ctx Context, targets []string, input string, keys bool) tea.Cmd {
+	return func() tea.Msg {
+		width := 0
+		for _, t := range targets {
+			width = max(width, len(t))
+		}
+		var (
+			lines  []string
+			failed int
+		)
+		for _, target := range targets {
+			status := "sent"
+			if err := broadcastTo(ctx, target, input, keys); err != nil {
+				failed++
+				status = err.Error()
+			}
+			lines = append(lines, fmt.Sprintf("%-*s  %s", width, target, status))
+		}
+		events.Pane.Broadcast(targets, keys, failed)
+		header := fmt.Sprintf("Sent to %d of %d pane(s)", len(targets)-failed, len(targets))
+		return ActionResult{Output: strings.Join(append([]string{header, ""}, lines...), "\n")}
+	}
+}
+
+func broadcastTo(ctx Context, target, input string, keys bool) error {
+	if keys {
+		_, err := tmuxOutput(ctx.SocketPath, append([]string{"send-keys", "-t", target}, strings.Fields(input)...)...)
+		return err
+	}
+	if _, err := tmuxOutput(ctx.SocketPath, "send-keys", "-t", target, "-l", input); err != nil {
+		return err
+	}
+	_, err := tmuxOutput(ctx.SocketPath, "send-keys", "-t", target, "Enter")
+	return err
+}
+
+// PaneBroadcastForm takes the input to broadcast. Tab switches between
+// literal text and key names.
+type PaneBroadcastForm struct {
+	input   textinput.Model
+	ctx     Context
+	targets []string
+	keys    bool
+}
+
+func NewPaneBroadcastForm(prompt PaneBroadcastPrompt) *PaneBroadcastForm {
+	ti := textinput.New()
+	styleFormInput(&ti)
+	ti.Placeholder = "command"
+	ti.CharLimit = 4096
+	ti.SetWidth(60)
+	ti.Focus()
+	return &PaneBroadcastForm{input: ti, ctx: prompt.Context, targets: prompt.Targets}
+}
+
+func (f *PaneBroadcastForm) Context() Context    { return f.ctx }
+func (f *PaneBroadcastForm) Target() string      { return strings.Join(f.targets, "\n") }
+func (f *PaneBroadcastForm) Value() string       { return f.input.Value() }
+func (f *PaneBroadcastForm) Keys() bool          { return f.keys }
+func (f *PaneBroadcastForm) InputView() string   { return f.input.View() }
+func (f *PaneBroadcastForm) Cursor() *tea.Cursor { return f.input.Cursor() }
+func (f *PaneBroadcastForm) FocusCmd() tea.Cmd   { return f.input.Focus() }
+func (f *PaneBroadcastForm) ActionID() string    { return "pane:broadcast" }
+func (f *PaneBroadcastForm) PendingLabel() string {
+	return fmt.Sprintf("broadcast to %d pane(s)", len(f.targets))
+}
+
+func (f *PaneBroadcastForm) Title() string {
+	if len(f.targets) == 1 {
+		return "Send input to " + f.targets[0]
+	}
+	return fmt.Sprintf("Send input to %d panes", len(f.targets))
+}
+
+// Help says how the input will be sent.
+func (f *PaneBroadcastForm) Help() string {
+	if f.keys {
+		return "Mode: key names, e.g. C-c or q Enter (Tab for literal). Press Enter to send. Esc to cancel."
+	}
+	return "Mode: literal, followed by Enter (Tab for key names). Press Enter to send. Esc to cancel."
+}
+
+func (f *PaneBroadcastForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch m.String() {
+		case "ctrl+u":
+			if f.input.Value() != "" {
+				f.input.SetValue("")
+				f.input.CursorStart()
+			}
+			return nil, false, false
+		case "tab":
+			f.keys = !f.keys
+			if f.keys {
+				f.input.Placeholder = "keys"
+			} else {
+				f.input.Placeholder = "command"
+			}
+			return nil, false, false
+		case "esc":
+			return nil, false, true
+		case "enter":
+			if strings.TrimSpace(f.input.Value()) == "" {
+				return nil, false, true
+			}
+			return PaneBroadcastCommand(f.ctx, f.targets, f.input.Value(), f.keys), true, false
+		}
+	}
+	updated, cmd := f.input.Update(msg)
+	f.input = updated
+	return cmd, false, false
+}
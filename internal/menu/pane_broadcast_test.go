@@ -0,0 +1,63 @@
+package menu
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestPaneBroadcastCommand(t *testing.T) {
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if args[2] == "gone:0.0" {
+			return []byte("can't find pane: gone:0.0"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}))
+	targets := []string{"work:0.0", "gone:0.0", "ops:1.2"}
+
+	res := PaneBroadcastCommand(Context{}, targets, "uptime; df -h", false)().(ActionResult)
+	want := []string{
+		"send-keys -t work:0.0 -l uptime; df -h",
+		"send-keys -t work:0.0 Enter",
+		"send-keys -t gone:0.0 -l uptime; df -h",
+		"send-keys -t ops:1.2 -l uptime; df -h",
+		"send-keys -t ops:1.2 Enter",
+	}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	lines := strings.Split(res.Output, "\n")
+	if lines[0] != "Sent to 2 of 3 pane(s)" || lines[2] != "work:0.0  sent" || !strings.Contains(lines[3], "can't find pane") {
+		t.Fatalf("unexpected summary:\n%s", res.Output)
+	}
+
+	calls = nil
+	PaneBroadcastCommand(Context{}, targets[:1], "C-c  q Enter", true)()
+	if want := []string{"send-keys -t work:0.0 C-c q Enter"}; !slices.Equal(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestPaneBroadcastForm(t *testing.T) {
+	prompt := PaneBroadcastAction(Context{}, Item{ID: "work:0.0\nops:1.2"})().(PaneBroadcastPrompt)
+	form := NewPaneBroadcastForm(prompt)
+	if form.Title() != "Send input to 2 panes" || form.Target() != "work:0.0\nops:1.2" {
+		t.Fatalf("unexpected form %q %q", form.Title(), form.Target())
+	}
+	form.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if !form.Keys() || !strings.HasPrefix(form.Help(), "Mode: key names") {
+		t.Fatalf("expected key mode, got %q", form.Help())
+	}
+	if _, done, cancel := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done || !cancel {
+		t.Fatal("expected an empty entry to cancel")
+	}
+	form.Update(tea.KeyPressMsg{Code: 'q', Text: "q"})
+	if cmd, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); !done || cmd == nil {
+		t.Fatal("expected the form to submit")
+	}
+}
@@ -0,0 +1,280 @@
+package menu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/panelog"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+const (
+	defaultLogTemplate = "~/tmux-logs/#{session_name}.#{window_index}.#{pane_index}.%F-%H-%M-%S.log"
+	// paneLogPathOption remembers, per pane, the file its output last went
+	// to, so the menus and preview can find it again.
+	paneLogPathOption = "@tmux-popup-control-log-path"
+	paneLogTailLines  = 200
+)
+
+var (
+	paneLogExecutableFn = os.Executable
+	expandFormatFn      = tmux.ExpandFormat
+)
+
+func loadPaneLogMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{"start", "stop"}), nil
+}
+
+// paneLogState is whether a pane's output is being piped, and where its
+// last log went.
+type paneLogState struct {
+	piped bool
+	path  string
+}
+
+// paneLogStates maps pane ids to their logging state.
+func paneLogStates(ctx Context) (map[string]paneLogState, error) {
+	out, err := tmuxOutput(ctx.SocketPath, "list-panes", "-a", "-F", "#{pane_id}\t#{pane_pipe}\t#{"+paneLogPathOption+"}")
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]paneLogState)
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		states[fields[0]] = paneLogState{piped: fields[1] == "1", path: fields[2]}
+	}
+	return states, nil
+}
+
+// paneLogItems lists the panes whose state passes keep, marking the ones
+// being logged.
+func paneLogItems(ctx Context, keep func(paneLogState) bool) ([]Item, error) {
+	states, err := paneLogStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, 0, len(ctx.Panes))
+	for _, entry := range ctx.Panes {
+		state := states[entry.PaneID]
+		if !keep(state) {
+			continue
+		}
+		label := entry.Label
+		if state.piped {
+			label += "  [logging]"
+		}
+		items = append(items, Item{ID: entry.ID, Label: label})
+	}
+	return items, nil
+}
+
+func loadPaneLogStartMenu(ctx Context) ([]Item, error) {
+	return paneLogItems(ctx, func(paneLogState) bool { return true })
+}
+
+func loadPaneLogStopMenu(ctx Context) ([]Item, error) {
+	return paneLogItems(ctx, func(s paneLogState) bool { return s.piped })
+}
+
+// paneLogPreview shows whether the highlighted pane is being logged and the
+// end of its most recent log.
+func paneLogPreview(ctx Context, item Item) ([]string, error) {
+	out, err := tmuxOutput(ctx.SocketPath, "display-message", "-p", "-t", item.ID, "#{pane_pipe}\t#{"+paneLogPathOption+"}")
+	if err != nil {
+		return nil, err
+	}
+	piped, path, _ := strings.Cut(strings.TrimRight(out, "\n"), "\t")
+	if path == "" {
+		if piped == "1" {
+			return []string{"piped elsewhere (not started from this menu)"}, nil
+		}
+		return []string{"not logging"}, nil
+	}
+	status := "logging to " + path
+	if piped != "1" {
+		status = "last logged to " + path
+	}
+	lines, err := panelog.Tail(path, paneLogTailLines)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(ansi.Strip(line), "\r", "")
+	}
+	return append([]string{status, ""}, lines...), nil
+}
+
+// PaneLogPrompt asks for the log file template for Targets.
+type PaneLogPrompt struct {
+	Context  Context
+	Targets  []string
+	Template string
+}
+
+// PaneLogStartAction opens the log form for the selected panes.
+func PaneLogStartAction(ctx Context, item Item) tea.Cmd {
+	targets := splitSelectionIDs(item.ID)
+	if len(targets) == 0 {
+		return failCmd("no pane selected")
+	}
+	return func() tea.Msg {
+		return PaneLogPrompt{Context: ctx, Targets: targets, Template: defaultLogTemplate}
+	}
+}
+
+// PaneLogStartCommand pipes each target's output to the file template
+// expands to for that pane, replacing any pipe it already has. With strip
+// set, output passes through the binary's log filter so the file holds
+// plain text.
+func PaneLogStartCommand(ctx Context, targets []string, template string, strip bool) tea.Cmd {
+	return func() tea.Msg {
+		binary, err := paneLogExecutableFn()
+		if err != nil {
+			return ActionResult{Err: fmt.Errorf("locate executable: %w", err)}
+		}
+		template = expandStrftime(expandTilde(template))
+		width := 0
+		for _, t := range targets {
+			width = max(width, len(t))
+		}
+		var (
+			lines  []string
+			failed int
+		)
+		for _, target := range targets {
+			status, err := startPaneLog(ctx, target, template, binary, strip)
+			if err != nil {
+				failed++
+				status = err.Error()
+			}
+			lines = append(lines, fmt.Sprintf("%-*s  %s", width, target, status))
+		}
+		events.Pane.LogStart(targets, template, strip, failed)
+		header := fmt.Sprintf("Logging %d of %d pane(s)", len(targets)-failed, len(targets))
+		return ActionResult{Output: strings.Join(append([]string{header, ""}, lines...), "\n")}
+	}
+}
+
+func startPaneLog(ctx Context, target, template, binary string, strip bool) (string, error) {
+	path, err := expandFormatFn(ctx.SocketPath, target, template)
+	if err != nil {
+		return "", fmt.Errorf("expand path: %w", err)
+	}
+	dir := filepath.Dir(path)
+	// owner-only, like capture: the log holds everything the pane prints.
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create directory %s: %w", dir, err)
+	}
+	if err := panelog.Create(path); err != nil {
+		return "", err
+	}
+	if _, err := tmuxOutput(ctx.SocketPath, "pipe-pane", "-t", target, panelog.PipeCommand(binary, path, strip)); err != nil {
+		return "", err
+	}
+	if _, err := tmuxOutput(ctx.SocketPath, "set-option", "-p", "-t", target, paneLogPathOption, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// PaneLogStopAction closes the pipe of every selected pane. The log path
+// option is left behind so the preview can still show the finished log.
+func PaneLogStopAction(ctx Context, item Item) tea.Cmd {
+	targets := splitSelectionIDs(item.ID)
+	if len(targets) == 0 {
+		return failCmd("no pane selected")
+	}
+	return func() tea.Msg {
+		for _, target := range targets {
+			if _, err := tmuxOutput(ctx.SocketPath, "pipe-pane", "-t", target); err != nil {
+				return ActionResult{Err: err}
+			}
+		}
+		events.Pane.LogStop(targets)
+		return ActionResult{Info: fmt.Sprintf("Stopped logging %d pane(s)", len(targets))}
+	}
+}
+
+// PaneLogForm takes the log file template. Tab toggles stripping escape
+// sequences from the log.
+type PaneLogForm struct {
+	input   textinput.Model
+	ctx     Context
+	targets []string
+	keepRaw bool
+}
+
+func NewPaneLogForm(prompt PaneLogPrompt) *PaneLogForm {
+	ti := textinput.New()
+	styleFormInput(&ti)
+	ti.Placeholder = "log file"
+	ti.CharLimit = 512
+	ti.SetWidth(60)
+	ti.SetValue(prompt.Template)
+	ti.CursorEnd()
+	ti.Focus()
+	return &PaneLogForm{input: ti, ctx: prompt.Context, targets: prompt.Targets}
+}
+
+func (f *PaneLogForm) Context() Context    { return f.ctx }
+func (f *PaneLogForm) Target() string      { return strings.Join(f.targets, "\n") }
+func (f *PaneLogForm) Value() string       { return f.input.Value() }
+func (f *PaneLogForm) Strip() bool         { return !f.keepRaw }
+func (f *PaneLogForm) InputView() string   { return f.input.View() }
+func (f *PaneLogForm) Cursor() *tea.Cursor { return f.input.Cursor() }
+func (f *PaneLogForm) FocusCmd() tea.Cmd   { return f.input.Focus() }
+func (f *PaneLogForm) ActionID() string    { return "pane:log:start" }
+func (f *PaneLogForm) PendingLabel() string {
+	return fmt.Sprintf("log %d pane(s)", len(f.targets))
+}
+
+func (f *PaneLogForm) Title() string {
+	if len(f.targets) == 1 {
+		return "Log " + f.targets[0] + " to"
+	}
+	return fmt.Sprintf("Log %d panes to", len(f.targets))
+}
+
+// Help says whether escape sequences will be kept.
+func (f *PaneLogForm) Help() string {
+	if f.keepRaw {
+		return "Escape sequences: kept (Tab to strip). Press Enter to start. Esc to cancel."
+	}
+	return "Escape sequences: stripped (Tab to keep). Press Enter to start. Esc to cancel."
+}
+
+func (f *PaneLogForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch m.String() {
+		case "ctrl+u":
+			if f.input.Value() != "" {
+				f.input.SetValue("")
+				f.input.CursorStart()
+			}
+			return nil, false, false
+		case "tab":
+			f.keepRaw = !f.keepRaw
+			return nil, false, false
+		case "esc":
+			return nil, false, true
+		case "enter":
+			if strings.TrimSpace(f.input.Value()) == "" {
+				return nil, false, true
+			}
+			return PaneLogStartCommand(f.ctx, f.targets, f.input.Value(), f.Strip()), true, false
+		}
+	}
+	updated, cmd := f.input.Update(msg)
+	f.input = updated
+	return cmd, false, false
+}
@@ -0,0 +1,135 @@
+package menu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestLoadPaneLogMenus(t *testing.T) {
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		return []byte("%1\t1\t/logs/a.log\n%2\t0\t/logs/old.log\n%3\t0\t\n"), nil
+	}))
+	ctx := Context{Panes: []PaneEntry{
+		{ID: "work:0.0", PaneID: "%1", Label: "work:0.0 vim"},
+		{ID: "work:0.1", PaneID: "%2", Label: "work:0.1 zsh"},
+		{ID: "ops:1.0", PaneID: "%3", Label: "ops:1.0 top"},
+	}}
+	items, err := loadPaneLogStartMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{
+		{ID: "work:0.0", Label: "work:0.0 vim  [logging]"},
+		{ID: "work:0.1", Label: "work:0.1 zsh"},
+		{ID: "ops:1.0", Label: "ops:1.0 top"},
+	}
+	if !slices.Equal(items, want) {
+		t.Fatalf("start items = %#v", items)
+	}
+	items, err = loadPaneLogStopMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(items, want[:1]) {
+		t.Fatalf("stop items = %#v", items)
+	}
+}
+
+func TestPaneLogStartCommand(t *testing.T) {
+	dir := t.TempDir()
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		return nil, nil
+	}))
+	t.Cleanup(withPaneStub(&paneLogExecutableFn, func() (string, error) { return "/bin/tpc", nil }))
+	t.Cleanup(withPaneStub(&expandFormatFn, func(_, target, format string) (string, error) {
+		if target == "gone:0.0" {
+			return "", errors.New("can't find pane")
+		}
+		return strings.ReplaceAll(format, "#{pane_id}", strings.ReplaceAll(target, ":", "-")), nil
+	}))
+
+	template := filepath.Join(dir, "logs", "#{pane_id}.log")
+	res := PaneLogStartCommand(Context{}, []string{"work:0.0", "gone:0.0"}, template, true)().(ActionResult)
+	path := filepath.Join(dir, "logs", "work-0.0.log")
+	want := []string{
+		"pipe-pane -t work:0.0 '/bin/tpc' 'log-filter' >> '" + path + "'",
+		"set-option -p -t work:0.0 @tmux-popup-control-log-path " + path,
+	}
+	if !slices.Equal(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected an owner-only log file, got %v %v", info, err)
+	}
+	lines := strings.Split(res.Output, "\n")
+	if lines[0] != "Logging 1 of 2 pane(s)" || !strings.HasSuffix(lines[2], path) || !strings.Contains(lines[3], "can't find pane") {
+		t.Fatalf("unexpected summary:\n%s", res.Output)
+	}
+
+	calls = nil
+	PaneLogStartCommand(Context{}, []string{"work:0.0"}, template, false)()
+	if !strings.HasSuffix(calls[0], "cat >> '"+path+"'") {
+		t.Fatalf("expected raw output to be appended with cat, got %q", calls[0])
+	}
+}
+
+func TestPaneLogStopAction(t *testing.T) {
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		return nil, nil
+	}))
+	res := PaneLogStopAction(Context{}, Item{ID: "work:0.0\nops:1.0"})().(ActionResult)
+	if want := []string{"pipe-pane -t work:0.0", "pipe-pane -t ops:1.0"}; !slices.Equal(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+	if res.Info != "Stopped logging 2 pane(s)" {
+		t.Fatalf("info = %q", res.Info)
+	}
+}
+
+func TestPaneLogPreview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane.log")
+	if err := os.WriteFile(path, []byte("\x1b[31mred\x1b[0m\r\nplain\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state := "1\t" + path
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		return []byte(state + "\n"), nil
+	}))
+	lines, err := paneLogPreview(Context{}, Item{ID: "work:0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"logging to " + path, "", "red", "plain"}; !slices.Equal(lines, want) {
+		t.Fatalf("preview = %q, want %q", lines, want)
+	}
+	state = "0\t"
+	if lines, _ := paneLogPreview(Context{}, Item{ID: "work:0.0"}); !slices.Equal(lines, []string{"not logging"}) {
+		t.Fatalf("preview = %q", lines)
+	}
+}
+
+func TestPaneLogForm(t *testing.T) {
+	prompt := PaneLogStartAction(Context{}, Item{ID: "work:0.0"})().(PaneLogPrompt)
+	form := NewPaneLogForm(prompt)
+	if form.Value() != defaultLogTemplate || form.Title() != "Log work:0.0 to" || !form.Strip() {
+		t.Fatalf("unexpected form %q %q %v", form.Value(), form.Title(), form.Strip())
+	}
+	form.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if form.Strip() || !strings.HasPrefix(form.Help(), "Escape sequences: kept") {
+		t.Fatalf("expected raw mode, got %q", form.Help())
+	}
+	if cmd, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); !done || cmd == nil {
+		t.Fatal("expected the form to submit")
+	}
+}
@@ -0,0 +1,345 @@
+package menu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+)
+
+const (
+	paneGeometryFormat = "#{window_layout}\t#{window_zoomed_flag}\t#{pane_id}"
+	// layoutDiagramCols is the width the geometry diagram is drawn at; its
+	// height follows the window's proportions.
+	layoutDiagramCols = 60
+	layoutDiagramMin  = 5
+	layoutDiagramMax  = 24
+)
+
+// paneGeometry is the layout of a pane's window, with the pane in it.
+type paneGeometry struct {
+	layout resurrect.LayoutCell
+	zoomed bool
+	paneID int
+}
+
+func readPaneGeometry(ctx Context, target string) (paneGeometry, error) {
+	out, err := tmuxOutput(ctx.SocketPath, "display-message", "-p", "-t", target, paneGeometryFormat)
+	if err != nil {
+		return paneGeometry{}, err
+	}
+	fields := strings.Split(strings.TrimSpace(out), "\t")
+	if len(fields) != 3 {
+		return paneGeometry{}, fmt.Errorf("unexpected geometry %q", out)
+	}
+	layout, err := resurrect.ParseLayout(fields[0])
+	if err != nil {
+		return paneGeometry{}, err
+	}
+	paneID, _ := strconv.Atoi(strings.TrimPrefix(fields[2], "%"))
+	return paneGeometry{layout: layout, zoomed: fields[1] == "1", paneID: paneID}, nil
+}
+
+// layoutDiagram draws the panes of root as ASCII boxes scaled to cols
+// columns, each labelled with its id and size, and the pane numbered mark
+// starred.
+func layoutDiagram(root resurrect.LayoutCell, mark, cols int) []string {
+	if root.Width <= 0 || root.Height <= 0 {
+		return nil
+	}
+	rows := min(max(cols*root.Height/root.Width, layoutDiagramMin), layoutDiagramMax)
+	grid := make([][]rune, rows)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", cols))
+	}
+	// borders sit one cell outside each pane, so map [-1, size] onto the grid.
+	mapX := func(x int) int { return ((x+1)*(cols-1)*2 + root.Width + 1) / ((root.Width + 1) * 2) }
+	mapY := func(y int) int { return ((y+1)*(rows-1)*2 + root.Height + 1) / ((root.Height + 1) * 2) }
+	put := func(y, x int, r rune) {
+		if cur := grid[y][x]; cur != ' ' && cur != r {
+			r = '+'
+		}
+		grid[y][x] = r
+	}
+	for i, pane := range root.Panes() {
+		l, r := mapX(pane.X-1), mapX(pane.X+pane.Width)
+		t, b := mapY(pane.Y-1), mapY(pane.Y+pane.Height)
+		for x := l; x <= r; x++ {
+			put(t, x, '-')
+			put(b, x, '-')
+		}
+		for y := t; y <= b; y++ {
+			put(y, l, '|')
+			put(y, r, '|')
+		}
+		for _, c := range [][2]int{{t, l}, {t, r}, {b, l}, {b, r}} {
+			grid[c[0]][c[1]] = '+'
+		}
+		label := fmt.Sprintf("#%d %dx%d", i, pane.Width, pane.Height)
+		if pane.PaneID >= 0 {
+			label = fmt.Sprintf("%%%d %dx%d", pane.PaneID, pane.Width, pane.Height)
+		}
+		if pane.PaneID == mark {
+			label = "*" + label
+		}
+		inner := r - l - 1
+		if inner <= 0 || b-t < 2 {
+			continue
+		}
+		label = string([]rune(label)[:min(len([]rune(label)), inner)])
+		start := l + 1 + (inner-len([]rune(label)))/2
+		copy(grid[t+(b-t)/2][start:], []rune(label))
+	}
+	lines := make([]string, rows)
+	for i, row := range grid {
+		lines[i] = strings.TrimRight(string(row), " ")
+	}
+	return lines
+}
+
+// equalizeArgs returns the tmux command that evens out the split holding
+// pane. When every pane of the window sits in one row or column that is
+// select-layout's even-horizontal or even-vertical; otherwise each cell of
+// the pane's parent split is resized to an equal share, leaving the rest of
+// the layout as it was. how names what was done, for the trace; both are
+// empty when the pane has no siblings.
+func equalizeArgs(root resurrect.LayoutCell, pane int, target string) (how string, args []string) {
+	parent, ok := parentSplit(root, pane)
+	if !ok {
+		return "", nil
+	}
+	if len(root.Panes()) == len(root.Children) {
+		how = "even-vertical"
+		if root.Horizontal {
+			how = "even-horizontal"
+		}
+		return how, []string{"select-layout", "-t", target, how}
+	}
+	total, flag := parent.Height, "-y"
+	if parent.Horizontal {
+		total, flag = parent.Width, "-x"
+	}
+	// siblings share what the borders between them leave; the first few
+	// take the remainder. Sizing all but the last leaves the last one the
+	// rest.
+	n := len(parent.Children)
+	share, extra := (total-(n-1))/n, (total-(n-1))%n
+	for i, child := range parent.Children[:n-1] {
+		size := share
+		if i < extra {
+			size++
+		}
+		if len(args) > 0 {
+			args = append(args, ";")
+		}
+		args = append(args, "resize-pane", "-t", "%"+strconv.Itoa(child.Panes()[0].PaneID), flag, strconv.Itoa(size))
+	}
+	return "split", args
+}
+
+// parentSplit finds the split cell directly holding the pane numbered pane.
+func parentSplit(c resurrect.LayoutCell, pane int) (resurrect.LayoutCell, bool) {
+	for _, child := range c.Children {
+		if len(child.Children) == 0 {
+			if child.PaneID == pane {
+				return c, true
+			}
+			continue
+		}
+		if parent, ok := parentSplit(child, pane); ok {
+			return parent, true
+		}
+	}
+	return resurrect.LayoutCell{}, false
+}
+
+// paneResizePreview draws the current window's geometry for the resize menus.
+func paneResizePreview(ctx Context, _ Item) ([]string, error) {
+	geo, err := readPaneGeometry(ctx, ctx.CurrentPaneID)
+	if err != nil {
+		return nil, err
+	}
+	return layoutDiagram(geo.layout, geo.paneID, layoutDiagramCols), nil
+}
+
+// PaneResizePrompt asks the UI to enter the interactive resize mode.
+type PaneResizePrompt struct {
+	Context Context
+	Target  string
+}
+
+// PaneResizeLayoutMsg carries the window geometry back to the resize mode
+// after each step.
+type PaneResizeLayoutMsg struct {
+	Layout resurrect.LayoutCell
+	Zoomed bool
+	PaneID int
+	Err    string
+	Seq    int
+}
+
+// PaneResizeInteractiveAction enters the resize mode for the current pane.
+func PaneResizeInteractiveAction(ctx Context, _ Item) tea.Cmd {
+	target := strings.TrimSpace(ctx.CurrentPaneID)
+	if target == "" {
+		return failCmd("no current pane")
+	}
+	return func() tea.Msg {
+		events.Pane.ResizeMode(target)
+		return PaneResizePrompt{Context: ctx, Target: target}
+	}
+}
+
+// PaneResizeMode resizes a pane live: each key runs one tmux command and
+// redraws the window's geometry.
+type PaneResizeMode struct {
+	ctx    Context
+	target string
+	layout resurrect.LayoutCell
+	zoomed bool
+	paneID int
+	err    string
+	seq    int
+}
+
+func NewPaneResizeMode(prompt PaneResizePrompt) *PaneResizeMode {
+	return &PaneResizeMode{ctx: prompt.Context, target: prompt.Target, paneID: -1}
+}
+
+// RefreshCmd reads the geometry without changing anything.
+func (m *PaneResizeMode) RefreshCmd() tea.Cmd {
+	return m.step(nil)
+}
+
+// step runs args, if any, against the target's window and reads the
+// geometry back. A failed command still redraws, with its error shown.
+func (m *PaneResizeMode) step(args []string) tea.Cmd {
+	m.seq++
+	ctx, target, seq := m.ctx, m.target, m.seq
+	return func() tea.Msg {
+		msg := PaneResizeLayoutMsg{Seq: seq}
+		if len(args) > 0 {
+			if _, err := tmuxOutput(ctx.SocketPath, args...); err != nil {
+				msg.Err = err.Error()
+			}
+		}
+		geo, err := readPaneGeometry(ctx, target)
+		if err != nil {
+			msg.Err = err.Error()
+			return msg
+		}
+		msg.Layout, msg.Zoomed, msg.PaneID = geo.layout, geo.zoomed, geo.paneID
+		return msg
+	}
+}
+
+// SetLayout applies a PaneResizeLayoutMsg, unless a later step has
+// already been sent.
+func (m *PaneResizeMode) SetLayout(msg PaneResizeLayoutMsg) {
+	if msg.Seq != m.seq {
+		return
+	}
+	m.err = msg.Err
+	if msg.Layout.Width > 0 {
+		m.layout, m.zoomed, m.paneID = msg.Layout, msg.Zoomed, msg.PaneID
+	}
+}
+
+type paneResizeStep struct {
+	direction string
+	flag      string
+	amount    int
+}
+
+var (
+	resizeLeft  = paneResizeStep{"left", "-L", 1}
+	resizeDown  = paneResizeStep{"down", "-D", 1}
+	resizeUp    = paneResizeStep{"up", "-U", 1}
+	resizeRight = paneResizeStep{"right", "-R", 1}
+)
+
+func (s paneResizeStep) times(n int) paneResizeStep {
+	s.amount *= n
+	return s
+}
+
+var paneResizeKeys = map[string]paneResizeStep{
+	"h": resizeLeft, "left": resizeLeft, "H": resizeLeft.times(5), "shift+left": resizeLeft.times(5),
+	"j": resizeDown, "down": resizeDown, "J": resizeDown.times(5), "shift+down": resizeDown.times(5),
+	"k": resizeUp, "up": resizeUp, "K": resizeUp.times(5), "shift+up": resizeUp.times(5),
+	"l": resizeRight, "right": resizeRight, "L": resizeRight.times(5), "shift+right": resizeRight.times(5),
+}
+
+// Update handles a key. It returns the command to run and whether the mode
+// is finished.
+func (m *PaneResizeMode) Update(msg tea.Msg) (tea.Cmd, bool) {
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return nil, false
+	}
+	s := key.String()
+	if r, ok := paneResizeKeys[s]; ok {
+		events.Pane.Resize(r.direction, r.amount)
+		return m.step([]string{"resize-pane", "-t", m.target, r.flag, strconv.Itoa(r.amount)}), false
+	}
+	switch s {
+	case "=":
+		how, args := equalizeArgs(m.layout, m.paneID, m.target)
+		if args == nil {
+			return nil, false
+		}
+		events.Pane.Equalize(m.target, how)
+		return m.step(args), false
+	case "z":
+		events.Pane.Zoom(m.target)
+		return m.step([]string{"resize-pane", "-t", m.target, "-Z"}), false
+	case "esc", "enter", "q":
+		return nil, true
+	}
+	return nil, false
+}
+
+func (m *PaneResizeMode) Title() string {
+	if m.zoomed {
+		return "Resize " + m.target + " (zoomed)"
+	}
+	return "Resize " + m.target
+}
+
+// Diagram draws the window's geometry for the preview panel, at most cols
+// wide (layoutDiagramCols when unknown) and, where the window's proportions
+// allow, rows tall.
+func (m *PaneResizeMode) Diagram(cols, rows int) []string {
+	if m.layout.Width <= 0 || m.layout.Height <= 0 {
+		return nil
+	}
+	if cols <= 0 {
+		cols = layoutDiagramCols
+	}
+	if rows > 0 && cols*m.layout.Height/m.layout.Width > rows {
+		cols = rows * m.layout.Width / m.layout.Height
+	}
+	return layoutDiagram(m.layout, m.paneID, max(cols, layoutDiagramMin))
+}
+
+// View is the target pane's size, or what went wrong reading or changing
+// the layout.
+func (m *PaneResizeMode) View() string {
+	if m.err != "" {
+		return "Error: " + m.err
+	}
+	for _, pane := range m.layout.Panes() {
+		if pane.PaneID == m.paneID && m.layout.Width > 0 {
+			return fmt.Sprintf("%%%d is %dx%d of %dx%d", pane.PaneID, pane.Width, pane.Height, m.layout.Width, m.layout.Height)
+		}
+	}
+	return "loading layout…"
+}
+
+func (m *PaneResizeMode) Help() string {
+	return "hjkl/arrows resize by 1, shift by 5. = evens out the pane's split, z zoom. Enter or Esc when done."
+}
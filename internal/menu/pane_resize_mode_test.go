@@ -0,0 +1,122 @@
+package menu
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+)
+
+const testWindowLayout = "bb62,159x48,0,0{79x48,0,0,1,79x48,80,0[79x24,80,0,2,79x23,80,25,3]}"
+
+func TestLayoutDiagram(t *testing.T) {
+	root, err := resurrect.ParseLayout(testWindowLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := layoutDiagram(root, 2, 40)
+	want := []string{
+		"+-------------------+------------------+",
+		"|                   |                  |",
+		"|                   |                  |",
+		"|                   |    *%2 79x24     |",
+		"|                   |                  |",
+		"|     %1 79x48      |                  |",
+		"|                   +------------------+",
+		"|                   |                  |",
+		"|                   |     %3 79x23     |",
+		"|                   |                  |",
+		"|                   |                  |",
+		"+-------------------+------------------+",
+	}
+	if !slices.Equal(lines, want) {
+		t.Fatalf("diagram:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestEqualizeArgs(t *testing.T) {
+	cases := []struct {
+		layout string
+		pane   int
+		want   string
+	}{
+		{"80x24,0,0,1", 1, ""},
+		{"80x24,0,0{40x24,0,0,1,39x24,41,0,2}", 2, "select-layout -t T even-horizontal"},
+		{"80x24,0,0[80x12,0,0,1,80x11,0,13,2]", 1, "select-layout -t T even-vertical"},
+		// a nested split only evens out the column holding the pane.
+		{testWindowLayout, 3, "resize-pane -t %2 -y 24"},
+		{"159x48,0,0{128x48,0,0,0,30x48,129,0[30x24,129,0,1,30x5,129,25,2,30x17,129,31,3]}", 2,
+			"resize-pane -t %1 -y 16 ; resize-pane -t %2 -y 15"},
+		{testWindowLayout, 1, "resize-pane -t %1 -x 79"},
+	}
+	for _, tc := range cases {
+		root, err := resurrect.ParseLayout(tc.layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, args := equalizeArgs(root, tc.pane, "T")
+		if got := strings.Join(args, " "); got != tc.want {
+			t.Errorf("equalizeArgs(%s, %%%d) = %q, want %q", tc.layout, tc.pane, got, tc.want)
+		}
+	}
+}
+
+func TestPaneResizeMode(t *testing.T) {
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if args[0] == "display-message" {
+			return []byte(testWindowLayout + "\t0\t%2\n"), nil
+		}
+		return nil, nil
+	}))
+	prompt := PaneResizeInteractiveAction(Context{CurrentPaneID: "work:0.1"}, Item{})().(PaneResizePrompt)
+	mode := NewPaneResizeMode(prompt)
+	mode.SetLayout(mode.RefreshCmd()().(PaneResizeLayoutMsg))
+	if diagram := strings.Join(mode.Diagram(60, 0), "\n"); !strings.Contains(diagram, "*%2 79x24") {
+		t.Fatalf("expected the target pane starred:\n%s", diagram)
+	}
+	if got := mode.View(); got != "%2 is 79x24 of 159x48" {
+		t.Fatalf("View = %q", got)
+	}
+
+	for _, key := range []tea.KeyPressMsg{
+		{Code: 'h', Text: "h"},
+		{Code: tea.KeyDown, Mod: tea.ModShift},
+		{Code: '=', Text: "="},
+		{Code: 'z', Text: "z"},
+	} {
+		cmd, done := mode.Update(key)
+		if done || cmd == nil {
+			t.Fatalf("expected a step for %s", key.String())
+		}
+		cmd()
+	}
+	var changes []string
+	for _, c := range calls {
+		if !strings.HasPrefix(c, "display-message") {
+			changes = append(changes, c)
+		}
+	}
+	want := []string{
+		"resize-pane -t work:0.1 -L 1",
+		"resize-pane -t work:0.1 -D 5",
+		"resize-pane -t %2 -y 24",
+		"resize-pane -t work:0.1 -Z",
+	}
+	if !slices.Equal(changes, want) {
+		t.Fatalf("calls = %q, want %q", changes, want)
+	}
+
+	stale := PaneResizeLayoutMsg{Seq: 1, Err: "stale"}
+	mode.SetLayout(stale)
+	if strings.Contains(mode.View(), "stale") {
+		t.Fatal("expected a superseded layout to be ignored")
+	}
+	if _, done := mode.Update(tea.KeyPressMsg{Code: tea.KeyEscape}); !done {
+		t.Fatal("expected esc to leave the mode")
+	}
+}
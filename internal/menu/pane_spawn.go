@@ -0,0 +1,367 @@
+package menu
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/spawn"
+)
+
+var (
+	spawnStateDirFn = resurrect.ResolveDir
+	spawnHomeDirFn  = os.UserHomeDir
+)
+
+// loadPaneSplitMenu lists where the new pane can go, with the split-window
+// flags each placement stands for.
+func loadPaneSplitMenu(Context) ([]Item, error) {
+	cells := [][]string{{"placement", "flags"}}
+	for _, p := range spawn.Placements {
+		cells = append(cells, []string{p.Name, strings.Join(p.Split.Flags(), " ")})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft})
+	items := []Item{{Label: aligned[0], Header: true}}
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: spawn.Placements[i].Name, Label: label})
+	}
+	return items, nil
+}
+
+func loadPaneRespawnMenu(ctx Context) ([]Item, error) {
+	current, ok := currentPaneItem(ctx)
+	return withCurrentFirst(PaneEntriesToItems(ctx.Panes), current, ok), nil
+}
+
+// PaneSpawnPrompt asks for the start directory and command of a pane being
+// split or respawned.
+type PaneSpawnPrompt struct {
+	Context Context
+	Target  string
+	// Placement names the split; empty for a respawn.
+	Placement string
+	// Dir is Target's working directory, the default start directory.
+	Dir     string
+	History []string
+	// Running is the command still running in a pane being respawned, which
+	// respawning kills.
+	Running string
+}
+
+// paneSpawnPrompt reads what the form needs to know about target.
+func paneSpawnPrompt(ctx Context, target, placement string) (PaneSpawnPrompt, error) {
+	out, err := tmuxOutput(ctx.SocketPath, "display-message", "-p", "-t", target, "#{pane_current_path}\t#{pane_dead}\t#{pane_current_command}")
+	if err != nil {
+		return PaneSpawnPrompt{}, err
+	}
+	fields := strings.SplitN(strings.TrimRight(out, "\n"), "\t", 3)
+	if len(fields) != 3 {
+		return PaneSpawnPrompt{}, fmt.Errorf("unexpected pane details %q", out)
+	}
+	prompt := PaneSpawnPrompt{Context: ctx, Target: target, Placement: placement, Dir: fields[0]}
+	if placement == "" && fields[1] != "1" {
+		prompt.Running = fields[2]
+	}
+	if stateDir, err := spawnStateDirFn(ctx.SocketPath); err == nil {
+		prompt.History, _ = spawn.LoadHistory(stateDir)
+	}
+	return prompt, nil
+}
+
+// PaneSplitAction opens the spawn form for splitting the current pane.
+func PaneSplitAction(ctx Context, item Item) tea.Cmd {
+	target := strings.TrimSpace(ctx.CurrentPaneID)
+	if target == "" {
+		return failCmd("no current pane")
+	}
+	if _, ok := spawn.FindPlacement(item.ID); !ok {
+		return failCmd("unknown placement %q", item.ID)
+	}
+	return func() tea.Msg {
+		prompt, err := paneSpawnPrompt(ctx, target, item.ID)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		return prompt
+	}
+}
+
+// PaneRespawnAction opens the spawn form for respawning the selected pane.
+func PaneRespawnAction(ctx Context, item Item) tea.Cmd {
+	target := strings.TrimSpace(item.ID)
+	if target == "" {
+		return failCmd("no pane selected")
+	}
+	return func() tea.Msg {
+		prompt, err := paneSpawnPrompt(ctx, target, "")
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		return prompt
+	}
+}
+
+// PaneSpawnCommand splits or respawns prompt's target to run command in
+// dir, and remembers command for next time. kill respawns a pane whose
+// process is still running.
+func PaneSpawnCommand(prompt PaneSpawnPrompt, percent int, dir, command string, kill bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx := prompt.Context
+		if home, err := spawnHomeDirFn(); err == nil {
+			dir = spawn.ExpandHome(dir, home)
+		}
+		var (
+			args []string
+			info string
+		)
+		if prompt.Placement != "" {
+			split, _ := spawn.FindPlacement(prompt.Placement)
+			args = spawn.SplitArgs(prompt.Target, split, percent, dir, command)
+			info = fmt.Sprintf("Split %s %s", prompt.Target, prompt.Placement)
+			events.Pane.Split(prompt.Target, prompt.Placement, percent)
+		} else {
+			args = spawn.RespawnArgs(prompt.Target, kill, dir, command)
+			info = "Respawned " + prompt.Target
+			events.Pane.Respawn(prompt.Target, kill)
+		}
+		if _, err := tmuxOutput(ctx.SocketPath, args...); err != nil {
+			return ActionResult{Err: err}
+		}
+		if stateDir, err := spawnStateDirFn(ctx.SocketPath); err == nil {
+			if err := spawn.RecordHistory(stateDir, command); err != nil {
+				events.Pane.HistoryError(err)
+			}
+		}
+		return ActionResult{Info: info}
+	}
+}
+
+const (
+	spawnFieldSize = iota
+	spawnFieldDir
+	spawnFieldCommand
+)
+
+var spawnFieldLabels = [...]string{"size", "dir", "command"}
+
+// spawnLabelWidth lines the inputs up after the field labels.
+const spawnLabelWidth = len("command ")
+
+// PaneSpawnForm takes the size of a split, then the start directory, then
+// the command to run. Tab completes the directory, ctrl+r puts the pane's
+// own back, and up and down walk the command history.
+type PaneSpawnForm struct {
+	prompt  PaneSpawnPrompt
+	inputs  [3]textinput.Model
+	fields  []int
+	focus   int // index into fields
+	history *spawn.History
+	status  string
+	confirm bool
+}
+
+func NewPaneSpawnForm(prompt PaneSpawnPrompt) *PaneSpawnForm {
+	f := &PaneSpawnForm{prompt: prompt, history: spawn.NewHistory(prompt.History)}
+	for i := range f.inputs {
+		ti := textinput.New()
+		styleFormInput(&ti)
+		ti.CharLimit = 4096
+		ti.SetWidth(50)
+		f.inputs[i] = ti
+	}
+	f.inputs[spawnFieldSize].Placeholder = "50"
+	f.inputs[spawnFieldSize].CharLimit = 3
+	f.inputs[spawnFieldSize].SetWidth(4)
+	f.inputs[spawnFieldDir].Placeholder = "tmux default"
+	f.inputs[spawnFieldDir].SetValue(prompt.Dir)
+	f.inputs[spawnFieldDir].CursorEnd()
+	if prompt.Placement != "" {
+		f.inputs[spawnFieldCommand].Placeholder = "default shell"
+		f.fields = []int{spawnFieldSize, spawnFieldDir, spawnFieldCommand}
+	} else {
+		// a blank respawn reruns the pane's original command.
+		f.inputs[spawnFieldCommand].Placeholder = "original command"
+		f.fields = []int{spawnFieldDir, spawnFieldCommand}
+	}
+	f.inputs[f.fields[0]].Focus()
+	return f
+}
+
+func (f *PaneSpawnForm) field() int        { return f.fields[f.focus] }
+func (f *PaneSpawnForm) Context() Context  { return f.prompt.Context }
+func (f *PaneSpawnForm) Target() string    { return f.prompt.Target }
+func (f *PaneSpawnForm) Value() string     { return strings.TrimSpace(f.inputs[spawnFieldCommand].Value()) }
+func (f *PaneSpawnForm) Dir() string       { return strings.TrimSpace(f.inputs[spawnFieldDir].Value()) }
+func (f *PaneSpawnForm) FocusCmd() tea.Cmd { return f.inputs[f.field()].Focus() }
+func (f *PaneSpawnForm) Focused() string   { return spawnFieldLabels[f.field()] }
+
+func (f *PaneSpawnForm) ActionID() string {
+	if f.prompt.Placement != "" {
+		return "pane:split"
+	}
+	return "pane:respawn"
+}
+
+func (f *PaneSpawnForm) PendingLabel() string {
+	if f.prompt.Placement != "" {
+		return "split " + f.prompt.Target
+	}
+	return "respawn " + f.prompt.Target
+}
+
+func (f *PaneSpawnForm) Title() string {
+	if f.prompt.Placement != "" {
+		return fmt.Sprintf("Split %s %s", f.prompt.Target, f.prompt.Placement)
+	}
+	return "Respawn " + f.prompt.Target
+}
+
+// InputView is one labelled input per line.
+func (f *PaneSpawnForm) InputView() string {
+	lines := make([]string, len(f.fields))
+	for i, field := range f.fields {
+		lines[i] = fmt.Sprintf("%-*s%s", spawnLabelWidth, spawnFieldLabels[field], f.inputs[field].View())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cursor is the focused input's cursor, moved past its label and down to
+// its line.
+func (f *PaneSpawnForm) Cursor() *tea.Cursor {
+	c := f.inputs[f.field()].Cursor()
+	if c == nil {
+		return nil
+	}
+	c.Position.X += spawnLabelWidth
+	c.Position.Y += f.focus
+	return c
+}
+
+// Help is the last completion, error, or confirmation when there is one,
+// else what the focused field's keys do.
+func (f *PaneSpawnForm) Help() string {
+	if f.status != "" {
+		return f.status
+	}
+	submit := "Enter for the next field"
+	if f.focus == len(f.fields)-1 {
+		submit = "Enter to " + strings.Fields(f.PendingLabel())[0]
+	}
+	switch f.field() {
+	case spawnFieldSize:
+		return "Percent of the pane, blank for half. " + submit + ". Esc to cancel."
+	case spawnFieldDir:
+		return "Tab completes, ctrl+r for the pane's directory. " + submit + ". Esc to cancel."
+	}
+	return "Up/down for history. " + submit + ". Esc to cancel."
+}
+
+func (f *PaneSpawnForm) move(delta int) tea.Cmd {
+	next := f.focus + delta
+	if next < 0 || next >= len(f.fields) {
+		return nil
+	}
+	f.inputs[f.field()].Blur()
+	f.focus = next
+	return f.inputs[f.field()].Focus()
+}
+
+func (f *PaneSpawnForm) setField(field int, value string) {
+	f.inputs[field].SetValue(value)
+	f.inputs[field].CursorEnd()
+}
+
+func (f *PaneSpawnForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	m, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		updated, cmd := f.inputs[f.field()].Update(msg)
+		f.inputs[f.field()] = updated
+		return cmd, false, false
+	}
+	key := m.String()
+	f.status = ""
+	if key != "enter" {
+		f.confirm = false
+	}
+	switch key {
+	case "esc":
+		return nil, false, true
+	case "shift+tab":
+		return f.move(-1), false, false
+	case "tab":
+		if f.field() != spawnFieldDir {
+			return f.move(1), false, false
+		}
+		f.completeDir()
+		return nil, false, false
+	case "ctrl+r":
+		if f.field() == spawnFieldDir {
+			f.setField(spawnFieldDir, f.prompt.Dir)
+			return nil, false, false
+		}
+	case "up":
+		if f.field() == spawnFieldCommand {
+			if entry, ok := f.history.Older(f.inputs[spawnFieldCommand].Value()); ok {
+				f.setField(spawnFieldCommand, entry)
+			}
+			return nil, false, false
+		}
+	case "down":
+		if f.field() == spawnFieldCommand {
+			if entry, ok := f.history.Newer(); ok {
+				f.setField(spawnFieldCommand, entry)
+			}
+			return nil, false, false
+		}
+	case "enter":
+		if f.focus < len(f.fields)-1 {
+			return f.move(1), false, false
+		}
+		return f.submit()
+	}
+	if f.field() == spawnFieldCommand {
+		f.history.Reset()
+	}
+	updated, cmd := f.inputs[f.field()].Update(msg)
+	f.inputs[f.field()] = updated
+	return cmd, false, false
+}
+
+func (f *PaneSpawnForm) completeDir() {
+	home, _ := spawnHomeDirFn()
+	completed, matches := spawn.CompleteDir(f.inputs[spawnFieldDir].Value(), f.prompt.Dir, home)
+	f.setField(spawnFieldDir, completed)
+	switch {
+	case len(matches) == 0:
+		f.status = "No matching directory."
+	case len(matches) > 1:
+		const shown = 6
+		f.status = strings.Join(matches[:min(len(matches), shown)], "  ")
+		if len(matches) > shown {
+			f.status += fmt.Sprintf("  (+%d more)", len(matches)-shown)
+		}
+	}
+}
+
+// submit checks the size and, before killing a running process, asks for
+// Enter a second time.
+func (f *PaneSpawnForm) submit() (tea.Cmd, bool, bool) {
+	percent, err := spawn.ParsePercent(f.inputs[spawnFieldSize].Value())
+	if err != nil {
+		f.status = err.Error()
+		return nil, false, false
+	}
+	kill := f.prompt.Running != ""
+	if kill && !f.confirm {
+		f.confirm = true
+		f.status = fmt.Sprintf("%s is still running %s. Press Enter again to kill it and respawn, Esc to cancel.", f.prompt.Target, f.prompt.Running)
+		return nil, false, false
+	}
+	return PaneSpawnCommand(f.prompt, percent, f.Dir(), f.Value(), kill), true, false
+}
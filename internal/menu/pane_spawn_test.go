@@ -0,0 +1,135 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/spawn"
+)
+
+// stubSpawn answers the pane details query with details, records the other
+// tmux calls, and keeps history in a temporary state dir under home.
+func stubSpawn(t *testing.T, details string) (home string, calls *[]string) {
+	t.Helper()
+	home = t.TempDir()
+	var recorded []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		if args[0] == "display-message" {
+			return []byte(details + "\n"), nil
+		}
+		recorded = append(recorded, strings.Join(args, " "))
+		return nil, nil
+	}))
+	t.Cleanup(withPaneStub(&spawnStateDirFn, func(string) (string, error) { return home, nil }))
+	t.Cleanup(withPaneStub(&spawnHomeDirFn, func() (string, error) { return home, nil }))
+	return home, &recorded
+}
+
+func typeInto(form *PaneSpawnForm, text string) {
+	for _, r := range text {
+		form.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+func TestLoadPaneSplitMenu(t *testing.T) {
+	items, err := loadPaneSplitMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !items[0].Header || items[1].ID != "right" || strings.Join(strings.Fields(items[1].Label), " ") != "right -h" {
+		t.Fatalf("unexpected items %#v", items[:2])
+	}
+	if last := items[len(items)-1]; last.ID != "above-full" || strings.Join(strings.Fields(last.Label), " ") != "above-full -v -b -f" {
+		t.Fatalf("unexpected last item %#v", last)
+	}
+}
+
+func TestPaneSplitFlow(t *testing.T) {
+	home, calls := stubSpawn(t, "/srv/app\t0\tzsh")
+	if err := os.Mkdir(filepath.Join(home, "projects"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := spawn.RecordHistory(home, "make test"); err != nil {
+		t.Fatal(err)
+	}
+	prompt := PaneSplitAction(Context{CurrentPaneID: "work:0.1"}, Item{ID: "below-full"})().(PaneSpawnPrompt)
+	form := NewPaneSpawnForm(prompt)
+	if form.Title() != "Split work:0.1 below-full" || form.Dir() != "/srv/app" || form.Focused() != "size" {
+		t.Fatalf("unexpected form %q %q %q", form.Title(), form.Dir(), form.Focused())
+	}
+
+	typeInto(form, "150")
+	form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done || !strings.HasPrefix(form.Help(), "size must be") {
+		t.Fatalf("expected a bad size to block the split, help %q", form.Help())
+	}
+	form.Update(tea.KeyPressMsg{Code: tea.KeyTab, Mod: tea.ModShift})
+	form.Update(tea.KeyPressMsg{Code: tea.KeyTab, Mod: tea.ModShift})
+	form.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+
+	form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	form.Update(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	typeInto(form, "~/pro")
+	form.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if form.Dir() != "~/projects/" {
+		t.Fatalf("expected the directory completed, got %q", form.Dir())
+	}
+	form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	form.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	if form.Value() != "make test" {
+		t.Fatalf("expected the history entry, got %q", form.Value())
+	}
+	cmd, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !done || cmd == nil {
+		t.Fatal("expected the form to submit")
+	}
+	if res := cmd().(ActionResult); res.Err != nil || res.Info != "Split work:0.1 below-full" {
+		t.Fatalf("unexpected result %#v", res)
+	}
+	want := "split-window -t work:0.1 -v -f -l 15% -c " + filepath.Join(home, "projects") + " make test"
+	if !slices.Equal(*calls, []string{want}) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestPaneRespawnConfirmsKill(t *testing.T) {
+	home, calls := stubSpawn(t, "/srv/app\t0\tvim")
+	prompt := PaneRespawnAction(Context{}, Item{ID: "work:0.2"})().(PaneSpawnPrompt)
+	if prompt.Running != "vim" {
+		t.Fatalf("expected the running command, got %q", prompt.Running)
+	}
+	form := NewPaneSpawnForm(prompt)
+	if placeholder := form.inputs[spawnFieldCommand].Placeholder; placeholder != "original command" {
+		t.Fatalf("unexpected respawn placeholder %q", placeholder)
+	}
+	form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	typeInto(form, "htop")
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done || !strings.Contains(form.Help(), "still running vim") {
+		t.Fatalf("expected a kill confirmation, help %q", form.Help())
+	}
+	cmd, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !done {
+		t.Fatal("expected the second enter to respawn")
+	}
+	cmd()
+	if want := []string{"respawn-pane -t work:0.2 -k -c /srv/app htop"}; !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+	if history, _ := spawn.LoadHistory(home); !slices.Equal(history, []string{"htop"}) {
+		t.Fatalf("history = %q", history)
+	}
+
+	prompt = PaneRespawnAction(Context{}, Item{ID: "work:0.2"})().(PaneSpawnPrompt)
+	prompt.Running = ""
+	form = NewPaneSpawnForm(prompt)
+	form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); !done {
+		t.Fatal("expected a dead pane to respawn without confirmation")
+	}
+}
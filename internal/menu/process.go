@@ -1,5 +1,37 @@
 package menu
 
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/process"
+)
+
+var (
+	processSnapshotFn = process.Snapshot
+	processSignalFn   = process.Signal
+	processCwdFn      = process.Cwd
+)
+
+// processSignalLevels maps each signal submenu onto the signal it delivers.
+var processSignalLevels = map[string]syscall.Signal{
+	"process:terminate": syscall.SIGTERM,
+	"process:kill":      syscall.SIGKILL,
+	"process:interrupt": syscall.SIGINT,
+	"process:continue":  syscall.SIGCONT,
+	"process:stop":      syscall.SIGSTOP,
+	"process:quit":      syscall.SIGQUIT,
+	"process:hangup":    syscall.SIGHUP,
+}
+
 func loadProcessMenu(Context) ([]Item, error) {
 	items := []string{
 		"display",
@@ -14,3 +46,310 @@ func loadProcessMenu(Context) ([]Item, error) {
 	}
 	return menuItemsFromIDs(items), nil
 }
+
+// paneProcess pairs a process-tree row with the pane whose shell it descends
+// from.
+type paneProcess struct {
+	pane PaneEntry
+	node process.Node
+}
+
+// paneProcesses snapshots /proc and walks each pane's #{pane_pid} down through
+// its descendants. Panes are visited in listing order; a pid is reported once.
+func paneProcesses(ctx Context) ([]paneProcess, process.Table, error) {
+	procs, err := processSnapshotFn()
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows []paneProcess
+	seen := make(map[int]bool)
+	for _, pane := range ctx.Panes {
+		if pane.PID <= 0 {
+			continue
+		}
+		for _, node := range procs.Tree(pane.PID) {
+			if seen[node.PID] {
+				continue
+			}
+			seen[node.PID] = true
+			rows = append(rows, paneProcess{pane: pane, node: node})
+		}
+	}
+	return rows, procs, nil
+}
+
+// paneForPID returns the pane whose process tree contains pid.
+func paneForPID(ctx Context, procs process.Table, pid int) (PaneEntry, bool) {
+	for _, pane := range ctx.Panes {
+		if pane.PID > 0 && procs.Contains(pane.PID, pid) {
+			return pane, true
+		}
+	}
+	return PaneEntry{}, false
+}
+
+// processListingItems renders the pane processes as a table with a header
+// row. When tree is true the command column carries box-drawing guides and
+// rows keep their depth-first order; otherwise rows are sorted by pane then
+// pid.
+func processListingItems(ctx Context, tree bool) ([]Item, error) {
+	rows, _, err := paneProcesses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	if !tree {
+		slices.SortStableFunc(rows, func(a, b paneProcess) int {
+			if c := cmp.Compare(a.pane.ID, b.pane.ID); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.node.PID, b.node.PID)
+		})
+	}
+	cells := make([][]string, 0, len(rows)+1)
+	cells = append(cells, []string{"pid", "state", "pane", "command"})
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		command := row.node.Args
+		if tree {
+			command = row.node.Prefix + command
+		}
+		cells = append(cells, []string{
+			strconv.Itoa(row.node.PID),
+			row.node.State,
+			row.pane.ID,
+			command,
+		})
+		ids = append(ids, strconv.Itoa(row.node.PID))
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignRight, table.AlignLeft, table.AlignLeft, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: ids[i], Label: label})
+	}
+	return items, nil
+}
+
+func loadProcessDisplayMenu(ctx Context) ([]Item, error) {
+	return processListingItems(ctx, false)
+}
+
+func loadProcessTreeMenu(ctx Context) ([]Item, error) {
+	return processListingItems(ctx, true)
+}
+
+func loadProcessSignalMenu(ctx Context) ([]Item, error) {
+	return processListingItems(ctx, true)
+}
+
+// IsProcessSignalLevel reports whether id is one of the signal submenus, which
+// gate their action behind a y/n confirmation.
+func IsProcessSignalLevel(id string) bool {
+	_, ok := processSignalLevels[id]
+	return ok
+}
+
+// ProcessSignalPrompt builds the confirmation question for sending the signal
+// of levelID to the pids selected in item, along with a short label naming
+// the pending action.
+func ProcessSignalPrompt(levelID string, item Item) (prompt, label string) {
+	name := signalName(processSignalLevels[levelID])
+	pids := splitSelectionIDs(item.ID)
+	if len(pids) == 1 {
+		return fmt.Sprintf("send %s to pid %s?", name, pids[0]),
+			fmt.Sprintf("%s → pid %s", name, pids[0])
+	}
+	return fmt.Sprintf("send %s to %d processes (%s)?", name, len(pids), strings.Join(pids, ", ")),
+		fmt.Sprintf("%s → %d processes", name, len(pids))
+}
+
+// ProcessPreviewLines renders the process tree of the pane that owns pid,
+// marking pid itself. Used by the preview panel on every process submenu.
+func ProcessPreviewLines(ctx Context, pid string) ([]string, error) {
+	target, err := strconv.Atoi(strings.TrimSpace(pid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pid %q", pid)
+	}
+	procs, err := processSnapshotFn()
+	if err != nil {
+		return nil, err
+	}
+	pane, ok := paneForPID(ctx, procs, target)
+	if !ok {
+		return nil, fmt.Errorf("pid %d is no longer running in a pane", target)
+	}
+	nodes := procs.Tree(pane.PID)
+	cells := make([][]string, 0, len(nodes))
+	for _, node := range nodes {
+		marker := " "
+		if node.PID == target {
+			marker = "*"
+		}
+		cells = append(cells, []string{
+			marker,
+			strconv.Itoa(node.PID),
+			node.State,
+			humanizeSaveSize(node.RSS),
+			node.Prefix + node.Args,
+		})
+	}
+	lines := []string{fmt.Sprintf("%s (%s)", pane.ID, pane.Command), ""}
+	lines = append(lines, table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignRight, table.AlignLeft, table.AlignRight, table.AlignLeft})...)
+	return lines, nil
+}
+
+// ProcessDisplayAction shows the details of the selected process in the
+// command output view.
+func ProcessDisplayAction(ctx Context, item Item) tea.Cmd {
+	pid, err := strconv.Atoi(strings.TrimSpace(item.ID))
+	if err != nil {
+		return failCmd("invalid pid %q", item.ID)
+	}
+	return func() tea.Msg {
+		events.Process.Display(pid)
+		procs, err := processSnapshotFn()
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		proc, ok := procs[pid]
+		if !ok {
+			return ActionResult{Err: fmt.Errorf("pid %d is no longer running", pid)}
+		}
+		pane := "-"
+		if entry, ok := paneForPID(ctx, procs, pid); ok {
+			pane = entry.ID
+		}
+		rows := [][]string{
+			{"pid", strconv.Itoa(proc.PID)},
+			{"ppid", strconv.Itoa(proc.PPID)},
+			{"state", fmt.Sprintf("%s (%s)", proc.State, process.StateName(proc.State))},
+			{"pane", pane},
+			{"threads", strconv.Itoa(proc.Threads)},
+			{"rss", humanizeSaveSize(proc.RSS)},
+			{"cwd", cmp.Or(processCwdFn(pid), "-")},
+			{"command", proc.Args},
+		}
+		lines := table.Format(rows, nil)
+		return ActionResult{
+			Info:   fmt.Sprintf("pid %d", pid),
+			Output: strings.Join(lines, "\n"),
+		}
+	}
+}
+
+// ProcessTreeAction switches to the pane whose process tree contains the
+// selected process.
+func ProcessTreeAction(ctx Context, item Item) tea.Cmd {
+	pid, err := strconv.Atoi(strings.TrimSpace(item.ID))
+	if err != nil {
+		return failCmd("invalid pid %q", item.ID)
+	}
+	return func() tea.Msg {
+		procs, err := processSnapshotFn()
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		pane, ok := paneForPID(ctx, procs, pid)
+		if !ok {
+			return ActionResult{Err: fmt.Errorf("pid %d is no longer running in a pane", pid)}
+		}
+		events.Process.Switch(pid, pane.ID)
+		if err := switchPaneFn(ctx.SocketPath, ctx.ClientID, pane.ID); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Switched to %s", pane.Label)}
+	}
+}
+
+func ProcessTerminateAction(ctx Context, item Item) tea.Cmd {
+	return processSignalCommand(ctx, item, syscall.SIGTERM)
+}
+
+func ProcessKillAction(ctx Context, item Item) tea.Cmd {
+	return processSignalCommand(ctx, item, syscall.SIGKILL)
+}
+
+func ProcessInterruptAction(ctx Context, item Item) tea.Cmd {
+	return processSignalCommand(ctx, item, syscall.SIGINT)
+}
+
+func ProcessContinueAction(ctx Context, item Item) tea.Cmd {
+	return processSignalCommand(ctx, item, syscall.SIGCONT)
+}
+
+func ProcessStopAction(ctx Context, item Item) tea.Cmd {
+	return processSignalCommand(ctx, item, syscall.SIGSTOP)
+}
+
+func ProcessQuitAction(ctx Context, item Item) tea.Cmd {
+	return processSignalCommand(ctx, item, syscall.SIGQUIT)
+}
+
+func ProcessHangupAction(ctx Context, item Item) tea.Cmd {
+	return processSignalCommand(ctx, item, syscall.SIGHUP)
+}
+
+// processSignalCommand delivers sig to every selected pid. Each pid is checked
+// against a fresh snapshot first so a pid recycled since the listing was
+// loaded is never signalled unless it still descends from a pane.
+func processSignalCommand(ctx Context, item Item, sig syscall.Signal) tea.Cmd {
+	raw := splitSelectionIDs(item.ID)
+	if len(raw) == 0 {
+		return failCmd("no process selected")
+	}
+	pids := make([]int, 0, len(raw))
+	for _, id := range raw {
+		pid, err := strconv.Atoi(id)
+		if err != nil {
+			return failCmd("invalid pid %q", id)
+		}
+		pids = append(pids, pid)
+	}
+	name := signalName(sig)
+	return func() tea.Msg {
+		events.Process.Signal(pids, name)
+		procs, err := processSnapshotFn()
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		for _, pid := range pids {
+			if _, ok := paneForPID(ctx, procs, pid); !ok {
+				return ActionResult{Err: fmt.Errorf("pid %d is no longer running in a pane", pid)}
+			}
+		}
+		for _, pid := range pids {
+			if err := processSignalFn(pid, sig); err != nil {
+				return ActionResult{Err: err}
+			}
+		}
+		if len(pids) == 1 {
+			return ActionResult{Info: fmt.Sprintf("Sent %s to pid %d", name, pids[0])}
+		}
+		return ActionResult{Info: fmt.Sprintf("Sent %s to %d processes", name, len(pids))}
+	}
+}
+
+// signalName returns the conventional SIGXXX spelling for sig.
+func signalName(sig syscall.Signal) string {
+	switch sig {
+	case syscall.SIGTERM:
+		return "SIGTERM"
+	case syscall.SIGKILL:
+		return "SIGKILL"
+	case syscall.SIGINT:
+		return "SIGINT"
+	case syscall.SIGCONT:
+		return "SIGCONT"
+	case syscall.SIGSTOP:
+		return "SIGSTOP"
+	case syscall.SIGQUIT:
+		return "SIGQUIT"
+	case syscall.SIGHUP:
+		return "SIGHUP"
+	default:
+		return sig.String()
+	}
+}
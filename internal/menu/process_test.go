@@ -0,0 +1,202 @@
+package menu
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/atomicstack/tmux-popup-control/internal/process"
+)
+
+// processFixture is a two-pane process table: pane s:0.0 runs a shell with a
+// make job and two compilers, pane s:0.1 an idle shell.
+func processFixture() (Context, process.Table) {
+	ctx := Context{
+		Panes: []PaneEntry{
+			{ID: "s:0.0", Label: "s:0.0", PID: 100, Command: "make"},
+			{ID: "s:0.1", Label: "s:0.1", PID: 200, Command: "zsh"},
+		},
+	}
+	table := process.Table{
+		1:   {PID: 1, PPID: 0, State: "S", Comm: "init", Args: "/sbin/init"},
+		100: {PID: 100, PPID: 1, State: "S", Comm: "bash", Args: "-bash"},
+		110: {PID: 110, PPID: 100, State: "S", Comm: "make", Args: "make -j2"},
+		111: {PID: 111, PPID: 110, State: "R", Comm: "cc1", Args: "cc1 a.c"},
+		112: {PID: 112, PPID: 110, State: "R", Comm: "cc1", Args: "cc1 b.c"},
+		200: {PID: 200, PPID: 1, State: "S", Comm: "zsh", Args: "-zsh"},
+		300: {PID: 300, PPID: 1, State: "S", Comm: "sshd", Args: "sshd"},
+	}
+	return ctx, table
+}
+
+func stubProcessSnapshot(t *testing.T, table process.Table) {
+	t.Helper()
+	restore := withPaneStub(&processSnapshotFn, func() (process.Table, error) { return table, nil })
+	t.Cleanup(restore)
+}
+
+func TestLoadProcessTreeMenu(t *testing.T) {
+	ctx, table := processFixture()
+	stubProcessSnapshot(t, table)
+
+	items, err := loadProcessTreeMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 6 {
+		t.Fatalf("expected header + 5 processes, got %d: %#v", len(items), items)
+	}
+	if !items[0].Header || !strings.Contains(items[0].Label, "command") {
+		t.Fatalf("expected header row first, got %#v", items[0])
+	}
+	var ids []string
+	for _, item := range items[1:] {
+		ids = append(ids, item.ID)
+	}
+	if got := strings.Join(ids, ","); got != "100,110,111,112,200" {
+		t.Fatalf("unexpected tree order %s", got)
+	}
+	if !strings.Contains(items[3].Label, "├─ cc1 a.c") {
+		t.Fatalf("expected tree guide on nested row, got %q", items[3].Label)
+	}
+}
+
+func TestLoadProcessDisplayMenuIsFlat(t *testing.T) {
+	ctx, table := processFixture()
+	stubProcessSnapshot(t, table)
+
+	items, err := loadProcessDisplayMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if strings.ContainsAny(item.Label, "├└") {
+			t.Fatalf("display listing should not carry tree guides: %q", item.Label)
+		}
+	}
+}
+
+func TestProcessSignalCommandSendsToEachPID(t *testing.T) {
+	ctx, table := processFixture()
+	stubProcessSnapshot(t, table)
+	var sent []int
+	var sig syscall.Signal
+	restore := withPaneStub(&processSignalFn, func(pid int, s syscall.Signal) error {
+		sent = append(sent, pid)
+		sig = s
+		return nil
+	})
+	defer restore()
+
+	res := ProcessTerminateAction(ctx, Item{ID: "111\n112"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	if len(sent) != 2 || sent[0] != 111 || sent[1] != 112 || sig != syscall.SIGTERM {
+		t.Fatalf("unexpected signals %v %v", sent, sig)
+	}
+	if res.Info != "Sent SIGTERM to 2 processes" {
+		t.Fatalf("unexpected info %q", res.Info)
+	}
+}
+
+func TestProcessSignalCommandRejectsForeignPID(t *testing.T) {
+	ctx, table := processFixture()
+	stubProcessSnapshot(t, table)
+	called := false
+	restore := withPaneStub(&processSignalFn, func(int, syscall.Signal) error {
+		called = true
+		return nil
+	})
+	defer restore()
+
+	res := ProcessKillAction(ctx, Item{ID: "300"})().(ActionResult)
+	if res.Err == nil {
+		t.Fatal("expected error for a pid outside every pane")
+	}
+	if called {
+		t.Fatal("signal must not be sent when validation fails")
+	}
+}
+
+func TestProcessSignalPrompt(t *testing.T) {
+	prompt, label := ProcessSignalPrompt("process:kill", Item{ID: "111"})
+	if prompt != "send SIGKILL to pid 111?" || label != "SIGKILL → pid 111" {
+		t.Fatalf("unexpected prompt/label %q / %q", prompt, label)
+	}
+	prompt, _ = ProcessSignalPrompt("process:stop", Item{ID: "111\n112"})
+	if prompt != "send SIGSTOP to 2 processes (111, 112)?" {
+		t.Fatalf("unexpected prompt %q", prompt)
+	}
+}
+
+func TestProcessPreviewLinesMarksSelection(t *testing.T) {
+	ctx, table := processFixture()
+	stubProcessSnapshot(t, table)
+
+	lines, err := ProcessPreviewLines(ctx, "112")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines[0] != "s:0.0 (make)" {
+		t.Fatalf("unexpected title line %q", lines[0])
+	}
+	var marked []string
+	for _, line := range lines[2:] {
+		if strings.HasPrefix(line, "*") {
+			marked = append(marked, line)
+		}
+	}
+	if len(marked) != 1 || !strings.Contains(marked[0], "cc1 b.c") {
+		t.Fatalf("expected only pid 112 marked, got %q", marked)
+	}
+}
+
+func TestProcessDisplayActionShowsOutput(t *testing.T) {
+	ctx, table := processFixture()
+	stubProcessSnapshot(t, table)
+	restore := withPaneStub(&processCwdFn, func(int) string { return "/src" })
+	defer restore()
+
+	res := ProcessDisplayAction(ctx, Item{ID: "110"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	for _, want := range []string{"make -j2", "s:0.0", "/src", "sleeping"} {
+		if !strings.Contains(res.Output, want) {
+			t.Fatalf("expected output to contain %q:\n%s", want, res.Output)
+		}
+	}
+}
+
+func TestProcessTreeActionSwitchesToOwningPane(t *testing.T) {
+	ctx, table := processFixture()
+	stubProcessSnapshot(t, table)
+	var target string
+	restore := withPaneStub(&switchPaneFn, func(_, _, tgt string) error {
+		target = tgt
+		return nil
+	})
+	defer restore()
+
+	res := ProcessTreeAction(ctx, Item{ID: "111"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+	if target != "s:0.0" {
+		t.Fatalf("expected switch to s:0.0, got %q", target)
+	}
+}
+
+func TestProcessSignalLevelsAreMultiSelect(t *testing.T) {
+	reg := BuildRegistry()
+	for id := range processSignalLevels {
+		node, ok := reg.Find(id)
+		if !ok || node.Loader == nil || node.Action == nil {
+			t.Fatalf("registry missing loader/action for %s", id)
+		}
+		if !node.MultiSelect {
+			t.Fatalf("expected %s to be multi-select", id)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package menu
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/usermenu"
+)
 
 // Node represents a menu entry definition within the registry tree.
 type Node struct {
@@ -10,16 +15,24 @@ type Node struct {
 	Children      map[string]*Node
 	MultiSelect   bool
 	FilterCommand bool
+	// Frecency lets the level sort its items by past picks when the sort
+	// mode asks for it.
+	Frecency bool
+	// Preview, when set, renders the preview panel for the highlighted item
+	// of this node's level. Built-in levels use the UI's own previews.
+	Preview func(Context, Item) ([]string, error)
 }
 
 // Registry exposes lookup utilities for menu definitions.
 type Registry struct {
-	root  *Node
-	nodes map[string]*Node
+	root      *Node
+	nodes     map[string]*Node
+	rootItems []Item
 }
 
-// BuildRegistry constructs the registry from existing loader/handler maps.
-func BuildRegistry() *Registry {
+// BuildRegistry constructs the registry from existing loader/handler maps,
+// then appends userMenus to the root menu.
+func BuildRegistry(userMenus ...usermenu.Def) *Registry {
 	nodes := make(map[string]*Node)
 
 	ensure := func(id string) *Node {
@@ -31,8 +44,9 @@ func BuildRegistry() *Registry {
 		return node
 	}
 
+	rootItems := RootItems()
 	root := ensure("root")
-	root.Loader = func(Context) ([]Item, error) { return RootItems(), nil }
+	root.Loader = func(Context) ([]Item, error) { return rootItems, nil }
 
 	for id, loader := range CategoryLoaders() {
 		node := ensure(id)
@@ -53,9 +67,35 @@ func BuildRegistry() *Registry {
 		"window:kill",
 		"pane:join",
 		"pane:kill",
+		"pane:broadcast",
+		"pane:log:start",
+		"pane:log:stop",
+		"trash:delete",
 		"plugins:update",
 		"plugins:uninstall",
 		"extract",
+		"process:terminate",
+		"process:kill",
+		"process:interrupt",
+		"process:continue",
+		"process:stop",
+		"process:quit",
+		"process:hangup",
+		"clipboard:buffer:delete",
+		"clipboard:history:delete",
+		"session:import:convert",
+		WorktreeRemoveID,
+		"ssh:window",
+		"ssh:tiled",
+		"ssh:tiled-sync",
+		"session:environment:unset",
+		"session:environment:remove",
+		"session:environment:copy-to-global",
+		"session:environment:refresh-panes",
+		"client:detach",
+		"client:detach-kill",
+		"client:refresh",
+		"client:lock",
 	}
 	for _, id := range markMultiSelect {
 		if node, ok := nodes[id]; ok {
@@ -66,6 +106,77 @@ func BuildRegistry() *Registry {
 	if node, ok := nodes["command"]; ok {
 		node.FilterCommand = true
 	}
+	if node, ok := nodes["session:new-from-template"]; ok {
+		node.Preview = sessionTemplatePreview
+	}
+	if node, ok := nodes["session:open-project"]; ok {
+		node.Preview = sessionProjectPreview
+	}
+	for _, id := range []string{"session:import:start", "session:import:convert"} {
+		if node, ok := nodes[id]; ok {
+			node.Preview = sessionImportPreview
+		}
+	}
+	for _, id := range []string{
+		"session:environment:set",
+		"session:environment:unset",
+		"session:environment:remove",
+		"session:environment:copy-to-global",
+		"session:environment:refresh-panes",
+	} {
+		if node, ok := nodes[id]; ok {
+			node.Preview = sessionEnvPreview
+		}
+	}
+	for _, id := range []string{"worktree:window", "worktree:session", WorktreeRemoveID} {
+		if node, ok := nodes[id]; ok {
+			node.Preview = worktreePreview
+		}
+	}
+	for _, id := range []string{"ssh:window", "ssh:split", "ssh:tiled", "ssh:tiled-sync"} {
+		if node, ok := nodes[id]; ok {
+			node.Preview = sshHostPreview
+		}
+	}
+	for _, id := range []string{"pane:split", "pane:resize", "pane:resize:left", "pane:resize:right", "pane:resize:up", "pane:resize:down"} {
+		if node, ok := nodes[id]; ok {
+			node.Preview = paneResizePreview
+		}
+	}
+	for _, id := range []string{"pane:log:start", "pane:log:stop"} {
+		if node, ok := nodes[id]; ok {
+			node.Preview = paneLogPreview
+		}
+	}
+	for _, id := range []string{"task:split", "task:window", "task:pane"} {
+		if node, ok := nodes[id]; ok {
+			node.Preview = taskPreview
+		}
+	}
+
+	markFrecency := []string{
+		"session:switch",
+		"window:switch",
+		"pane:switch",
+		"session:open-project",
+		"command",
+	}
+	for _, id := range markFrecency {
+		if node, ok := nodes[id]; ok {
+			node.Frecency = true
+		}
+	}
+
+	for _, def := range userMenus {
+		// a user menu only adds to the tree; one named after a built-in node
+		// would replace its loader and action.
+		if _, taken := nodes[def.ID]; taken || def.ID == PaletteID {
+			events.UserMenu.Clash(def.ID)
+			continue
+		}
+		rootItems = append(rootItems, Item{ID: def.ID, Label: def.Label})
+		registerUserMenu(ensure, def.ID, def)
+	}
 
 	for id, node := range nodes {
 		if id == "root" {
@@ -76,7 +187,12 @@ func BuildRegistry() *Registry {
 		parent.Children[key] = node
 	}
 
-	return &Registry{root: root, nodes: nodes}
+	reg := &Registry{root: root, nodes: nodes, rootItems: rootItems}
+	palette := ensure(PaletteID)
+	palette.Loader = reg.paletteItems
+	palette.Frecency = true
+	root.Children[PaletteID] = palette
+	return reg
 }
 
 // Root returns the registry root node.
@@ -84,6 +200,11 @@ func (r *Registry) Root() *Node {
 	return r.root
 }
 
+// RootItems returns the top-level menu entries, user menus included.
+func (r *Registry) RootItems() []Item {
+	return r.rootItems
+}
+
 // Find locates a node by ID.
 func (r *Registry) Find(id string) (*Node, bool) {
 	node, ok := r.nodes[id]
@@ -0,0 +1,300 @@
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/scrollsearch"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+// searchScrollbackFn is swappable in tests.
+var searchScrollbackFn = tmux.CaptureScrollback
+
+const (
+	// searchWorkers bounds the number of capture-pane calls in flight.
+	searchWorkers = 8
+	// searchLimit caps the result list; a broader query is the better fix.
+	searchLimit = 1000
+	// searchContext is the number of lines the preview shows either side of
+	// a match.
+	searchContext = 10
+)
+
+var (
+	searchMatchOn  = ansi.NewStyle().Reverse(true).String()
+	searchMatchOff = ansi.NewStyle().NoReverse().String()
+	searchGutterOn = ansi.NewStyle().Faint().String()
+	searchReset    = ansi.NewStyle().Reset().String()
+)
+
+// loadSearchMenu offers the scopes a search can cover.
+func loadSearchMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{"server", "session", "window"}), nil
+}
+
+// searchScopePanes returns the panes scope covers: every pane, those of the
+// current session, or those of the current pane's window.
+func searchScopePanes(ctx Context, scope string) []PaneEntry {
+	var current PaneEntry
+	for _, p := range ctx.Panes {
+		if p.ID == strings.TrimSpace(ctx.CurrentPaneID) || p.PaneID == tmux.OriginPaneID() {
+			current = p
+			break
+		}
+	}
+	var panes []PaneEntry
+	for _, p := range ctx.Panes {
+		switch scope {
+		case "session":
+			if p.Session != strings.TrimSpace(ctx.Current) {
+				continue
+			}
+		case "window":
+			if p.Session != current.Session || p.WindowIdx != current.WindowIdx {
+				continue
+			}
+		}
+		panes = append(panes, p)
+	}
+	return panes
+}
+
+// SearchPrompt asks for the query of a scrollback search over Scope.
+type SearchPrompt struct {
+	Context Context
+	Scope   string
+}
+
+// SearchAction opens the query form for the chosen scope.
+func SearchAction(ctx Context, item Item) tea.Cmd {
+	scope := strings.TrimSpace(item.ID)
+	return func() tea.Msg {
+		return SearchPrompt{Context: ctx, Scope: scope}
+	}
+}
+
+// loadSearchResultsMenu captures the scrollback of every pane in
+// ctx.SearchScope and lists the lines matching ctx.SearchQuery. Item IDs are
+// "PANE_ID:LINE", LINE counting from 0 at the top of the history.
+func loadSearchResultsMenu(ctx Context) ([]Item, error) {
+	match, err := scrollsearch.Compile(ctx.SearchQuery, ctx.SearchRegex)
+	if err != nil {
+		return nil, err
+	}
+	panes := searchScopePanes(ctx, ctx.SearchScope)
+	if len(panes) == 0 {
+		return nil, fmt.Errorf("no panes to search in %s", ctx.SearchScope)
+	}
+	ids := make([]string, len(panes))
+	for i, p := range panes {
+		ids[i] = p.PaneID
+	}
+	captures := scrollsearch.CaptureAll(ids, searchWorkers, func(id string) (string, error) {
+		return searchScrollbackFn(ctx.SocketPath, id)
+	})
+	var (
+		items  []Item
+		failed int
+	)
+	for i, c := range captures {
+		if c.Err != nil {
+			failed++
+			events.Search.Error(c.Target, c.Err)
+			continue
+		}
+		for _, hit := range scrollsearch.Find(c.Lines, match) {
+			if len(items) == searchLimit {
+				break
+			}
+			items = append(items, Item{
+				ID:    fmt.Sprintf("%s:%d", c.Target, hit.Line),
+				Label: fmt.Sprintf("%s line %d: %s", panes[i].ID, hit.Line+1, strings.TrimSpace(c.Lines[hit.Line])),
+			})
+		}
+	}
+	events.Search.Run(ctx.SearchScope, ctx.SearchQuery, ctx.SearchRegex, len(panes), len(items))
+	if failed == len(captures) {
+		return nil, errors.New("could not capture any pane")
+	}
+	return items, nil
+}
+
+// parseSearchID splits a result ID into its pane and line.
+func parseSearchID(id string) (string, int, error) {
+	i := strings.LastIndex(id, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid search result %q", id)
+	}
+	line, err := strconv.Atoi(id[i+1:])
+	if err != nil || line < 0 {
+		return "", 0, fmt.Errorf("invalid search result %q", id)
+	}
+	return id[:i], line, nil
+}
+
+// SearchPreviewLines recaptures the result's pane and shows the lines around
+// the match, the match itself highlighted. The lines carry ANSI styling.
+func SearchPreviewLines(ctx Context, id string) ([]string, error) {
+	pane, line, err := parseSearchID(id)
+	if err != nil {
+		return nil, err
+	}
+	match, err := scrollsearch.Compile(ctx.SearchQuery, ctx.SearchRegex)
+	if err != nil {
+		return nil, err
+	}
+	text, err := searchScrollbackFn(ctx.SocketPath, pane)
+	if err != nil {
+		return nil, err
+	}
+	lines := scrollsearch.Lines(text)
+	if line >= len(lines) {
+		return nil, fmt.Errorf("line %d is no longer in the scrollback", line+1)
+	}
+	from, to := max(line-searchContext, 0), min(line+searchContext+1, len(lines))
+	width := len(strconv.Itoa(to))
+	out := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		text := lines[i]
+		if i == line {
+			if start, end, ok := match(text); ok {
+				text = scrollsearch.Highlight(text, start, end, searchMatchOn, searchMatchOff)
+			}
+		}
+		out = append(out, fmt.Sprintf("%s%*d │%s %s", searchGutterOn, width, i+1, searchReset, text))
+	}
+	return out, nil
+}
+
+// SearchJumpAction switches to the result's pane, enters copy mode and
+// scrolls the matching line to the top of the pane.
+func SearchJumpAction(ctx Context, item Item) tea.Cmd {
+	pane, line, err := parseSearchID(strings.TrimSpace(item.ID))
+	if err != nil {
+		return failCmd("%w", err)
+	}
+	return func() tea.Msg {
+		var target string
+		for _, p := range ctx.Panes {
+			if p.PaneID == pane {
+				target = p.ID
+				break
+			}
+		}
+		if target == "" {
+			return ActionResult{Err: fmt.Errorf("pane %s no longer exists", pane)}
+		}
+		out, err := tmuxOutput(ctx.SocketPath, "display-message", "-p", "-t", pane, "#{history_size}\t#{pane_width}")
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		size, width, _ := strings.Cut(strings.TrimSpace(out), "\t")
+		history, _ := strconv.Atoi(size)
+		cols, _ := strconv.Atoi(width)
+		text, err := searchScrollbackFn(ctx.SocketPath, pane)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		// goto-line counts rows up from the bottom of the history.
+		scroll := min(max(history-scrollsearch.Row(scrollsearch.Lines(text), line, cols), 0), history)
+		events.Search.Jump(pane, line, scroll)
+		if err := switchPaneFn(ctx.SocketPath, ctx.ClientID, target); err != nil {
+			return ActionResult{Err: err}
+		}
+		if _, err := tmuxOutput(ctx.SocketPath, "copy-mode", "-t", pane); err != nil {
+			return ActionResult{Err: err}
+		}
+		if _, err := tmuxOutput(ctx.SocketPath, "send-keys", "-t", pane, "-X", "goto-line", strconv.Itoa(scroll)); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Jumped to %s line %d", target, line+1)}
+	}
+}
+
+// SearchForm takes the query of a scrollback search. Tab switches between
+// fuzzy and regular expression matching.
+type SearchForm struct {
+	input textinput.Model
+	ctx   Context
+	scope string
+	regex bool
+}
+
+func NewSearchForm(prompt SearchPrompt) *SearchForm {
+	ti := textinput.New()
+	styleFormInput(&ti)
+	ti.Placeholder = "query"
+	ti.CharLimit = 256
+	ti.SetWidth(50)
+	ti.Focus()
+	return &SearchForm{input: ti, ctx: prompt.Context, scope: prompt.Scope}
+}
+
+func (f *SearchForm) Context() Context    { return f.ctx }
+func (f *SearchForm) Scope() string       { return f.scope }
+func (f *SearchForm) Regex() bool         { return f.regex }
+func (f *SearchForm) Value() string       { return f.input.Value() }
+func (f *SearchForm) InputView() string   { return f.input.View() }
+func (f *SearchForm) Cursor() *tea.Cursor { return f.input.Cursor() }
+func (f *SearchForm) FocusCmd() tea.Cmd   { return f.input.Focus() }
+func (f *SearchForm) ActionID() string    { return "search:results" }
+func (f *SearchForm) PendingLabel() string {
+	return fmt.Sprintf("search %s for %s", f.scope, f.input.Value())
+}
+
+func (f *SearchForm) Title() string {
+	return fmt.Sprintf("Search the scrollback of the %s", f.scope)
+}
+
+// Help names the matching mode, or why the regular expression won't do.
+func (f *SearchForm) Help() string {
+	mode, other := "fuzzy", "regex"
+	if f.regex {
+		mode, other = "regex", "fuzzy"
+	}
+	if strings.TrimSpace(f.input.Value()) != "" {
+		if _, err := scrollsearch.Compile(f.input.Value(), f.regex); err != nil {
+			return err.Error()
+		}
+	}
+	return fmt.Sprintf("Mode: %s (Tab for %s). Press Enter to search. Esc to cancel.", mode, other)
+}
+
+// Update returns done once the query compiles; the UI then opens the
+// results.
+func (f *SearchForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch m.String() {
+		case "ctrl+u":
+			if f.input.Value() != "" {
+				f.input.SetValue("")
+				f.input.CursorStart()
+			}
+			return nil, false, false
+		case "tab":
+			f.regex = !f.regex
+			return nil, false, false
+		case "esc":
+			return nil, false, true
+		case "enter":
+			if strings.TrimSpace(f.input.Value()) == "" {
+				return nil, false, true
+			}
+			if _, err := scrollsearch.Compile(f.input.Value(), f.regex); err != nil {
+				return nil, false, false
+			}
+			return nil, true, false
+		}
+	}
+	updated, cmd := f.input.Update(msg)
+	f.input = updated
+	return cmd, false, false
+}
@@ -0,0 +1,137 @@
+package menu
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// stubSearch serves scrollback for %1 and %2 in session "work" and %3 in
+// "other", fails %4, and records the other tmux calls.
+func stubSearch(t *testing.T) (Context, *[]string) {
+	t.Helper()
+	scrollback := map[string]string{
+		"%1": "$ make\npanic: runtime error\n\tmain.go:12\n$\n\n",
+		"%2": "all good\n",
+		"%3": "PANIC in other\n",
+	}
+	t.Cleanup(withPaneStub(&searchScrollbackFn, func(_, pane string) (string, error) {
+		if text, ok := scrollback[pane]; ok {
+			return text, nil
+		}
+		return "", errors.New("no such pane")
+	}))
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if args[0] == "display-message" {
+			return []byte("40\t80\n"), nil
+		}
+		return nil, nil
+	}))
+	t.Cleanup(withPaneStub(&switchPaneFn, func(_, _, target string) error {
+		calls = append(calls, "switch "+target)
+		return nil
+	}))
+	ctx := Context{
+		Current:       "work",
+		CurrentPaneID: "work:0.0",
+		Panes: []PaneEntry{
+			{ID: "work:0.0", PaneID: "%1", Session: "work", WindowIdx: 0},
+			{ID: "work:1.0", PaneID: "%2", Session: "work", WindowIdx: 1},
+			{ID: "other:0.0", PaneID: "%3", Session: "other", WindowIdx: 0},
+			{ID: "other:0.1", PaneID: "%4", Session: "other", WindowIdx: 0},
+		},
+	}
+	return ctx, &calls
+}
+
+func TestLoadSearchResultsMenu(t *testing.T) {
+	ctx, _ := stubSearch(t)
+	ctx.SearchScope, ctx.SearchQuery, ctx.SearchRegex = "server", "(?i)panic", true
+	items, err := loadSearchResultsMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{
+		{ID: "%1:1", Label: "work:0.0 line 2: panic: runtime error"},
+		{ID: "%3:0", Label: "other:0.0 line 1: PANIC in other"},
+	}
+	if !slices.Equal(items, want) {
+		t.Fatalf("items = %#v, want %#v", items, want)
+	}
+
+	ctx.SearchScope, ctx.SearchQuery, ctx.SearchRegex = "window", "mk", false
+	items, err = loadSearchResultsMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != "%1:0" {
+		t.Fatalf("expected the fuzzy match in the current window only, got %#v", items)
+	}
+}
+
+func TestSearchPreviewLines(t *testing.T) {
+	ctx, _ := stubSearch(t)
+	ctx.SearchQuery, ctx.SearchRegex = "runtime", true
+	lines, err := SearchPreviewLines(ctx, "%1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("expected the whole short scrollback, got %q", lines)
+	}
+	if !strings.Contains(lines[1], "2 │") || !strings.Contains(lines[1], searchMatchOn+"runtime"+searchMatchOff) {
+		t.Fatalf("expected a highlighted match on line 2, got %q", lines[1])
+	}
+	if _, err := SearchPreviewLines(ctx, "%1:99"); err == nil {
+		t.Fatal("expected an error for a line past the scrollback")
+	}
+}
+
+func TestSearchJumpAction(t *testing.T) {
+	ctx, calls := stubSearch(t)
+	res := SearchJumpAction(ctx, Item{ID: "%1:2"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []string{
+		"display-message -p -t %1 #{history_size}\t#{pane_width}",
+		"switch work:0.0",
+		"copy-mode -t %1",
+		"send-keys -t %1 -X goto-line 38",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+	if res := SearchJumpAction(ctx, Item{ID: "%9:0"})().(ActionResult); res.Err == nil {
+		t.Fatal("expected an error for a pane that is gone")
+	}
+}
+
+func TestSearchForm(t *testing.T) {
+	form := NewSearchForm(SearchPrompt{Scope: "session"})
+	for _, r := range "(" {
+		form.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if !strings.HasPrefix(form.Help(), "Mode: fuzzy") {
+		t.Fatalf("help = %q", form.Help())
+	}
+	form.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if !form.Regex() || strings.HasPrefix(form.Help(), "Mode:") {
+		t.Fatalf("expected a regex error after tab, got %q", form.Help())
+	}
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done {
+		t.Fatal("expected an invalid regex to keep the form open")
+	}
+	form.Update(tea.KeyPressMsg{Code: ')', Text: ")"})
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); !done {
+		t.Fatal("expected the form to submit")
+	}
+	if form.Scope() != "session" || form.Value() != "()" {
+		t.Fatalf("unexpected form state %q %q", form.Scope(), form.Value())
+	}
+}
@@ -0,0 +1,297 @@
+package menu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	serverStartTimeFn = tmux.ServerStartTime
+	serverSaveFn      = saveBeforeKill
+)
+
+// serverToggles are the server options the menu flips between on and off.
+var serverToggles = []string{"exit-empty", "exit-unattached"}
+
+// loadServerMenu lists the server actions, with each toggle showing its
+// current value.
+func loadServerMenu(ctx Context) ([]Item, error) {
+	items := menuItemsFromIDs([]string{"reload", "info"})
+	for _, opt := range serverToggles {
+		items = append(items, Item{ID: opt, Label: fmt.Sprintf("%s (%s)", opt, serverOption(ctx, opt))})
+	}
+	return append(items, Item{ID: "kill-server", Label: "kill-server"}), nil
+}
+
+func serverOption(ctx Context, opt string) string {
+	out, err := tmuxOutput(ctx.SocketPath, "show-options", "-sv", opt)
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(out)
+}
+
+// serverConfigFiles returns the files the server loaded at startup, from
+// #{config_files}.
+func serverConfigFiles(ctx Context) ([]string, error) {
+	out, err := tmuxOutput(ctx.SocketPath, "display-message", "-p", "#{config_files}")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for f := range strings.SplitSeq(strings.TrimSpace(out), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// ServerReloadAction sources each config file the server started with. Parse
+// errors, and anything the config prints, open in the command output view
+// instead of vanishing with the popup.
+func ServerReloadAction(ctx Context, _ Item) tea.Cmd {
+	return func() tea.Msg {
+		files, err := serverConfigFiles(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if len(files) == 0 {
+			return ActionResult{Err: errors.New("the server was started without a config file")}
+		}
+		home, _ := os.UserHomeDir()
+		var (
+			report []string
+			failed int
+		)
+		for _, f := range files {
+			out, err := runCommandOutputFn(ctx.SocketPath, "source-file", f)
+			text := strings.TrimSpace(string(out))
+			if err != nil {
+				failed++
+				if text == "" {
+					text = err.Error()
+				}
+			}
+			if text != "" {
+				report = append(report, "source-file "+abbreviateHome(f, home)+":", text, "")
+			}
+		}
+		events.Server.Reload(files, failed)
+		if failed > 0 {
+			report = append([]string{fmt.Sprintf("%d of %d config file(s) failed to load", failed, len(files)), ""}, report...)
+		}
+		if len(report) > 0 {
+			return ActionResult{Output: strings.Join(report, "\n")}
+		}
+		return ActionResult{Info: fmt.Sprintf("Reloaded %d config file(s)", len(files))}
+	}
+}
+
+// ServerInfoAction shows the server's pid, version, uptime, socket and
+// object counts in the command output view.
+func ServerInfoAction(ctx Context, _ Item) tea.Cmd {
+	return func() tea.Msg {
+		out, err := tmuxOutput(ctx.SocketPath, "display-message", "-p", "#{pid}\t#{version}\t#{socket_path}\t#{config_files}")
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		fields := strings.SplitN(strings.TrimRight(out, "\n"), "\t", 4)
+		for len(fields) < 4 {
+			fields = append(fields, "")
+		}
+		uptime := "unknown"
+		if started, err := serverStartTimeFn(ctx.SocketPath); err == nil {
+			uptime = fmt.Sprintf("%s (since %s)", formatUptime(time.Since(started)), started.Format("2006-01-02 15:04"))
+		}
+		attached := 0
+		for _, s := range ctx.Sessions {
+			if s.Attached {
+				attached++
+			}
+		}
+		lines := []string{
+			"pid:             " + fields[0],
+			"version:         " + fields[1],
+			"uptime:          " + uptime,
+			"socket:          " + fields[2],
+			"config:          " + fields[3],
+			fmt.Sprintf("sessions:        %d (%d attached)", len(ctx.Sessions), attached),
+			fmt.Sprintf("windows:         %d", len(ctx.Windows)),
+			fmt.Sprintf("panes:           %d", len(ctx.Panes)),
+		}
+		for _, opt := range serverToggles {
+			lines = append(lines, fmt.Sprintf("%-16s %s", opt+":", serverOption(ctx, opt)))
+		}
+		return ActionResult{Output: strings.Join(lines, "\n")}
+	}
+}
+
+// formatUptime renders d as days, hours and minutes, e.g. "3d 4h 12m".
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// ServerExitEmptyAction toggles whether the server exits once no sessions
+// are left.
+func ServerExitEmptyAction(ctx Context, _ Item) tea.Cmd {
+	return serverToggleAction(ctx, "exit-empty")
+}
+
+// ServerExitUnattachedAction toggles whether the server exits once no
+// clients are attached.
+func ServerExitUnattachedAction(ctx Context, _ Item) tea.Cmd {
+	return serverToggleAction(ctx, "exit-unattached")
+}
+
+func serverToggleAction(ctx Context, opt string) tea.Cmd {
+	return func() tea.Msg {
+		value := "on"
+		if serverOption(ctx, opt) == "on" {
+			value = "off"
+		}
+		events.Server.Toggle(opt, value)
+		if _, err := tmuxOutput(ctx.SocketPath, "set-option", "-s", opt, value); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Set %s %s", opt, value)}
+	}
+}
+
+// ServerKillPrompt asks for the server's socket name before killing it.
+type ServerKillPrompt struct {
+	Context Context
+	Name    string
+}
+
+// ServerKillAction opens the typed confirmation for kill-server.
+func ServerKillAction(ctx Context, _ Item) tea.Cmd {
+	return func() tea.Msg {
+		out, err := tmuxOutput(ctx.SocketPath, "display-message", "-p", "#{socket_path}")
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		return ServerKillPrompt{Context: ctx, Name: filepath.Base(strings.TrimSpace(out))}
+	}
+}
+
+// ServerKillCommand saves every session, then kills the server. A failed
+// save leaves the server running.
+func ServerKillCommand(ctx Context) tea.Cmd {
+	return func() tea.Msg {
+		if err := serverSaveFn(ctx); err != nil {
+			return ActionResult{Err: fmt.Errorf("not killing the server, save failed: %w", err)}
+		}
+		events.Server.Kill(ctx.SocketPath)
+		if _, err := tmuxOutput(ctx.SocketPath, "kill-server"); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: "Saved all sessions and killed the server"}
+	}
+}
+
+// saveBeforeKill runs a named resurrect save and waits for it to finish.
+func saveBeforeKill(ctx Context) error {
+	dir, err := resurrect.ResolveDir(ctx.SocketPath)
+	if err != nil {
+		return err
+	}
+	cfg := resurrect.Config{
+		SocketPath:          ctx.SocketPath,
+		SaveDir:             dir,
+		CapturePaneContents: resurrect.ResolvePaneContents(ctx.SocketPath),
+		Name:                "before-kill-server",
+		Kind:                resurrect.SaveKindManual,
+		ClientID:            ctx.ClientID,
+	}
+	for event := range resurrect.Save(context.Background(), cfg) {
+		if event.Done {
+			return event.Err
+		}
+	}
+	return errors.New("save ended without finishing")
+}
+
+// ServerKillForm makes kill-server wait until the socket name is typed.
+type ServerKillForm struct {
+	input textinput.Model
+	ctx   Context
+	name  string
+}
+
+func NewServerKillForm(prompt ServerKillPrompt) *ServerKillForm {
+	ti := textinput.New()
+	styleFormInput(&ti)
+	ti.Placeholder = prompt.Name
+	ti.CharLimit = 256
+	ti.SetWidth(40)
+	ti.Focus()
+	return &ServerKillForm{input: ti, ctx: prompt.Context, name: prompt.Name}
+}
+
+func (f *ServerKillForm) Context() Context     { return f.ctx }
+func (f *ServerKillForm) Target() string       { return f.name }
+func (f *ServerKillForm) Value() string        { return strings.TrimSpace(f.input.Value()) }
+func (f *ServerKillForm) InputView() string    { return f.input.View() }
+func (f *ServerKillForm) Cursor() *tea.Cursor  { return f.input.Cursor() }
+func (f *ServerKillForm) FocusCmd() tea.Cmd    { return f.input.Focus() }
+func (f *ServerKillForm) ActionID() string     { return "server:kill-server" }
+func (f *ServerKillForm) PendingLabel() string { return "kill-server " + f.name }
+func (f *ServerKillForm) Title() string        { return "Kill the tmux server " + f.name }
+
+// Help says what to type, and what happens once it matches.
+func (f *ServerKillForm) Help() string {
+	if f.Value() != f.name {
+		return fmt.Sprintf("Type %q to confirm. Esc to cancel.", f.name)
+	}
+	return "Press Enter to save all sessions and kill the server. Esc to cancel."
+}
+
+func (f *ServerKillForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch m.String() {
+		case "ctrl+u":
+			if f.input.Value() != "" {
+				f.input.SetValue("")
+				f.input.CursorStart()
+			}
+			return nil, false, false
+		case "esc":
+			return nil, false, true
+		case "enter":
+			if f.Value() == "" {
+				return nil, false, true
+			}
+			if f.Value() != f.name {
+				return nil, false, false
+			}
+			return ServerKillCommand(f.ctx), true, false
+		}
+	}
+	updated, cmd := f.input.Update(msg)
+	f.input = updated
+	return cmd, false, false
+}
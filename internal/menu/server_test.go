@@ -0,0 +1,126 @@
+package menu
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// stubServer answers the server queries and records every other tmux call.
+// Sourcing a file named bad.conf fails with a parse error.
+func stubServer(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		joined := strings.Join(args, " ")
+		switch {
+		case joined == "display-message -p #{config_files}":
+			return []byte("/etc/tmux.conf,/home/u/bad.conf\n"), nil
+		case joined == "display-message -p #{socket_path}":
+			return []byte("/tmp/tmux-1000/work\n"), nil
+		case strings.HasPrefix(joined, "display-message -p #{pid}"):
+			return []byte("4242\t3.4\t/tmp/tmux-1000/work\t/etc/tmux.conf\n"), nil
+		case joined == "show-options -sv exit-empty":
+			return []byte("on\n"), nil
+		case joined == "show-options -sv exit-unattached":
+			return []byte("off\n"), nil
+		case joined == "source-file /home/u/bad.conf":
+			calls = append(calls, joined)
+			return []byte("/home/u/bad.conf:3: unknown command: sett\n"), errors.New("exit status 1")
+		}
+		calls = append(calls, joined)
+		return nil, nil
+	}))
+	return &calls
+}
+
+func TestLoadServerMenuShowsToggleState(t *testing.T) {
+	stubServer(t)
+	items, err := loadServerMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, it := range items {
+		labels = append(labels, it.Label)
+	}
+	want := []string{"reload", "info", "exit-empty (on)", "exit-unattached (off)", "kill-server"}
+	if !slices.Equal(labels, want) {
+		t.Fatalf("labels = %q, want %q", labels, want)
+	}
+}
+
+func TestServerReloadReportsParseErrors(t *testing.T) {
+	calls := stubServer(t)
+	res := ServerReloadAction(Context{}, Item{})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := []string{"source-file /etc/tmux.conf", "source-file /home/u/bad.conf"}; !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+	if !strings.HasPrefix(res.Output, "1 of 2 config file(s) failed to load") ||
+		!strings.Contains(res.Output, "bad.conf:3: unknown command: sett") {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+}
+
+func TestServerInfoAndToggle(t *testing.T) {
+	calls := stubServer(t)
+	t.Cleanup(withPaneStub(&serverStartTimeFn, func(string) (time.Time, error) {
+		return time.Now().Add(-26*time.Hour - 5*time.Minute), nil
+	}))
+	ctx := Context{
+		Sessions: []SessionEntry{{Name: "work", Attached: true}, {Name: "ops"}},
+		Panes:    []PaneEntry{{ID: "work:0.0"}},
+	}
+	res := ServerInfoAction(ctx, Item{})().(ActionResult)
+	for _, want := range []string{"pid:             4242", "uptime:          1d 2h 5m", "sessions:        2 (1 attached)", "exit-unattached: off"} {
+		if !strings.Contains(res.Output, want) {
+			t.Fatalf("info missing %q:\n%s", want, res.Output)
+		}
+	}
+	if res := ServerExitEmptyAction(ctx, Item{})().(ActionResult); res.Info != "Set exit-empty off" {
+		t.Fatalf("unexpected toggle result %#v", res)
+	}
+	if want := []string{"set-option -s exit-empty off"}; !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestServerKillFormSavesBeforeKilling(t *testing.T) {
+	calls := stubServer(t)
+	saveErr := errors.New("disk full")
+	t.Cleanup(withPaneStub(&serverSaveFn, func(Context) error { return saveErr }))
+
+	prompt := ServerKillAction(Context{}, Item{})().(ServerKillPrompt)
+	if prompt.Name != "work" {
+		t.Fatalf("prompt name = %q", prompt.Name)
+	}
+	form := NewServerKillForm(prompt)
+	for _, r := range "wor" {
+		form.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done {
+		t.Fatal("expected a partial name to keep the form open")
+	}
+	form.Update(tea.KeyPressMsg{Code: 'k', Text: "k"})
+	cmd, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !done || cmd == nil {
+		t.Fatal("expected the typed name to confirm")
+	}
+	if res := cmd().(ActionResult); res.Err == nil || len(*calls) != 0 {
+		t.Fatalf("expected a failed save to keep the server, got %#v calls %q", res, *calls)
+	}
+	saveErr = nil
+	if res := cmd().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := []string{"kill-server"}; !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
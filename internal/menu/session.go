@@ -10,6 +10,7 @@ import (
 
 	"github.com/atomicstack/tmux-popup-control/internal/format/table"
 	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
 	"github.com/atomicstack/tmux-popup-control/internal/tmux"
 )
 
@@ -19,6 +20,12 @@ func loadSessionMenu(Context) ([]Item, error) {
 		"detach",
 		"rename",
 		"new",
+		"new-from-template",
+		"import",
+		"open-project",
+		"new-grouped",
+		"clone",
+		"environment",
 		"switch",
 		"tree",
 	}), nil
@@ -94,7 +101,10 @@ func SessionKillAction(ctx Context, item Item) tea.Cmd {
 	}
 	return runAction(
 		func() { events.Session.Kill(target) },
-		func() error { return tmux.KillSessions(ctx.SocketPath, []string{target}) },
+		func() error {
+			trashKilledFn(ctx, resurrect.TrashSession, []string{target})
+			return tmux.KillSessions(ctx.SocketPath, []string{target})
+		},
 		fmt.Sprintf("Killed %s", label),
 	)
 }
@@ -135,6 +145,10 @@ func SessionCommandForAction(req SessionRequest) tea.Cmd {
 	switch req.Action {
 	case "session:rename":
 		return SessionRenameCommand(req)
+	case "session:new-grouped":
+		return SessionGroupedCommand(req)
+	case "session:clone":
+		return SessionCloneCommand(req)
 	default:
 		return SessionCreateCommand(req)
 	}
@@ -184,6 +198,12 @@ func NewSessionForm(prompt SessionPrompt) *SessionForm {
 		}
 		help = "Press Enter to rename. Esc to cancel."
 	}
+	switch prompt.Action {
+	case "session:new-grouped":
+		title = fmt.Sprintf("new session grouped with %s", target)
+	case "session:clone":
+		title = fmt.Sprintf("clone %s", target)
+	}
 	form := &SessionForm{
 		input:    ti,
 		existing: map[string]struct{}{},
@@ -269,7 +289,7 @@ func (f *SessionForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
 				}
 				f.err = ""
 				events.Session.SubmitNew(value)
-				return SessionCreateCommand(f.Request()), true, false
+				return SessionCommandForAction(f.Request()), true, false
 			case sessionFormModeRename:
 				if value == "" {
 					events.Session.CancelRename(f.target, events.SessionReasonEmpty)
@@ -429,10 +449,12 @@ func SessionEntriesFromTmux(sessions []tmux.Session) []SessionEntry {
 		entry := SessionEntry{
 			Name:     sess.Name,
 			Label:    sess.Label,
+			Path:     sess.Path,
 			Attached: sess.Attached,
 			Current:  sess.Current,
 			Clients:  slices.Clone(sess.Clients),
 			Windows:  sess.Windows,
+			Group:    sess.Group,
 		}
 		entries = append(entries, entry)
 	}
@@ -496,6 +518,8 @@ func sessionTableItems(entries []SessionEntry) []Item {
 	if len(entries) == 0 {
 		return nil
 	}
+	// the group column only appears once some session is grouped.
+	grouped := slices.ContainsFunc(entries, func(e SessionEntry) bool { return e.Group != "" })
 	rows := make([][]string, 0, len(entries))
 	ids := make([]string, 0, len(entries))
 	for _, entry := range entries {
@@ -506,10 +530,25 @@ func sessionTableItems(entries []SessionEntry) []Item {
 		if entry.Current {
 			current = "current"
 		}
-		rows = append(rows, []string{name, windows, status, current})
+		row := []string{name, windows, status, current}
+		if grouped {
+			row = []string{name, windows, sessionGroupLabel(entry), status, current}
+		}
+		rows = append(rows, row)
 		ids = append(ids, entry.Name)
 	}
-	return tableItems(rows, ids, []table.Alignment{table.AlignLeft, table.AlignRight, table.AlignLeft, table.AlignLeft})
+	aligns := []table.Alignment{table.AlignLeft, table.AlignRight, table.AlignLeft, table.AlignLeft}
+	if grouped {
+		aligns = append(aligns, table.AlignLeft)
+	}
+	return tableItems(rows, ids, aligns)
+}
+
+func sessionGroupLabel(entry SessionEntry) string {
+	if entry.Group == "" {
+		return ""
+	}
+	return "group " + entry.Group
 }
 
 func sessionStatus(entry SessionEntry) string {
@@ -0,0 +1,575 @@
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/process"
+	"github.com/atomicstack/tmux-popup-control/internal/tmuxenv"
+)
+
+var paneEnvironFn = process.Environ
+
+// envValueWidth caps how much of a value the tables show.
+const envValueWidth = 40
+
+// sessionEnv is the current session's environment, the global one, and
+// the variables tmux refreshes from the client on attach.
+type sessionEnv struct {
+	session string
+	local   []tmuxenv.Var
+	global  []tmuxenv.Var
+	update  []string
+}
+
+func loadSessionEnv(ctx Context) (sessionEnv, error) {
+	session := strings.TrimSpace(ctx.Current)
+	if session == "" {
+		return sessionEnv{}, errors.New("no current session")
+	}
+	local, err := tmuxOutput(ctx.SocketPath, "show-environment", "-t", session)
+	if err != nil {
+		return sessionEnv{}, err
+	}
+	global, err := tmuxOutput(ctx.SocketPath, "show-environment", "-g")
+	if err != nil {
+		return sessionEnv{}, err
+	}
+	// update-environment only annotates the lists; an old server without
+	// it just gets no annotations.
+	update, _ := tmuxOutput(ctx.SocketPath, "show-options", "-gv", "update-environment")
+	return sessionEnv{
+		session: session,
+		local:   tmuxenv.Parse(local),
+		global:  tmuxenv.Parse(global),
+		update:  tmuxenv.ParseUpdateList(update),
+	}, nil
+}
+
+func (e sessionEnv) effective(name string) (string, bool) {
+	return tmuxenv.Effective(e.local, e.global, name)
+}
+
+// names lists every variable either scope mentions, session ones first.
+func (e sessionEnv) names() []string {
+	var out []string
+	for _, v := range slices.Concat(e.local, e.global) {
+		if !slices.Contains(out, v.Name) {
+			out = append(out, v.Name)
+		}
+	}
+	return out
+}
+
+// paneEnviron is the environment a pane's shell started with; err is set
+// when /proc could not be read for it.
+type paneEnviron struct {
+	pane PaneEntry
+	env  map[string]string
+	err  error
+}
+
+// sessionPaneEnvirons reads the starting environment of every pane of the
+// session.
+func sessionPaneEnvirons(ctx Context, session string) []paneEnviron {
+	var out []paneEnviron
+	for _, pane := range ctx.Panes {
+		if pane.Session != session {
+			continue
+		}
+		pe := paneEnviron{pane: pane}
+		if pane.PID <= 0 {
+			pe.err = fmt.Errorf("no pid for pane %s", pane.PaneID)
+		} else {
+			pe.env, pe.err = paneEnvironFn(pane.PID)
+		}
+		out = append(out, pe)
+	}
+	return out
+}
+
+// stalePanes returns the panes whose starting value of name differs from
+// what a new pane would get, and how many could not be read.
+func stalePanes(e sessionEnv, panes []paneEnviron, name string) ([]paneEnviron, int) {
+	value, set := e.effective(name)
+	var (
+		stale      []paneEnviron
+		unreadable int
+	)
+	for _, p := range panes {
+		switch {
+		case p.err != nil:
+			unreadable++
+		case tmuxenv.Stale(p.env, name, value, set):
+			stale = append(stale, p)
+		}
+	}
+	return stale, unreadable
+}
+
+// envID keys a variable in the lists: "session:NAME" or "global:NAME".
+func envID(global bool, name string) string {
+	if global {
+		return "global:" + name
+	}
+	return "session:" + name
+}
+
+// parseEnvID splits an envID. A bare name is a session variable.
+func parseEnvID(id string) (global bool, name string) {
+	if rest, ok := strings.CutPrefix(id, "global:"); ok {
+		return true, rest
+	}
+	return false, strings.TrimPrefix(id, "session:")
+}
+
+func clipEnvValue(v tmuxenv.Var) string {
+	if v.Removed {
+		return "(removed)"
+	}
+	runes := []rune(v.Value)
+	if len(runes) > envValueWidth {
+		return string(runes[:envValueWidth-1]) + "…"
+	}
+	return v.Value
+}
+
+func loadSessionEnvMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{
+		"new",
+		"set",
+		"unset",
+		"remove",
+		"copy-to-global",
+		"refresh-panes",
+	}), nil
+}
+
+// loadSessionEnvListMenu lists the session's variables, then the global
+// ones, as a table keyed by envID.
+func loadSessionEnvListMenu(ctx Context) ([]Item, error) {
+	return sessionEnvTable(ctx, true)
+}
+
+// loadSessionEnvLocalMenu lists only the session's own variables.
+func loadSessionEnvLocalMenu(ctx Context) ([]Item, error) {
+	return sessionEnvTable(ctx, false)
+}
+
+func sessionEnvTable(ctx Context, withGlobal bool) ([]Item, error) {
+	e, err := loadSessionEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	panes := sessionPaneEnvirons(ctx, e.session)
+	type row struct {
+		global bool
+		v      tmuxenv.Var
+	}
+	var rows []row
+	for _, v := range e.local {
+		rows = append(rows, row{false, v})
+	}
+	if withGlobal {
+		for _, v := range e.global {
+			rows = append(rows, row{true, v})
+		}
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	cells := make([][]string, 0, len(rows)+1)
+	cells = append(cells, []string{"scope", "name", "value", "notes"})
+	for _, r := range rows {
+		var notes []string
+		if slices.Contains(e.update, r.v.Name) {
+			notes = append(notes, "update-environment")
+		}
+		if _, shadowed := tmuxenv.Lookup(e.local, r.v.Name); r.global && shadowed {
+			notes = append(notes, "overridden")
+		} else if stale, _ := stalePanes(e, panes, r.v.Name); len(stale) > 0 {
+			notes = append(notes, fmt.Sprintf("%d stale pane(s)", len(stale)))
+		}
+		scope := e.session
+		if r.global {
+			scope = "global"
+		}
+		cells = append(cells, []string{scope, r.v.Name, clipEnvValue(r.v), strings.Join(notes, ", ")})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignLeft, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: envID(rows[i].global, rows[i].v.Name), Label: label})
+	}
+	return items, nil
+}
+
+// loadSessionEnvStaleMenu lists the variables some pane of the session
+// started with a different value of, update-environment ones first since a
+// reattach from another machine is what usually moves them.
+func loadSessionEnvStaleMenu(ctx Context) ([]Item, error) {
+	e, err := loadSessionEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	panes := sessionPaneEnvirons(ctx, e.session)
+	names := e.names()
+	slices.SortStableFunc(names, func(a, b string) int {
+		ua, ub := slices.Contains(e.update, a), slices.Contains(e.update, b)
+		switch {
+		case ua && !ub:
+			return -1
+		case ub && !ua:
+			return 1
+		}
+		return 0
+	})
+	var (
+		ids   []string
+		cells = [][]string{{"name", "value", "stale"}}
+	)
+	for _, name := range names {
+		stale, _ := stalePanes(e, panes, name)
+		if len(stale) == 0 {
+			continue
+		}
+		value, set := e.effective(name)
+		ids = append(ids, name)
+		cells = append(cells, []string{name, clipEnvValue(tmuxenv.Var{Value: value, Removed: !set}), fmt.Sprintf("%d/%d", len(stale), len(panes))})
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignRight})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: envID(false, ids[i]), Label: label})
+	}
+	return items, nil
+}
+
+// sessionEnvPreview shows both scopes' values of the highlighted variable
+// and which of the session's panes started before it changed.
+func sessionEnvPreview(ctx Context, item Item) ([]string, error) {
+	e, err := loadSessionEnv(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, name := parseEnvID(item.ID)
+	show := func(vars []tmuxenv.Var) string {
+		v, ok := tmuxenv.Lookup(vars, name)
+		if !ok {
+			return "(unset)"
+		}
+		if v.Removed {
+			return "(removed)"
+		}
+		return v.Value
+	}
+	value, set := e.effective(name)
+	if !set {
+		value = "(unset)"
+	}
+	lines := []string{
+		name,
+		"session " + e.session + ": " + show(e.local),
+		"global: " + show(e.global),
+		"new panes get: " + value,
+	}
+	if slices.Contains(e.update, name) {
+		lines = append(lines, "in update-environment: refreshed from the client on attach")
+	}
+	panes := sessionPaneEnvirons(ctx, e.session)
+	stale, unreadable := stalePanes(e, panes, name)
+	lines = append(lines, "")
+	if len(stale) == 0 {
+		lines = append(lines, fmt.Sprintf("all %d readable pane(s) are up to date", len(panes)-unreadable))
+	} else {
+		lines = append(lines, "stale panes (started with another value):")
+		for _, p := range stale {
+			was, ok := p.env[name]
+			if !ok {
+				was = "(unset)"
+			}
+			lines = append(lines, fmt.Sprintf("  %s  %s  %s", p.pane.ID, p.pane.Command, was))
+		}
+	}
+	if unreadable > 0 {
+		lines = append(lines, fmt.Sprintf("%d pane(s) unreadable", unreadable))
+	}
+	return lines, nil
+}
+
+// SessionEnvPrompt asks for a NAME=value to set in the session's scope or
+// the global one.
+type SessionEnvPrompt struct {
+	Context Context
+	Global  bool
+	Initial string
+}
+
+// SessionEnvNewAction opens the form for a new session variable.
+func SessionEnvNewAction(ctx Context, _ Item) tea.Cmd {
+	return func() tea.Msg {
+		return SessionEnvPrompt{Context: ctx}
+	}
+}
+
+// SessionEnvSetAction opens the form on the picked variable, in its scope.
+func SessionEnvSetAction(ctx Context, item Item) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no variable selected")
+	}
+	return func() tea.Msg {
+		e, err := loadSessionEnv(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		global, name := parseEnvID(id)
+		vars := e.local
+		if global {
+			vars = e.global
+		}
+		v, _ := tmuxenv.Lookup(vars, name)
+		return SessionEnvPrompt{Context: ctx, Global: global, Initial: name + "=" + v.Value}
+	}
+}
+
+// SessionEnvSetCommand sets name for panes started from now on.
+func SessionEnvSetCommand(ctx Context, global bool, name, value string) tea.Cmd {
+	return func() tea.Msg {
+		if err := tmuxenv.CheckName(name); err != nil {
+			return ActionResult{Err: err}
+		}
+		args := append([]string{"set-environment"}, envScopeArgs(ctx, global)...)
+		if _, err := tmuxOutput(ctx.SocketPath, append(args, name, value)...); err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Environment.Change("set", envID(global, name))
+		return ActionResult{Info: fmt.Sprintf("Set %s in %s", name, envScopeLabel(ctx, global))}
+	}
+}
+
+func envScopeArgs(ctx Context, global bool) []string {
+	if global {
+		return []string{"-g"}
+	}
+	return []string{"-t", strings.TrimSpace(ctx.Current)}
+}
+
+func envScopeLabel(ctx Context, global bool) string {
+	if global {
+		return "the global environment"
+	}
+	return strings.TrimSpace(ctx.Current)
+}
+
+// SessionEnvUnsetAction deletes the selected variables from their scope;
+// a session variable unset this way falls back to the global value.
+func SessionEnvUnsetAction(ctx Context, item Item) tea.Cmd {
+	return sessionEnvFlagAction(ctx, item, "-u", "unset", "Unset")
+}
+
+// SessionEnvRemoveAction marks the selected variables as removed, keeping
+// them out of new panes even where the global environment sets them.
+func SessionEnvRemoveAction(ctx Context, item Item) tea.Cmd {
+	return sessionEnvFlagAction(ctx, item, "-r", "remove", "Removed")
+}
+
+func sessionEnvFlagAction(ctx Context, item Item, flag, op, verb string) tea.Cmd {
+	ids := splitSelectionIDs(item.ID)
+	if len(ids) == 0 {
+		return failCmd("no variable selected")
+	}
+	return func() tea.Msg {
+		for _, id := range ids {
+			global, name := parseEnvID(id)
+			args := append([]string{"set-environment"}, envScopeArgs(ctx, global)...)
+			if _, err := tmuxOutput(ctx.SocketPath, append(args, flag, name)...); err != nil {
+				return ActionResult{Err: err}
+			}
+			events.Environment.Change(op, id)
+		}
+		return ActionResult{Info: fmt.Sprintf("%s %d variable(s)", verb, len(ids))}
+	}
+}
+
+// SessionEnvCopyGlobalAction copies the selected session variables, or
+// their removal, into the global environment.
+func SessionEnvCopyGlobalAction(ctx Context, item Item) tea.Cmd {
+	ids := splitSelectionIDs(item.ID)
+	if len(ids) == 0 {
+		return failCmd("no variable selected")
+	}
+	return func() tea.Msg {
+		e, err := loadSessionEnv(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		for _, id := range ids {
+			_, name := parseEnvID(id)
+			v, ok := tmuxenv.Lookup(e.local, name)
+			if !ok {
+				return ActionResult{Err: fmt.Errorf("%s is not set in %s", name, e.session)}
+			}
+			args := []string{"set-environment", "-g", name, v.Value}
+			if v.Removed {
+				args = []string{"set-environment", "-g", "-r", name}
+			}
+			if _, err := tmuxOutput(ctx.SocketPath, args...); err != nil {
+				return ActionResult{Err: err}
+			}
+			events.Environment.Change("copy-to-global", id)
+		}
+		return ActionResult{Info: fmt.Sprintf("Copied %d variable(s) to the global environment", len(ids))}
+	}
+}
+
+// SessionEnvRefreshPanesAction types an export, or unset, of each selected
+// variable into the stale panes that sit at a shell prompt. Panes running
+// anything else are skipped so the keys never land in an editor or a REPL.
+// /proc keeps reporting the environment a shell started with, so refreshed
+// panes still count as stale afterwards.
+func SessionEnvRefreshPanesAction(ctx Context, item Item) tea.Cmd {
+	ids := splitSelectionIDs(item.ID)
+	if len(ids) == 0 {
+		return failCmd("no variable selected")
+	}
+	return func() tea.Msg {
+		e, err := loadSessionEnv(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		panes := sessionPaneEnvirons(ctx, e.session)
+		updated, skipped := 0, 0
+		for _, id := range ids {
+			_, name := parseEnvID(id)
+			value, set := e.effective(name)
+			stale, _ := stalePanes(e, panes, name)
+			for _, p := range stale {
+				if !tmuxenv.IsShell(p.pane.Command) {
+					skipped++
+					continue
+				}
+				line := tmuxenv.ShellCommand(p.pane.Command, name, value, set)
+				if _, err := tmuxOutput(ctx.SocketPath, "send-keys", "-t", p.pane.PaneID, "-l", line); err != nil {
+					return ActionResult{Err: err}
+				}
+				if _, err := tmuxOutput(ctx.SocketPath, "send-keys", "-t", p.pane.PaneID, "Enter"); err != nil {
+					return ActionResult{Err: err}
+				}
+				updated++
+			}
+			events.Environment.Change("refresh-panes", id)
+		}
+		info := fmt.Sprintf("Refreshed %d pane variable(s)", updated)
+		if skipped > 0 {
+			info += fmt.Sprintf(", skipped %d busy pane(s)", skipped)
+		}
+		return ActionResult{Info: info}
+	}
+}
+
+// SessionEnvForm takes a NAME=value for set-environment.
+type SessionEnvForm struct {
+	input  textinput.Model
+	ctx    Context
+	global bool
+}
+
+func NewSessionEnvForm(prompt SessionEnvPrompt) *SessionEnvForm {
+	ti := textinput.New()
+	styleFormInput(&ti)
+	ti.Placeholder = "NAME=value"
+	ti.CharLimit = 4096
+	ti.SetWidth(60)
+	ti.SetValue(prompt.Initial)
+	ti.CursorEnd()
+	ti.Focus()
+	return &SessionEnvForm{input: ti, ctx: prompt.Context, global: prompt.Global}
+}
+
+func (f *SessionEnvForm) Context() Context    { return f.ctx }
+func (f *SessionEnvForm) Value() string       { return f.input.Value() }
+func (f *SessionEnvForm) InputView() string   { return f.input.View() }
+func (f *SessionEnvForm) Cursor() *tea.Cursor { return f.input.Cursor() }
+func (f *SessionEnvForm) FocusCmd() tea.Cmd   { return f.input.Focus() }
+func (f *SessionEnvForm) ActionID() string    { return "session:environment:set" }
+func (f *SessionEnvForm) Target() string {
+	name, _ := f.parts()
+	return envID(f.global, name)
+}
+func (f *SessionEnvForm) PendingLabel() string {
+	name, _ := f.parts()
+	return "set " + name
+}
+
+func (f *SessionEnvForm) Title() string {
+	if f.global {
+		return "Set a global variable"
+	}
+	return "Set a variable in " + strings.TrimSpace(f.ctx.Current)
+}
+
+// parts splits the input at its first '='; the value is kept verbatim.
+func (f *SessionEnvForm) parts() (string, string) {
+	name, value, _ := strings.Cut(f.input.Value(), "=")
+	return strings.TrimSpace(name), value
+}
+
+func (f *SessionEnvForm) validate() error {
+	if !strings.Contains(f.input.Value(), "=") {
+		return errors.New("expected NAME=value")
+	}
+	name, _ := f.parts()
+	return tmuxenv.CheckName(name)
+}
+
+// Help says what the entry will do, or why it can't be set.
+func (f *SessionEnvForm) Help() string {
+	if strings.TrimSpace(f.input.Value()) == "" {
+		return "Enter NAME=value. Esc to cancel."
+	}
+	if err := f.validate(); err != nil {
+		return err.Error()
+	}
+	name, _ := f.parts()
+	return fmt.Sprintf("Sets %s for new panes of %s. Press Enter to set. Esc to cancel.", name, envScopeLabel(f.ctx, f.global))
+}
+
+func (f *SessionEnvForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch m.String() {
+		case "ctrl+u":
+			if f.input.Value() != "" {
+				f.input.SetValue("")
+				f.input.CursorStart()
+			}
+			return nil, false, false
+		case "esc":
+			return nil, false, true
+		case "enter":
+			if strings.TrimSpace(f.input.Value()) == "" {
+				return nil, false, true
+			}
+			if f.validate() != nil {
+				return nil, false, false
+			}
+			name, value := f.parts()
+			return SessionEnvSetCommand(f.ctx, f.global, name, value), true, false
+		}
+	}
+	updated, cmd := f.input.Update(msg)
+	f.input = updated
+	return cmd, false, false
+}
@@ -0,0 +1,182 @@
+package menu
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// stubSessionEnv serves show-environment for session "work" and the global
+// scope, gives panes %1 (zsh) and %2 (vim) an old SSH_AUTH_SOCK, leaves %3
+// unreadable, and records the other tmux calls.
+func stubSessionEnv(t *testing.T) (Context, *[]string) {
+	t.Helper()
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		switch strings.Join(args, " ") {
+		case "show-environment -t work":
+			return []byte("SSH_AUTH_SOCK=/tmp/ssh-new/agent\n-DISPLAY\nEDITOR=vim\n"), nil
+		case "show-environment -g":
+			return []byte("DISPLAY=:0\nEDITOR=nano\nTERM=xterm\n"), nil
+		case "show-options -gv update-environment":
+			return []byte("DISPLAY\nSSH_AUTH_SOCK\n"), nil
+		}
+		calls = append(calls, strings.Join(args, " "))
+		return nil, nil
+	}))
+	environs := map[int]map[string]string{
+		11: {"SSH_AUTH_SOCK": "/tmp/ssh-old/agent", "EDITOR": "vim", "TERM": "xterm"},
+		12: {"SSH_AUTH_SOCK": "/tmp/ssh-old/agent", "EDITOR": "vim", "TERM": "xterm"},
+	}
+	t.Cleanup(withPaneStub(&paneEnvironFn, func(pid int) (map[string]string, error) {
+		if env, ok := environs[pid]; ok {
+			return env, nil
+		}
+		return nil, errors.New("permission denied")
+	}))
+	ctx := Context{
+		Current: "work",
+		Panes: []PaneEntry{
+			{ID: "work:0.0", PaneID: "%1", Session: "work", Command: "zsh", PID: 11},
+			{ID: "work:1.0", PaneID: "%2", Session: "work", Command: "vim", PID: 12},
+			{ID: "work:2.0", PaneID: "%3", Session: "work", Command: "bash", PID: 13},
+			{ID: "other:0.0", PaneID: "%4", Session: "other", Command: "zsh", PID: 14},
+		},
+	}
+	return ctx, &calls
+}
+
+func TestLoadSessionEnvListMenu(t *testing.T) {
+	ctx, _ := stubSessionEnv(t)
+	items, err := loadSessionEnvListMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, it := range items[1:] {
+		ids = append(ids, it.ID)
+	}
+	want := []string{"session:SSH_AUTH_SOCK", "session:DISPLAY", "session:EDITOR", "global:DISPLAY", "global:EDITOR", "global:TERM"}
+	if !items[0].Header || !slices.Equal(ids, want) {
+		t.Fatalf("ids = %q, want %q", ids, want)
+	}
+	if label := items[1].Label; !strings.Contains(label, "update-environment, 2 stale pane(s)") {
+		t.Fatalf("expected SSH_AUTH_SOCK marked stale, got %q", label)
+	}
+	if label := items[2].Label; !strings.Contains(label, "(removed)") {
+		t.Fatalf("expected DISPLAY marked removed, got %q", label)
+	}
+	if label := items[5].Label; !strings.Contains(label, "overridden") {
+		t.Fatalf("expected global EDITOR marked overridden, got %q", label)
+	}
+
+	local, err := loadSessionEnvLocalMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(local) != 4 {
+		t.Fatalf("expected session variables only, got %#v", local)
+	}
+}
+
+func TestLoadSessionEnvStaleMenuAndPreview(t *testing.T) {
+	ctx, _ := stubSessionEnv(t)
+	items, err := loadSessionEnvStaleMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[1].ID != "session:SSH_AUTH_SOCK" || !strings.HasSuffix(items[1].Label, "2/3") {
+		t.Fatalf("unexpected stale items %#v", items)
+	}
+	lines, err := sessionEnvPreview(ctx, items[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"new panes get: /tmp/ssh-new/agent",
+		"in update-environment",
+		"work:0.0  zsh  /tmp/ssh-old/agent",
+		"work:1.0  vim  /tmp/ssh-old/agent",
+		"1 pane(s) unreadable",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("preview missing %q:\n%s", want, joined)
+		}
+	}
+}
+
+func TestSessionEnvRefreshPanesSkipsBusyPanes(t *testing.T) {
+	ctx, calls := stubSessionEnv(t)
+	res := SessionEnvRefreshPanesAction(ctx, Item{ID: "session:SSH_AUTH_SOCK"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []string{
+		"send-keys -t %1 -l export SSH_AUTH_SOCK='/tmp/ssh-new/agent'",
+		"send-keys -t %1 Enter",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+	if res.Info != "Refreshed 1 pane variable(s), skipped 1 busy pane(s)" {
+		t.Fatalf("info = %q", res.Info)
+	}
+}
+
+func TestSessionEnvFlagAndCopyActions(t *testing.T) {
+	ctx, calls := stubSessionEnv(t)
+	if res := SessionEnvUnsetAction(ctx, Item{ID: "session:EDITOR\nglobal:TERM"})().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if res := SessionEnvRemoveAction(ctx, Item{ID: "global:EDITOR"})().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if res := SessionEnvCopyGlobalAction(ctx, Item{ID: "session:SSH_AUTH_SOCK\nsession:DISPLAY"})().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []string{
+		"set-environment -t work -u EDITOR",
+		"set-environment -g -u TERM",
+		"set-environment -g -r EDITOR",
+		"set-environment -g SSH_AUTH_SOCK /tmp/ssh-new/agent",
+		"set-environment -g -r DISPLAY",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestSessionEnvForm(t *testing.T) {
+	ctx, calls := stubSessionEnv(t)
+	prompt := SessionEnvSetAction(ctx, Item{ID: "global:EDITOR"})().(SessionEnvPrompt)
+	if !prompt.Global || prompt.Initial != "EDITOR=nano" {
+		t.Fatalf("unexpected prompt %#v", prompt)
+	}
+
+	form := NewSessionEnvForm(SessionEnvPrompt{Context: ctx, Initial: "BAD NAME=x"})
+	if !strings.Contains(form.Help(), "whitespace") {
+		t.Fatalf("expected a name error, got %q", form.Help())
+	}
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done {
+		t.Fatal("expected an invalid entry to keep the form open")
+	}
+
+	form = NewSessionEnvForm(SessionEnvPrompt{Context: ctx, Initial: "GREETING=a = b"})
+	cmd, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !done || cmd == nil {
+		t.Fatal("expected the form to submit")
+	}
+	if form.Target() != "session:GREETING" {
+		t.Fatalf("target = %q", form.Target())
+	}
+	if res := cmd().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := []string{"set-environment -t work GREETING a = b"}; !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
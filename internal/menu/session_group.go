@@ -0,0 +1,103 @@
+package menu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	newGroupedSessionFn = tmux.NewGroupedSession
+	cloneSessionFn      = resurrect.CloneSession
+	groupSwitchFn       = tmux.SwitchClient
+)
+
+func loadSessionSourceMenu(ctx Context) ([]Item, error) {
+	return SessionSourceItems(ctx.Sessions), nil
+}
+
+// SessionSourceItems lists the sessions to group with or clone, the current
+// one first since it is the usual pick.
+func SessionSourceItems(entries []SessionEntry) []Item {
+	ordered := make([]SessionEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Current {
+			ordered = append([]SessionEntry{entry}, ordered...)
+			continue
+		}
+		ordered = append(ordered, entry)
+	}
+	return sessionTableItems(ordered)
+}
+
+// SessionNewGroupedAction asks for the name of a session to create in the
+// picked session's group.
+func SessionNewGroupedAction(ctx Context, item Item) tea.Cmd {
+	return sessionSourcePrompt(ctx, item, "session:new-grouped")
+}
+
+// SessionCloneAction asks for the name of an independent copy of the picked
+// session.
+func SessionCloneAction(ctx Context, item Item) tea.Cmd {
+	return sessionSourcePrompt(ctx, item, "session:clone")
+}
+
+func sessionSourcePrompt(ctx Context, item Item, action string) tea.Cmd {
+	target := strings.TrimSpace(item.ID)
+	if target == "" {
+		return failCmd("invalid session target")
+	}
+	return func() tea.Msg {
+		events.Session.NewPrompt(len(ctx.Sessions))
+		return SessionPrompt{
+			Context: ctx,
+			Action:  action,
+			Target:  target,
+			Initial: uniqueSessionName(ctx.Sessions, target),
+		}
+	}
+}
+
+// SessionGroupedCommand creates req.Value in req.Target's group and switches
+// to it; the other clients keep their own current window and size.
+func SessionGroupedCommand(req SessionRequest) tea.Cmd {
+	return sessionFromSource(req, "grouped with", func(socket, target, name string) error {
+		return newGroupedSessionFn(socket, target, name)
+	})
+}
+
+// SessionCloneCommand copies req.Target's windows, layouts and working
+// directories into a new session named req.Value and switches to it.
+func SessionCloneCommand(req SessionRequest) tea.Cmd {
+	return sessionFromSource(req, "cloned from", func(socket, target, name string) error {
+		cfg := resurrect.Config{SocketPath: socket, ClientID: req.Context.ClientID}
+		return cloneSessionFn(context.Background(), cfg, target, name)
+	})
+}
+
+func sessionFromSource(req SessionRequest, verb string, create func(socket, target, name string) error) tea.Cmd {
+	return func() tea.Msg {
+		target := strings.TrimSpace(req.Target)
+		if target == "" {
+			return ActionResult{Err: fmt.Errorf("session target required")}
+		}
+		name := strings.TrimSpace(req.Value)
+		if name == "" {
+			return ActionResult{Err: fmt.Errorf("session name required")}
+		}
+		events.Session.CreateFrom(req.Action, target, name)
+		if err := create(req.Context.SocketPath, target, name); err != nil {
+			return ActionResult{Err: err}
+		}
+		if err := groupSwitchFn(req.Context.SocketPath, req.Context.ClientID, name); err != nil {
+			return ActionResult{Err: fmt.Errorf("created session %s but failed to switch: %w", name, err)}
+		}
+		return ActionResult{Info: fmt.Sprintf("Created %s, %s %s", name, verb, target)}
+	}
+}
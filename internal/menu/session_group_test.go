@@ -0,0 +1,88 @@
+package menu
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+)
+
+func TestSessionSourceItemsPutCurrentFirstAndShowGroups(t *testing.T) {
+	items := SessionSourceItems([]SessionEntry{
+		{Name: "one", Windows: 2, Group: "one"},
+		{Name: "two", Windows: 1, Current: true},
+		{Name: "one-2", Windows: 2, Group: "one"},
+	})
+	if len(items) != 3 || items[0].ID != "two" || items[1].ID != "one" || items[2].ID != "one-2" {
+		t.Fatalf("unexpected order %#v", items)
+	}
+	if !strings.Contains(items[1].Label, "group one") || strings.Contains(items[0].Label, "group") {
+		t.Fatalf("unexpected group column: %q / %q", items[0].Label, items[1].Label)
+	}
+	if plain := SessionSourceItems([]SessionEntry{{Name: "solo", Windows: 1}}); strings.Contains(plain[0].Label, "group") {
+		t.Fatalf("ungrouped tables should have no group column, got %q", plain[0].Label)
+	}
+}
+
+func TestSessionNewGroupedActionPromptsForUniqueName(t *testing.T) {
+	ctx := Context{Sessions: []SessionEntry{{Name: "main"}, {Name: "main-2"}}}
+	prompt, ok := SessionNewGroupedAction(ctx, Item{ID: "main"})().(SessionPrompt)
+	if !ok || prompt.Action != "session:new-grouped" || prompt.Target != "main" || prompt.Initial != "main-3" {
+		t.Fatalf("unexpected prompt %#v", prompt)
+	}
+	form := NewSessionForm(prompt)
+	if form.Title() != "new session grouped with main" {
+		t.Fatalf("title = %q", form.Title())
+	}
+}
+
+func TestSessionFormDispatchesGroupedAndClone(t *testing.T) {
+	var grouped, cloned, switched []string
+	t.Cleanup(withPaneStub(&newGroupedSessionFn, func(_, target, name string) error {
+		grouped = append(grouped, target+" "+name)
+		return nil
+	}))
+	t.Cleanup(withPaneStub(&cloneSessionFn, func(_ context.Context, _ resurrect.Config, source, name string) error {
+		cloned = append(cloned, source+" "+name)
+		return nil
+	}))
+	t.Cleanup(withPaneStub(&groupSwitchFn, func(_, _, name string) error {
+		switched = append(switched, name)
+		return nil
+	}))
+
+	for _, action := range []string{"session:new-grouped", "session:clone"} {
+		form := NewSessionForm(SessionPrompt{Action: action, Target: "main", Initial: "copy"})
+		cmd, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+		if !done || cmd == nil {
+			t.Fatalf("%s: expected the form to submit", action)
+		}
+		if res := cmd().(ActionResult); res.Err != nil {
+			t.Fatalf("%s: %v", action, res.Err)
+		}
+	}
+	if len(grouped) != 1 || grouped[0] != "main copy" || len(cloned) != 1 || cloned[0] != "main copy" {
+		t.Fatalf("grouped %q cloned %q", grouped, cloned)
+	}
+	if len(switched) != 2 {
+		t.Fatalf("expected a switch after each create, got %q", switched)
+	}
+}
+
+func TestSessionCloneCommandReportsFailure(t *testing.T) {
+	t.Cleanup(withPaneStub(&cloneSessionFn, func(context.Context, resurrect.Config, string, string) error {
+		return errors.New("session copy already exists")
+	}))
+	t.Cleanup(withPaneStub(&groupSwitchFn, func(_, _, _ string) error {
+		t.Fatal("should not switch after a failed clone")
+		return nil
+	}))
+	res := SessionCloneCommand(SessionRequest{Action: "session:clone", Target: "main", Value: "copy"})().(ActionResult)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "already exists") {
+		t.Fatalf("expected the clone error, got %#v", res)
+	}
+}
@@ -0,0 +1,121 @@
+package menu
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/sessiontemplate"
+)
+
+var importDirsFn = func() map[sessiontemplate.Format][]string {
+	return sessiontemplate.ImportDirs(os.Getenv)
+}
+
+func loadSessionImportMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{"start", "convert"}), nil
+}
+
+// loadSessionImportListMenu lists the tmuxinator and tmuxp project files,
+// keyed by path. A broken file is logged and skipped unless nothing else
+// loaded.
+func loadSessionImportListMenu(Context) ([]Item, error) {
+	imports, err := sessiontemplate.LoadImports(importDirsFn())
+	if err != nil {
+		if len(imports) == 0 {
+			return nil, err
+		}
+		events.Session.TemplateLoadError(err)
+	}
+	if len(imports) == 0 {
+		return nil, nil
+	}
+	cells := make([][]string, 0, len(imports)+1)
+	cells = append(cells, []string{"format", "project", "windows", "warnings"})
+	for _, imp := range imports {
+		warnings := ""
+		if n := len(imp.Warnings); n > 0 {
+			warnings = strconv.Itoa(n)
+		}
+		cells = append(cells, []string{string(imp.Format), imp.Template.ID, strconv.Itoa(len(imp.Template.Windows)), warnings})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignRight, table.AlignRight})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: imports[i].Path, Label: label})
+	}
+	return items, nil
+}
+
+// sessionImportPreview shows the highlighted file's warnings above the tree
+// of the template it maps to.
+func sessionImportPreview(_ Context, item Item) ([]string, error) {
+	imp, err := sessiontemplate.FindImport(importDirsFn(), item.ID)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{imp.Path}
+	for _, w := range imp.Warnings {
+		lines = append(lines, "warning: "+w)
+	}
+	lines = append(lines, "")
+	return append(lines, sessiontemplate.Tree(imp.Template)...), nil
+}
+
+// SessionImportStartAction opens the template form for the picked project
+// file, without converting it.
+func SessionImportStartAction(ctx Context, item Item) tea.Cmd {
+	path := strings.TrimSpace(item.ID)
+	if path == "" {
+		return failCmd("no project file selected")
+	}
+	return func() tea.Msg {
+		imp, err := sessiontemplate.FindImport(importDirsFn(), path)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Session.ImportStart(path)
+		return SessionTemplatePrompt{Context: ctx, Template: imp.Template}
+	}
+}
+
+// SessionImportConvertAction writes each picked project file into the
+// templates directory, listing what was dropped along the way.
+func SessionImportConvertAction(ctx Context, item Item) tea.Cmd {
+	paths := splitSelectionIDs(item.ID)
+	if len(paths) == 0 {
+		return failCmd("no project file selected")
+	}
+	return func() tea.Msg {
+		dir := sessionTemplateDir(ctx.SocketPath)
+		if dir == "" {
+			return ActionResult{Err: fmt.Errorf("no templates directory configured")}
+		}
+		events.Session.ImportConvert(paths)
+		var output []string
+		for _, path := range paths {
+			imp, err := sessiontemplate.FindImport(importDirsFn(), path)
+			if err != nil {
+				return ActionResult{Err: err}
+			}
+			saved, err := sessiontemplate.Save(dir, imp.Template)
+			if err != nil {
+				return ActionResult{Err: err}
+			}
+			output = append(output, fmt.Sprintf("%s → %s", path, saved))
+			for _, w := range imp.Warnings {
+				output = append(output, "  warning: "+w)
+			}
+		}
+		return ActionResult{
+			Info:   fmt.Sprintf("Converted %d project file(s)", len(paths)),
+			Output: strings.Join(output, "\n"),
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/atomicstack/tmux-popup-control/internal/sessiontemplate"
+)
+
+const shopTmuxinator = `
+name: shop
+root: ~/src/shop
+tmux_options: -2
+windows:
+  - editor: vim
+`
+
+// withImportDirs points the tmuxinator lookup at a temp dir holding files.
+func withImportDirs(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	restore := withPaneStub(&importDirsFn, func() map[sessiontemplate.Format][]string {
+		return map[sessiontemplate.Format][]string{sessiontemplate.FormatTmuxinator: {dir}}
+	})
+	t.Cleanup(restore)
+	return dir
+}
+
+func TestLoadSessionImportMenuAndPreview(t *testing.T) {
+	dir := withImportDirs(t, map[string]string{"shop.yml": shopTmuxinator})
+
+	items, err := loadSessionImportListMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "shop.yml")
+	if len(items) != 2 || !items[0].Header || items[1].ID != path {
+		t.Fatalf("expected header + shop.yml, got %#v", items)
+	}
+	lines, err := sessionImportPreview(Context{}, items[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview := strings.Join(lines, "\n")
+	if !strings.Contains(preview, `warning: unsupported key "tmux_options"`) || !strings.Contains(preview, "editor") {
+		t.Fatalf("unexpected preview %q", lines)
+	}
+}
+
+func TestSessionImportStartPrompts(t *testing.T) {
+	dir := withImportDirs(t, map[string]string{"shop.yml": shopTmuxinator})
+	msg := SessionImportStartAction(Context{SocketPath: "sock"}, Item{ID: filepath.Join(dir, "shop.yml")})()
+	prompt, ok := msg.(SessionTemplatePrompt)
+	if !ok || prompt.Template.Project != "shop" || prompt.Context.SocketPath != "sock" {
+		t.Fatalf("unexpected msg %#v", msg)
+	}
+}
+
+func TestSessionImportConvertSavesTemplates(t *testing.T) {
+	dir := withImportDirs(t, map[string]string{"shop.yml": shopTmuxinator, "blog.yml": "windows:\n  - main: hugo server\n"})
+	templates := withTemplateDir(t, nil)
+	ids := filepath.Join(dir, "shop.yml") + "\n" + filepath.Join(dir, "blog.yml")
+
+	res := SessionImportConvertAction(Context{}, Item{ID: ids})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if !strings.Contains(res.Output, filepath.Join(templates, "shop.json")) || !strings.Contains(res.Output, "tmux_options") {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+	if _, err := sessiontemplate.Find(templates, "blog"); err != nil {
+		t.Fatalf("blog should have been converted: %v", err)
+	}
+
+	res = SessionImportConvertAction(Context{}, Item{ID: filepath.Join(dir, "shop.yml")})().(ActionResult)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "already exists") {
+		t.Fatalf("expected converting twice to fail, got %#v", res)
+	}
+}
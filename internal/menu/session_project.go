@@ -0,0 +1,163 @@
+package menu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/project"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	projectOptionFn        = tmux.ShowOption
+	projectCreateSessionFn = tmux.CreateSession
+	projectSwitchFn        = tmux.SwitchClient
+)
+
+// projectReadmeLines caps the README excerpt in the preview.
+const projectReadmeLines = 20
+
+func projectSettings(socketPath string) project.Settings {
+	option := func(opt string) string { return projectOptionFn(socketPath, opt) }
+	return project.ResolveSettings(os.Getenv, option)
+}
+
+// loadSessionProjectMenu lists the project directories, keyed by path, and
+// marks the ones that already have a session. An unreadable root is logged
+// and skipped unless nothing was found at all.
+func loadSessionProjectMenu(ctx Context) ([]Item, error) {
+	projects, err := project.List(projectSettings(ctx.SocketPath))
+	if err != nil {
+		if len(projects) == 0 {
+			return nil, err
+		}
+		events.Session.ProjectLoadError(err)
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+	home, _ := os.UserHomeDir()
+	cells := make([][]string, 0, len(projects)+1)
+	cells = append(cells, []string{"project", "path", "session"})
+	for _, p := range projects {
+		session, _ := projectSession(ctx.Sessions, p.Path)
+		cells = append(cells, []string{p.Name(), abbreviateHome(p.Path, home), session})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: projects[i].Path, Label: label})
+	}
+	return items, nil
+}
+
+// sessionProjectPreview describes the highlighted directory: the session
+// Enter would switch to or create, the git branch and the top of the README.
+func sessionProjectPreview(ctx Context, item Item) ([]string, error) {
+	dir := item.ID
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	lines := []string{dir}
+	if name, ok := projectSession(ctx.Sessions, dir); ok {
+		lines = append(lines, "session: "+name)
+	} else {
+		lines = append(lines, "new session: "+uniqueSessionName(ctx.Sessions, project.SessionName(dir)))
+	}
+	if branch := project.Branch(dir); branch != "" {
+		lines = append(lines, "branch:  "+branch)
+	}
+	if readme := project.ReadmeHead(dir, projectReadmeLines); len(readme) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, readme...)
+	}
+	return lines, nil
+}
+
+// SessionOpenProjectAction switches to the session whose start directory is
+// the picked project, creating one named after the directory when there is
+// none.
+func SessionOpenProjectAction(ctx Context, item Item) tea.Cmd {
+	dir := strings.TrimSpace(item.ID)
+	if dir == "" {
+		return failCmd("no project selected")
+	}
+	return func() tea.Msg {
+		name, created, err := openDirSession(ctx, dir, project.SessionName(dir))
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Session.OpenProject(dir, name, created)
+		if created {
+			return ActionResult{Info: fmt.Sprintf("Created %s in %s", name, dir)}
+		}
+		return ActionResult{Info: fmt.Sprintf("Switched to %s", name)}
+	}
+}
+
+// openDirSession switches the client to the session started in dir, first
+// creating it as name (made unique) when there is none.
+func openDirSession(ctx Context, dir, name string) (string, bool, error) {
+	session, ok := projectSession(ctx.Sessions, dir)
+	if !ok {
+		session = uniqueSessionName(ctx.Sessions, name)
+		spec := tmux.SessionSpec{SocketPath: ctx.SocketPath, Name: session, Dir: dir}
+		if err := projectCreateSessionFn(spec); err != nil {
+			return "", false, err
+		}
+	}
+	if err := projectSwitchFn(ctx.SocketPath, ctx.ClientID, session); err != nil {
+		return "", false, err
+	}
+	return session, !ok, nil
+}
+
+// projectSession returns the session started in dir, if there is one.
+func projectSession(sessions []SessionEntry, dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	for _, s := range sessions {
+		if s.Path != "" && filepath.Clean(s.Path) == dir {
+			return s.Name, true
+		}
+	}
+	return "", false
+}
+
+// uniqueSessionName returns name, or name-2, name-3... when a session from
+// another directory already took it.
+func uniqueSessionName(sessions []SessionEntry, name string) string {
+	taken := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		taken[s.Name] = true
+	}
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = name + "-" + strconv.Itoa(i)
+	}
+	return candidate
+}
+
+func abbreviateHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~/" + rest
+	}
+	return path
+}
@@ -0,0 +1,91 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+// withProjectRoot points the project roots at a fresh temp dir holding dirs.
+func withProjectRoot(t *testing.T, dirs ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("TMUX_POPUP_CONTROL_PROJECT_ROOTS", root)
+	t.Setenv("TMUX_POPUP_CONTROL_PROJECT_DEPTH", "1")
+	t.Setenv("TMUX_POPUP_CONTROL_PROJECT_ZOXIDE", "")
+	t.Cleanup(withPaneStub(&projectOptionFn, func(string, string) string { return "" }))
+	return root
+}
+
+func TestLoadSessionProjectMenuMarksOpenSessions(t *testing.T) {
+	root := withProjectRoot(t, "api/.git", "web")
+	ctx := Context{Sessions: []SessionEntry{{Name: "backend", Path: filepath.Join(root, "api") + "/"}}}
+
+	items, err := loadSessionProjectMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[0].Header || items[1].ID != filepath.Join(root, "api") {
+		t.Fatalf("expected header + api + web, got %#v", items)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(items[1].Label), "backend") {
+		t.Fatalf("api should show its session, got %q", items[1].Label)
+	}
+}
+
+func TestSessionProjectPreview(t *testing.T) {
+	root := withProjectRoot(t, "api/.git")
+	dir := filepath.Join(root, "api")
+	for name, body := range map[string]string{".git/HEAD": "ref: refs/heads/main\n", "README.md": "# api\nserves things\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines, err := sessionProjectPreview(Context{Sessions: []SessionEntry{{Name: "api", Path: "/elsewhere"}}}, Item{ID: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{dir, "new session: api-2", "branch:  main", "", "# api", "serves things"}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("preview = %q, want %q", lines, want)
+	}
+}
+
+func TestSessionOpenProjectAction(t *testing.T) {
+	var (
+		created  []tmux.SessionSpec
+		switched []string
+	)
+	t.Cleanup(withPaneStub(&projectCreateSessionFn, func(spec tmux.SessionSpec) error {
+		created = append(created, spec)
+		return nil
+	}))
+	t.Cleanup(withPaneStub(&projectSwitchFn, func(_, _, target string) error {
+		switched = append(switched, target)
+		return nil
+	}))
+	ctx := Context{SocketPath: "sock", Sessions: []SessionEntry{{Name: "api", Path: "/src/api"}}}
+
+	res := SessionOpenProjectAction(ctx, Item{ID: "/src/api"})().(ActionResult)
+	if res.Err != nil || len(created) != 0 || switched[0] != "api" {
+		t.Fatalf("expected a switch to the existing session, got %#v %#v %q", res, created, switched)
+	}
+
+	res = SessionOpenProjectAction(ctx, Item{ID: "/other/api"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := tmux.SessionSpec{SocketPath: "sock", Name: "api-2", Dir: "/other/api"}
+	if len(created) != 1 || !reflect.DeepEqual(created[0], want) || switched[1] != "api-2" {
+		t.Fatalf("expected api-2 to be created and switched to, got %#v %q", created, switched)
+	}
+}
@@ -0,0 +1,429 @@
+package menu
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/sessiontemplate"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var (
+	templateOptionFn         = tmux.ShowOption
+	templateCreateSessionFn  = tmux.CreateSessionID
+	templateCreateWindowFn   = tmux.CreateWindow
+	templateSplitPaneFn      = tmux.SplitPane
+	templateRespawnPaneFn    = tmux.RespawnPane
+	templateLayoutFn         = tmux.SelectLayoutTarget
+	templateWindowIndicesFn  = tmux.WindowIndices
+	templateDefaultCommandFn = tmux.DefaultCommand
+	templateSwitchFn         = tmux.SwitchClient
+	templateHookFn           = runTemplateHook
+)
+
+// SessionTemplatePrompt requests the form that fills in a template's
+// variables before the session is built.
+type SessionTemplatePrompt struct {
+	Context  Context
+	Template sessiontemplate.Template
+}
+
+func sessionTemplateDir(socketPath string) string {
+	option := func(opt string) string { return templateOptionFn(socketPath, opt) }
+	return sessiontemplate.ResolveDir(os.Getenv, option)
+}
+
+// loadSessionTemplateMenu lists the templates in the templates directory. A
+// broken template is logged and skipped unless nothing else loaded.
+func loadSessionTemplateMenu(ctx Context) ([]Item, error) {
+	templates, err := sessiontemplate.Load(sessionTemplateDir(ctx.SocketPath))
+	if err != nil {
+		if len(templates) == 0 {
+			return nil, err
+		}
+		events.Session.TemplateLoadError(err)
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	cells := make([][]string, 0, len(templates)+1)
+	cells = append(cells, []string{"template", "windows", "description"})
+	for _, t := range templates {
+		cells = append(cells, []string{t.ID, strconv.Itoa(len(t.Windows)), t.Description})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignRight, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: templates[i].ID, Label: label})
+	}
+	return items, nil
+}
+
+// sessionTemplatePreview renders the highlighted template as a tree.
+func sessionTemplatePreview(ctx Context, item Item) ([]string, error) {
+	t, err := sessiontemplate.Find(sessionTemplateDir(ctx.SocketPath), item.ID)
+	if err != nil {
+		return nil, err
+	}
+	return sessiontemplate.Tree(t), nil
+}
+
+// SessionTemplateAction opens the variables form for the picked template.
+func SessionTemplateAction(ctx Context, item Item) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no template selected")
+	}
+	return func() tea.Msg {
+		t, err := sessiontemplate.Find(sessionTemplateDir(ctx.SocketPath), id)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Session.TemplatePrompt(id)
+		return SessionTemplatePrompt{Context: ctx, Template: t}
+	}
+}
+
+// SessionTemplateCommand builds the session described by t, whose
+// placeholders are already expanded, runs its post hooks and switches to it.
+func SessionTemplateCommand(ctx Context, t sessiontemplate.Template) tea.Cmd {
+	return func() tea.Msg {
+		events.Session.TemplateCreate(t.ID, t.Name, t.Root)
+		id, err := buildTemplateSession(ctx.SocketPath, t)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if err := runTemplateHooks(ctx.SocketPath, t, t.Post); err != nil {
+			return ActionResult{Err: fmt.Errorf("created session %s but a post hook failed: %w", t.Name, err)}
+		}
+		if err := templateSwitchFn(ctx.SocketPath, ctx.ClientID, id); err != nil {
+			return ActionResult{Err: fmt.Errorf("created session %s but failed to switch: %w", t.Name, err)}
+		}
+		return ActionResult{Info: fmt.Sprintf("Created %s from template %s", t.Name, t.ID)}
+	}
+}
+
+// buildTemplateSession runs the pre hooks, then creates the session, its
+// windows and panes with the same spec primitives restore uses, and returns
+// the session's ID. everything after new-session targets that ID rather
+// than the name, and a session that fails half-way is killed rather than
+// left behind incomplete.
+func buildTemplateSession(socket string, t sessiontemplate.Template) (string, error) {
+	if err := runTemplateHooks(socket, t, t.Pre); err != nil {
+		return "", fmt.Errorf("pre hook failed: %w", err)
+	}
+	env := t.EnvList()
+	shell := templateDefaultCommandFn(socket)
+	// new-session's directory is also session_path, where new windows open,
+	// so it is the template root. a first pane that wants to be elsewhere
+	// is respawned there, and only then runs its command, so it runs once.
+	first := t.Windows[0].Panes[0]
+	spec := tmux.SessionSpec{SocketPath: socket, Name: t.Name, Dir: t.Root, Env: env}
+	if first.Root == t.Root {
+		spec.Command = templatePaneCommand(first, shell)
+	}
+	id, err := templateCreateSessionFn(spec)
+	if err != nil {
+		return "", fmt.Errorf("creating session %s: %w", t.Name, err)
+	}
+	if err := populateTemplateSession(socket, id, t, env, shell); err != nil {
+		_, _ = tmuxOutput(socket, "kill-session", "-t", id)
+		return "", err
+	}
+	return id, nil
+}
+
+func populateTemplateSession(socket, id string, t sessiontemplate.Template, env []string, shell string) error {
+	// new panes inherit these too, not just the ones the template creates.
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		if _, err := tmuxOutput(socket, "set-environment", "-t", id, key, value); err != nil {
+			return err
+		}
+	}
+	indices, err := templateWindowIndicesFn(socket, id)
+	if err != nil {
+		return fmt.Errorf("listing windows for session %s: %w", t.Name, err)
+	}
+	base := -1
+	for idx := range indices {
+		if base == -1 || idx < base {
+			base = idx
+		}
+	}
+	focus := ""
+	for i, w := range t.Windows {
+		target := fmt.Sprintf("%s:%d", id, base+i)
+		first := w.Panes[0]
+		if i == 0 {
+			if _, err := tmuxOutput(socket, "rename-window", "-t", target, w.Name); err != nil {
+				return err
+			}
+			if first.Root != t.Root {
+				if err := templateRespawnPaneFn(tmux.PaneSpec{
+					SocketPath: socket,
+					Target:     target,
+					Dir:        first.Root,
+					Command:    templatePaneCommand(first, shell),
+					Env:        env,
+				}); err != nil {
+					return fmt.Errorf("respawning pane %s: %w", target, err)
+				}
+			}
+		} else if err := templateCreateWindowFn(tmux.WindowSpec{
+			SocketPath: socket,
+			Session:    id,
+			Index:      base + i,
+			Name:       w.Name,
+			Dir:        first.Root,
+			Command:    templatePaneCommand(first, shell),
+			Env:        env,
+		}); err != nil {
+			return fmt.Errorf("creating window %s: %w", target, err)
+		}
+		if err := splitTemplatePanes(socket, target, w, env, shell); err != nil {
+			return err
+		}
+		if w.Focus || focus == "" {
+			focus = target
+		}
+	}
+	_, err = tmuxOutput(socket, "select-window", "-t", focus)
+	return err
+}
+
+// splitTemplatePanes adds w's remaining panes to target. each split is
+// detached from the first pane, which puts the new pane right after it, so
+// the panes are split in reverse to end up in template order. the layout is
+// applied after every split so the first pane never runs out of room;
+// windows without a layout are tiled.
+func splitTemplatePanes(socket, target string, w sessiontemplate.Window, env []string, shell string) error {
+	layout := cmp.Or(w.Layout, "tiled")
+	for j := len(w.Panes) - 1; j >= 1; j-- {
+		p := w.Panes[j]
+		if err := templateSplitPaneFn(tmux.PaneSpec{
+			SocketPath: socket,
+			Target:     target,
+			Dir:        p.Root,
+			Command:    templatePaneCommand(p, shell),
+			Env:        env,
+		}); err != nil {
+			return fmt.Errorf("splitting %s: %w", target, err)
+		}
+		if err := templateLayoutFn(socket, target, layout); err != nil {
+			return fmt.Errorf("applying layout %s to %s: %w", layout, target, err)
+		}
+	}
+	for j, p := range w.Panes {
+		if !p.Focus {
+			continue
+		}
+		out, err := tmuxOutput(socket, "list-panes", "-t", target, "-F", "#{pane_id}")
+		if err != nil {
+			return err
+		}
+		ids := strings.Fields(out)
+		if j < len(ids) {
+			_, err = tmuxOutput(socket, "select-pane", "-t", ids[j])
+		}
+		return err
+	}
+	return nil
+}
+
+// templatePaneCommand runs p's command, then execs the user's shell so the
+// pane survives the command exiting. a pane without a command is the shell.
+func templatePaneCommand(p sessiontemplate.Pane, shell string) string {
+	if strings.TrimSpace(p.Command) == "" {
+		return ""
+	}
+	return p.Command + "; exec " + shell
+}
+
+// runTemplateHooks runs hooks in order with the template's environment,
+// from the project root when it exists (a pre hook may be what creates it).
+func runTemplateHooks(socket string, t sessiontemplate.Template, hooks []string) error {
+	dir := t.Root
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = ""
+	}
+	env := t.EnvList()
+	for _, hook := range hooks {
+		if err := templateHookFn(socket, dir, env, hook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runTemplateHook runs script with sh. A failure carries its output.
+func runTemplateHook(socket, dir string, env []string, script string) error {
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = dir
+	cmd.Env = append(tmuxCommandEnv(socket), env...)
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if detail := strings.TrimSpace(string(out)); detail != "" {
+			return fmt.Errorf("%s: %s", script, detail)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", script, err)
+	}
+	return nil
+}
+
+const (
+	templateFieldProject = iota
+	templateFieldRoot
+)
+
+// SessionTemplateForm fills in a template's variables: the project name and
+// its root directory, prefilled from the template.
+type SessionTemplateForm struct {
+	inputs   []textinput.Model
+	focus    int
+	tmpl     sessiontemplate.Template
+	home     string
+	existing map[string]struct{}
+	ctx      Context
+	err      string
+}
+
+// NewSessionTemplateForm creates the form for prompt's template.
+func NewSessionTemplateForm(prompt SessionTemplatePrompt) *SessionTemplateForm {
+	project := textinput.New()
+	styleFormInput(&project)
+	project.Prompt = "project » "
+	project.Placeholder = "name"
+	project.CharLimit = 64
+	project.SetWidth(40)
+	project.SetValue(prompt.Template.Project)
+	project.Focus()
+
+	root := textinput.New()
+	styleFormInput(&root)
+	root.Prompt = "root    » "
+	root.Placeholder = "~"
+	root.SetWidth(40)
+	root.SetValue(prompt.Template.Root)
+
+	home, _ := os.UserHomeDir()
+	existing := make(map[string]struct{}, len(prompt.Context.Sessions))
+	for _, entry := range prompt.Context.Sessions {
+		if name := strings.ToLower(strings.TrimSpace(entry.Name)); name != "" {
+			existing[name] = struct{}{}
+		}
+	}
+	f := &SessionTemplateForm{
+		inputs:   []textinput.Model{project, root},
+		tmpl:     prompt.Template,
+		home:     home,
+		existing: existing,
+		ctx:      prompt.Context,
+	}
+	f.err = f.validate()
+	return f
+}
+
+func (f *SessionTemplateForm) Context() Context    { return f.ctx }
+func (f *SessionTemplateForm) TemplateID() string  { return f.tmpl.ID }
+func (f *SessionTemplateForm) Cursor() *tea.Cursor { return f.inputs[f.focus].Cursor() }
+func (f *SessionTemplateForm) Focus() int          { return f.focus }
+func (f *SessionTemplateForm) Error() string       { return f.err }
+func (f *SessionTemplateForm) ActionID() string    { return "session:new-from-template" }
+func (f *SessionTemplateForm) Title() string       { return "new from " + f.tmpl.ID }
+func (f *SessionTemplateForm) FocusCmd() tea.Cmd   { return f.inputs[f.focus].Focus() }
+
+func (f *SessionTemplateForm) Help() string {
+	return "Tab to switch field. Enter to create. Esc to cancel."
+}
+
+// Project is the entered project name.
+func (f *SessionTemplateForm) Project() string {
+	return strings.TrimSpace(f.inputs[templateFieldProject].Value())
+}
+
+// InputViews renders the project and root inputs, one per line.
+func (f *SessionTemplateForm) InputViews() []string {
+	views := make([]string, len(f.inputs))
+	for i := range f.inputs {
+		views[i] = f.inputs[i].View()
+	}
+	return views
+}
+
+// Expanded returns the template with the form's current values filled in.
+func (f *SessionTemplateForm) Expanded() sessiontemplate.Template {
+	return f.tmpl.Expand(sessiontemplate.Vars{
+		Project: f.Project(),
+		Root:    f.inputs[templateFieldRoot].Value(),
+	}, f.home)
+}
+
+// Summary says what enter would create.
+func (f *SessionTemplateForm) Summary() string {
+	t := f.Expanded()
+	return fmt.Sprintf("session %s in %s", t.Name, t.Root)
+}
+
+func (f *SessionTemplateForm) PendingLabel() string {
+	return f.Expanded().Name
+}
+
+// Update processes a message and returns (cmd, done, cancel).
+func (f *SessionTemplateForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if kp, ok := msg.(tea.KeyPressMsg); ok {
+		switch kp.String() {
+		case "esc":
+			return nil, false, true
+		case "tab", "shift+tab", "up", "down":
+			f.inputs[f.focus].Blur()
+			f.focus = (f.focus + 1) % len(f.inputs)
+			return f.inputs[f.focus].Focus(), false, false
+		case "ctrl+u":
+			f.inputs[f.focus].SetValue("")
+			f.inputs[f.focus].CursorStart()
+			f.err = f.validate()
+			return nil, false, false
+		case "enter":
+			if f.err = f.validate(); f.err != "" {
+				return nil, false, false
+			}
+			return SessionTemplateCommand(f.ctx, f.Expanded()), true, false
+		}
+	}
+	updated, cmd := f.inputs[f.focus].Update(msg)
+	f.inputs[f.focus] = updated
+	f.err = f.validate()
+	return cmd, false, false
+}
+
+func (f *SessionTemplateForm) validate() string {
+	if f.Project() == "" {
+		return "Project name required"
+	}
+	name := strings.TrimSpace(f.Expanded().Name)
+	if name == "" {
+		return "Session name required"
+	}
+	if strings.ContainsAny(name, "\n\r\t") {
+		return "name must not contain control characters"
+	}
+	if _, exists := f.existing[strings.ToLower(name)]; exists {
+		return fmt.Sprintf("Session %s already exists", name)
+	}
+	return ""
+}
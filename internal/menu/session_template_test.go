@@ -0,0 +1,285 @@
+package menu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/sessiontemplate"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+const webappTemplate = `{
+	"description": "web app",
+	"root": "/src/{project}",
+	"env": {"APP_ENV": "dev"},
+	"pre": ["echo pre {project}"],
+	"post": ["echo post"],
+	"windows": [
+		{"name": "editor", "layout": "main-vertical", "panes": [
+			{"command": "nvim"}, {"root": "cmd"}, {"command": "make watch", "focus": true}
+		]},
+		{"name": "logs", "root": "/var/log", "focus": true}
+	]
+}`
+
+// withTemplateDir points the templates directory at a fresh temp dir holding
+// files.
+func withTemplateDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("TMUX_POPUP_CONTROL_TEMPLATES_DIR", dir)
+	restore := withPaneStub(&templateOptionFn, func(string, string) string { return "" })
+	t.Cleanup(restore)
+	return dir
+}
+
+// templateCalls records every tmux call a template build makes.
+type templateCalls struct {
+	sessions []tmux.SessionSpec
+	windows  []tmux.WindowSpec
+	splits   []tmux.PaneSpec
+	respawns []tmux.PaneSpec
+	layouts  []string
+	hooks    []string
+	tmux     []string
+	switched string
+}
+
+func stubTemplateBuild(t *testing.T, failOn string) *templateCalls {
+	t.Helper()
+	calls := &templateCalls{}
+	restores := []func(){
+		withPaneStub(&templateCreateSessionFn, func(spec tmux.SessionSpec) (string, error) {
+			calls.sessions = append(calls.sessions, spec)
+			return "$9", nil
+		}),
+		withPaneStub(&templateRespawnPaneFn, func(spec tmux.PaneSpec) error {
+			calls.respawns = append(calls.respawns, spec)
+			return nil
+		}),
+		withPaneStub(&templateCreateWindowFn, func(spec tmux.WindowSpec) error {
+			calls.windows = append(calls.windows, spec)
+			return nil
+		}),
+		withPaneStub(&templateSplitPaneFn, func(spec tmux.PaneSpec) error {
+			calls.splits = append(calls.splits, spec)
+			if failOn == "split" {
+				return errors.New("no space for new pane")
+			}
+			return nil
+		}),
+		withPaneStub(&templateLayoutFn, func(_, target, layout string) error {
+			calls.layouts = append(calls.layouts, target+" "+layout)
+			return nil
+		}),
+		withPaneStub(&templateWindowIndicesFn, func(string, string) (map[int]bool, error) {
+			return map[int]bool{1: true}, nil
+		}),
+		withPaneStub(&templateDefaultCommandFn, func(string) string { return "zsh" }),
+		withPaneStub(&templateSwitchFn, func(_, _, target string) error {
+			calls.switched = target
+			return nil
+		}),
+		withPaneStub(&templateHookFn, func(_, _ string, _ []string, script string) error {
+			calls.hooks = append(calls.hooks, script)
+			return nil
+		}),
+		withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+			calls.tmux = append(calls.tmux, strings.Join(args, " "))
+			if args[0] == "list-panes" {
+				return []byte("%1\n%2\n%3\n"), nil
+			}
+			return nil, nil
+		}),
+	}
+	t.Cleanup(func() {
+		for _, restore := range restores {
+			restore()
+		}
+	})
+	return calls
+}
+
+func TestLoadSessionTemplateMenuAndPreview(t *testing.T) {
+	withTemplateDir(t, map[string]string{"webapp.json": webappTemplate, "broken.json": "{"})
+
+	items, err := loadSessionTemplateMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || !items[0].Header || items[1].ID != "webapp" {
+		t.Fatalf("expected header + webapp, got %#v", items)
+	}
+	if !strings.Contains(items[1].Label, "web app") {
+		t.Fatalf("expected description in label, got %q", items[1].Label)
+	}
+	lines, err := sessionTemplatePreview(Context{}, items[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "├─ editor [main-vertical]") {
+		t.Fatalf("unexpected preview %q", lines)
+	}
+}
+
+func TestSessionTemplateActionPrompts(t *testing.T) {
+	withTemplateDir(t, map[string]string{"webapp.json": webappTemplate})
+	msg := SessionTemplateAction(Context{SocketPath: "sock"}, Item{ID: "webapp"})()
+	prompt, ok := msg.(SessionTemplatePrompt)
+	if !ok || prompt.Template.ID != "webapp" || prompt.Context.SocketPath != "sock" {
+		t.Fatalf("unexpected msg %#v", msg)
+	}
+}
+
+func TestSessionTemplateCommandBuildsSession(t *testing.T) {
+	calls := stubTemplateBuild(t, "")
+	tmpl, err := sessiontemplate.Parse("webapp", []byte(webappTemplate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expanded := tmpl.Expand(sessiontemplate.Vars{Project: "shop", Root: tmpl.Root}, "/home/me")
+
+	res := SessionTemplateCommand(Context{SocketPath: "sock", ClientID: "c1"}, expanded)().(ActionResult)
+	if res.Err != nil {
+		t.Fatalf("unexpected error: %v", res.Err)
+	}
+
+	want := tmux.SessionSpec{SocketPath: "sock", Name: "shop", Dir: "/src/shop", Command: "nvim; exec zsh", Env: []string{"APP_ENV=dev"}}
+	if len(calls.sessions) != 1 || !reflect.DeepEqual(calls.sessions[0], want) {
+		t.Fatalf("sessions = %#v", calls.sessions)
+	}
+	if len(calls.splits) != 2 || calls.splits[0].Command != "make watch; exec zsh" || calls.splits[1].Dir != "/src/shop/cmd" {
+		t.Fatalf("panes should be split in reverse, got %#v", calls.splits)
+	}
+	if len(calls.windows) != 1 || calls.windows[0].Index != 2 || calls.windows[0].Dir != "/var/log" {
+		t.Fatalf("windows = %#v", calls.windows)
+	}
+	if !reflect.DeepEqual(calls.layouts, []string{"$9:1 main-vertical", "$9:1 main-vertical"}) {
+		t.Fatalf("layouts = %q", calls.layouts)
+	}
+	if !reflect.DeepEqual(calls.hooks, []string{"echo pre 'shop'", "echo post"}) {
+		t.Fatalf("hooks = %q", calls.hooks)
+	}
+	for _, want := range []string{
+		"set-environment -t $9 APP_ENV dev",
+		"rename-window -t $9:1 editor",
+		"select-pane -t %3",
+		"select-window -t $9:2",
+	} {
+		if !slices.Contains(calls.tmux, want) {
+			t.Errorf("missing tmux call %q in %q", want, calls.tmux)
+		}
+	}
+	if len(calls.respawns) != 0 || calls.switched != "$9" {
+		t.Fatalf("respawned %#v, switched to %q", calls.respawns, calls.switched)
+	}
+}
+
+func TestSessionTemplateFirstPaneStartsInItsOwnRoot(t *testing.T) {
+	calls := stubTemplateBuild(t, "")
+	tmpl, err := sessiontemplate.Parse("api", []byte(`{
+		"root": "/src/api",
+		"windows": [{"name": "db", "root": "/srv/db", "panes": [{"command": "psql"}]}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expanded := tmpl.Expand(sessiontemplate.Vars{Project: "api", Root: tmpl.Root}, "/home/me")
+
+	if res := SessionTemplateCommand(Context{}, expanded)().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if len(calls.sessions) != 1 || calls.sessions[0].Dir != "/src/api" || calls.sessions[0].Command != "" {
+		t.Fatalf("the session should start idle in the template root, got %#v", calls.sessions)
+	}
+	want := []tmux.PaneSpec{{Target: "$9:1", Dir: "/srv/db", Command: "psql; exec zsh", Env: []string{}}}
+	if !reflect.DeepEqual(calls.respawns, want) {
+		t.Fatalf("respawns = %#v, want %#v", calls.respawns, want)
+	}
+}
+
+func TestSessionTemplateSanitisesDottedName(t *testing.T) {
+	calls := stubTemplateBuild(t, "")
+	tmpl, _ := sessiontemplate.Parse("webapp", []byte(webappTemplate))
+	expanded := tmpl.Expand(sessiontemplate.Vars{Project: "example.com", Root: tmpl.Root}, "/home/me")
+	if expanded.Name != "example_com" {
+		t.Fatalf("expected the name tmux will use, got %q", expanded.Name)
+	}
+	if res := SessionTemplateCommand(Context{}, expanded)().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if calls.sessions[0].Name != "example_com" || calls.switched != "$9" {
+		t.Fatalf("sessions = %#v, switched to %q", calls.sessions, calls.switched)
+	}
+
+	form := NewSessionTemplateForm(SessionTemplatePrompt{
+		Context:  Context{Sessions: []SessionEntry{{Name: "example_com"}}},
+		Template: tmpl,
+	})
+	form.Update(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	for _, r := range "example.com" {
+		form.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if !strings.Contains(form.Error(), "example_com already exists") {
+		t.Fatalf("expected the sanitised name to clash, got %q", form.Error())
+	}
+}
+
+func TestSessionTemplateCommandKillsHalfBuiltSession(t *testing.T) {
+	calls := stubTemplateBuild(t, "split")
+	tmpl, _ := sessiontemplate.Parse("webapp", []byte(webappTemplate))
+	expanded := tmpl.Expand(sessiontemplate.Vars{Project: "shop", Root: tmpl.Root}, "/home/me")
+
+	res := SessionTemplateCommand(Context{}, expanded)().(ActionResult)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "no space") {
+		t.Fatalf("expected split error, got %#v", res)
+	}
+	if !slices.Contains(calls.tmux, "kill-session -t $9") {
+		t.Fatalf("expected the session to be killed, got %q", calls.tmux)
+	}
+	if len(calls.hooks) != 1 || calls.switched != "" {
+		t.Fatalf("post hook and switch must not run: %q %q", calls.hooks, calls.switched)
+	}
+}
+
+func TestSessionTemplateFormValidatesAndSubmits(t *testing.T) {
+	tmpl, _ := sessiontemplate.Parse("webapp", []byte(webappTemplate))
+	form := NewSessionTemplateForm(SessionTemplatePrompt{
+		Context:  Context{Sessions: []SessionEntry{{Name: "webapp"}}},
+		Template: tmpl,
+	})
+	if !strings.Contains(form.Error(), "already exists") {
+		t.Fatalf("expected clash with the existing session, got %q", form.Error())
+	}
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done {
+		t.Fatal("enter must not submit an invalid form")
+	}
+
+	form.Update(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	for _, r := range "shop" {
+		form.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if form.Error() != "" || form.Summary() != "session shop in /src/shop" {
+		t.Fatalf("unexpected state %q %q", form.Error(), form.Summary())
+	}
+	form.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if form.Focus() != 1 {
+		t.Fatalf("tab should move to the root field, focus = %d", form.Focus())
+	}
+	cmd, done, cancel := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !done || cancel || cmd == nil {
+		t.Fatalf("expected submit, got done=%v cancel=%v cmd=%v", done, cancel, cmd != nil)
+	}
+}
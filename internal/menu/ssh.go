@@ -0,0 +1,238 @@
+package menu
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/sshconfig"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+var sshOptionFn = tmux.ShowOption
+
+func sshSettings(socketPath string) sshconfig.Settings {
+	option := func(opt string) string { return sshOptionFn(socketPath, opt) }
+	return sshconfig.ResolveSettings(os.Getenv, option)
+}
+
+// loadSSHHosts reads the configured hosts. An unreadable file is logged and
+// skipped unless nothing was found at all.
+func loadSSHHosts(ctx Context) ([]sshconfig.Host, error) {
+	home, _ := os.UserHomeDir()
+	hosts, err := sshconfig.Load(sshSettings(ctx.SocketPath), home)
+	if err != nil {
+		if len(hosts) == 0 {
+			return nil, err
+		}
+		events.SSH.LoadError(err)
+	}
+	return hosts, nil
+}
+
+func findSSHHosts(ctx Context, ids []string) ([]sshconfig.Host, error) {
+	hosts, err := loadSSHHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]sshconfig.Host, len(hosts))
+	for _, h := range hosts {
+		byID[h.ID()] = h
+	}
+	out := make([]sshconfig.Host, 0, len(ids))
+	for _, id := range ids {
+		h, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown ssh host %s", id)
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+func loadSSHMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{"window", "split", "tiled", "tiled-sync"}), nil
+}
+
+// loadSSHHostMenu lists the hosts as a table keyed by Host.ID.
+func loadSSHHostMenu(ctx Context) ([]Item, error) {
+	hosts, err := loadSSHHosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+	cells := make([][]string, 0, len(hosts)+1)
+	cells = append(cells, []string{"host", "hostname", "user", "source"})
+	for _, h := range hosts {
+		hostname := h.HostName
+		if h.Port != "" {
+			hostname += ":" + h.Port
+		}
+		cells = append(cells, []string{h.Alias, hostname, h.User, string(h.Source)})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignLeft, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: hosts[i].ID(), Label: label})
+	}
+	return items, nil
+}
+
+// sshHostPreview shows where the highlighted host resolves to.
+func sshHostPreview(ctx Context, item Item) ([]string, error) {
+	hosts, err := findSSHHosts(ctx, []string{item.ID})
+	if err != nil {
+		return nil, err
+	}
+	h := hosts[0]
+	home, _ := os.UserHomeDir()
+	lines := []string{
+		"host:      " + h.Alias,
+		"hostname:  " + h.HostName,
+		"user:      " + cmp.Or(h.User, "(default)"),
+		"port:      " + cmp.Or(h.Port, "22"),
+	}
+	if h.ProxyJump != "" {
+		lines = append(lines, "proxyjump: "+h.ProxyJump)
+	}
+	if h.IdentityFile != "" {
+		lines = append(lines, "identity:  "+h.IdentityFile)
+	}
+	lines = append(lines, "")
+	if h.Source == sshconfig.SourceKnownHosts {
+		lines = append(lines, "from known_hosts")
+	} else if h.File != "" {
+		lines = append(lines, "from "+abbreviateHome(h.File, home))
+	}
+	lines = append(lines, "$ "+h.Command())
+	return lines, nil
+}
+
+// SSHWindowAction opens each selected host in its own window of the current
+// session, named after the host.
+func SSHWindowAction(ctx Context, item Item) tea.Cmd {
+	ids := splitSelectionIDs(item.ID)
+	if len(ids) == 0 {
+		return failCmd("no host selected")
+	}
+	return func() tea.Msg {
+		hosts, err := findSSHHosts(ctx, ids)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.SSH.Open(ids, "window")
+		session := strings.TrimSpace(ctx.Current)
+		for _, h := range hosts {
+			if _, err := tmuxOutput(ctx.SocketPath, "new-window", "-t", session+":", "-n", h.Alias, h.Command()); err != nil {
+				return ActionResult{Err: err}
+			}
+		}
+		if len(hosts) == 1 {
+			return ActionResult{Info: fmt.Sprintf("Opened %s in a new window", hosts[0].Alias)}
+		}
+		return ActionResult{Info: fmt.Sprintf("Opened %d hosts in new windows", len(hosts))}
+	}
+}
+
+// SSHSplitAction opens the host in a split of the pane that opened the
+// popup, titled after the host.
+func SSHSplitAction(ctx Context, item Item) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no host selected")
+	}
+	return func() tea.Msg {
+		hosts, err := findSSHHosts(ctx, []string{id})
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		h := hosts[0]
+		events.SSH.Open([]string{id}, "split")
+		pane, err := tmuxOutput(ctx.SocketPath, "split-window", "-t", bufferPasteTarget(ctx), "-P", "-F", "#{pane_id}", h.Command())
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if _, err := tmuxOutput(ctx.SocketPath, "select-pane", "-t", strings.TrimSpace(pane), "-T", h.Alias); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Opened %s in a split", h.Alias)}
+	}
+}
+
+// SSHTiledAction opens the selected hosts as panes of one new tiled window.
+func SSHTiledAction(ctx Context, item Item) tea.Cmd {
+	return sshTiled(ctx, item, false)
+}
+
+// SSHTiledSyncAction is SSHTiledAction with synchronize-panes turned on, so
+// input goes to every host at once.
+func SSHTiledSyncAction(ctx Context, item Item) tea.Cmd {
+	return sshTiled(ctx, item, true)
+}
+
+func sshTiled(ctx Context, item Item, sync bool) tea.Cmd {
+	ids := splitSelectionIDs(item.ID)
+	if len(ids) == 0 {
+		return failCmd("no host selected")
+	}
+	return func() tea.Msg {
+		hosts, err := findSSHHosts(ctx, ids)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		mode := "tiled"
+		if sync {
+			mode = "tiled-sync"
+		}
+		events.SSH.Open(ids, mode)
+		if err := openSSHTiled(ctx, hosts, sync); err != nil {
+			return ActionResult{Err: err}
+		}
+		info := fmt.Sprintf("Opened %d host(s) in a tiled window", len(hosts))
+		if sync {
+			info += " with synchronized panes"
+		}
+		return ActionResult{Info: info}
+	}
+}
+
+// openSSHTiled creates a window for the first host and splits it for the
+// rest, re-tiling after each split so later ones always have room.
+func openSSHTiled(ctx Context, hosts []sshconfig.Host, sync bool) error {
+	if len(hosts) == 0 {
+		return errors.New("no host selected")
+	}
+	name := hosts[0].Alias
+	if len(hosts) > 1 {
+		name = fmt.Sprintf("%s+%d", name, len(hosts)-1)
+	}
+	session := strings.TrimSpace(ctx.Current)
+	out, err := tmuxOutput(ctx.SocketPath, "new-window", "-t", session+":", "-n", name, "-P", "-F", "#{window_id}", hosts[0].Command())
+	if err != nil {
+		return err
+	}
+	window := strings.TrimSpace(out)
+	for _, h := range hosts[1:] {
+		if _, err := tmuxOutput(ctx.SocketPath, "split-window", "-t", window, h.Command()); err != nil {
+			return err
+		}
+		if _, err := tmuxOutput(ctx.SocketPath, "select-layout", "-t", window, "tiled"); err != nil {
+			return err
+		}
+	}
+	if sync {
+		if _, err := tmuxOutput(ctx.SocketPath, "set-window-option", "-t", window, "synchronize-panes", "on"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubSSH points HOME at a temp dir holding config and records tmux calls.
+func stubSSH(t *testing.T, config string) *[]string {
+	t.Helper()
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX_POPUP_CONTROL_SSH_CONFIG", "")
+	t.Setenv("TMUX_POPUP_CONTROL_SSH_KNOWN_HOSTS", "")
+	t.Cleanup(withPaneStub(&sshOptionFn, func(string, string) string { return "" }))
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if slices.Contains(args, "-P") {
+			return []byte("@9\n"), nil
+		}
+		return nil, nil
+	}))
+	return &calls
+}
+
+const sshTestConfig = `Host web
+  HostName web.example.com
+  User deploy
+Host db
+  HostName 10.0.0.5
+  ProxyJump web
+Host *
+  Port 2200
+`
+
+func TestLoadSSHHostMenu(t *testing.T) {
+	stubSSH(t, sshTestConfig)
+	items, err := loadSSHHostMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[0].Header || items[1].ID != "web" || items[2].ID != "db" {
+		t.Fatalf("unexpected items %#v", items)
+	}
+	if !strings.Contains(items[1].Label, "web.example.com:2200") {
+		t.Fatalf("label should show the resolved address, got %q", items[1].Label)
+	}
+}
+
+func TestSSHHostPreview(t *testing.T) {
+	stubSSH(t, sshTestConfig)
+	lines, err := sshHostPreview(Context{}, Item{ID: "db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(lines, "\n")
+	for _, want := range []string{"hostname:  10.0.0.5", "user:      (default)", "port:      2200", "proxyjump: web", "from ~/.ssh/config", "$ ssh 'db'"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("preview missing %q:\n%s", want, joined)
+		}
+	}
+}
+
+func TestSSHWindowActionOpensEachHost(t *testing.T) {
+	calls := stubSSH(t, sshTestConfig)
+	res := SSHWindowAction(Context{Current: "work"}, Item{ID: "web\ndb"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []string{"new-window -t work: -n web ssh 'web'", "new-window -t work: -n db ssh 'db'"}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestSSHTiledSyncAction(t *testing.T) {
+	calls := stubSSH(t, sshTestConfig)
+	res := SSHTiledSyncAction(Context{Current: "work"}, Item{ID: "web\ndb"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []string{
+		"new-window -t work: -n web+1 -P -F #{window_id} ssh 'web'",
+		"split-window -t @9 ssh 'db'",
+		"select-layout -t @9 tiled",
+		"set-window-option -t @9 synchronize-panes on",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestSSHActionRejectsUnknownHost(t *testing.T) {
+	stubSSH(t, sshTestConfig)
+	res := SSHSplitAction(Context{}, Item{ID: "nope"})().(ActionResult)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "unknown ssh host nope") {
+		t.Fatalf("expected an unknown host error, got %#v", res)
+	}
+}
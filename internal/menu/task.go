@@ -0,0 +1,201 @@
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/taskrunner"
+)
+
+var (
+	taskPaneDirFn  = currentPaneDir
+	taskStateDirFn = resurrect.ResolveDir
+)
+
+// discoverTasks returns the tasks of the current pane's directory. A file
+// that fails to parse is logged and skipped unless nothing was found at all.
+func discoverTasks(ctx Context) (string, []taskrunner.Task, error) {
+	dir, err := taskPaneDirFn(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	tasks, err := taskrunner.Discover(dir)
+	if err != nil {
+		if len(tasks) == 0 {
+			return dir, nil, err
+		}
+		events.Task.Error(dir, err)
+	}
+	return dir, tasks, nil
+}
+
+// loadTaskMenu offers the places a task can run, plus rerunning the
+// directory's last task when there is one.
+func loadTaskMenu(ctx Context) ([]Item, error) {
+	items := menuItemsFromIDs([]string{"split", "window", "pane"})
+	if last, ok := lastTask(ctx); ok {
+		items = append(items, Item{ID: "rerun", Label: fmt.Sprintf("rerun (%s in %s)", last.ID, last.Place)})
+	}
+	return items, nil
+}
+
+func lastTask(ctx Context) (taskrunner.Last, bool) {
+	dir, err := taskPaneDirFn(ctx)
+	if err != nil {
+		return taskrunner.Last{}, false
+	}
+	stateDir, err := taskStateDirFn(ctx.SocketPath)
+	if err != nil {
+		return taskrunner.Last{}, false
+	}
+	last, ok, err := taskrunner.LoadLast(stateDir, dir)
+	if err != nil {
+		return taskrunner.Last{}, false
+	}
+	return last, ok
+}
+
+// loadTaskListMenu lists the tasks as a table keyed by Task.ID.
+func loadTaskListMenu(ctx Context) ([]Item, error) {
+	_, tasks, err := discoverTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	cells := make([][]string, 0, len(tasks)+1)
+	cells = append(cells, []string{"task", "runner", "command"})
+	for _, t := range tasks {
+		cells = append(cells, []string{t.Name, t.Runner, t.Command})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: tasks[i].ID(), Label: label})
+	}
+	return items, nil
+}
+
+// taskPreview shows the recipe behind the highlighted task.
+func taskPreview(ctx Context, item Item) ([]string, error) {
+	_, tasks, err := discoverTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := taskrunner.Find(tasks, item.ID)
+	if !ok {
+		return nil, fmt.Errorf("unknown task %s", item.ID)
+	}
+	lines := []string{"$ " + t.Command, t.File, ""}
+	return append(lines, t.Body...), nil
+}
+
+// TaskSplitAction runs the task in a new split of the current pane.
+func TaskSplitAction(ctx Context, item Item) tea.Cmd {
+	return runTaskAction(ctx, item, "split")
+}
+
+// TaskWindowAction runs the task in a new window named after it.
+func TaskWindowAction(ctx Context, item Item) tea.Cmd {
+	return runTaskAction(ctx, item, "window")
+}
+
+// TaskPaneAction types the task's command into the current pane.
+func TaskPaneAction(ctx Context, item Item) tea.Cmd {
+	return runTaskAction(ctx, item, "pane")
+}
+
+func runTaskAction(ctx Context, item Item, place string) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no task selected")
+	}
+	return func() tea.Msg {
+		dir, tasks, err := discoverTasks(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		t, ok := taskrunner.Find(tasks, id)
+		if !ok {
+			return ActionResult{Err: fmt.Errorf("unknown task %s", id)}
+		}
+		last := taskrunner.Last{ID: t.ID(), Command: t.Command, Place: place, At: time.Now()}
+		return runTask(ctx, dir, last)
+	}
+}
+
+// TaskRerunAction runs the directory's last task again, in the same place.
+func TaskRerunAction(ctx Context, _ Item) tea.Cmd {
+	return func() tea.Msg {
+		dir, err := taskPaneDirFn(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		stateDir, err := taskStateDirFn(ctx.SocketPath)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		last, ok, err := taskrunner.LoadLast(stateDir, dir)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if !ok {
+			return ActionResult{Err: fmt.Errorf("no task has run in %s yet", dir)}
+		}
+		last.At = time.Now()
+		return runTask(ctx, dir, last)
+	}
+}
+
+// runTask starts a shell in the chosen place and types the command into
+// it, so the pane stays open, with the command in its history, once the
+// task ends. The run is then remembered as dir's last task.
+func runTask(ctx Context, dir string, last taskrunner.Last) tea.Msg {
+	var (
+		pane string
+		err  error
+	)
+	origin := bufferPasteTarget(ctx)
+	switch last.Place {
+	case "split":
+		pane, err = tmuxOutput(ctx.SocketPath, "split-window", "-t", origin, "-c", dir, "-P", "-F", "#{pane_id}")
+	case "window":
+		session := strings.TrimSpace(ctx.Current)
+		pane, err = tmuxOutput(ctx.SocketPath, "new-window", "-t", session+":", "-c", dir, "-n", last.ID, "-P", "-F", "#{pane_id}")
+	case "pane":
+		pane = origin
+	default:
+		err = fmt.Errorf("unknown place %q", last.Place)
+	}
+	if err != nil {
+		return ActionResult{Err: err}
+	}
+	pane = strings.TrimSpace(pane)
+	if pane == "" {
+		return ActionResult{Err: errors.New("no pane to run the task in")}
+	}
+	events.Task.Run(dir, last.ID, last.Place)
+	if _, err := tmuxOutput(ctx.SocketPath, "send-keys", "-t", pane, "-l", last.Command); err != nil {
+		return ActionResult{Err: err}
+	}
+	if _, err := tmuxOutput(ctx.SocketPath, "send-keys", "-t", pane, "Enter"); err != nil {
+		return ActionResult{Err: err}
+	}
+	stateDir, err := taskStateDirFn(ctx.SocketPath)
+	if err == nil {
+		err = taskrunner.SaveLast(stateDir, dir, last)
+	}
+	if err != nil {
+		events.Task.Error(dir, err)
+	}
+	return ActionResult{Info: fmt.Sprintf("Running %s in %s", last.ID, last.Place)}
+}
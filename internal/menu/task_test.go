@@ -0,0 +1,96 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubTasks puts the current pane in a directory holding a Makefile, keeps
+// task state in a temp dir, and records tmux calls.
+func stubTasks(t *testing.T) (string, *[]string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Makefile"), []byte("build:\n\tgo build\ntest:\n\tgo test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	state := t.TempDir()
+	t.Cleanup(withPaneStub(&taskPaneDirFn, func(Context) (string, error) { return dir, nil }))
+	t.Cleanup(withPaneStub(&taskStateDirFn, func(string) (string, error) { return state, nil }))
+	t.Setenv("TMUX_POPUP_CONTROL_PANE_ID", "")
+	var calls []string
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if slices.Contains(args, "-P") {
+			return []byte("%7\n"), nil
+		}
+		return nil, nil
+	}))
+	return dir, &calls
+}
+
+func TestLoadTaskListMenuAndPreview(t *testing.T) {
+	stubTasks(t)
+	items, err := loadTaskListMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[0].Header || items[1].ID != "make build" || items[2].ID != "make test" {
+		t.Fatalf("unexpected items %#v", items)
+	}
+	lines, err := taskPreview(Context{}, items[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines[0] != "$ make 'test'" || lines[len(lines)-1] != "go test" {
+		t.Fatalf("preview = %q", lines)
+	}
+}
+
+func TestTaskWindowActionThenRerun(t *testing.T) {
+	dir, calls := stubTasks(t)
+	ctx := Context{Current: "work", CurrentPaneID: "%1"}
+
+	items, _ := loadTaskMenu(ctx)
+	if slices.ContainsFunc(items, func(it Item) bool { return it.ID == "rerun" }) {
+		t.Fatalf("rerun should be hidden before any task ran, got %#v", items)
+	}
+
+	res := TaskWindowAction(ctx, Item{ID: "make test"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []string{
+		"new-window -t work: -c " + dir + " -n make test -P -F #{pane_id}",
+		"send-keys -t %7 -l make 'test'",
+		"send-keys -t %7 Enter",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+
+	items, _ = loadTaskMenu(ctx)
+	if last := items[len(items)-1]; last.ID != "rerun" || last.Label != "rerun (make test in window)" {
+		t.Fatalf("unexpected rerun item %#v", last)
+	}
+	*calls = nil
+	if res := TaskRerunAction(ctx, Item{ID: "rerun"})().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if !slices.Equal(*calls, want) {
+		t.Fatalf("rerun calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestTaskPaneActionTypesIntoCurrentPane(t *testing.T) {
+	_, calls := stubTasks(t)
+	res := TaskPaneAction(Context{CurrentPaneID: "%1"}, Item{ID: "make build"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := []string{"send-keys -t %1 -l make 'build'", "send-keys -t %1 Enter"}; !slices.Equal(*calls, want) {
+		t.Fatalf("calls = %q, want %q", *calls, want)
+	}
+}
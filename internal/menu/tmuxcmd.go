@@ -1,6 +1,7 @@
 package menu
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -62,3 +63,16 @@ func tmuxCommandEnv(socket string) []string {
 	}
 	return env
 }
+
+// tmuxOutput runs a tmux command, returning its output and, on failure, an
+// error carrying tmux's own message.
+func tmuxOutput(socket string, args ...string) (string, error) {
+	out, err := runCommandOutputFn(socket, args...)
+	if err != nil {
+		if detail := strings.TrimSpace(string(out)); detail != "" {
+			return "", fmt.Errorf("tmux %s: %s", strings.Join(args, " "), detail)
+		}
+		return "", fmt.Errorf("tmux %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,144 @@
+package menu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+)
+
+var (
+	trashDirFn      = resurrect.ResolveDir
+	trashCaptureFn  = resurrect.CaptureTrash
+	trashContentsFn = resurrect.ResolvePaneContents
+	trashMaxFn      = resurrect.ResolveTrashMax
+	trashRestoreFn  = resurrect.RestoreTrash
+	// trashKilledFn runs before every kill; the menu tests stub it so they
+	// never snapshot a real server into the developer's trash.
+	trashKilledFn = trashKilled
+)
+
+// trashKilled moves targets to the trash ahead of a kill. trashing is best
+// effort: a target that cannot be captured is logged and killed anyway, so a
+// full disk or an odd pane never blocks a kill. a trash max of 0 skips it.
+func trashKilled(ctx Context, kind resurrect.TrashKind, targets []string) {
+	maxEntries := trashMaxFn(ctx.SocketPath)
+	if maxEntries == 0 {
+		return
+	}
+	dir, err := trashDirFn(ctx.SocketPath)
+	if err != nil {
+		events.Trash.CaptureError(string(kind), targets, err)
+		return
+	}
+	events.Trash.Capture(string(kind), targets)
+	withContents := trashContentsFn(ctx.SocketPath)
+	for _, target := range targets {
+		sf, contents, err := trashCaptureFn(ctx.SocketPath, kind, target, withContents)
+		if err == nil {
+			_, err = resurrect.WriteTrash(dir, sf, contents, maxEntries)
+		}
+		if err != nil {
+			events.Trash.CaptureError(string(kind), []string{target}, err)
+		}
+	}
+}
+
+func loadTrashMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{"restore", "restore-new", "delete"}), nil
+}
+
+// loadTrashListMenu lists the trash, newest first, keyed by entry path.
+func loadTrashListMenu(ctx Context) ([]Item, error) {
+	dir, err := trashDirFn(ctx.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := resurrect.ListTrash(dir)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	now := time.Now()
+	cells := make([][]string, 0, len(entries)+1)
+	cells = append(cells, []string{"type", "target", "age", "info"})
+	for _, e := range entries {
+		info := fmt.Sprintf("%dw %dp", e.WindowCount, e.PaneCount)
+		if e.HasPaneContents {
+			info += " +contents"
+		}
+		cells = append(cells, []string{string(e.TrashInfo.Kind), e.TrashInfo.Target, resurrect.RelativeTime(e.Killed, now), info})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignRight, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: entries[i].Path, Label: label})
+	}
+	return items, nil
+}
+
+// TrashRestoreAction restores a killed item into its original session,
+// recreating the session when it is gone.
+func TrashRestoreAction(ctx Context, item Item) tea.Cmd {
+	return trashRestoreCmd(ctx, item, false)
+}
+
+// TrashRestoreNewAction restores a killed item into a new session named
+// after its original one.
+func TrashRestoreNewAction(ctx Context, item Item) tea.Cmd {
+	return trashRestoreCmd(ctx, item, true)
+}
+
+func trashRestoreCmd(ctx Context, item Item, newSession bool) tea.Cmd {
+	path := strings.TrimSpace(item.ID)
+	if path == "" {
+		return failCmd("no trash entry selected")
+	}
+	return func() tea.Msg {
+		dir, err := trashDirFn(ctx.SocketPath)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Trash.Restore(path, newSession)
+		cfg := resurrect.Config{SocketPath: ctx.SocketPath, SaveDir: dir, ClientID: ctx.ClientID}
+		session, err := trashRestoreFn(context.Background(), cfg, path, newSession)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Restored into %s", session)}
+	}
+}
+
+// TrashDeleteAction empties the picked entries out of the trash for good.
+func TrashDeleteAction(ctx Context, item Item) tea.Cmd {
+	paths := splitSelectionIDs(item.ID)
+	if len(paths) == 0 {
+		return failCmd("no trash entry selected")
+	}
+	okMsg := "Deleted trash entry"
+	if len(paths) > 1 {
+		okMsg = fmt.Sprintf("Deleted %d trash entries", len(paths))
+	}
+	return runAction(
+		func() { events.Trash.Delete(paths) },
+		func() error {
+			dir, err := trashDirFn(ctx.SocketPath)
+			if err != nil {
+				return err
+			}
+			for _, path := range paths {
+				if err := resurrect.DeleteTrash(dir, path); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		okMsg,
+	)
+}
@@ -0,0 +1,138 @@
+package menu
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+)
+
+// withTrashDir points the trash at a fresh temp dir and stubs the capture so
+// each target becomes a one-pane entry of the given kind.
+func withTrashDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	restores := []func(){
+		withPaneStub(&trashDirFn, func(string) (string, error) { return dir, nil }),
+		withPaneStub(&trashContentsFn, func(string) bool { return false }),
+		withPaneStub(&trashMaxFn, func(string) int { return 20 }),
+		withPaneStub(&trashCaptureFn, func(_ string, kind resurrect.TrashKind, target string, _ bool) (*resurrect.SaveFile, map[string]string, error) {
+			return &resurrect.SaveFile{
+				Timestamp: time.Now(),
+				Sessions:  []resurrect.Session{{Name: "main", Windows: []resurrect.Window{{Panes: []resurrect.Pane{{}}}}}},
+				Trash:     &resurrect.TrashInfo{Kind: kind, Target: target},
+			}, nil, nil
+		}),
+	}
+	t.Cleanup(func() {
+		for _, restore := range restores {
+			restore()
+		}
+	})
+	return dir
+}
+
+func TestTrashKilledStoresEveryTarget(t *testing.T) {
+	dir := withTrashDir(t)
+	trashKilled(Context{}, resurrect.TrashPane, []string{"main:1.1", "main:1.0"})
+	entries, err := resurrect.ListTrash(dir)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 trash entries, got %#v %v", entries, err)
+	}
+	items, err := loadTrashListMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[0].Header || !strings.HasPrefix(items[1].Label, "pane") || items[1].ID != entries[0].Path {
+		t.Fatalf("unexpected items %#v", items)
+	}
+}
+
+func TestTrashKilledIsBestEffort(t *testing.T) {
+	dir := withTrashDir(t)
+	restore := withPaneStub(&trashCaptureFn, func(string, resurrect.TrashKind, string, bool) (*resurrect.SaveFile, map[string]string, error) {
+		return nil, nil, errors.New("disk full")
+	})
+	defer restore()
+	trashKilled(Context{}, resurrect.TrashPane, []string{"main:1.0"})
+	if entries, _ := resurrect.ListTrash(dir); len(entries) != 0 {
+		t.Fatalf("expected nothing trashed, got %#v", entries)
+	}
+
+	killed := false
+	restoreKill := withPaneStub(&killPanesFn, func(string, []string) error {
+		killed = true
+		return nil
+	})
+	defer restoreKill()
+	restoreTrash := withPaneStub(&trashKilledFn, trashKilled)
+	defer restoreTrash()
+	if res := PaneKillAction(Context{}, Item{ID: "main:1.0"})().(ActionResult); res.Err != nil || !killed {
+		t.Fatalf("expected the kill to go ahead, got %#v killed=%v", res, killed)
+	}
+}
+
+func TestTrashKilledSkipsWhenMaxIsZero(t *testing.T) {
+	dir := withTrashDir(t)
+	restore := withPaneStub(&trashMaxFn, func(string) int { return 0 })
+	defer restore()
+	trashKilled(Context{}, resurrect.TrashPane, []string{"main:1.0"})
+	if entries, _ := resurrect.ListTrash(dir); len(entries) != 0 {
+		t.Fatalf("expected the trash off, got %#v", entries)
+	}
+}
+
+func TestWindowKillTrashesBeforeUnlinking(t *testing.T) {
+	var order []string
+	restoreTrash := withPaneStub(&trashKilledFn, func(_ Context, kind resurrect.TrashKind, targets []string) {
+		order = append(order, string(kind)+" "+strings.Join(targets, ","))
+	})
+	defer restoreTrash()
+	restoreUnlink := withPaneStub(&unlinkWindowsFn, func(string, []string) error {
+		order = append(order, "unlink")
+		return nil
+	})
+	defer restoreUnlink()
+
+	WindowKillAction(Context{}, Item{ID: "main:1\nmain:2"})()
+	if want := []string{"window main:2,main:1", "unlink"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %q, want %q", order, want)
+	}
+}
+
+func TestTrashRestoreNewAction(t *testing.T) {
+	dir := withTrashDir(t)
+	var gotPath string
+	var gotNew bool
+	var gotDir string
+	restore := withPaneStub(&trashRestoreFn, func(_ context.Context, cfg resurrect.Config, path string, newSession bool) (string, error) {
+		gotPath, gotNew, gotDir = path, newSession, cfg.SaveDir
+		return "main-2", nil
+	})
+	defer restore()
+
+	res := TrashRestoreNewAction(Context{}, Item{ID: "/trash/entry.json"})().(ActionResult)
+	if res.Err != nil || res.Info != "Restored into main-2" {
+		t.Fatalf("unexpected result %#v", res)
+	}
+	if gotPath != "/trash/entry.json" || !gotNew || gotDir != dir {
+		t.Fatalf("restore(%q, new=%v) in %q", gotPath, gotNew, gotDir)
+	}
+}
+
+func TestTrashRegistry(t *testing.T) {
+	reg := BuildRegistry()
+	if node, ok := reg.Find("trash:delete"); !ok || !node.MultiSelect {
+		t.Fatal("expected trash:delete to be multi-select")
+	}
+	for _, id := range []string{"trash:restore", "trash:restore-new", "trash:delete"} {
+		node, ok := reg.Find(id)
+		if !ok || node.Loader == nil || node.Action == nil {
+			t.Fatalf("registry missing loader/action for %s", id)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package menu
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+	"github.com/atomicstack/tmux-popup-control/internal/undo"
+)
+
+var (
+	undoDirFn    = resurrect.ResolveDir
+	undoServerFn = tmux.ServerStartTime
+)
+
+// undoServerKey names the journal of the server behind socketPath. tmux
+// reuses socket paths, so the server's start time keeps a restarted server
+// from replaying the previous one's window and pane IDs.
+func undoServerKey(socketPath string) (string, error) {
+	start, err := undoServerFn(socketPath)
+	if err != nil {
+		return "", fmt.Errorf("identify tmux server: %w", err)
+	}
+	return fmt.Sprintf("%s@%d", socketPath, start.Unix()), nil
+}
+
+// RecordUndo journals the inverse of actionID, which has just run on item.
+// ctx must describe tmux as it was before the action: the inverse restores
+// the names, layouts and positions it holds. Actions without an inverse are
+// ignored.
+func RecordUndo(ctx Context, actionID string, item Item) error {
+	entry, ok := inverseFor(ctx, actionID, item)
+	if !ok {
+		return nil
+	}
+	dir, err := undoDirFn(ctx.SocketPath)
+	if err != nil {
+		return err
+	}
+	server, err := undoServerKey(ctx.SocketPath)
+	if err != nil {
+		return err
+	}
+	events.Undo.Record(actionID, entry.Label)
+	return undo.Push(dir, server, entry)
+}
+
+// inverseFor builds the journal entry that reverses actionID on item.
+func inverseFor(ctx Context, actionID string, item Item) (undo.Entry, bool) {
+	entry := undo.Entry{Action: actionID}
+	switch actionID {
+	case "session:rename":
+		// item carries the old name as ID and the new one as Label.
+		oldName, newName := strings.TrimSpace(item.ID), strings.TrimSpace(item.Label)
+		if oldName == "" || newName == "" || oldName == newName {
+			return entry, false
+		}
+		entry.Label = fmt.Sprintf("%s → %s", oldName, newName)
+		entry.Commands = [][]string{{"rename-session", "-t", "=" + newName, oldName}}
+		entry.Requires = []string{"=" + newName + ":"}
+	case "window:rename":
+		win, ok := findWindowEntry(ctx, item.ID)
+		if !ok || win.InternalID == "" || win.Name == "" || win.Name == strings.TrimSpace(item.Label) {
+			return entry, false
+		}
+		entry.Label = fmt.Sprintf("%s %s → %s", win.ID, win.Name, strings.TrimSpace(item.Label))
+		entry.Commands = [][]string{{"rename-window", "-t", win.InternalID, win.Name}}
+		entry.Requires = []string{win.InternalID}
+	case "pane:rename":
+		pane, ok := findPaneEntry(ctx, item.ID)
+		if !ok || pane.PaneID == "" || pane.Title == strings.TrimSpace(item.Label) {
+			return entry, false
+		}
+		entry.Label = fmt.Sprintf("%s %s → %s", pane.ID, pane.Title, strings.TrimSpace(item.Label))
+		entry.Commands = [][]string{{"select-pane", "-t", pane.PaneID, "-T", pane.Title}}
+		entry.Requires = []string{pane.PaneID}
+	case "window:swap", "pane:swap":
+		// a swap pair arrives as two joined IDs; swapping the same two
+		// positions again puts both back.
+		ids := splitSelectionIDs(item.ID)
+		if len(ids) != 2 {
+			return entry, false
+		}
+		command := "swap-window"
+		if actionID == "pane:swap" {
+			command = "swap-pane"
+		}
+		entry.Label = fmt.Sprintf("%s ↔ %s", ids[0], ids[1])
+		entry.Commands = [][]string{{command, "-s", ids[0], "-t", ids[1]}}
+		entry.Requires = ids
+	case "window:push-to-session":
+		return inverseWindowMove(entry, ctx, ctx.CurrentWindowID, strings.TrimSpace(item.ID))
+	case "window:pull-from-session":
+		source := strings.TrimSpace(item.ID)
+		if trimmed, ok := strings.CutPrefix(source, TreePrefixWindow); ok {
+			source = trimmed
+		}
+		return inverseWindowMove(entry, ctx, source, ctx.CurrentWindowSession)
+	case "window:link":
+		win, ok := findWindowEntry(ctx, item.ID)
+		session := strings.TrimSpace(ctx.CurrentWindowSession)
+		if !ok || win.InternalID == "" || session == "" {
+			return entry, false
+		}
+		linked := session + ":" + win.InternalID
+		entry.Label = fmt.Sprintf("%s into %s", win.ID, session)
+		entry.Commands = [][]string{{"unlink-window", "-t", linked}}
+		entry.Requires = []string{linked}
+	case "window:layout":
+		win, ok := findWindowEntry(ctx, ctx.CurrentWindowID)
+		layout := strings.TrimSpace(ctx.CurrentWindowLayout)
+		if !ok || win.InternalID == "" || layout == "" || layout == strings.TrimSpace(item.ID) {
+			return entry, false
+		}
+		entry.Label = fmt.Sprintf("%s → %s", win.ID, strings.TrimSpace(item.ID))
+		entry.Commands = [][]string{{"select-layout", "-t", win.InternalID, layout}}
+		entry.Requires = []string{win.InternalID}
+	case "pane:break":
+		pane, ok := findPaneEntry(ctx, item.ID)
+		if !ok || pane.PaneID == "" {
+			return entry, false
+		}
+		win, ok := findWindowEntry(ctx, fmt.Sprintf("%s:%d", pane.Session, pane.WindowIdx))
+		if !ok || win.InternalID == "" || windowPaneCount(ctx, pane) < 2 {
+			// breaking a lone pane moves its whole window; nothing to rejoin.
+			return entry, false
+		}
+		entry.Label = fmt.Sprintf("%s out of %s", pane.ID, win.ID)
+		entry.Commands = [][]string{{"join-pane", "-d", "-s", pane.PaneID, "-t", win.InternalID}}
+		if win.Layout != "" {
+			entry.Commands = append(entry.Commands, []string{"select-layout", "-t", win.InternalID, win.Layout})
+		}
+		entry.Requires = []string{pane.PaneID, win.InternalID}
+	case "pane:join":
+		return inversePaneJoin(entry, ctx, splitSelectionIDs(item.ID))
+	default:
+		return entry, false
+	}
+	return entry, true
+}
+
+// inverseWindowMove reverses moving window source into session: the window
+// goes back to its old session and index, found again by its stable @ID.
+func inverseWindowMove(entry undo.Entry, ctx Context, source, session string) (undo.Entry, bool) {
+	win, ok := findWindowEntry(ctx, source)
+	session = strings.TrimSpace(session)
+	if !ok || win.InternalID == "" || session == "" || session == win.Session {
+		return entry, false
+	}
+	moved := session + ":" + win.InternalID
+	entry.Label = fmt.Sprintf("%s to %s", win.ID, session)
+	entry.Commands = [][]string{{"move-window", "-s", moved, "-t", fmt.Sprintf("%s:%d", win.Session, win.Index)}}
+	entry.Requires = []string{moved, "=" + win.Session + ":"}
+	return entry, true
+}
+
+// inversePaneJoin reverses joining ids into the current window: a pane whose
+// old window survived goes back into it, a pane that was its window's last
+// is broken out to a new window at the old index. The current window then
+// gets its old layout back.
+func inversePaneJoin(entry undo.Entry, ctx Context, ids []string) (undo.Entry, bool) {
+	var requires []string
+	for _, id := range ids {
+		pane, ok := findPaneEntry(ctx, id)
+		if !ok || pane.PaneID == "" {
+			return entry, false
+		}
+		windowID := fmt.Sprintf("%s:%d", pane.Session, pane.WindowIdx)
+		win, ok := findWindowEntry(ctx, windowID)
+		if !ok || win.InternalID == "" {
+			return entry, false
+		}
+		requires = append(requires, pane.PaneID)
+		if windowPaneCount(ctx, pane) > 1 {
+			entry.Commands = append(entry.Commands, []string{"join-pane", "-d", "-s", pane.PaneID, "-t", win.InternalID})
+			if win.Layout != "" {
+				entry.Commands = append(entry.Commands, []string{"select-layout", "-t", win.InternalID, win.Layout})
+			}
+			continue
+		}
+		entry.Commands = append(entry.Commands, []string{"break-pane", "-d", "-s", pane.PaneID, "-t", windowID, "-n", win.Name})
+	}
+	if len(entry.Commands) == 0 {
+		return entry, false
+	}
+	if current, ok := findWindowEntry(ctx, ctx.CurrentWindowID); ok && current.InternalID != "" && ctx.CurrentWindowLayout != "" {
+		entry.Commands = append(entry.Commands, []string{"select-layout", "-t", current.InternalID, ctx.CurrentWindowLayout})
+	}
+	entry.Label = fmt.Sprintf("%d pane(s) into %s", len(ids), ctx.CurrentWindowID)
+	entry.Requires = requires
+	return entry, true
+}
+
+func findWindowEntry(ctx Context, id string) (WindowEntry, bool) {
+	id = strings.TrimSpace(id)
+	for _, win := range ctx.Windows {
+		if win.ID == id {
+			return win, true
+		}
+	}
+	return WindowEntry{}, false
+}
+
+func findPaneEntry(ctx Context, id string) (PaneEntry, bool) {
+	id = strings.TrimSpace(id)
+	for _, pane := range ctx.Panes {
+		if pane.ID == id {
+			return pane, true
+		}
+	}
+	return PaneEntry{}, false
+}
+
+func windowPaneCount(ctx Context, pane PaneEntry) int {
+	n := 0
+	for _, p := range ctx.Panes {
+		if p.Session == pane.Session && p.WindowIdx == pane.WindowIdx {
+			n++
+		}
+	}
+	return n
+}
+
+func loadUndoMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{"last", "history"}), nil
+}
+
+func loadUndoHistoryMenu(ctx Context) ([]Item, error) {
+	dir, err := undoDirFn(ctx.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	server, err := undoServerKey(ctx.SocketPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := undo.List(dir, server)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	now := time.Now()
+	cells := make([][]string, 0, len(entries)+1)
+	cells = append(cells, []string{"age", "action", "change"})
+	for _, e := range entries {
+		cells = append(cells, []string{resurrect.RelativeTime(e.Created, now), e.Action, e.Label})
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: entries[i].ID, Label: label})
+	}
+	return items, nil
+}
+
+// UndoLastAction reverses the most recent journalled action.
+func UndoLastAction(ctx Context, _ Item) tea.Cmd {
+	return undoCmd(ctx, "")
+}
+
+// UndoHistoryAction reverses the picked journal entry.
+func UndoHistoryAction(ctx Context, item Item) tea.Cmd {
+	id := strings.TrimSpace(item.ID)
+	if id == "" {
+		return failCmd("no undo entry selected")
+	}
+	return undoCmd(ctx, id)
+}
+
+// undoCmd takes entry id (the newest when empty) off the journal and
+// replays it. the entry is dropped even when the replay fails: a target that
+// no longer exists will not come back, and a half-applied inverse must not
+// be replayed twice.
+func undoCmd(ctx Context, id string) tea.Cmd {
+	return func() tea.Msg {
+		dir, err := undoDirFn(ctx.SocketPath)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		server, err := undoServerKey(ctx.SocketPath)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		entry, ok, err := undo.Take(dir, server, id)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if !ok {
+			return ActionResult{Info: "Nothing to undo"}
+		}
+		events.Undo.Replay(entry.Action, entry.Label)
+		if err := replayUndo(ctx.SocketPath, entry); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Undid %s %s", entry.Action, entry.Label)}
+	}
+}
+
+// replayUndo checks that every target entry needs still exists, then runs
+// its commands in order.
+func replayUndo(socketPath string, entry undo.Entry) error {
+	for _, target := range entry.Requires {
+		if _, err := runCommandOutputFn(socketPath, "display-message", "-p", "-t", target, "#{pane_id}"); err != nil {
+			return fmt.Errorf("cannot undo %s %s: %s no longer exists", entry.Action, entry.Label, target)
+		}
+	}
+	for _, args := range entry.Commands {
+		if out, err := runCommandOutputFn(socketPath, args...); err != nil {
+			if detail := strings.TrimSpace(string(out)); detail != "" {
+				return fmt.Errorf("tmux %s: %s", strings.Join(args, " "), detail)
+			}
+			return fmt.Errorf("tmux %s: %w", strings.Join(args, " "), err)
+		}
+	}
+	return nil
+}
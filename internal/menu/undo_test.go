@@ -0,0 +1,189 @@
+package menu
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/undo"
+)
+
+// withUndoJournal points the journal at a fresh temp dir under a fixed
+// server start time.
+func withUndoJournal(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	restoreDir := withPaneStub(&undoDirFn, func(string) (string, error) { return dir, nil })
+	restoreServer := withPaneStub(&undoServerFn, func(string) (time.Time, error) { return time.Unix(1_700_000_000, 0), nil })
+	t.Cleanup(func() {
+		restoreServer()
+		restoreDir()
+	})
+	return dir
+}
+
+func undoTestContext() Context {
+	return Context{
+		CurrentWindowID:      "main:1",
+		CurrentWindowSession: "main",
+		CurrentWindowLayout:  "tiled-layout",
+		Windows: []WindowEntry{
+			{ID: "main:1", InternalID: "@1", Name: "editor", Session: "main", Index: 1, Layout: "main-layout"},
+			{ID: "work:3", InternalID: "@7", Name: "logs", Session: "work", Index: 3, Layout: "logs-layout"},
+			{ID: "work:4", InternalID: "@8", Name: "shell", Session: "work", Index: 4, Layout: "shell-layout"},
+		},
+		Panes: []PaneEntry{
+			{ID: "main:1.0", PaneID: "%1", Session: "main", WindowIdx: 1, Title: "vim"},
+			{ID: "work:3.0", PaneID: "%5", Session: "work", WindowIdx: 3, Title: "tail"},
+			{ID: "work:3.1", PaneID: "%6", Session: "work", WindowIdx: 3, Title: "grep"},
+			{ID: "work:4.0", PaneID: "%9", Session: "work", WindowIdx: 4, Title: "zsh"},
+		},
+	}
+}
+
+func TestInverseFor(t *testing.T) {
+	ctx := undoTestContext()
+	cases := []struct {
+		action string
+		item   Item
+		want   [][]string
+	}{
+		{"session:rename", Item{ID: "work", Label: "play"}, [][]string{{"rename-session", "-t", "=play", "work"}}},
+		{"window:rename", Item{ID: "work:3", Label: "errors"}, [][]string{{"rename-window", "-t", "@7", "logs"}}},
+		{"pane:rename", Item{ID: "work:3.1", Label: "ripgrep"}, [][]string{{"select-pane", "-t", "%6", "-T", "grep"}}},
+		{"pane:swap", Item{ID: "work:3.0\nwork:4.0"}, [][]string{{"swap-pane", "-s", "work:3.0", "-t", "work:4.0"}}},
+		{"window:push-to-session", Item{ID: "work"}, [][]string{{"move-window", "-s", "work:@1", "-t", "main:1"}}},
+		{"window:pull-from-session", Item{ID: "work:4"}, [][]string{{"move-window", "-s", "main:@8", "-t", "work:4"}}},
+		{"window:link", Item{ID: "work:3"}, [][]string{{"unlink-window", "-t", "main:@7"}}},
+		{"window:layout", Item{ID: "even-horizontal"}, [][]string{{"select-layout", "-t", "@1", "tiled-layout"}}},
+		{"pane:break", Item{ID: "work:3.1"}, [][]string{
+			{"join-pane", "-d", "-s", "%6", "-t", "@7"},
+			{"select-layout", "-t", "@7", "logs-layout"},
+		}},
+		{"pane:join", Item{ID: "work:3.1\nwork:4.0"}, [][]string{
+			{"join-pane", "-d", "-s", "%6", "-t", "@7"},
+			{"select-layout", "-t", "@7", "logs-layout"},
+			{"break-pane", "-d", "-s", "%9", "-t", "work:4", "-n", "shell"},
+			{"select-layout", "-t", "@1", "tiled-layout"},
+		}},
+	}
+	for _, tc := range cases {
+		entry, ok := inverseFor(ctx, tc.action, tc.item)
+		if !ok {
+			t.Errorf("%s: expected an inverse", tc.action)
+			continue
+		}
+		if !reflect.DeepEqual(entry.Commands, tc.want) {
+			t.Errorf("%s: commands = %q, want %q", tc.action, entry.Commands, tc.want)
+		}
+	}
+}
+
+func TestInverseForSkipsIrreversible(t *testing.T) {
+	ctx := undoTestContext()
+	for _, tc := range []struct {
+		action string
+		item   Item
+	}{
+		{"window:kill", Item{ID: "work:3"}},
+		{"session:rename", Item{ID: "work", Label: "work"}},
+		{"pane:break", Item{ID: "main:1.0"}},
+		{"window:swap", Item{ID: "main:1"}},
+		{"window:rename", Item{ID: "gone:9", Label: "x"}},
+	} {
+		if entry, ok := inverseFor(ctx, tc.action, tc.item); ok {
+			t.Errorf("%s %q: expected no inverse, got %#v", tc.action, tc.item.ID, entry)
+		}
+	}
+}
+
+func TestUndoLastReplaysNewest(t *testing.T) {
+	dir := withUndoJournal(t)
+	ctx := undoTestContext()
+	if err := RecordUndo(ctx, "window:rename", Item{ID: "work:3", Label: "errors"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordUndo(ctx, "pane:rename", Item{ID: "work:3.1", Label: "ripgrep"}); err != nil {
+		t.Fatal(err)
+	}
+	var ran [][]string
+	restore := withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		ran = append(ran, args)
+		return nil, nil
+	})
+	defer restore()
+
+	res := UndoLastAction(ctx, Item{})().(ActionResult)
+	if res.Err != nil || !strings.HasPrefix(res.Info, "Undid pane:rename") {
+		t.Fatalf("unexpected result %#v", res)
+	}
+	want := [][]string{
+		{"display-message", "-p", "-t", "%6", "#{pane_id}"},
+		{"select-pane", "-t", "%6", "-T", "grep"},
+	}
+	if !reflect.DeepEqual(ran, want) {
+		t.Fatalf("ran %q, want %q", ran, want)
+	}
+	server, _ := undoServerKey("")
+	if left, _ := undo.List(dir, server); len(left) != 1 || left[0].Action != "window:rename" {
+		t.Fatalf("expected window:rename left, got %#v", left)
+	}
+}
+
+func TestUndoMissingTargetDropsEntry(t *testing.T) {
+	dir := withUndoJournal(t)
+	if err := RecordUndo(undoTestContext(), "window:rename", Item{ID: "work:3", Label: "errors"}); err != nil {
+		t.Fatal(err)
+	}
+	var ran [][]string
+	restore := withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		ran = append(ran, args)
+		return []byte("can't find window: @7"), errors.New("exit status 1")
+	})
+	defer restore()
+
+	res := UndoLastAction(Context{}, Item{})().(ActionResult)
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "@7 no longer exists") {
+		t.Fatalf("expected missing target error, got %#v", res)
+	}
+	if len(ran) != 1 {
+		t.Fatalf("expected only the existence check, ran %q", ran)
+	}
+	server, _ := undoServerKey("")
+	if left, _ := undo.List(dir, server); len(left) != 0 {
+		t.Fatalf("expected the stale entry dropped, got %#v", left)
+	}
+}
+
+func TestLoadUndoHistoryMenu(t *testing.T) {
+	withUndoJournal(t)
+	ctx := undoTestContext()
+	if err := RecordUndo(ctx, "window:rename", Item{ID: "work:3", Label: "errors"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordUndo(ctx, "session:rename", Item{ID: "work", Label: "play"}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := loadUndoHistoryMenu(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || !items[0].Header {
+		t.Fatalf("expected header + 2 entries, got %#v", items)
+	}
+	if !strings.Contains(items[1].Label, "session:rename") || items[1].ID == "" {
+		t.Fatalf("expected newest entry first, got %#v", items[1])
+	}
+}
+
+func TestUndoRegistry(t *testing.T) {
+	reg := BuildRegistry()
+	if node, ok := reg.Find("undo:last"); !ok || node.Action == nil {
+		t.Fatal("registry missing undo:last action")
+	}
+	if node, ok := reg.Find("undo:history"); !ok || node.Loader == nil || node.Action == nil {
+		t.Fatal("registry missing loader/action for undo:history")
+	}
+}
@@ -0,0 +1,262 @@
+package menu
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/shquote"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+	"github.com/atomicstack/tmux-popup-control/internal/usermenu"
+)
+
+var (
+	userMenuOptionsFn = tmux.UserOptions
+	userMenuOptionFn  = tmux.ShowOption
+	userMenuFormatFn  = tmux.ExpandFormat
+	userMenuShellFn   = runUserShell
+)
+
+// LoadUserMenus reads the user-defined menus from the menus file and the
+// @tmux-popup-control-menu-* options, an option replacing the file's menu of
+// the same ID. A broken definition, or one whose ID names a built-in menu
+// node, is reported in the error without dropping the others.
+func LoadUserMenus(socketPath string) ([]usermenu.Def, error) {
+	option := func(opt string) string { return userMenuOptionFn(socketPath, opt) }
+	var errs []error
+	defs, err := usermenu.Load(usermenu.ResolvePath(os.Getenv, option))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	names, err := userMenuOptionsFn(socketPath)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	var fromOptions []usermenu.Def
+	for _, name := range names {
+		if !strings.HasPrefix(name, usermenu.OptionPrefix) {
+			continue
+		}
+		def, err := usermenu.ParseOption(name, option(name))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fromOptions = append(fromOptions, def)
+	}
+	builtin := BuildRegistry()
+	var kept []usermenu.Def
+	for _, def := range usermenu.Merge(defs, fromOptions) {
+		if _, taken := builtin.Find(def.ID); taken {
+			errs = append(errs, fmt.Errorf("user menu %q clashes with a built-in menu", def.ID))
+			continue
+		}
+		kept = append(kept, def)
+	}
+	events.UserMenu.Load(len(kept))
+	return kept, errors.Join(errs...)
+}
+
+// registerUserMenu adds def and its descendants to the registry under id.
+func registerUserMenu(ensure func(string) *Node, id string, def usermenu.Def) {
+	node := ensure(id)
+	node.MultiSelect = def.MultiSelect
+	if def.Preview != "" {
+		node.Preview = userMenuPreview(def.Preview)
+	}
+	if len(def.Items) > 0 {
+		items := make([]Item, 0, len(def.Items))
+		for _, child := range def.Items {
+			items = append(items, Item{ID: child.ID, Label: child.Label})
+			registerUserMenu(ensure, id+":"+child.ID, child)
+		}
+		node.Loader = func(Context) ([]Item, error) { return items, nil }
+		return
+	}
+	node.Action = userMenuAction(def)
+	if def.Loader != "" {
+		node.Loader = userMenuLoader(def.Loader)
+	}
+}
+
+// userMenuLoader runs a loader command and turns its output lines into items.
+func userMenuLoader(command string) Loader {
+	return func(ctx Context) ([]Item, error) {
+		script, err := expandUserFormats(ctx, command, shquote.Quote)
+		if err != nil {
+			return nil, err
+		}
+		events.UserMenu.Run("loader", script)
+		out, err := userMenuShellFn(ctx.SocketPath, script)
+		if err != nil {
+			return nil, err
+		}
+		loaded := usermenu.ParseItems(string(out))
+		items := make([]Item, 0, len(loaded))
+		for _, item := range loaded {
+			items = append(items, Item{ID: item.ID, Label: item.Label})
+		}
+		return items, nil
+	}
+}
+
+// userMenuPreview runs a preview command for the highlighted item.
+func userMenuPreview(command string) func(Context, Item) ([]string, error) {
+	return func(ctx Context, item Item) ([]string, error) {
+		script, err := expandUserFormats(ctx, command, shquote.Quote)
+		if err != nil {
+			return nil, err
+		}
+		script = usermenu.ExpandShell(script, usermenu.Selection{IDs: []string{item.ID}, Label: item.Label})
+		out, err := userMenuShellFn(ctx.SocketPath, script)
+		if err != nil {
+			return nil, err
+		}
+		return splitLines(string(out)), nil
+	}
+}
+
+// userMenuAction runs def's command for the picked item. With several items
+// marked, a command using {items} runs once; any other runs once per item.
+// Output, if any, is shown in the popup like the command menu's.
+func userMenuAction(def usermenu.Def) Action {
+	return func(ctx Context, item Item) tea.Cmd {
+		ids := splitSelectionIDs(item.ID)
+		if len(ids) == 0 {
+			return failCmd("no item selected")
+		}
+		selections := []usermenu.Selection{{IDs: ids, Label: item.Label}}
+		if len(ids) > 1 && !usermenu.Batched(def.Tmux+def.Shell) {
+			selections = selections[:0]
+			for _, id := range ids {
+				selections = append(selections, usermenu.Selection{IDs: []string{id}, Label: id})
+			}
+		}
+		return func() tea.Msg {
+			var output strings.Builder
+			for _, sel := range selections {
+				out, err := runUserMenuCommand(ctx, def, sel)
+				output.WriteString(out)
+				if err != nil {
+					return ActionResult{Err: err}
+				}
+			}
+			return ActionResult{
+				Info:   fmt.Sprintf("Ran: %s", def.Label),
+				Output: strings.TrimRight(output.String(), "\r\n"),
+			}
+		}
+	}
+}
+
+func runUserMenuCommand(ctx Context, def usermenu.Def, sel usermenu.Selection) (string, error) {
+	if def.Tmux != "" {
+		fields := shquote.Fields(def.Tmux)
+		for i, field := range fields {
+			expanded, err := expandUserFormats(ctx, field, nil)
+			if err != nil {
+				return "", err
+			}
+			fields[i] = expanded
+		}
+		args := usermenu.ExpandArgs(fields, sel)
+		if len(args) == 0 {
+			return "", fmt.Errorf("menu %s: empty tmux command", def.ID)
+		}
+		events.UserMenu.Run("tmux", strings.Join(args, " "))
+		out, err := runCommandOutputFn(ctx.SocketPath, args...)
+		if err != nil {
+			if detail := strings.TrimSpace(string(out)); detail != "" {
+				return "", fmt.Errorf("tmux %s: %s", strings.Join(args, " "), detail)
+			}
+			return "", fmt.Errorf("tmux %s: %w", strings.Join(args, " "), err)
+		}
+		return string(out), nil
+	}
+	script, err := expandUserFormats(ctx, def.Shell, shquote.Quote)
+	if err != nil {
+		return "", err
+	}
+	script = usermenu.ExpandShell(script, sel)
+	events.UserMenu.Run("shell", script)
+	out, err := userMenuShellFn(ctx.SocketPath, script)
+	return string(out), err
+}
+
+// expandUserFormats resolves each #{…} format in s against the originating
+// pane. it runs before placeholder substitution, so an item can never
+// smuggle in a #() format. quote, when set, is applied to every value so a
+// pane path or window name holding shell syntax stays one word; #{q:…} is
+// already quoted by tmux and #{raw:…} opts out, splicing the value as is.
+// strings without a format skip the round trip.
+func expandUserFormats(ctx Context, s string, quote func(string) string) (string, error) {
+	var out strings.Builder
+	for {
+		start := strings.Index(s, "#{")
+		if start < 0 {
+			break
+		}
+		end := formatEnd(s, start)
+		if end < 0 {
+			break
+		}
+		format, raw := s[start:end], quote == nil
+		if inner, ok := strings.CutPrefix(format, "#{raw:"); ok {
+			format, raw = "#{"+inner, true
+		} else if strings.HasPrefix(format, "#{q:") {
+			raw = true
+		}
+		value, err := userMenuFormatFn(ctx.SocketPath, bufferPasteTarget(ctx), format)
+		if err != nil {
+			return "", err
+		}
+		if !raw {
+			value = quote(value)
+		}
+		out.WriteString(s[:start])
+		out.WriteString(value)
+		s = s[end:]
+	}
+	out.WriteString(s)
+	return out.String(), nil
+}
+
+// formatEnd returns the index just past the #{…} format opening at start,
+// counting nested formats, or -1 when it is never closed.
+func formatEnd(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "#{"):
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// runUserShell runs script with sh, returning its stdout. A failure carries
+// the script's stderr.
+func runUserShell(socket, script string) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Env = tmuxCommandEnv(socket)
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if detail := strings.TrimSpace(string(exitErr.Stderr)); detail != "" {
+			return out, fmt.Errorf("%s: %s", script, detail)
+		}
+		return out, fmt.Errorf("%s: %w", script, err)
+	}
+	return out, err
+}
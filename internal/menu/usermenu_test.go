@@ -0,0 +1,236 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/atomicstack/tmux-popup-control/internal/usermenu"
+)
+
+// withUserMenuOptions serves tmux options from opts for LoadUserMenus.
+func withUserMenuOptions(t *testing.T, opts map[string]string) {
+	t.Helper()
+	restoreNames := withPaneStub(&userMenuOptionsFn, func(string) ([]string, error) {
+		names := make([]string, 0, len(opts))
+		for name := range opts {
+			names = append(names, name)
+		}
+		return names, nil
+	})
+	restoreOpt := withPaneStub(&userMenuOptionFn, func(_, opt string) string { return opts[opt] })
+	t.Cleanup(func() {
+		restoreOpt()
+		restoreNames()
+	})
+}
+
+func TestLoadUserMenusMergesFileAndOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "menus.json")
+	body := `[{"id": "deploy", "shell": "make deploy"}, {"id": "session", "tmux": "new-session"}]`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TMUX_POPUP_CONTROL_MENUS_FILE", path)
+	withUserMenuOptions(t, map[string]string{
+		"@tmux-popup-control-menu-deploy": `{"label": "Deploy!", "shell": "make ship"}`,
+		"@tmux-popup-control-menu-broken": `{"label": "no command"}`,
+		"@unrelated":                      "x",
+	})
+
+	defs, err := LoadUserMenus("")
+	if err == nil || !strings.Contains(err.Error(), "session") || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected errors for the clash and the broken option, got %v", err)
+	}
+	if len(defs) != 1 || defs[0].ID != "deploy" || defs[0].Shell != "make ship" {
+		t.Fatalf("unexpected menus %#v", defs)
+	}
+}
+
+func TestUserMenusCannotReplaceBuiltins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "menus.json")
+	body := `[{"id": "root", "shell": "true"}, {"id": "palette", "shell": "true"}, {"id": "ssh", "shell": "true"}, {"id": "ok", "shell": "true"}]`
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TMUX_POPUP_CONTROL_MENUS_FILE", path)
+	withUserMenuOptions(t, nil)
+	defs, err := LoadUserMenus("")
+	if err == nil || len(defs) != 1 || defs[0].ID != "ok" {
+		t.Fatalf("expected only ok kept, got %#v %v", defs, err)
+	}
+
+	builtin := BuildRegistry()
+	session, _ := builtin.Find("session")
+	reg := BuildRegistry(usermenu.Def{ID: "session", Label: "session", Shell: "true"}, usermenu.Def{ID: "palette", Label: "palette", Shell: "true"})
+	if len(reg.RootItems()) != len(builtin.RootItems()) {
+		t.Fatalf("expected no duplicate root entries, got %#v", reg.RootItems())
+	}
+	if node, _ := reg.Find("session"); node.Action != nil || (node.Loader == nil) != (session.Loader == nil) {
+		t.Fatalf("expected the built-in session node kept, got %#v", node)
+	}
+}
+
+func TestBuildRegistryAddsUserMenus(t *testing.T) {
+	defs, err := usermenu.Parse([]byte(`[{"id": "k8s", "items": [
+		{"id": "contexts", "loader": "echo", "shell": "use {item}", "multi_select": true, "preview": "describe {item}"},
+		{"id": "pods", "tmux": "display-message pods"}
+	]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := BuildRegistry(defs...)
+	root := reg.RootItems()
+	if last := root[len(root)-1]; last.ID != "k8s" {
+		t.Fatalf("expected k8s appended to the root menu, got %#v", last)
+	}
+	items, err := reg.Root().Loader(Context{})
+	if err != nil || !reflect.DeepEqual(items, root) {
+		t.Fatalf("root loader = %#v, %v", items, err)
+	}
+	k8s, ok := reg.Child("root", "k8s")
+	if !ok || k8s.Loader == nil || k8s.Action != nil {
+		t.Fatal("expected k8s to be a static submenu")
+	}
+	contexts, ok := reg.Child("k8s", "contexts")
+	if !ok || contexts.Loader == nil || contexts.Action == nil || !contexts.MultiSelect || contexts.Preview == nil {
+		t.Fatalf("unexpected contexts node %#v", contexts)
+	}
+	pods, ok := reg.Find("k8s:pods")
+	if !ok || pods.Loader != nil || pods.Action == nil {
+		t.Fatalf("unexpected pods node %#v", pods)
+	}
+}
+
+func TestUserMenuLoaderParsesOutput(t *testing.T) {
+	var gotScript string
+	restore := withPaneStub(&userMenuShellFn, func(_, script string) ([]byte, error) {
+		gotScript = script
+		return []byte("prod\tProduction\nstaging\n"), nil
+	})
+	defer restore()
+
+	items, err := userMenuLoader("kubectl config get-contexts -o name")(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{{ID: "prod", Label: "Production"}, {ID: "staging", Label: "staging"}}
+	if gotScript != "kubectl config get-contexts -o name" || !reflect.DeepEqual(items, want) {
+		t.Fatalf("script %q items %#v", gotScript, items)
+	}
+}
+
+func TestUserMenuShellActionRunsPerItem(t *testing.T) {
+	var scripts []string
+	restore := withPaneStub(&userMenuShellFn, func(_, script string) ([]byte, error) {
+		scripts = append(scripts, script)
+		return nil, nil
+	})
+	defer restore()
+
+	action := userMenuAction(usermenu.Def{ID: "ctx", Label: "ctx", Shell: "use {item}"})
+	res := action(Context{}, Item{ID: "a\nb c", Label: "a, b c"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := []string{"use 'a'", "use 'b c'"}; !reflect.DeepEqual(scripts, want) {
+		t.Fatalf("scripts = %q, want %q", scripts, want)
+	}
+
+	scripts = nil
+	batched := userMenuAction(usermenu.Def{ID: "ctx", Label: "ctx", Shell: "use {items}"})
+	batched(Context{}, Item{ID: "a\nb c"})()
+	if want := []string{"use 'a' 'b c'"}; !reflect.DeepEqual(scripts, want) {
+		t.Fatalf("scripts = %q, want %q", scripts, want)
+	}
+}
+
+func TestUserMenuTmuxActionExpandsFormatsBeforeItems(t *testing.T) {
+	t.Setenv("TMUX_POPUP_CONTROL_PANE_ID", "%3")
+	var formats []string
+	restoreFormat := withPaneStub(&userMenuFormatFn, func(_, target, format string) (string, error) {
+		formats = append(formats, target+" "+format)
+		return "/home/me/src", nil
+	})
+	defer restoreFormat()
+	var gotArgs []string
+	restoreRun := withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		gotArgs = args
+		return []byte("done\n"), nil
+	})
+	defer restoreRun()
+
+	action := userMenuAction(usermenu.Def{ID: "logs", Label: "Logs", Tmux: `new-window -c "#{pane_current_path}" -n {item}`})
+	res := action(Context{}, Item{ID: "#{pane_id}"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := []string{"%3 #{pane_current_path}"}; !reflect.DeepEqual(formats, want) {
+		t.Fatalf("formats = %q, want %q (item text must not be expanded)", formats, want)
+	}
+	if want := []string{"new-window", "-c", "/home/me/src", "-n", "#{pane_id}"}; !reflect.DeepEqual(gotArgs, want) {
+		t.Fatalf("args = %q, want %q", gotArgs, want)
+	}
+	if res.Output != "done" {
+		t.Fatalf("expected command output to be shown, got %#v", res)
+	}
+}
+
+func TestUserMenuShellQuotesFormats(t *testing.T) {
+	var formats []string
+	restoreFormat := withPaneStub(&userMenuFormatFn, func(_, _, format string) (string, error) {
+		formats = append(formats, format)
+		switch format {
+		case "#{window_name}":
+			return "$(rm -rf ~)", nil
+		case "#{q:pane_current_path}":
+			return `/src/my\ app`, nil
+		}
+		return "--verbose", nil
+	})
+	defer restoreFormat()
+	var script string
+	restore := withPaneStub(&userMenuShellFn, func(_, s string) ([]byte, error) {
+		script = s
+		return nil, nil
+	})
+	defer restore()
+
+	def := usermenu.Def{ID: "x", Label: "x", Shell: "echo #{window_name} #{q:pane_current_path} #{raw:@flags} {item}"}
+	if res := userMenuAction(def)(Context{}, Item{ID: "a"})().(ActionResult); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := `echo '$(rm -rf ~)' /src/my\ app --verbose 'a'`; script != want {
+		t.Fatalf("script = %q, want %q", script, want)
+	}
+	if want := []string{"#{window_name}", "#{q:pane_current_path}", "#{@flags}"}; !reflect.DeepEqual(formats, want) {
+		t.Fatalf("formats = %q, want %q", formats, want)
+	}
+}
+
+func TestUserMenuPreviewRunsForItem(t *testing.T) {
+	restore := withPaneStub(&userMenuShellFn, func(_, script string) ([]byte, error) {
+		return []byte(script + "\nline two\n"), nil
+	})
+	defer restore()
+
+	lines, err := userMenuPreview("describe {item}")(Context{}, Item{ID: "prod", Label: "Production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"describe 'prod'", "line two"}; !reflect.DeepEqual(lines, want) {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestRunUserShellReportsStderr(t *testing.T) {
+	out, err := runUserShell("", "echo out; echo oops >&2; exit 3")
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Fatalf("expected stderr in error, got %v", err)
+	}
+	if string(out) != "out\n" {
+		t.Fatalf("stdout = %q", out)
+	}
+}
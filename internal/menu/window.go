@@ -12,6 +12,7 @@ import (
 
 	"github.com/atomicstack/tmux-popup-control/internal/format/table"
 	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
 	"github.com/atomicstack/tmux-popup-control/internal/tmux"
 )
 
@@ -305,6 +306,7 @@ func WindowKillAction(ctx Context, item Item) tea.Cmd {
 	label := item.Label
 	return func() tea.Msg {
 		events.Window.Kill(sorted)
+		trashKilledFn(ctx, resurrect.TrashWindow, sorted)
 		if err := unlinkWindowsFn(ctx.SocketPath, sorted); err != nil {
 			return ActionResult{Err: err}
 		}
@@ -0,0 +1,425 @@
+package menu
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/format/table"
+	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/project"
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+	"github.com/atomicstack/tmux-popup-control/internal/worktree"
+)
+
+// WorktreeRemoveID is the registry id of the worktree removal menu, which
+// asks for confirmation before anything is removed.
+const WorktreeRemoveID = "worktree:remove"
+
+var (
+	worktreeGitFn     = runGit
+	worktreePaneDirFn = currentPaneDir
+)
+
+// runGit runs git in dir, returning stdout and, on failure, an error
+// carrying git's own message.
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return out, fmt.Errorf("git %s: %s", strings.Join(args, " "), detail)
+		}
+		return out, fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return out, nil
+}
+
+// currentPaneDir is the working directory of the pane that opened the popup.
+func currentPaneDir(ctx Context) (string, error) {
+	target := cmp.Or(tmux.OriginPaneID(), strings.TrimSpace(ctx.CurrentPaneID))
+	dir, err := tmux.ExpandFormat(ctx.SocketPath, target, "#{pane_current_path}")
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", errors.New("the current pane has no working directory")
+	}
+	return dir, nil
+}
+
+// listWorktrees returns the worktrees of the current pane's repository, the
+// main one first.
+func listWorktrees(ctx Context) ([]worktree.Worktree, error) {
+	dir, err := worktreePaneDirFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := worktreeGitFn(dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository: %w", dir, err)
+	}
+	return worktree.Parse(out), nil
+}
+
+func findWorktree(ctx Context, path string) (worktree.Worktree, error) {
+	trees, err := listWorktrees(ctx)
+	if err != nil {
+		return worktree.Worktree{}, err
+	}
+	for _, w := range trees {
+		if filepath.Clean(w.Path) == filepath.Clean(path) {
+			return w, nil
+		}
+	}
+	return worktree.Worktree{}, fmt.Errorf("no worktree at %s", path)
+}
+
+func loadWorktreeMenu(Context) ([]Item, error) {
+	return menuItemsFromIDs([]string{"window", "session", "add", "remove"}), nil
+}
+
+func loadWorktreeOpenMenu(ctx Context) ([]Item, error) {
+	trees, err := listWorktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(trees) == 0 {
+		return nil, nil
+	}
+	return worktreeItems(trees, trees[0].Path), nil
+}
+
+// loadWorktreeRemoveMenu lists every worktree but the main one, stale ones
+// included.
+func loadWorktreeRemoveMenu(ctx Context) ([]Item, error) {
+	trees, err := listWorktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(trees) < 2 {
+		return nil, nil
+	}
+	return worktreeItems(trees[1:], ""), nil
+}
+
+// worktreeItems renders trees as a table keyed by path, marking the main
+// worktree and skipping bare entries, which have no files to open.
+func worktreeItems(trees []worktree.Worktree, main string) []Item {
+	home, _ := os.UserHomeDir()
+	var shown []worktree.Worktree
+	cells := [][]string{{"worktree", "path", "state"}}
+	for _, w := range trees {
+		if w.Bare {
+			continue
+		}
+		var state []string
+		if w.Path == main {
+			state = append(state, "main")
+		}
+		if w.Locked {
+			state = append(state, "locked")
+		}
+		if w.Prunable {
+			state = append(state, "stale")
+		}
+		shown = append(shown, w)
+		cells = append(cells, []string{w.Name(), abbreviateHome(w.Path, home), strings.Join(state, ", ")})
+	}
+	if len(shown) == 0 {
+		return nil
+	}
+	aligned := table.Format(cells, []table.Alignment{table.AlignLeft, table.AlignLeft, table.AlignLeft})
+	items := make([]Item, 0, len(aligned))
+	items = append(items, Item{Label: aligned[0], Header: true})
+	for i, label := range aligned[1:] {
+		items = append(items, Item{ID: shown[i].Path, Label: label})
+	}
+	return items
+}
+
+// worktreePreview shows the highlighted worktree's branch, upstream, dirty
+// state and last commit.
+func worktreePreview(_ Context, item Item) ([]string, error) {
+	dir := item.ID
+	if _, err := os.Stat(dir); err != nil {
+		return []string{dir, "", "stale: the directory is gone; removing it prunes the entry"}, nil
+	}
+	out, err := worktreeGitFn(dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return nil, err
+	}
+	status := worktree.ParseStatus(out)
+	lines := []string{dir, "branch:   " + status.Branch}
+	if status.Upstream != "" {
+		lines = append(lines, fmt.Sprintf("upstream: %s (+%d -%d)", status.Upstream, status.Ahead, status.Behind))
+	}
+	lines = append(lines, "status:   "+status.String())
+	if out, err := worktreeGitFn(dir, "log", "-1", "--format=%h %s (%cr)"); err == nil {
+		if commit := strings.TrimSpace(string(out)); commit != "" {
+			lines = append(lines, "commit:   "+commit)
+		}
+	}
+	return lines, nil
+}
+
+// WorktreeWindowAction selects the current session's window already in the
+// worktree, or opens a new one there named after its branch.
+func WorktreeWindowAction(ctx Context, item Item) tea.Cmd {
+	dir := strings.TrimSpace(item.ID)
+	if dir == "" {
+		return failCmd("no worktree selected")
+	}
+	return func() tea.Msg {
+		w, err := findWorktree(ctx, dir)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if _, err := os.Stat(dir); err != nil {
+			return ActionResult{Err: fmt.Errorf("worktree %s is gone", dir)}
+		}
+		session := strings.TrimSpace(ctx.Current)
+		out, err := tmuxOutput(ctx.SocketPath, "list-windows", "-t", session, "-F", "#{window_id}\t#{pane_current_path}")
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+			id, path, _ := strings.Cut(line, "\t")
+			if path != "" && filepath.Clean(path) == filepath.Clean(dir) {
+				events.Worktree.Open(dir, "window", false)
+				if _, err := tmuxOutput(ctx.SocketPath, "select-window", "-t", id); err != nil {
+					return ActionResult{Err: err}
+				}
+				return ActionResult{Info: fmt.Sprintf("Switched to %s", w.Name())}
+			}
+		}
+		events.Worktree.Open(dir, "window", true)
+		if _, err := tmuxOutput(ctx.SocketPath, "new-window", "-t", session+":", "-c", dir, "-n", w.Name()); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Opened %s in a new window", w.Name())}
+	}
+}
+
+// WorktreeSessionAction switches to the session started in the worktree,
+// creating one named after its directory when there is none.
+func WorktreeSessionAction(ctx Context, item Item) tea.Cmd {
+	dir := strings.TrimSpace(item.ID)
+	if dir == "" {
+		return failCmd("no worktree selected")
+	}
+	return func() tea.Msg {
+		if _, err := os.Stat(dir); err != nil {
+			return ActionResult{Err: fmt.Errorf("worktree %s is gone", dir)}
+		}
+		name, created, err := openDirSession(ctx, dir, project.SessionName(dir))
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		events.Worktree.Open(dir, "session", created)
+		if created {
+			return ActionResult{Info: fmt.Sprintf("Created %s in %s", name, dir)}
+		}
+		return ActionResult{Info: fmt.Sprintf("Switched to %s", name)}
+	}
+}
+
+// WorktreeRemovePrompt builds the confirmation question for removing the
+// worktrees selected in item, along with a short pending label.
+func WorktreeRemovePrompt(item Item) (prompt, label string) {
+	paths := splitSelectionIDs(item.ID)
+	if len(paths) == 1 {
+		name := filepath.Base(paths[0])
+		return fmt.Sprintf("remove worktree %s?", name), "remove " + name
+	}
+	return fmt.Sprintf("remove %d worktrees?", len(paths)), fmt.Sprintf("remove %d worktrees", len(paths))
+}
+
+// WorktreeRemoveAction removes the selected worktrees. Stale entries, whose
+// directory is already gone, are pruned instead; git refuses to remove a
+// worktree with uncommitted changes or a lock, and that error is shown.
+func WorktreeRemoveAction(ctx Context, item Item) tea.Cmd {
+	paths := splitSelectionIDs(item.ID)
+	if len(paths) == 0 {
+		return failCmd("no worktree selected")
+	}
+	return func() tea.Msg {
+		trees, err := listWorktrees(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if len(trees) == 0 {
+			return ActionResult{Err: errors.New("no worktrees found")}
+		}
+		main := trees[0].Path
+		byPath := make(map[string]worktree.Worktree, len(trees))
+		for _, w := range trees[1:] {
+			byPath[filepath.Clean(w.Path)] = w
+		}
+		events.Worktree.Remove(paths)
+		prune := false
+		for _, path := range paths {
+			w, ok := byPath[filepath.Clean(path)]
+			if !ok {
+				return ActionResult{Err: fmt.Errorf("no removable worktree at %s", path)}
+			}
+			if w.Prunable {
+				prune = true
+				continue
+			}
+			if _, err := worktreeGitFn(main, "worktree", "remove", w.Path); err != nil {
+				return ActionResult{Err: err}
+			}
+		}
+		if prune {
+			if _, err := worktreeGitFn(main, "worktree", "prune"); err != nil {
+				return ActionResult{Err: err}
+			}
+		}
+		return ActionResult{Info: fmt.Sprintf("Removed %d worktree(s)", len(paths))}
+	}
+}
+
+// WorktreePrompt asks for the branch of a new worktree of the repository
+// whose main worktree is Main.
+type WorktreePrompt struct {
+	Context Context
+	Main    string
+}
+
+// WorktreeAddAction opens the new-worktree form for the current pane's
+// repository.
+func WorktreeAddAction(ctx Context, _ Item) tea.Cmd {
+	return func() tea.Msg {
+		trees, err := listWorktrees(ctx)
+		if err != nil {
+			return ActionResult{Err: err}
+		}
+		if len(trees) == 0 {
+			return ActionResult{Err: errors.New("no worktrees found")}
+		}
+		return WorktreePrompt{Context: ctx, Main: trees[0].Path}
+	}
+}
+
+// WorktreeAddCommand creates a worktree for branch at path. An existing
+// local branch, or a remote one git can track, is checked out; any other
+// name becomes a new branch from the main worktree's HEAD.
+func WorktreeAddCommand(ctx Context, main, branch, path string) tea.Cmd {
+	return func() tea.Msg {
+		args := []string{"worktree", "add", path, branch}
+		if !gitBranchKnown(main, branch) {
+			args = []string{"worktree", "add", "-b", branch, path}
+		}
+		events.Worktree.Add(main, branch, path)
+		if _, err := worktreeGitFn(main, args...); err != nil {
+			return ActionResult{Err: err}
+		}
+		return ActionResult{Info: fmt.Sprintf("Added worktree %s at %s", branch, path)}
+	}
+}
+
+// gitBranchKnown reports whether branch exists locally or on a remote.
+func gitBranchKnown(dir, branch string) bool {
+	if _, err := worktreeGitFn(dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return true
+	}
+	out, err := worktreeGitFn(dir, "for-each-ref", "--format=%(refname)", "refs/remotes/*/"+branch)
+	return err == nil && strings.TrimSpace(string(out)) != ""
+}
+
+// WorktreeForm asks for the branch of a new worktree and shows where it
+// will be created.
+type WorktreeForm struct {
+	input textinput.Model
+	ctx   Context
+	main  string
+}
+
+func NewWorktreeForm(prompt WorktreePrompt) *WorktreeForm {
+	ti := textinput.New()
+	styleFormInput(&ti)
+	ti.Placeholder = "branch name"
+	ti.CharLimit = 128
+	ti.SetWidth(40)
+	ti.Focus()
+	return &WorktreeForm{input: ti, ctx: prompt.Context, main: prompt.Main}
+}
+
+func (f *WorktreeForm) Context() Context    { return f.ctx }
+func (f *WorktreeForm) Target() string      { return f.main }
+func (f *WorktreeForm) Value() string       { return strings.TrimSpace(f.input.Value()) }
+func (f *WorktreeForm) InputView() string   { return f.input.View() }
+func (f *WorktreeForm) Cursor() *tea.Cursor { return f.input.Cursor() }
+func (f *WorktreeForm) FocusCmd() tea.Cmd   { return f.input.Focus() }
+func (f *WorktreeForm) ActionID() string    { return "worktree:add" }
+func (f *WorktreeForm) PendingLabel() string {
+	return "worktree " + f.Value()
+}
+
+func (f *WorktreeForm) Title() string {
+	return "New worktree of " + filepath.Base(f.main)
+}
+
+// Path is where the worktree for the entered branch will be created.
+func (f *WorktreeForm) Path() string {
+	return worktree.DefaultPath(f.main, f.Value())
+}
+
+// Help shows where the worktree will go, or why it can't be created.
+func (f *WorktreeForm) Help() string {
+	if f.Value() == "" {
+		return "Press Enter to create. Esc to cancel."
+	}
+	if err := f.validate(); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("Creates %s. Press Enter to create. Esc to cancel.", f.Path())
+}
+
+func (f *WorktreeForm) validate() error {
+	if err := worktree.CheckBranch(f.Value()); err != nil {
+		return err
+	}
+	if _, err := os.Stat(f.Path()); err == nil {
+		return fmt.Errorf("%s already exists", f.Path())
+	}
+	return nil
+}
+
+func (f *WorktreeForm) Update(msg tea.Msg) (tea.Cmd, bool, bool) {
+	if m, ok := msg.(tea.KeyPressMsg); ok {
+		switch m.String() {
+		case "ctrl+u":
+			if f.input.Value() != "" {
+				f.input.SetValue("")
+				f.input.CursorStart()
+			}
+			return nil, false, false
+		case "esc":
+			return nil, false, true
+		case "enter":
+			if f.Value() == "" {
+				return nil, false, true
+			}
+			if f.validate() != nil {
+				return nil, false, false
+			}
+			return WorktreeAddCommand(f.ctx, f.main, f.Value(), f.Path()), true, false
+		}
+	}
+	updated, cmd := f.input.Update(msg)
+	f.input = updated
+	return cmd, false, false
+}
@@ -0,0 +1,193 @@
+package menu
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// stubWorktrees runs every git call through fn, with the current pane in
+// dir, and records the calls as "dir: args".
+func stubWorktrees(t *testing.T, dir string, fn func(dir string, args ...string) ([]byte, error)) *[]string {
+	t.Helper()
+	var calls []string
+	t.Cleanup(withPaneStub(&worktreePaneDirFn, func(Context) (string, error) { return dir, nil }))
+	t.Cleanup(withPaneStub(&worktreeGitFn, func(dir string, args ...string) ([]byte, error) {
+		calls = append(calls, dir+": "+strings.Join(args, " "))
+		return fn(dir, args...)
+	}))
+	return &calls
+}
+
+func worktreeListing(main, feature, stale string) []byte {
+	return []byte("worktree " + main + "\nHEAD 1111111\nbranch refs/heads/main\n\n" +
+		"worktree " + feature + "\nHEAD 2222222\nbranch refs/heads/feature/x\n\n" +
+		"worktree " + stale + "\nHEAD 3333333\nbranch refs/heads/old\nprunable gitdir file points to non-existent location\n")
+}
+
+func TestLoadWorktreeMenus(t *testing.T) {
+	stubWorktrees(t, "/src/app", func(string, ...string) ([]byte, error) {
+		return worktreeListing("/src/app", "/src/app-feature-x", "/tmp/gone"), nil
+	})
+
+	items, err := loadWorktreeOpenMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 || !items[0].Header || items[1].ID != "/src/app" || !strings.Contains(items[1].Label, "main") {
+		t.Fatalf("unexpected open items %#v", items)
+	}
+	items, err = loadWorktreeRemoveMenu(Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[1].ID != "/src/app-feature-x" || !strings.Contains(items[2].Label, "stale") {
+		t.Fatalf("unexpected remove items %#v", items)
+	}
+}
+
+func TestLoadWorktreeMenuOutsideRepository(t *testing.T) {
+	stubWorktrees(t, "/tmp", func(string, ...string) ([]byte, error) {
+		return nil, errors.New("git worktree list --porcelain: fatal: not a git repository")
+	})
+	if _, err := loadWorktreeOpenMenu(Context{}); err == nil || !strings.Contains(err.Error(), "/tmp is not in a git repository") {
+		t.Fatalf("expected a not-a-repository error, got %v", err)
+	}
+}
+
+func TestWorktreePreview(t *testing.T) {
+	dir := t.TempDir()
+	stubWorktrees(t, dir, func(_ string, args ...string) ([]byte, error) {
+		if args[0] == "log" {
+			return []byte("abc1234 fix things (2 hours ago)\n"), nil
+		}
+		return []byte("# branch.head feature/x\n# branch.upstream origin/feature/x\n# branch.ab +1 -0\n? new.go\n"), nil
+	})
+	lines, err := worktreePreview(Context{}, Item{ID: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{dir, "branch:   feature/x", "upstream: origin/feature/x (+1 -0)", "status:   1 untracked", "commit:   abc1234 fix things (2 hours ago)"}
+	if !slices.Equal(lines, want) {
+		t.Fatalf("preview = %q, want %q", lines, want)
+	}
+	lines, _ = worktreePreview(Context{}, Item{ID: filepath.Join(dir, "gone")})
+	if !strings.Contains(strings.Join(lines, "\n"), "stale") {
+		t.Fatalf("expected a stale note, got %q", lines)
+	}
+}
+
+func TestWorktreeWindowAction(t *testing.T) {
+	root := t.TempDir()
+	feature := filepath.Join(root, "app-feature-x")
+	if err := os.Mkdir(feature, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stubWorktrees(t, root, func(string, ...string) ([]byte, error) {
+		return worktreeListing(root, feature, "/tmp/gone"), nil
+	})
+	var tmuxCalls []string
+	windows := "@1\t" + root + "\n"
+	t.Cleanup(withPaneStub(&runCommandOutputFn, func(_ string, args ...string) ([]byte, error) {
+		tmuxCalls = append(tmuxCalls, strings.Join(args, " "))
+		if args[0] == "list-windows" {
+			return []byte(windows), nil
+		}
+		return nil, nil
+	}))
+	ctx := Context{Current: "work"}
+
+	res := WorktreeWindowAction(ctx, Item{ID: feature})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if want := "new-window -t work: -c " + feature + " -n feature/x"; !slices.Contains(tmuxCalls, want) {
+		t.Fatalf("missing %q in %q", want, tmuxCalls)
+	}
+
+	windows += "@2\t" + feature + "\n"
+	res = WorktreeWindowAction(ctx, Item{ID: feature})().(ActionResult)
+	if res.Err != nil || !slices.Contains(tmuxCalls, "select-window -t @2") {
+		t.Fatalf("expected the existing window to be selected, got %#v %q", res, tmuxCalls)
+	}
+}
+
+func TestWorktreeRemoveActionRemovesAndPrunes(t *testing.T) {
+	calls := stubWorktrees(t, "/src/app", func(_ string, args ...string) ([]byte, error) {
+		if args[1] == "list" {
+			return worktreeListing("/src/app", "/src/app-feature-x", "/tmp/gone"), nil
+		}
+		return nil, nil
+	})
+	res := WorktreeRemoveAction(Context{}, Item{ID: "/src/app-feature-x\n/tmp/gone"})().(ActionResult)
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	for _, want := range []string{"/src/app: worktree remove /src/app-feature-x", "/src/app: worktree prune"} {
+		if !slices.Contains(*calls, want) {
+			t.Errorf("missing git call %q in %q", want, *calls)
+		}
+	}
+	res = WorktreeRemoveAction(Context{}, Item{ID: "/src/app"})().(ActionResult)
+	if res.Err == nil {
+		t.Fatal("the main worktree must not be removable")
+	}
+}
+
+func TestWorktreeAddCommand(t *testing.T) {
+	calls := stubWorktrees(t, "/src/app", func(_ string, args ...string) ([]byte, error) {
+		switch {
+		case args[0] == "rev-parse" && strings.HasSuffix(args[3], "/existing"):
+			return []byte("abc\n"), nil
+		case args[0] == "rev-parse":
+			return nil, errors.New("exit status 1")
+		}
+		return nil, nil
+	})
+	WorktreeAddCommand(Context{}, "/src/app", "existing", "/src/app-existing")()
+	WorktreeAddCommand(Context{}, "/src/app", "feature/new", "/src/app-feature-new")()
+	for _, want := range []string{
+		"/src/app: worktree add /src/app-existing existing",
+		"/src/app: worktree add -b feature/new /src/app-feature-new",
+	} {
+		if !slices.Contains(*calls, want) {
+			t.Errorf("missing git call %q in %q", want, *calls)
+		}
+	}
+}
+
+func TestWorktreeFormValidates(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "app")
+	if err := os.MkdirAll(filepath.Join(root, "app-taken"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	form := NewWorktreeForm(WorktreePrompt{Main: main})
+	typeText := func(s string) {
+		form.Update(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+		for _, r := range s {
+			form.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+		}
+	}
+
+	typeText("taken")
+	if !strings.Contains(form.Help(), "already exists") {
+		t.Fatalf("expected a clash with the existing dir, got %q", form.Help())
+	}
+	if _, done, _ := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter}); done {
+		t.Fatal("enter must not submit an invalid branch")
+	}
+	typeText("feature/x")
+	if want := filepath.Join(root, "app-feature-x"); form.Path() != want || !strings.Contains(form.Help(), want) {
+		t.Fatalf("unexpected path %q / help %q", form.Path(), form.Help())
+	}
+	cmd, done, cancel := form.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if !done || cancel || cmd == nil {
+		t.Fatalf("expected submit, got done=%v cancel=%v", done, cancel)
+	}
+}
@@ -0,0 +1,97 @@
+// Package panelog supports logging pane output with pipe-pane: the filter
+// the binary runs as the pipe command to strip escape sequences, the shell
+// command handed to pipe-pane, and reading back the end of a log. Running
+// tmux is the menu package's job, so there are no tmux, bubbletea, or menu
+// imports here.
+package panelog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/atomicstack/tmux-popup-control/internal/shquote"
+)
+
+// FilterCommand is the subcommand that runs Filter over stdin.
+const FilterCommand = "log-filter"
+
+// tailChunk bounds how much of the end of a log Tail reads.
+const tailChunk = 64 << 10
+
+// Filter copies r to w a line at a time, dropping ANSI escape sequences and
+// carriage returns. Each line is written as soon as it is complete, so the
+// log keeps up with the pane; a final unterminated line is written at EOF.
+func Filter(r io.Reader, w io.Writer) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			clean := ansi.Strip(string(line))
+			clean = strings.ReplaceAll(clean, "\r", "")
+			if _, werr := io.WriteString(w, clean); werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// PipeCommand returns the pipe-pane command that appends a pane's output to
+// path, through binary's log filter when strip is set.
+func PipeCommand(binary, path string, strip bool) string {
+	if strip {
+		return shquote.JoinCommand(binary, FilterCommand) + " >> " + shquote.Quote(path)
+	}
+	return "cat >> " + shquote.Quote(path)
+}
+
+// Create makes sure the log at path exists, owner-only, before the pipe
+// command's redirection opens it with the shell's umask.
+func Create(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Tail returns up to n lines from the end of the file at path.
+func Tail(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := max(info.Size()-tailChunk, 0)
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if offset > 0 {
+		// drop the partial first line.
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			buf = buf[i+1:]
+		}
+	}
+	text := strings.TrimRight(string(buf), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+	return lines[max(len(lines)-n, 0):], nil
+}
@@ -0,0 +1,61 @@
+package panelog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	in := "\x1b[1;32muser@host\x1b[0m:~$ ls\r\n\x1b]0;title\x07file.txt\r\nprompt$ "
+	var out strings.Builder
+	if err := Filter(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "user@host:~$ ls\nfile.txt\nprompt$ "; out.String() != want {
+		t.Fatalf("Filter = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPipeCommand(t *testing.T) {
+	if got := PipeCommand("/opt/bin/tpc", "/logs/it's.log", true); got != `'/opt/bin/tpc' 'log-filter' >> '/logs/it'\''s.log'` {
+		t.Fatalf("PipeCommand = %s", got)
+	}
+	if got := PipeCommand("/opt/bin/tpc", "/logs/a.log", false); got != `cat >> '/logs/a.log'` {
+		t.Fatalf("PipeCommand = %s", got)
+	}
+}
+
+func TestCreateAndTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pane.log")
+	if err := Create(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if lines, err := Tail(path, 5); err != nil || lines != nil {
+		t.Fatalf("Tail of an empty log = %q %v", lines, err)
+	}
+	var b strings.Builder
+	for i := range 20000 {
+		b.WriteString(strings.Repeat("x", i%7) + "\n")
+	}
+	b.WriteString("second to last\nlast\n")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := Tail(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(lines, []string{"second to last", "last"}) {
+		t.Fatalf("Tail = %q", lines)
+	}
+}
@@ -0,0 +1,266 @@
+// Package process reads the /proc filesystem to build the process trees that
+// hang off tmux panes, and delivers signals to them. it is consumer-agnostic:
+// no tmux, bubbletea, or menu imports.
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrUnavailable is returned when the proc filesystem cannot be read, e.g. on
+// platforms without /proc.
+var ErrUnavailable = errors.New("process: /proc is not available")
+
+// procRoot is the mount point of the proc filesystem. it is a package var so
+// tests can point the parser at a fixture tree.
+var procRoot = "/proc"
+
+// killFn delivers a signal to a pid. stubbed in tests so nothing real is
+// signalled.
+var killFn = syscall.Kill
+
+// Process is a single entry parsed from /proc/<pid>/stat and cmdline.
+type Process struct {
+	PID     int
+	PPID    int
+	State   string // single-letter state code, e.g. "S" or "R"
+	Comm    string // executable name as reported by the kernel
+	Args    string // full command line; "[comm]" for kernel threads
+	Threads int
+	RSS     int64 // resident set size in bytes
+}
+
+// Table maps pids to their parsed process entries.
+type Table map[int]Process
+
+// Snapshot reads every numeric entry under /proc. processes that exit while
+// the scan is in progress are skipped silently.
+func Snapshot() (Table, error) {
+	return snapshotFrom(procRoot)
+}
+
+func snapshotFrom(root string) (Table, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	table := make(Table, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid <= 0 {
+			continue
+		}
+		proc, err := readProcess(root, pid)
+		if err != nil {
+			continue
+		}
+		table[pid] = proc
+	}
+	if len(table) == 0 {
+		return nil, ErrUnavailable
+	}
+	return table, nil
+}
+
+func readProcess(root string, pid int) (Process, error) {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, err
+	}
+	proc, err := parseStat(string(stat))
+	if err != nil {
+		return Process{}, err
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		proc.Args = parseCmdline(cmdline)
+	}
+	if proc.Args == "" {
+		proc.Args = "[" + proc.Comm + "]"
+	}
+	return proc, nil
+}
+
+// parseStat parses the contents of /proc/<pid>/stat. the comm field is
+// wrapped in parentheses and may itself contain spaces or ')', so the fields
+// after it are located from the last ')' in the line.
+func parseStat(data string) (Process, error) {
+	open := strings.IndexByte(data, '(')
+	end := strings.LastIndexByte(data, ')')
+	if open < 0 || end < open {
+		return Process{}, fmt.Errorf("malformed stat line %q", data)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(data[:open]))
+	if err != nil {
+		return Process{}, fmt.Errorf("malformed stat pid: %w", err)
+	}
+	// fields after comm, starting at field 3 (state).
+	rest := strings.Fields(data[end+1:])
+	if len(rest) < 22 {
+		return Process{}, fmt.Errorf("short stat line for pid %d", pid)
+	}
+	ppid, err := strconv.Atoi(rest[1])
+	if err != nil {
+		return Process{}, fmt.Errorf("malformed stat ppid for pid %d: %w", pid, err)
+	}
+	threads, _ := strconv.Atoi(rest[17])
+	rssPages, _ := strconv.ParseInt(rest[21], 10, 64)
+	return Process{
+		PID:     pid,
+		PPID:    ppid,
+		State:   rest[0],
+		Comm:    data[open+1 : end],
+		Threads: threads,
+		RSS:     rssPages * int64(os.Getpagesize()),
+	}, nil
+}
+
+// parseCmdline joins the NUL-separated argv from /proc/<pid>/cmdline.
+func parseCmdline(data []byte) string {
+	trimmed := strings.TrimRight(string(data), "\x00")
+	if trimmed == "" {
+		return ""
+	}
+	return strings.Join(strings.Split(trimmed, "\x00"), " ")
+}
+
+// Cwd returns the working directory of pid, or "" when it cannot be read
+// (typically because the process belongs to another user).
+func Cwd(pid int) string {
+	dir, err := os.Readlink(filepath.Join(procRoot, strconv.Itoa(pid), "cwd"))
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// Environ returns the environment pid was started with. changes a process
+// makes to its own environment afterwards are not visible here.
+func Environ(pid int) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return nil, err
+	}
+	return parseEnviron(data), nil
+}
+
+// parseEnviron splits the NUL-separated NAME=value pairs of
+// /proc/<pid>/environ.
+func parseEnviron(data []byte) map[string]string {
+	env := make(map[string]string)
+	for entry := range strings.SplitSeq(string(data), "\x00") {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			continue
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// StateName expands a single-letter state code into a readable word.
+func StateName(state string) string {
+	switch state {
+	case "R":
+		return "running"
+	case "S":
+		return "sleeping"
+	case "D":
+		return "disk sleep"
+	case "T":
+		return "stopped"
+	case "t":
+		return "tracing stop"
+	case "Z":
+		return "zombie"
+	case "X":
+		return "dead"
+	case "I":
+		return "idle"
+	default:
+		return state
+	}
+}
+
+// Node is one row of a rendered process tree.
+type Node struct {
+	Process
+	Depth  int
+	Prefix string // box-drawing guide, e.g. "│  └─ "
+}
+
+// Tree walks the descendants of root depth-first, children ordered by pid,
+// and returns them as rows with box-drawing prefixes. the root itself is the
+// first row (depth 0, empty prefix). it returns nil when root is not present.
+func (t Table) Tree(root int) []Node {
+	proc, ok := t[root]
+	if !ok {
+		return nil
+	}
+	children := t.childIndex()
+	nodes := []Node{{Process: proc}}
+	var walk func(pid, depth int, guide string)
+	walk = func(pid, depth int, guide string) {
+		kids := children[pid]
+		for i, kid := range kids {
+			last := i == len(kids)-1
+			branch, next := "├─ ", "│  "
+			if last {
+				branch, next = "└─ ", "   "
+			}
+			nodes = append(nodes, Node{Process: t[kid], Depth: depth, Prefix: guide + branch})
+			walk(kid, depth+1, guide+next)
+		}
+	}
+	walk(root, 1, "")
+	return nodes
+}
+
+// Contains reports whether pid is root or one of its descendants.
+func (t Table) Contains(root, pid int) bool {
+	seen := make(map[int]bool)
+	for cur := pid; cur > 0 && !seen[cur]; {
+		if cur == root {
+			return true
+		}
+		seen[cur] = true
+		proc, ok := t[cur]
+		if !ok {
+			return false
+		}
+		cur = proc.PPID
+	}
+	return false
+}
+
+func (t Table) childIndex() map[int][]int {
+	children := make(map[int][]int, len(t))
+	for pid, proc := range t {
+		if proc.PPID == pid {
+			continue
+		}
+		children[proc.PPID] = append(children[proc.PPID], pid)
+	}
+	for _, kids := range children {
+		slices.Sort(kids)
+	}
+	return children
+}
+
+// Signal sends sig to pid.
+func Signal(pid int, sig syscall.Signal) error {
+	if pid <= 1 {
+		return fmt.Errorf("refusing to signal pid %d", pid)
+	}
+	if err := killFn(pid, sig); err != nil {
+		return fmt.Errorf("signalling pid %d: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package process
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// statLine builds a /proc/<pid>/stat line with enough trailing fields for
+// parseStat; threads and rss land in fields 20 and 24.
+func statLine(pid int, comm, state string, ppid int) string {
+	fields := []string{state, strconv.Itoa(ppid)}
+	for i := 0; i < 20; i++ {
+		switch i {
+		case 15:
+			fields = append(fields, "2") // num_threads
+		case 19:
+			fields = append(fields, "10") // rss pages
+		default:
+			fields = append(fields, "0")
+		}
+	}
+	return strconv.Itoa(pid) + " (" + comm + ") " + strings.Join(fields, " ") + "\n"
+}
+
+// writeProc lays out a fake /proc entry under root.
+func writeProc(t *testing.T, root string, pid int, comm string, ppid int, argv ...string) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(statLine(pid, comm, "S", ppid)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmdline := ""
+	if len(argv) > 0 {
+		cmdline = strings.Join(argv, "\x00") + "\x00"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseStatHandlesParensInComm(t *testing.T) {
+	proc, err := parseStat(statLine(42, "weird) name (x", "R", 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proc.PID != 42 || proc.PPID != 7 {
+		t.Fatalf("pid/ppid = %d/%d, want 42/7", proc.PID, proc.PPID)
+	}
+	if proc.Comm != "weird) name (x" {
+		t.Fatalf("comm = %q", proc.Comm)
+	}
+	if proc.State != "R" {
+		t.Fatalf("state = %q, want R", proc.State)
+	}
+	if proc.Threads != 2 {
+		t.Fatalf("threads = %d, want 2", proc.Threads)
+	}
+	if proc.RSS != 10*int64(os.Getpagesize()) {
+		t.Fatalf("rss = %d", proc.RSS)
+	}
+}
+
+func TestParseStatRejectsMalformed(t *testing.T) {
+	for _, line := range []string{"", "12 no-parens S 1", "12 (short) S 1 2 3"} {
+		if _, err := parseStat(line); err == nil {
+			t.Fatalf("expected error for %q", line)
+		}
+	}
+}
+
+func TestSnapshotAndTree(t *testing.T) {
+	root := t.TempDir()
+	writeProc(t, root, 100, "bash", 1, "-bash")
+	writeProc(t, root, 120, "make", 100, "make", "-j8")
+	writeProc(t, root, 130, "cc1", 120, "cc1", "foo.c")
+	writeProc(t, root, 125, "cc1", 120, "cc1", "bar.c")
+	writeProc(t, root, 200, "kworker", 2)
+	if err := os.WriteFile(filepath.Join(root, "self"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := snapshotFrom(root)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(table) != 5 {
+		t.Fatalf("table size = %d, want 5", len(table))
+	}
+	if got := table[200].Args; got != "[kworker]" {
+		t.Fatalf("kernel thread args = %q, want [kworker]", got)
+	}
+	if got := table[120].Args; got != "make -j8" {
+		t.Fatalf("args = %q, want %q", got, "make -j8")
+	}
+
+	nodes := table.Tree(100)
+	var got []string
+	for _, n := range nodes {
+		got = append(got, n.Prefix+strconv.Itoa(n.PID))
+	}
+	want := []string{"100", "└─ 120", "   ├─ 125", "   └─ 130"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("tree = %q, want %q", got, want)
+	}
+	if nodes[2].Depth != 2 {
+		t.Fatalf("depth = %d, want 2", nodes[2].Depth)
+	}
+
+	if !table.Contains(100, 130) {
+		t.Fatal("expected 130 to descend from 100")
+	}
+	if table.Contains(100, 200) {
+		t.Fatal("did not expect 200 to descend from 100")
+	}
+	if table.Tree(999) != nil {
+		t.Fatal("expected nil tree for unknown root")
+	}
+}
+
+func TestSnapshotMissingRoot(t *testing.T) {
+	_, err := snapshotFrom(filepath.Join(t.TempDir(), "missing"))
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("err = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestSignal(t *testing.T) {
+	orig := killFn
+	t.Cleanup(func() { killFn = orig })
+
+	var gotPID int
+	var gotSig syscall.Signal
+	killFn = func(pid int, sig syscall.Signal) error {
+		gotPID, gotSig = pid, sig
+		return nil
+	}
+	if err := Signal(4321, syscall.SIGTERM); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPID != 4321 || gotSig != syscall.SIGTERM {
+		t.Fatalf("kill(%d, %v), want kill(4321, SIGTERM)", gotPID, gotSig)
+	}
+
+	killFn = func(int, syscall.Signal) error { return syscall.ESRCH }
+	if err := Signal(4321, syscall.SIGKILL); !errors.Is(err, syscall.ESRCH) {
+		t.Fatalf("err = %v, want ESRCH", err)
+	}
+	if err := Signal(1, syscall.SIGKILL); err == nil {
+		t.Fatal("expected refusal to signal pid 1")
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	root := t.TempDir()
+	orig := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = orig })
+
+	writeProc(t, root, 100, "bash", 1, "-bash")
+	environ := "SSH_AUTH_SOCK=/tmp/ssh-old/agent\x00EMPTY=\x00EQ=a=b\x00junk\x00"
+	if err := os.WriteFile(filepath.Join(root, "100", "environ"), []byte(environ), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env, err := Environ(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 3 || env["SSH_AUTH_SOCK"] != "/tmp/ssh-old/agent" || env["EQ"] != "a=b" {
+		t.Fatalf("env = %#v", env)
+	}
+	if v, ok := env["EMPTY"]; !ok || v != "" {
+		t.Fatalf("expected EMPTY set to the empty string, got %q %v", v, ok)
+	}
+	if _, err := Environ(999); err == nil {
+		t.Fatal("expected an error for a missing process")
+	}
+}
@@ -0,0 +1,260 @@
+// Package project finds project directories to open as tmux sessions, the
+// way sesh and tmux-sessionizer do: it scans configured roots down to a
+// fixed depth, stopping at git repositories, and can merge in directories
+// from zoxide's database. it only finds and describes directories: creating
+// or switching sessions is the menu package's job, so there are no tmux,
+// bubbletea, or menu imports here.
+package project
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Source says where a project directory was found.
+type Source string
+
+const (
+	SourceScan   Source = "scan"
+	SourceZoxide Source = "zoxide"
+)
+
+// Project is one directory that can be opened as a session.
+type Project struct {
+	Path   string
+	Source Source
+}
+
+// Name returns the session name for the project: its base name, with the
+// characters tmux refuses in session names replaced.
+func (p Project) Name() string {
+	return SessionName(p.Path)
+}
+
+// SessionName turns a directory into a session name.
+func SessionName(dir string) string {
+	base := filepath.Base(filepath.Clean(dir))
+	if base == "/" || base == "." {
+		return "root"
+	}
+	return strings.NewReplacer(".", "_", ":", "_").Replace(base)
+}
+
+// DefaultDepth is how many levels below each root are scanned.
+const DefaultDepth = 2
+
+// Settings configure where projects are looked for.
+type Settings struct {
+	Roots  []string
+	Depth  int
+	Zoxide string // path of zoxide's database; empty when the import is off
+}
+
+const (
+	envRoots  = "TMUX_POPUP_CONTROL_PROJECT_ROOTS"
+	envDepth  = "TMUX_POPUP_CONTROL_PROJECT_DEPTH"
+	envZoxide = "TMUX_POPUP_CONTROL_PROJECT_ZOXIDE"
+	optRoots  = "@tmux-popup-control-project-roots"
+	optDepth  = "@tmux-popup-control-project-depth"
+	optZoxide = "@tmux-popup-control-project-zoxide"
+)
+
+// defaultRoots are scanned when no roots are configured; missing ones are
+// skipped.
+var defaultRoots = []string{"~/src", "~/code", "~/projects"}
+
+// ResolveSettings reads each setting from the environment first, then the
+// tmux option, then the default. Roots are a colon-separated list that may
+// use ~ and environment variables.
+func ResolveSettings(getenv, option func(string) string) Settings {
+	lookup := func(envKey, optKey string) string {
+		if v := strings.TrimSpace(getenv(envKey)); v != "" {
+			return v
+		}
+		return strings.TrimSpace(option(optKey))
+	}
+	home := strings.TrimSpace(getenv("HOME"))
+	s := Settings{Depth: DefaultDepth}
+	roots := defaultRoots
+	if v := lookup(envRoots, optRoots); v != "" {
+		roots = filepath.SplitList(v)
+	}
+	for _, root := range roots {
+		if root = expandHome(os.Expand(strings.TrimSpace(root), getenv), home); root != "" && !slices.Contains(s.Roots, root) {
+			s.Roots = append(s.Roots, root)
+		}
+	}
+	if n, err := strconv.Atoi(lookup(envDepth, optDepth)); err == nil && n > 0 {
+		s.Depth = n
+	}
+	if parseBool(lookup(envZoxide, optZoxide)) {
+		s.Zoxide = ZoxideDB(getenv)
+	}
+	return s
+}
+
+func parseBool(s string) bool {
+	switch strings.ToLower(s) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
+
+func expandHome(dir, home string) string {
+	if home == "" {
+		return dir
+	}
+	if dir == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return dir
+}
+
+// List returns the projects for s: zoxide's directories first, best ranked
+// first, then the scanned ones in path order. A directory found both ways is
+// listed once. Errors from a root or the database are joined without
+// dropping what the others found.
+func List(s Settings) ([]Project, error) {
+	var (
+		out  []Project
+		errs []error
+		seen = make(map[string]bool)
+	)
+	add := func(path string, source Source) {
+		path = filepath.Clean(path)
+		if !seen[path] {
+			seen[path] = true
+			out = append(out, Project{Path: path, Source: source})
+		}
+	}
+	if s.Zoxide != "" {
+		dirs, err := ReadZoxide(s.Zoxide)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, dir := range dirs {
+			// zoxide keeps directories until they age out, deleted or not.
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				add(dir, SourceZoxide)
+			}
+		}
+	}
+	var scanned []string
+	for _, root := range s.Roots {
+		dirs, err := Scan(root, s.Depth)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		scanned = append(scanned, dirs...)
+	}
+	slices.Sort(scanned)
+	for _, dir := range scanned {
+		add(dir, SourceScan)
+	}
+	return out, errors.Join(errs...)
+}
+
+// Scan returns every directory below root down to depth levels. A git
+// repository is a project of its own, so its subdirectories are not listed;
+// hidden directories are skipped. A missing root yields nothing.
+func Scan(root string, depth int) ([]string, error) {
+	var out []string
+	var walk func(dir string, level int) error
+	walk = func(dir string, level int) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			out = append(out, path)
+			if level < depth && !IsGitRepo(path) {
+				// an unreadable subdirectory is still listed, just not
+				// descended into.
+				_ = walk(path, level+1)
+			}
+		}
+		return nil
+	}
+	if err := walk(root, 1); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return out, err
+	}
+	return out, nil
+}
+
+// IsGitRepo reports whether dir is the top of a git work tree. .git is a
+// file in worktrees and submodules.
+func IsGitRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// Branch returns the checked-out branch of the repository at dir, or the
+// short commit when HEAD is detached. It reads .git directly so previews
+// don't fork git; empty when dir is not a repository.
+func Branch(dir string) string {
+	gitDir := filepath.Join(dir, ".git")
+	if info, err := os.Stat(gitDir); err != nil {
+		return ""
+	} else if !info.IsDir() {
+		// worktrees and submodules point at the real git dir.
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return ""
+		}
+		ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return ""
+		}
+		if !filepath.IsAbs(ref) {
+			ref = filepath.Join(dir, ref)
+		}
+		gitDir = ref
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return head
+}
+
+var readmeNames = []string{"README.md", "README", "README.markdown", "README.rst", "README.txt", "readme.md"}
+
+// ReadmeHead returns up to n lines from the top of dir's README, if it has
+// one.
+func ReadmeHead(dir string, n int) []string {
+	for _, name := range readmeNames {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		var lines []string
+		scanner := bufio.NewScanner(f)
+		for len(lines) < n && scanner.Scan() {
+			lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+		}
+		return lines
+	}
+	return nil
+}
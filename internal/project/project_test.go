@@ -0,0 +1,188 @@
+package project
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mkdirs(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanStopsAtDepthAndGitRepos(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root,
+		"work/api/.git",
+		"work/api/internal",
+		"work/web/src/deep",
+		"dotfiles",
+		".cache/thing",
+	)
+	got, err := Scan(root, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(root, "dotfiles"),
+		filepath.Join(root, "work"),
+		filepath.Join(root, "work/api"),
+		filepath.Join(root, "work/web"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Scan = %q, want %q", got, want)
+	}
+	if got, err := Scan(filepath.Join(root, "missing"), 2); err != nil || got != nil {
+		t.Fatalf("missing root should yield nothing, got %q %v", got, err)
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "WORK": "/work", "TMUX_POPUP_CONTROL_PROJECT_ZOXIDE": "on"}
+	opts := map[string]string{optRoots: "~/src:$WORK::~/src", optDepth: "3"}
+	s := ResolveSettings(func(k string) string { return env[k] }, func(k string) string { return opts[k] })
+	want := Settings{
+		Roots:  []string{"/home/me/src", "/work"},
+		Depth:  3,
+		Zoxide: "/home/me/.local/share/zoxide/db.zo",
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Fatalf("settings = %#v, want %#v", s, want)
+	}
+
+	home := func(k string) string {
+		if k == "HOME" {
+			return "/home/me"
+		}
+		return ""
+	}
+	s = ResolveSettings(home, func(string) string { return "" })
+	if s.Depth != DefaultDepth || s.Zoxide != "" || len(s.Roots) != len(defaultRoots) || s.Roots[0] != "/home/me/src" {
+		t.Fatalf("unexpected defaults %#v", s)
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	for dir, want := range map[string]string{
+		"/src/api":         "api",
+		"/src/example.com": "example_com",
+		"/src/a:b/":        "a_b",
+		"/":                "root",
+	} {
+		if got := SessionName(dir); got != want {
+			t.Errorf("SessionName(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestBranchAndReadme(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "repo/.git", "plain", "worktree")
+	write := func(path, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, path), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("repo/.git/HEAD", "ref: refs/heads/feature/x\n")
+	write("repo/README.md", "# repo\r\n\nline two\nline three\n")
+	write("worktree/.git", "gitdir: ../repo/.git\n")
+
+	if got := Branch(filepath.Join(root, "repo")); got != "feature/x" {
+		t.Fatalf("Branch = %q", got)
+	}
+	if got := Branch(filepath.Join(root, "worktree")); got != "feature/x" {
+		t.Fatalf("worktree Branch = %q", got)
+	}
+	if got := Branch(filepath.Join(root, "plain")); got != "" {
+		t.Fatalf("plain dir Branch = %q", got)
+	}
+	write("repo/.git/HEAD", "0123456789abcdef\n")
+	if got := Branch(filepath.Join(root, "repo")); got != "0123456" {
+		t.Fatalf("detached Branch = %q", got)
+	}
+	if got := ReadmeHead(filepath.Join(root, "repo"), 2); !reflect.DeepEqual(got, []string{"# repo", ""}) {
+		t.Fatalf("ReadmeHead = %q", got)
+	}
+	if got := ReadmeHead(filepath.Join(root, "plain"), 2); got != nil {
+		t.Fatalf("ReadmeHead without README = %q", got)
+	}
+}
+
+type zoxideEntry struct {
+	path  string
+	rank  float64
+	epoch int64
+}
+
+// zoxideDB encodes entries the way zoxide writes them.
+func zoxideDB(entries ...zoxideEntry) []byte {
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	_ = binary.Write(&buf, le, uint32(zoxideVersion))
+	_ = binary.Write(&buf, le, uint64(len(entries)))
+	for _, e := range entries {
+		_ = binary.Write(&buf, le, uint64(len(e.path)))
+		buf.WriteString(e.path)
+		_ = binary.Write(&buf, le, e.rank)
+		_ = binary.Write(&buf, le, uint64(e.epoch))
+	}
+	return buf.Bytes()
+}
+
+func TestParseZoxideOrdersByScore(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	data := zoxideDB(
+		zoxideEntry{"/old", 10, now.Add(-30 * 24 * time.Hour).Unix()},
+		zoxideEntry{"/recent", 2, now.Add(-time.Minute).Unix()},
+		zoxideEntry{"/today", 3, now.Add(-2 * time.Hour).Unix()},
+	)
+	got, err := parseZoxide(data, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/recent", "/today", "/old"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseZoxide = %q, want %q", got, want)
+	}
+	if _, err := parseZoxide(data[:len(data)-4], now); err == nil {
+		t.Fatal("expected a truncated database to fail")
+	}
+	if _, err := parseZoxide([]byte{2, 0, 0, 0}, now); err == nil {
+		t.Fatal("expected an old database version to fail")
+	}
+}
+
+func TestListMergesZoxideAndScan(t *testing.T) {
+	root := t.TempDir()
+	mkdirs(t, root, "src/api/.git", "src/web", "elsewhere")
+	db := filepath.Join(root, "db.zo")
+	data := zoxideDB(
+		zoxideEntry{filepath.Join(root, "src/web"), 5, time.Now().Unix()},
+		zoxideEntry{filepath.Join(root, "elsewhere"), 1, time.Now().Unix()},
+		zoxideEntry{filepath.Join(root, "deleted"), 9, 0},
+	)
+	if err := os.WriteFile(db, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := List(Settings{Roots: []string{filepath.Join(root, "src")}, Depth: 1, Zoxide: db})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Project{
+		{Path: filepath.Join(root, "src/web"), Source: SourceZoxide},
+		{Path: filepath.Join(root, "elsewhere"), Source: SourceZoxide},
+		{Path: filepath.Join(root, "src/api"), Source: SourceScan},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("List = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package project
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// zoxideVersion is the database format zoxide has written since 0.8.
+const zoxideVersion = 3
+
+// ZoxideDB returns where zoxide keeps its database: $_ZO_DATA_DIR, else
+// $XDG_DATA_HOME/zoxide, else ~/.local/share/zoxide.
+func ZoxideDB(getenv func(string) string) string {
+	dir := strings.TrimSpace(getenv("_ZO_DATA_DIR"))
+	if dir == "" {
+		base := strings.TrimSpace(getenv("XDG_DATA_HOME"))
+		if base == "" {
+			home := strings.TrimSpace(getenv("HOME"))
+			if home == "" {
+				return ""
+			}
+			base = filepath.Join(home, ".local", "share")
+		}
+		dir = filepath.Join(base, "zoxide")
+	}
+	return filepath.Join(dir, "db.zo")
+}
+
+// ReadZoxide returns the directories in zoxide's database at path, best
+// scored first. A missing database yields nothing.
+func ReadZoxide(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := parseZoxide(data, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return dirs, nil
+}
+
+// parseZoxide decodes the bincode database: a u32 version, then a u64 count
+// of (string path, f64 rank, u64 last-accessed epoch) entries, all little
+// endian with u64 string lengths. Entries are ordered the way `zoxide query`
+// orders them: rank weighted by how recently the directory was used.
+func parseZoxide(data []byte, now time.Time) ([]string, error) {
+	r := bytes.NewReader(data)
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("read zoxide database: %w", err)
+	}
+	if version != zoxideVersion {
+		return nil, fmt.Errorf("unsupported zoxide database version %d", version)
+	}
+	var count uint64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, fmt.Errorf("read zoxide database: %w", err)
+	}
+	type entry struct {
+		path  string
+		score float64
+	}
+	var entries []entry
+	for range count {
+		var size uint64
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return nil, fmt.Errorf("read zoxide database: %w", err)
+		}
+		if size > uint64(r.Len()) {
+			return nil, errors.New("read zoxide database: truncated path")
+		}
+		path := make([]byte, size)
+		if _, err := io.ReadFull(r, path); err != nil {
+			return nil, fmt.Errorf("read zoxide database: %w", err)
+		}
+		var fields struct {
+			Rank         float64
+			LastAccessed uint64
+		}
+		if err := binary.Read(r, binary.LittleEndian, &fields); err != nil {
+			return nil, fmt.Errorf("read zoxide database: %w", err)
+		}
+		entries = append(entries, entry{path: string(path), score: zoxideScore(fields.Rank, fields.LastAccessed, now)})
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int { return cmp.Compare(b.score, a.score) })
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.path
+	}
+	return out, nil
+}
+
+// zoxideScore weights rank by age the same way zoxide does.
+func zoxideScore(rank float64, lastAccessed uint64, now time.Time) float64 {
+	age := now.Sub(time.Unix(int64(min(lastAccessed, math.MaxInt64)), 0))
+	switch {
+	case age < time.Hour:
+		return rank * 4
+	case age < 24*time.Hour:
+		return rank * 2
+	case age < 7*24*time.Hour:
+		return rank / 2
+	default:
+		return rank / 4
+	}
+}
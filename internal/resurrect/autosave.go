@@ -8,8 +8,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"syscall"
 	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/statefile"
 )
 
 const defaultAutosaveStatusIcon = "💾"
@@ -46,22 +47,14 @@ var autosaveNowFn = time.Now
 var autosaveSleepFn = time.Sleep
 
 var withAutosaveLockFn = func(dir string, critical func() error) error {
-	lockPath := autosaveLockPath(dir)
-	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o600)
-	if err != nil {
-		return fmt.Errorf("opening autosave lock: %w", err)
+	unlock, err := statefile.TryLock(autosaveLockPath(dir), "autosave state")
+	if errors.Is(err, statefile.ErrLocked) {
+		return ErrAutoSaveLocked
 	}
-	defer lockFile.Close()
-
-	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
-		if errors.Is(err, syscall.EWOULDBLOCK) || errors.Is(err, syscall.EAGAIN) {
-			return ErrAutoSaveLocked
-		}
-		return fmt.Errorf("locking autosave state: %w", err)
+	if err != nil {
+		return err
 	}
-	defer func() {
-		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
-	}()
+	defer unlock()
 
 	return critical()
 }
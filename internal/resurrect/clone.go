@@ -0,0 +1,47 @@
+package resurrect
+
+import (
+	"context"
+	"fmt"
+)
+
+// CloneSession copies source's windows, layouts and pane working directories
+// into a new, independent session called name. Unlike a grouped session the
+// clone shares nothing with source afterwards. Pane commands and scrollback
+// are not copied: every pane starts the default shell.
+func CloneSession(ctx context.Context, cfg Config, source, name string) error {
+	existingSnap, err := restoreDeps.ExistingSessions(cfg.SocketPath)
+	if err != nil {
+		return fmt.Errorf("fetching existing sessions: %w", err)
+	}
+	for _, s := range existingSnap.Sessions {
+		if s.Name == name {
+			return fmt.Errorf("session %s already exists", name)
+		}
+	}
+	// a trash capture of a session is the same snapshot a clone starts from.
+	sf, _, err := CaptureTrash(cfg.SocketPath, TrashSession, source, false)
+	if err != nil {
+		return err
+	}
+	sess := cloneSession(sf.Sessions[0])
+	sess.Name = name
+
+	// restore helpers report through progress events nobody is watching here;
+	// drain them and rely on the returned errors.
+	ch := make(chan ProgressEvent)
+	go func() {
+		for range ch {
+		}
+	}()
+	defer close(ch)
+
+	run := &restoreRun{
+		ctx:           ctx,
+		cfg:           cfg,
+		ch:            ch,
+		total:         sessionStepCount(sess),
+		lookupPaneCmd: func(string, int, int) string { return "" },
+	}
+	return run.restoreTrashSession(sess, false)
+}
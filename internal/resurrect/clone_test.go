@@ -0,0 +1,65 @@
+package resurrect
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+func TestCloneSession(t *testing.T) {
+	defer stubTrashTmux(trashWindows, trashPanes, "main")()
+	var sessions []tmux.SessionSpec
+	defer withCreateSessionFn(func(spec tmux.SessionSpec) error {
+		sessions = append(sessions, spec)
+		return nil
+	})()
+	var respawned []tmux.PaneSpec
+	defer withRespawnPaneFn(func(spec tmux.PaneSpec) error {
+		respawned = append(respawned, spec)
+		return nil
+	})()
+	var windows []tmux.WindowSpec
+	defer withCreateWindowFn(func(spec tmux.WindowSpec) error {
+		windows = append(windows, spec)
+		return nil
+	})()
+	var splits []tmux.PaneSpec
+	defer withSplitPaneFn(func(spec tmux.PaneSpec) error {
+		splits = append(splits, spec)
+		return nil
+	})()
+	var layouts []string
+	defer withSelectLayoutTargetFn(func(_, target, layout string) error {
+		layouts = append(layouts, target+" "+layout)
+		return nil
+	})()
+
+	if err := CloneSession(context.Background(), Config{}, "main", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "copy" {
+		t.Fatalf("sessions %#v", sessions)
+	}
+	if len(respawned) != 1 || respawned[0].Target != "copy:0.0" || respawned[0].Dir != "/src" || respawned[0].Command != "" {
+		t.Fatalf("respawned %#v", respawned)
+	}
+	if len(windows) != 1 || windows[0].Session != "copy" || windows[0].Name != "logs" || windows[0].Dir != "/var/log" {
+		t.Fatalf("windows %#v", windows)
+	}
+	if len(splits) != 1 || splits[0].Dir != "/tmp" || splits[0].Command != "" {
+		t.Fatalf("splits %#v", splits)
+	}
+	if !strings.Contains(strings.Join(layouts, "\n"), "80x12,0,0") {
+		t.Fatalf("expected the saved layout to be applied, got %q", layouts)
+	}
+}
+
+func TestCloneSessionRefusesTakenName(t *testing.T) {
+	defer stubTrashTmux(trashWindows, trashPanes, "main", "copy")()
+	err := CloneSession(context.Background(), Config{}, "main", "copy")
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a taken-name error, got %v", err)
+	}
+}
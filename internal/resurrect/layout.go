@@ -2,6 +2,7 @@ package resurrect
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -25,6 +26,116 @@ func selectableLayout(layout string) string {
 	return fmt.Sprintf("%04x,%s", layoutChecksum(rewritten), rewritten)
 }
 
+// LayoutCell is one cell of a parsed window layout: a pane when Children is
+// empty, otherwise a split laid out side by side (Horizontal, written {})
+// or stacked (written []).
+type LayoutCell struct {
+	Width, Height int
+	X, Y          int
+	// PaneID is the number of the pane's %id, or -1 when the layout carries
+	// no pane ids.
+	PaneID     int
+	Horizontal bool
+	Children   []LayoutCell
+}
+
+// Panes returns the pane cells under c in layout order.
+func (c LayoutCell) Panes() []LayoutCell {
+	if len(c.Children) == 0 {
+		return []LayoutCell{c}
+	}
+	var panes []LayoutCell
+	for _, child := range c.Children {
+		panes = append(panes, child.Panes()...)
+	}
+	return panes
+}
+
+// ParseLayout parses a #{window_layout} string, with or without its
+// checksum, pane ids, or visible-layout suffix.
+func ParseLayout(layout string) (LayoutCell, error) {
+	body := strings.TrimSpace(layout)
+	if isExactLayout(body) {
+		body = body[5:]
+	}
+	if idx := strings.IndexRune(body, '<'); idx > 0 && strings.HasSuffix(body, ">") {
+		body = body[:idx]
+	}
+	i := 0
+	cell, ok := parseLayoutCell(body, &i)
+	if !ok || i != len(body) {
+		return LayoutCell{}, fmt.Errorf("invalid layout %q", layout)
+	}
+	return cell, nil
+}
+
+func parseLayoutCell(s string, i *int) (LayoutCell, bool) {
+	cell := LayoutCell{PaneID: -1}
+	var ok bool
+	if cell.Width, ok = parseLayoutNumber(s, i); !ok || !skipLayoutByte(s, i, 'x') {
+		return cell, false
+	}
+	if cell.Height, ok = parseLayoutNumber(s, i); !ok || !skipLayoutByte(s, i, ',') {
+		return cell, false
+	}
+	if cell.X, ok = parseLayoutNumber(s, i); !ok || !skipLayoutByte(s, i, ',') {
+		return cell, false
+	}
+	if cell.Y, ok = parseLayoutNumber(s, i); !ok {
+		return cell, false
+	}
+	if *i >= len(s) {
+		return cell, true
+	}
+	switch s[*i] {
+	case '{', '[':
+		cell.Horizontal = s[*i] == '{'
+		closer := byte(']')
+		if cell.Horizontal {
+			closer = '}'
+		}
+		*i++
+		for {
+			child, ok := parseLayoutCell(s, i)
+			if !ok {
+				return cell, false
+			}
+			cell.Children = append(cell.Children, child)
+			if skipLayoutByte(s, i, closer) {
+				return cell, true
+			}
+			if !skipLayoutByte(s, i, ',') {
+				return cell, false
+			}
+		}
+	case ',':
+		// a pane id, unless the comma starts the next sibling cell.
+		j := *i + 1
+		if id, ok := parseLayoutNumber(s, &j); ok && (j >= len(s) || s[j] != 'x') {
+			cell.PaneID = id
+			*i = j
+		}
+	}
+	return cell, true
+}
+
+func parseLayoutNumber(s string, i *int) (int, bool) {
+	start := *i
+	if !scanLayoutNumber(s, i) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s[start:*i])
+	return n, err == nil
+}
+
+func skipLayoutByte(s string, i *int, b byte) bool {
+	if *i < len(s) && s[*i] == b {
+		*i++
+		return true
+	}
+	return false
+}
+
 func isExactLayout(layout string) bool {
 	if len(layout) < 6 || layout[4] != ',' {
 		return false
@@ -1,6 +1,9 @@
 package resurrect
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestSelectableLayoutLeavesNamedLayoutUnchanged(t *testing.T) {
 	if got := selectableLayout(" tiled "); got != "tiled" {
@@ -23,3 +26,38 @@ func TestSelectableLayoutRemovesVisibleSuffixAndPaneIDs(t *testing.T) {
 		t.Fatalf("selectableLayout() = %q, want %q", got, want)
 	}
 }
+
+func TestParseLayout(t *testing.T) {
+	got, err := ParseLayout("bb62,159x48,0,0{79x48,0,0,1,79x48,80,0[79x24,80,0,2,79x23,80,25,3]}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LayoutCell{Width: 159, Height: 48, PaneID: -1, Horizontal: true, Children: []LayoutCell{
+		{Width: 79, Height: 48, PaneID: 1},
+		{Width: 79, Height: 48, X: 80, PaneID: -1, Children: []LayoutCell{
+			{Width: 79, Height: 24, X: 80, PaneID: 2},
+			{Width: 79, Height: 23, X: 80, Y: 25, PaneID: 3},
+		}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLayout() = %+v, want %+v", got, want)
+	}
+	if panes := got.Panes(); len(panes) != 3 || panes[2].PaneID != 3 {
+		t.Fatalf("Panes() = %+v", panes)
+	}
+}
+
+func TestParseLayoutWithoutPaneIDs(t *testing.T) {
+	got, err := ParseLayout("5dcb,179x58,0,0{89x58,0,0,89x58,90,0}<89x58,0,0,37,89x58,90,0,39>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Children) != 2 || got.Children[1].X != 90 || got.Children[1].PaneID != -1 {
+		t.Fatalf("ParseLayout() = %+v", got)
+	}
+	for _, bad := range []string{"", "tiled", "80x24,0,0{", "80x24,0,0{40x24,0,0,1"} {
+		if _, err := ParseLayout(bad); err == nil {
+			t.Fatalf("ParseLayout(%q) succeeded", bad)
+		}
+	}
+}
@@ -335,7 +335,8 @@ func scheduleContentCleanup(contentDir string) {
 }
 
 // restoreSession restores (or merges, or skips) a single saved session,
-// emitting the same progress-event sequence as the original monolithic loop.
+// emitting the same progress-event sequence as the original monolithic loop,
+// then records the idempotency marker.
 func (r *restoreRun) restoreSession(sess Session, merge bool) error {
 	// indexMap translates saved window indices to actual target indices. For
 	// new sessions, indices are used as-is. For merges, saved windows are
@@ -356,61 +357,25 @@ func (r *restoreRun) restoreSession(sess Session, merge bool) error {
 	}
 	replayWaitChannels = append(replayWaitChannels, paneChannels...)
 
-	return r.finalizeSession(sess, indexMap, replayWaitChannels)
+	if err := r.finalizeSession(sess, indexMap, replayWaitChannels); err != nil {
+		return err
+	}
+
+	// mark restored sessions so re-running the same restore is idempotent
+	markerKey := restoreMarkerKey(sess.Name)
+	if err := restoreDeps.SetSessionOption(r.cfg.SocketPath, sess.Name, markerKey, "1"); err != nil {
+		return sendError(r.ctx, r.ch, "setting restore marker for session %s: %w", sess.Name, err)
+	}
+	return nil
 }
 
 // createOrMergeSession handles the session-creation / merge / skip header. It
 // returns the window index map, whether the session was skipped (already
 // restored), and any error.
 func (r *restoreRun) createOrMergeSession(sess Session, merge bool) (map[int]int, bool, error) {
-	indexMap := make(map[int]int, len(sess.Windows))
-
 	if !merge {
-		for _, win := range sess.Windows {
-			indexMap[win.Index] = win.Index
-		}
-
-		r.step++
-		if !r.emit(ProgressEvent{
-			Step:    r.step,
-			Message: fmt.Sprintf("restoring session %s...", sess.Name),
-			Kind:    "session",
-			ID:      sess.Name,
-		}) {
-			return nil, false, r.ctx.Err()
-		}
-		// create the session with $HOME as the working directory so that new
-		// windows inherit the correct default. we must pass -c explicitly
-		// because tmux otherwise inherits the cwd of whatever process
-		// (control-mode client, popup, etc.) sends the new-session command.
-		sessionDir := os.Getenv("HOME")
-		if err := restoreDeps.CreateSession(tmux.SessionSpec{
-			SocketPath: r.cfg.SocketPath,
-			Name:       sess.Name,
-			Dir:        sessionDir,
-		}); err != nil {
-			return nil, false, sendError(r.ctx, r.ch, "creating session %s: %w", sess.Name, err)
-		}
-
-		// the first pane is auto-created with the session; respawn it in the
-		// correct working directory with any startup command. this avoids
-		// polluting session_path with a pane-specific dir.
-		if len(sess.Windows) > 0 && len(sess.Windows[0].Panes) > 0 {
-			p0 := sess.Windows[0].Panes[0]
-			paneCmd := r.lookupPaneCmd(sess.Name, sess.Windows[0].Index, p0.Index)
-			if p0.WorkingDir != "" || paneCmd != "" {
-				paneTarget := fmt.Sprintf("%s:0.0", sess.Name)
-				if err := restoreDeps.RespawnPane(tmux.PaneSpec{
-					SocketPath: r.cfg.SocketPath,
-					Target:     paneTarget,
-					Dir:        p0.WorkingDir,
-					Command:    paneCmd,
-				}); err != nil {
-					return nil, false, sendError(r.ctx, r.ch, "respawning pane %s: %w", paneTarget, err)
-				}
-			}
-		}
-		return indexMap, false, nil
+		indexMap, err := r.createSession(sess)
+		return indexMap, false, err
 	}
 
 	// merge path: idempotency — skip if this slot was already merged.
@@ -428,9 +393,67 @@ func (r *restoreRun) createOrMergeSession(sess Session, merge bool) (map[int]int
 		return nil, true, nil
 	}
 
+	indexMap, err := r.mergeSession(sess)
+	return indexMap, false, err
+}
+
+// createSession creates sess as a new session whose windows keep their saved
+// indices.
+func (r *restoreRun) createSession(sess Session) (map[int]int, error) {
+	indexMap := make(map[int]int, len(sess.Windows))
+	for _, win := range sess.Windows {
+		indexMap[win.Index] = win.Index
+	}
+
+	r.step++
+	if !r.emit(ProgressEvent{
+		Step:    r.step,
+		Message: fmt.Sprintf("restoring session %s...", sess.Name),
+		Kind:    "session",
+		ID:      sess.Name,
+	}) {
+		return nil, r.ctx.Err()
+	}
+	// create the session with $HOME as the working directory so that new
+	// windows inherit the correct default. we must pass -c explicitly
+	// because tmux otherwise inherits the cwd of whatever process
+	// (control-mode client, popup, etc.) sends the new-session command.
+	sessionDir := os.Getenv("HOME")
+	if err := restoreDeps.CreateSession(tmux.SessionSpec{
+		SocketPath: r.cfg.SocketPath,
+		Name:       sess.Name,
+		Dir:        sessionDir,
+	}); err != nil {
+		return nil, sendError(r.ctx, r.ch, "creating session %s: %w", sess.Name, err)
+	}
+
+	// the first pane is auto-created with the session; respawn it in the
+	// correct working directory with any startup command. this avoids
+	// polluting session_path with a pane-specific dir.
+	if len(sess.Windows) > 0 && len(sess.Windows[0].Panes) > 0 {
+		p0 := sess.Windows[0].Panes[0]
+		paneCmd := r.lookupPaneCmd(sess.Name, sess.Windows[0].Index, p0.Index)
+		if p0.WorkingDir != "" || paneCmd != "" {
+			paneTarget := fmt.Sprintf("%s:0.0", sess.Name)
+			if err := restoreDeps.RespawnPane(tmux.PaneSpec{
+				SocketPath: r.cfg.SocketPath,
+				Target:     paneTarget,
+				Dir:        p0.WorkingDir,
+				Command:    paneCmd,
+			}); err != nil {
+				return nil, sendError(r.ctx, r.ch, "respawning pane %s: %w", paneTarget, err)
+			}
+		}
+	}
+	return indexMap, nil
+}
+
+// mergeSession maps sess's windows onto fresh indices appended after the
+// highest index of the existing session of the same name.
+func (r *restoreRun) mergeSession(sess Session) (map[int]int, error) {
 	existingIndices, err := restoreDeps.ExistingWindowIndices(r.cfg.SocketPath, sess.Name)
 	if err != nil {
-		return nil, false, sendError(r.ctx, r.ch, "listing windows for session %s: %w", sess.Name, err)
+		return nil, sendError(r.ctx, r.ch, "listing windows for session %s: %w", sess.Name, err)
 	}
 	maxIdx := -1
 	for idx := range existingIndices {
@@ -438,6 +461,7 @@ func (r *restoreRun) createOrMergeSession(sess Session, merge bool) (map[int]int
 			maxIdx = idx
 		}
 	}
+	indexMap := make(map[int]int, len(sess.Windows))
 	nextIdx := maxIdx + 1
 	for _, win := range sess.Windows {
 		indexMap[win.Index] = nextIdx
@@ -451,9 +475,9 @@ func (r *restoreRun) createOrMergeSession(sess Session, merge bool) (map[int]int
 		Kind:    "session",
 		ID:      sess.Name,
 	}) {
-		return nil, false, r.ctx.Err()
+		return nil, r.ctx.Err()
 	}
-	return indexMap, false, nil
+	return indexMap, nil
 }
 
 // restoreWindows creates (or renames) the windows for a session and returns the
@@ -547,13 +571,16 @@ func (r *restoreRun) splitPanes(sess Session, indexMap map[int]int) ([]string, e
 }
 
 // finalizeSession applies layouts, waits for pane replays, selects active panes
-// and the active window, emits the finalize event, and records the idempotency
-// marker.
+// and the active window, and emits the finalize event. A window saved without
+// a layout keeps the one tmux gave it.
 func (r *restoreRun) finalizeSession(sess Session, indexMap map[int]int, replayWaitChannels []string) error {
 	for _, win := range sess.Windows {
 		targetIdx := indexMap[win.Index]
 		winTarget := fmt.Sprintf("%s:%d", sess.Name, targetIdx)
 		r.step++
+		if win.Layout == "" {
+			continue
+		}
 		if err := restoreDeps.SelectLayoutTarget(r.cfg.SocketPath, winTarget, selectableLayout(win.Layout)); err != nil {
 			return sendError(r.ctx, r.ch, "applying layout for %s: %w", winTarget, err)
 		}
@@ -601,12 +628,6 @@ func (r *restoreRun) finalizeSession(sess Session, indexMap map[int]int, replayW
 	}) {
 		return r.ctx.Err()
 	}
-
-	// mark restored sessions so re-running the same restore is idempotent
-	markerKey := restoreMarkerKey(sess.Name)
-	if err := restoreDeps.SetSessionOption(r.cfg.SocketPath, sess.Name, markerKey, "1"); err != nil {
-		return sendError(r.ctx, r.ch, "setting restore marker for session %s: %w", sess.Name, err)
-	}
 	return nil
 }
 
@@ -216,28 +216,8 @@ func runSave(ctx context.Context, cfg Config, ch chan<- ProgressEvent) error {
 			for _, w := range wins {
 				winIDs = append(winIDs, fmt.Sprintf("%s:%d", s.Name, w.Index))
 
-				autoRename := autoRenameMap[w.InternalID]
 				key := sessionWindow{session: s.Name, windowIdx: w.Index}
-				var savedPanes []Pane
-				for _, p := range panesByWindow[key] {
-					savedPanes = append(savedPanes, Pane{
-						Index:      p.Index,
-						WorkingDir: p.Path,
-						Title:      p.Title,
-						Command:    p.Command,
-						Width:      p.Width,
-						Height:     p.Height,
-						Active:     p.Active,
-					})
-				}
-				sess.Windows = append(sess.Windows, Window{
-					Index:           w.Index,
-					Name:            w.Name,
-					Layout:          selectableLayout(w.Layout),
-					Active:          w.Active,
-					AutomaticRename: autoRename,
-					Panes:           savedPanes,
-				})
+				sess.Windows = append(sess.Windows, savedWindow(w, panesByWindow[key], autoRenameMap[w.InternalID]))
 			}
 
 			step += len(wins)
@@ -345,6 +325,34 @@ func runSave(ctx context.Context, cfg Config, ch chan<- ProgressEvent) error {
 	return nil
 }
 
+// savedWindow converts a live window and its panes to their save-file form.
+func savedWindow(w tmux.Window, panes []tmux.Pane, autoRename bool) Window {
+	var savedPanes []Pane
+	for _, p := range panes {
+		savedPanes = append(savedPanes, savedPane(p))
+	}
+	return Window{
+		Index:           w.Index,
+		Name:            w.Name,
+		Layout:          selectableLayout(w.Layout),
+		Active:          w.Active,
+		AutomaticRename: autoRename,
+		Panes:           savedPanes,
+	}
+}
+
+func savedPane(p tmux.Pane) Pane {
+	return Pane{
+		Index:      p.Index,
+		WorkingDir: p.Path,
+		Title:      p.Title,
+		Command:    p.Command,
+		Width:      p.Width,
+		Height:     p.Height,
+		Active:     p.Active,
+	}
+}
+
 // parseCreated returns the session creation timestamp.
 // tmux.Session does not currently expose the raw Created string from the
 // gotmux layer — wiring that value is left for a future task. For now we
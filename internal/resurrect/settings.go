@@ -16,6 +16,8 @@ const (
 	optAutosaveMax             = "@tmux-popup-control-autosave-max"
 	optAutosaveIcon            = "@tmux-popup-control-autosave-icon"
 	optAutosaveIconSeconds     = "@tmux-popup-control-autosave-icon-seconds"
+	envTrashMax                = "TMUX_POPUP_CONTROL_TRASH_MAX"
+	optTrashMax                = "@tmux-popup-control-trash-max"
 )
 
 // resolveOption resolves a configuration value from, in order, an environment
@@ -49,6 +51,12 @@ func ResolveAutosaveIcon(socketPath string) string {
 	return resolveOption(socketPath, envAutosaveIcon, optAutosaveIcon, parseNonEmpty, defaultAutosaveStatusIcon)
 }
 
+// ResolveTrashMax returns how many killed sessions, windows and panes the
+// trash keeps before dropping the oldest. 0 turns the trash off.
+func ResolveTrashMax(socketPath string) int {
+	return resolveOption(socketPath, envTrashMax, optTrashMax, parseTrashMax, 20)
+}
+
 // ResolvePaneContents reports whether pane content capture is enabled.
 // Lookup chain:
 //  1. TMUX_POPUP_CONTROL_RESTORE_PANE_CONTENTS env var
@@ -109,6 +117,20 @@ func parseAutosaveMax(value string) (int, bool) {
 	return n, true
 }
 
+// parseTrashMax accepts a present, non-negative value; an invalid one resolves
+// to the default of 20 and a blank one falls through.
+func parseTrashMax(value string) (int, bool) {
+	v := strings.TrimSpace(value)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 20, true
+	}
+	return n, true
+}
+
 // parseBool returns true for common truthy values.
 func parseBool(s string) bool {
 	switch strings.ToLower(strings.TrimSpace(s)) {
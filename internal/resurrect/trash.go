@@ -0,0 +1,419 @@
+package resurrect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+// TrashKind is what a trash entry was before it was killed.
+type TrashKind string
+
+const (
+	TrashSession TrashKind = "session"
+	TrashWindow  TrashKind = "window"
+	TrashPane    TrashKind = "pane"
+)
+
+// TrashInfo marks a save file as a trash entry. The file's single session
+// holds the killed item: a whole session, one window, or one window holding
+// one pane.
+type TrashInfo struct {
+	Kind   TrashKind `json:"kind"`
+	Target string    `json:"target"` // "main", "main:2" or "main:2.1" when killed
+	// WindowID is the @ID of a killed pane's window, so the pane can be split
+	// back into it while it survives.
+	WindowID string `json:"window_id,omitempty"`
+}
+
+// TrashEntry is one trash file in the listing.
+type TrashEntry struct {
+	Path            string
+	TrashInfo       TrashInfo
+	Session         string
+	Killed          time.Time
+	HasPaneContents bool
+	WindowCount     int
+	PaneCount       int
+}
+
+// trashDir holds the trash entries: one save file, plus its pane archive, per
+// killed item.
+func trashDir(dir string) string {
+	return filepath.Join(dir, "trash")
+}
+
+// CaptureTrash snapshots target — a session name, a "session:window" window
+// or a "session:window.pane" pane — so it can be restored after it is
+// killed. With withContents the panes' scrollback is captured too, keyed for
+// the save file's pane archive.
+func CaptureTrash(socketPath string, kind TrashKind, target string, withContents bool) (*SaveFile, map[string]string, error) {
+	windowSnap, err := saveDeps.FetchWindows(socketPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching windows: %w", err)
+	}
+	paneSnap, err := saveDeps.FetchPanes(socketPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching panes: %w", err)
+	}
+	autoRenameMap, err := saveDeps.QueryWindowOptions(socketPath)
+	if err != nil {
+		autoRenameMap = map[string]bool{}
+	}
+	panesOf := func(w tmux.Window) []tmux.Pane {
+		var panes []tmux.Pane
+		for _, p := range paneSnap.Panes {
+			if p.Session == w.Session && p.WindowIdx == w.Index {
+				panes = append(panes, p)
+			}
+		}
+		return panes
+	}
+
+	info := TrashInfo{Kind: kind, Target: target}
+	var sess Session
+	var captured []tmux.Pane
+	switch kind {
+	case TrashSession:
+		sess.Name = target
+		for _, w := range windowSnap.Windows {
+			if w.Session != target {
+				continue
+			}
+			panes := panesOf(w)
+			sess.Windows = append(sess.Windows, savedWindow(w, panes, autoRenameMap[w.InternalID]))
+			captured = append(captured, panes...)
+		}
+	case TrashWindow:
+		for _, w := range windowSnap.Windows {
+			if fmt.Sprintf("%s:%d", w.Session, w.Index) != target {
+				continue
+			}
+			panes := panesOf(w)
+			win := savedWindow(w, panes, autoRenameMap[w.InternalID])
+			win.Active = true
+			sess = Session{Name: w.Session, Windows: []Window{win}}
+			captured = panes
+			break
+		}
+	case TrashPane:
+		for _, p := range paneSnap.Panes {
+			if p.ID != target {
+				continue
+			}
+			win := Window{Index: p.WindowIdx, Name: p.Window, Active: true, Panes: []Pane{savedPane(p)}}
+			win.Panes[0].Active = true
+			for _, w := range windowSnap.Windows {
+				if w.Session == p.Session && w.Index == p.WindowIdx {
+					win.Name, win.AutomaticRename = w.Name, autoRenameMap[w.InternalID]
+					info.WindowID = w.InternalID
+					break
+				}
+			}
+			sess = Session{Name: p.Session, Windows: []Window{win}}
+			captured = []tmux.Pane{p}
+			break
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown trash kind %q", kind)
+	}
+	if len(sess.Windows) == 0 {
+		return nil, nil, fmt.Errorf("%s %s not found", kind, target)
+	}
+
+	contents := map[string]string{}
+	if withContents {
+		for _, p := range captured {
+			content, err := saveDeps.CapturePaneContents(socketPath, p.ID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("capturing pane %s: %w", p.ID, err)
+			}
+			contents[fmt.Sprintf("%s:%d.%d", p.Session, p.WindowIdx, p.Index)] = strings.TrimRight(content, "\n") + "\n"
+		}
+	}
+	return &SaveFile{
+		Version:         currentVersion,
+		Timestamp:       time.Now(),
+		Name:            target,
+		Kind:            SaveKindTrash,
+		HasPaneContents: len(contents) > 0,
+		Sessions:        []Session{sess},
+		Trash:           &info,
+	}, contents, nil
+}
+
+// WriteTrash stores a captured entry and its pane contents, then drops the
+// oldest entries beyond maxEntries. It returns the entry's path.
+func WriteTrash(dir string, sf *SaveFile, contents map[string]string, maxEntries int) (string, error) {
+	tdir, err := ensureDir(trashDir(dir))
+	if err != nil {
+		return "", err
+	}
+	path := savePath(tdir, "")
+	if err := WriteSaveFile(path, sf); err != nil {
+		return "", err
+	}
+	if len(contents) > 0 {
+		if err := WritePaneArchive(paneArchivePath(path), contents); err != nil {
+			_ = os.Remove(path)
+			return "", err
+		}
+	}
+	entries, err := ListTrash(dir)
+	if err != nil {
+		return path, err
+	}
+	for _, entry := range entries[min(max(maxEntries, 1), len(entries)):] {
+		if err := DeleteSave("", entry.Path); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+// ListTrash returns the trash entries in dir, newest first.
+func ListTrash(dir string) ([]TrashEntry, error) {
+	matches, err := filepath.Glob(filepath.Join(trashDir(dir), "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list trash: %w", err)
+	}
+	var entries []TrashEntry
+	for _, p := range matches {
+		sf, err := ReadSaveFile(p)
+		if err != nil || sf.Trash == nil || len(sf.Sessions) != 1 {
+			// skip unreadable / malformed files
+			continue
+		}
+		entry := TrashEntry{
+			Path:            p,
+			TrashInfo:       *sf.Trash,
+			Session:         sf.Sessions[0].Name,
+			Killed:          sf.Timestamp,
+			HasPaneContents: sf.HasPaneContents,
+			WindowCount:     len(sf.Sessions[0].Windows),
+		}
+		for _, w := range sf.Sessions[0].Windows {
+			entry.PaneCount += len(w.Panes)
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b TrashEntry) int {
+		return b.Killed.Compare(a.Killed)
+	})
+	return entries, nil
+}
+
+// DeleteTrash removes the trash entry at path. Paths outside dir's trash are
+// refused, so a forged menu item can never delete a real save.
+func DeleteTrash(dir, path string) error {
+	if filepath.Dir(path) != trashDir(dir) {
+		return fmt.Errorf("%s is not in the trash", path)
+	}
+	return DeleteSave("", path)
+}
+
+// RestoreTrash recreates the trash entry at path and removes it from the
+// trash. A window or pane goes back into its original session while that
+// session exists, a pane into its original window while that window does; a
+// killed session, or a window or pane whose session is gone, is recreated as
+// a session of its old name. With newSession, or when a killed session's
+// name has been taken since, it becomes a new session named after the old
+// one. It returns the session restored into.
+func RestoreTrash(ctx context.Context, cfg Config, path string, newSession bool) (string, error) {
+	if filepath.Dir(path) != trashDir(cfg.SaveDir) {
+		return "", fmt.Errorf("%s is not in the trash", path)
+	}
+	sf, err := ReadSaveFile(path)
+	if err != nil {
+		return "", err
+	}
+	if sf.Trash == nil || len(sf.Sessions) != 1 || len(sf.Sessions[0].Windows) == 0 {
+		return "", fmt.Errorf("%s is not a trash entry", path)
+	}
+	saved := sf.Sessions[0]
+
+	// restore helpers report through progress events nobody is watching here;
+	// drain them and rely on the returned errors.
+	ch := make(chan ProgressEvent)
+	go func() {
+		for range ch {
+		}
+	}()
+	defer close(ch)
+
+	contentDir, lookupPaneCmd, err := preparePaneContent(ctx, cfg, path, ch)
+	if err != nil {
+		return "", err
+	}
+	defer scheduleContentCleanup(contentDir)
+
+	existingSnap, err := restoreDeps.ExistingSessions(cfg.SocketPath)
+	if err != nil {
+		return "", fmt.Errorf("fetching existing sessions: %w", err)
+	}
+	existing := make(map[string]bool, len(existingSnap.Sessions))
+	for _, s := range existingSnap.Sessions {
+		existing[s.Name] = true
+	}
+
+	sess := cloneSession(saved)
+	merge := existing[sess.Name]
+	if newSession || (merge && sf.Trash.Kind == TrashSession) {
+		sess.Name = freeSessionName(saved.Name, existing)
+		merge = false
+	}
+	if sf.Trash.Kind != TrashSession {
+		// a lone pane becomes its window's first pane, and a lone window a
+		// new session's first window.
+		for i := range sess.Windows[0].Panes {
+			sess.Windows[0].Panes[i].Index = i
+		}
+		if !merge {
+			sess.Windows[0].Index = 0
+		}
+	}
+	if err := rekeyPaneContent(contentDir, saved, sess); err != nil {
+		return "", err
+	}
+
+	run := &restoreRun{
+		ctx:           ctx,
+		cfg:           cfg,
+		ch:            ch,
+		total:         sessionStepCount(sess),
+		lookupPaneCmd: lookupPaneCmd,
+	}
+	if merge && sf.Trash.Kind == TrashPane && sf.Trash.WindowID != "" && windowExists(cfg.SocketPath, sf.Trash.WindowID) {
+		err = run.splitIntoWindow(sess, sf.Trash.WindowID)
+	} else {
+		err = run.restoreTrashSession(sess, merge)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := DeleteSave("", path); err != nil {
+		return sess.Name, err
+	}
+	return sess.Name, nil
+}
+
+// restoreTrashSession creates or merges sess like a regular restore, without
+// the idempotency marker: a trash entry is removed once it is restored.
+func (r *restoreRun) restoreTrashSession(sess Session, merge bool) error {
+	var indexMap map[int]int
+	var err error
+	if merge {
+		indexMap, err = r.mergeSession(sess)
+	} else {
+		indexMap, err = r.createSession(sess)
+	}
+	if err != nil {
+		return err
+	}
+	replayWaitChannels, err := r.restoreWindows(sess, indexMap, merge)
+	if err != nil {
+		return err
+	}
+	paneChannels, err := r.splitPanes(sess, indexMap)
+	if err != nil {
+		return err
+	}
+	return r.finalizeSession(sess, indexMap, append(replayWaitChannels, paneChannels...))
+}
+
+// splitIntoWindow restores a killed pane by splitting it back into the window
+// it came from; tmux's layout is left alone, the old one having no slot for
+// the pane any more.
+func (r *restoreRun) splitIntoWindow(sess Session, windowID string) error {
+	win := sess.Windows[0]
+	pane := win.Panes[0]
+	paneCmd := r.lookupPaneCmd(sess.Name, win.Index, pane.Index)
+	if err := restoreDeps.SplitPane(tmux.PaneSpec{
+		SocketPath: r.cfg.SocketPath,
+		Target:     windowID,
+		Dir:        pane.WorkingDir,
+		Command:    paneCmd,
+	}); err != nil {
+		return fmt.Errorf("splitting pane into %s: %w", windowID, err)
+	}
+	if paneCmd == "" {
+		return nil
+	}
+	channel := paneReplayWaitChannel(sess.Name, win.Index, pane.Index)
+	if err := restoreDeps.WaitFor(r.ctx, r.cfg.SocketPath, channel); err != nil {
+		return fmt.Errorf("waiting for pane replay %s: %w", channel, err)
+	}
+	return nil
+}
+
+func windowExists(socketPath, windowID string) bool {
+	snap, err := saveDeps.FetchWindows(socketPath)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(snap.Windows, func(w tmux.Window) bool { return w.InternalID == windowID })
+}
+
+func cloneSession(sess Session) Session {
+	out := sess
+	out.Windows = make([]Window, len(sess.Windows))
+	for i, w := range sess.Windows {
+		w.Panes = slices.Clone(w.Panes)
+		out.Windows[i] = w
+	}
+	return out
+}
+
+// freeSessionName returns base, or base-2, base-3 … if that is taken.
+func freeSessionName(base string, existing map[string]bool) string {
+	if !existing[base] {
+		return base
+	}
+	for n := 2; ; n++ {
+		name := fmt.Sprintf("%s-%d", base, n)
+		if !existing[name] {
+			return name
+		}
+	}
+}
+
+// rekeyPaneContent renames extracted pane contents from the positions in
+// saved to the matching positions in restored, which has the same windows
+// and panes in the same order, so the restore's lookups find them.
+func rekeyPaneContent(contentDir string, saved, restored Session) error {
+	if contentDir == "" {
+		return nil
+	}
+	type move struct{ from, to string }
+	var moves []move
+	for i, w := range saved.Windows {
+		for j, p := range w.Panes {
+			from := filepath.Join(contentDir, fmt.Sprintf("%s:%d.%d", saved.Name, w.Index, p.Index))
+			rw := restored.Windows[i]
+			to := filepath.Join(contentDir, fmt.Sprintf("%s:%d.%d", restored.Name, rw.Index, rw.Panes[j].Index))
+			if from != to {
+				moves = append(moves, move{from, to})
+			}
+		}
+	}
+	// two passes, through temporary names, so renumbering can never clobber
+	// a file that has yet to move.
+	for i, m := range moves {
+		if err := os.Rename(m.from, fmt.Sprintf("%s.rekey%d", m.from, i)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rekeying pane content: %w", err)
+		}
+	}
+	for i, m := range moves {
+		if err := os.Rename(fmt.Sprintf("%s.rekey%d", m.from, i), m.to); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("rekeying pane content: %w", err)
+		}
+	}
+	return nil
+}
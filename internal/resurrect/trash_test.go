@@ -0,0 +1,250 @@
+package resurrect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+)
+
+// stubTrashTmux serves windows and panes to capture and restore, and
+// returns a restore function.
+func stubTrashTmux(windows []tmux.Window, panes []tmux.Pane, sessions ...string) func() {
+	snap := tmux.SessionSnapshot{}
+	for _, name := range sessions {
+		snap.Sessions = append(snap.Sessions, tmux.Session{Name: name})
+	}
+	restores := []func(){
+		withFetchWindowsFn(func(string) (tmux.WindowSnapshot, error) { return tmux.WindowSnapshot{Windows: windows}, nil }),
+		withFetchPanesFn(func(string) (tmux.PaneSnapshot, error) { return tmux.PaneSnapshot{Panes: panes}, nil }),
+		withCapturePaneContentsFn(func(_, target string) (string, error) { return "scrollback of " + target, nil }),
+		withExistingSessionsFn(func(string) (tmux.SessionSnapshot, error) { return snap, nil }),
+		withExistingWindowIndicesFn(func(string, string) (map[int]bool, error) { return map[int]bool{0: true, 1: true}, nil }),
+		withCreateSessionFn(noopSession),
+		withCreateWindowFn(noopWindow),
+		withRenameWindowFn(noopRename),
+		withSplitPaneFn(noopSplit),
+		withSelectLayoutTargetFn(noopLayout),
+		withRespawnPaneFn(noopRespawn),
+		withWaitForFn(noopWait),
+		withSelectPaneFn(noopPane),
+		withSelectWindowFn(noopSelectWindow),
+		withDefaultCommandFn(noopDefaultCommand),
+		withSetSessionOptionFn(func(_, session, _, _ string) error {
+			panic("trash restore must not set the restore marker on " + session)
+		}),
+	}
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+}
+
+var (
+	trashWindows = []tmux.Window{
+		{Session: "main", Index: 0, Name: "editor", InternalID: "@1", Layout: "b25d,80x24,0,0,1"},
+		{Session: "main", Index: 3, Name: "logs", InternalID: "@4", Layout: "c3f1,80x24,0,0[80x12,0,0,5,80x11,0,13,6]"},
+	}
+	trashPanes = []tmux.Pane{
+		{ID: "main:0.0", Session: "main", WindowIdx: 0, Index: 0, Path: "/src"},
+		{ID: "main:3.0", Session: "main", WindowIdx: 3, Index: 0, Path: "/var/log"},
+		{ID: "main:3.1", Session: "main", WindowIdx: 3, Index: 1, Path: "/tmp", Title: "grep"},
+	}
+)
+
+func TestCaptureTrashPane(t *testing.T) {
+	defer stubTrashTmux(trashWindows, trashPanes)()
+	sf, contents, err := CaptureTrash("", TrashPane, "main:3.1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.Trash == nil || sf.Trash.WindowID != "@4" || sf.Kind != SaveKindTrash {
+		t.Fatalf("unexpected trash info %#v", sf.Trash)
+	}
+	win := sf.Sessions[0].Windows[0]
+	if sf.Sessions[0].Name != "main" || win.Index != 3 || win.Name != "logs" || len(win.Panes) != 1 || win.Panes[0].WorkingDir != "/tmp" {
+		t.Fatalf("unexpected capture %#v", sf.Sessions[0])
+	}
+	if contents["main:3.1"] != "scrollback of main:3.1\n" || len(contents) != 1 {
+		t.Fatalf("unexpected contents %q", contents)
+	}
+}
+
+func TestCaptureTrashMissingTarget(t *testing.T) {
+	defer stubTrashTmux(trashWindows, trashPanes)()
+	if _, _, err := CaptureTrash("", TrashWindow, "main:9", false); err == nil {
+		t.Fatal("expected an error for a missing window")
+	}
+}
+
+func TestWriteTrashKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now()
+	for i, target := range []string{"a", "b", "c"} {
+		sf := &SaveFile{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Sessions:  []Session{{Name: target, Windows: []Window{{Panes: []Pane{{}}}}}},
+			Trash:     &TrashInfo{Kind: TrashSession, Target: target},
+		}
+		if _, err := WriteTrash(dir, sf, map[string]string{target + ":0.0": "x\n"}, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := ListTrash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].TrashInfo.Target != "c" || entries[1].TrashInfo.Target != "b" {
+		t.Fatalf("unexpected entries %#v", entries)
+	}
+	archives, _ := filepath.Glob(filepath.Join(trashDir(dir), "*.panes.tar.gz"))
+	if len(archives) != 2 {
+		t.Fatalf("expected the pruned entry's archive removed, got %v", archives)
+	}
+}
+
+func TestResolveTrashMaxAllowsZero(t *testing.T) {
+	value := "0"
+	restore := withTmuxOptionFn(func(_, opt string) string {
+		if opt == "@tmux-popup-control-trash-max" {
+			return value
+		}
+		return ""
+	})
+	defer restore()
+	t.Setenv("TMUX_POPUP_CONTROL_TRASH_MAX", "")
+
+	if got := ResolveTrashMax("dummy"); got != 0 {
+		t.Fatalf("expected 0 to turn the trash off, got %d", got)
+	}
+	value = "nope"
+	if got := ResolveTrashMax("dummy"); got != 20 {
+		t.Fatalf("expected an invalid max to use the default, got %d", got)
+	}
+}
+
+func TestRestoreTrashWindowMergesIntoSession(t *testing.T) {
+	defer stubTrashTmux(trashWindows, trashPanes, "main")()
+	dir := t.TempDir()
+	sf, contents, err := CaptureTrash("", TrashWindow, "main:3", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := WriteTrash(dir, sf, contents, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created []tmux.WindowSpec
+	defer withCreateWindowFn(func(spec tmux.WindowSpec) error {
+		created = append(created, spec)
+		return nil
+	})()
+	var split []string
+	defer withSplitPaneFn(func(spec tmux.PaneSpec) error {
+		split = append(split, spec.Target+" "+spec.Dir)
+		return nil
+	})()
+
+	name, err := RestoreTrash(context.Background(), Config{SaveDir: dir}, path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "main" || len(created) != 1 || created[0].Index != 2 || created[0].Name != "logs" || created[0].Dir != "/var/log" {
+		t.Fatalf("restored into %q, created %#v", name, created)
+	}
+	if len(split) != 1 || split[0] != "main:2 /tmp" {
+		t.Fatalf("split %q", split)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the entry removed from the trash, stat err %v", err)
+	}
+}
+
+func TestRestoreTrashPaneSplitsIntoSurvivingWindow(t *testing.T) {
+	defer stubTrashTmux(trashWindows, trashPanes, "main")()
+	dir := t.TempDir()
+	sf, contents, err := CaptureTrash("", TrashPane, "main:3.1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := WriteTrash(dir, sf, contents, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var split []tmux.PaneSpec
+	defer withSplitPaneFn(func(spec tmux.PaneSpec) error {
+		split = append(split, spec)
+		return nil
+	})()
+	defer withCreateWindowFn(func(tmux.WindowSpec) error {
+		t.Fatal("expected no new window")
+		return nil
+	})()
+
+	if _, err := RestoreTrash(context.Background(), Config{SaveDir: dir}, path, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(split) != 1 || split[0].Target != "@4" || split[0].Dir != "/tmp" {
+		t.Fatalf("split %#v", split)
+	}
+}
+
+func TestRestoreTrashSessionNameTaken(t *testing.T) {
+	defer stubTrashTmux(trashWindows, trashPanes, "main", "main-2")()
+	dir := t.TempDir()
+	sf, contents, err := CaptureTrash("", TrashSession, "main", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := WriteTrash(dir, sf, contents, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created []string
+	defer withCreateSessionFn(func(spec tmux.SessionSpec) error {
+		created = append(created, spec.Name)
+		return nil
+	})()
+
+	name, err := RestoreTrash(context.Background(), Config{SaveDir: dir}, path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "main-3" || len(created) != 1 || created[0] != "main-3" {
+		t.Fatalf("restored into %q, created %q", name, created)
+	}
+}
+
+func TestRestoreTrashRejectsPathsOutsideTrash(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := RestoreTrash(context.Background(), Config{SaveDir: dir}, filepath.Join(dir, "save.json"), false); err == nil {
+		t.Fatal("expected a path outside the trash to be refused")
+	}
+	if err := DeleteTrash(dir, filepath.Join(dir, "save.json")); err == nil {
+		t.Fatal("expected a path outside the trash to be refused")
+	}
+}
+
+func TestRekeyPaneContent(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main:3.1", "main:3.2"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved := Session{Name: "main", Windows: []Window{{Index: 3, Panes: []Pane{{Index: 1}, {Index: 2}}}}}
+	restored := Session{Name: "main-2", Windows: []Window{{Index: 0, Panes: []Pane{{Index: 0}, {Index: 1}}}}}
+	if err := rekeyPaneContent(dir, saved, restored); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"main-2:0.0": "main:3.1", "main-2:0.1": "main:3.2"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != want {
+			t.Fatalf("%s = %q %v, want %q", name, data, err, want)
+		}
+	}
+}
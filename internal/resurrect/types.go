@@ -9,6 +9,7 @@ type SaveKind string
 const (
 	SaveKindManual SaveKind = "manual"
 	SaveKindAuto   SaveKind = "auto"
+	SaveKindTrash  SaveKind = "trash"
 )
 
 // Config is passed to Save/Restore by the caller.
@@ -23,14 +24,15 @@ type Config struct {
 
 // SaveFile is the top-level JSON structure written to disk.
 type SaveFile struct {
-	Version           int       `json:"version"`
-	Timestamp         time.Time `json:"timestamp"`
-	Name              string    `json:"name"`
-	Kind              SaveKind  `json:"kind"`
-	HasPaneContents   bool      `json:"has_pane_contents"`
-	ClientSession     string    `json:"client_session"`
-	ClientLastSession string    `json:"client_last_session"`
-	Sessions          []Session `json:"sessions"`
+	Version           int        `json:"version"`
+	Timestamp         time.Time  `json:"timestamp"`
+	Name              string     `json:"name"`
+	Kind              SaveKind   `json:"kind"`
+	HasPaneContents   bool       `json:"has_pane_contents"`
+	ClientSession     string     `json:"client_session"`
+	ClientLastSession string     `json:"client_last_session"`
+	Sessions          []Session  `json:"sessions"`
+	Trash             *TrashInfo `json:"trash,omitempty"`
 }
 
 // Session represents one tmux session in the save file.
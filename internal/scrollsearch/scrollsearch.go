@@ -0,0 +1,191 @@
+// Package scrollsearch finds lines in captured pane scrollback. A query is
+// either a regular expression or a fuzzy pattern whose letters must appear
+// in order on one line. capturing panes is the caller's job, handed in as a
+// func, so there are no tmux, bubbletea, or menu imports here.
+package scrollsearch
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// fuzzySpan caps how far apart a fuzzy match may spread: at most this many
+// runes per query rune. Without it a short query matches nearly every long
+// line.
+const fuzzySpan = 3
+
+// Matcher reports the byte range of the first match on line.
+type Matcher func(line string) (start, end int, ok bool)
+
+// Compile builds the matcher for query, a regular expression when regex is
+// set and a fuzzy pattern otherwise.
+func Compile(query string, regex bool) (Matcher, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("empty query")
+	}
+	if !regex {
+		return fuzzy(query), nil
+	}
+	re, err := regexp.Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	return func(line string) (int, int, bool) {
+		loc := re.FindStringIndex(line)
+		if loc == nil || loc[0] == loc[1] {
+			return 0, 0, false
+		}
+		return loc[0], loc[1], true
+	}, nil
+}
+
+// fuzzy matches the non-space runes of query, case-insensitively and in
+// order. The span is the shortest one ending at the earliest possible end.
+func fuzzy(query string) Matcher {
+	var pattern []rune
+	for _, r := range query {
+		if !unicode.IsSpace(r) {
+			pattern = append(pattern, unicode.ToLower(r))
+		}
+	}
+	return func(line string) (int, int, bool) {
+		runes := []rune(line)
+		lower := make([]rune, len(runes))
+		for i, r := range runes {
+			lower[i] = unicode.ToLower(r)
+		}
+		for from := 0; from < len(lower); from++ {
+			end := forward(lower, pattern, from)
+			if end < 0 {
+				return 0, 0, false
+			}
+			start := backward(lower, pattern, end)
+			if end-start+1 <= fuzzySpan*len(pattern) {
+				return byteOffset(runes, start), byteOffset(runes, end+1), true
+			}
+			from = start
+		}
+		return 0, 0, false
+	}
+}
+
+// forward returns the index of the rune completing pattern when matched
+// greedily from from, or -1.
+func forward(line, pattern []rune, from int) int {
+	p := 0
+	for i := from; i < len(line); i++ {
+		if line[i] == pattern[p] {
+			p++
+			if p == len(pattern) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// backward walks pattern back from end, returning the latest start.
+func backward(line, pattern []rune, end int) int {
+	p := len(pattern) - 1
+	for i := end; i >= 0; i-- {
+		if line[i] == pattern[p] {
+			if p == 0 {
+				return i
+			}
+			p--
+		}
+	}
+	return 0
+}
+
+func byteOffset(runes []rune, n int) int {
+	size := 0
+	for _, r := range runes[:n] {
+		size += utf8.RuneLen(r)
+	}
+	return size
+}
+
+// Hit is one matching line: its 0-based index and the byte range matched.
+type Hit struct {
+	Line  int
+	Start int
+	End   int
+}
+
+// Find returns every line match accepts, in order.
+func Find(lines []string, match Matcher) []Hit {
+	var hits []Hit
+	for i, line := range lines {
+		if start, end, ok := match(line); ok {
+			hits = append(hits, Hit{Line: i, Start: start, End: end})
+		}
+	}
+	return hits
+}
+
+// Lines splits captured text into lines, dropping the blank rows below the
+// last output.
+func Lines(text string) []string {
+	lines := strings.Split(strings.TrimRight(text, " \n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
+
+// Row returns the screen row lines[line] starts on, counting from the top of
+// the history, for a capture whose wrapped lines were joined (capture-pane
+// -J) on a pane width cells wide.
+func Row(lines []string, line, width int) int {
+	row := 0
+	for _, l := range lines[:min(line, len(lines))] {
+		row++
+		if width > 0 {
+			if w := ansi.StringWidth(l); w > width {
+				row += (w - 1) / width
+			}
+		}
+	}
+	return row
+}
+
+// Capture is one target's scrollback, split into lines.
+type Capture struct {
+	Target string
+	Lines  []string
+	Err    error
+}
+
+// CaptureAll captures every target with at most workers captures running
+// at once, returning the results in target order.
+func CaptureAll(targets []string, workers int, capture func(string) (string, error)) []Capture {
+	out := make([]Capture, len(targets))
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			text, err := capture(target)
+			out[i] = Capture{Target: target, Lines: Lines(text), Err: err}
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// Highlight wraps line[start:end] in on and off.
+func Highlight(line string, start, end int, on, off string) string {
+	if start < 0 || end > len(line) || start >= end {
+		return line
+	}
+	return line[:start] + on + line[start:end] + off + line[end:]
+}
@@ -0,0 +1,116 @@
+package scrollsearch
+
+import (
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCompileRegex(t *testing.T) {
+	match, err := Compile(`panic: .*nil`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := "goroutine 1: panic: runtime error: nil map"
+	start, end, ok := match(line)
+	if !ok || line[start:end] != "panic: runtime error: nil" {
+		t.Fatalf("match = %d %d %v", start, end, ok)
+	}
+	if _, _, ok := match("all good"); ok {
+		t.Fatal("expected no match")
+	}
+	if _, err := Compile(`(`, true); err == nil {
+		t.Fatal("expected a regexp error")
+	}
+	if _, err := Compile("  ", false); err == nil {
+		t.Fatal("expected an empty query error")
+	}
+}
+
+func TestCompileFuzzy(t *testing.T) {
+	match, err := Compile("nil ptr", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{"invalid memory address or NIL Pointer dereference", "NIL Pointer", true},
+		{"nobody is listening, please try rebooting", "", false},
+		{"nothing here", "", false},
+		{"né nil_ptr", "nil_ptr", true},
+	}
+	for _, c := range cases {
+		start, end, ok := match(c.line)
+		if ok != c.ok || (ok && c.line[start:end] != c.want) {
+			t.Errorf("match(%q) = %q %v, want %q %v", c.line, c.line[start:end], ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFindAndLines(t *testing.T) {
+	lines := Lines("one\nerror two\n\nthree error\n\n\n")
+	if len(lines) != 4 {
+		t.Fatalf("Lines = %q", lines)
+	}
+	match, _ := Compile("error", true)
+	want := []Hit{{Line: 1, Start: 0, End: 5}, {Line: 3, Start: 6, End: 11}}
+	if got := Find(lines, match); !slices.Equal(got, want) {
+		t.Fatalf("Find = %#v, want %#v", got, want)
+	}
+	if Lines("\n\n") != nil {
+		t.Fatal("expected no lines for a blank capture")
+	}
+}
+
+func TestRow(t *testing.T) {
+	lines := []string{"short", "0123456789abcdefghij0", "", "x"}
+	if got := Row(lines, 3, 10); got != 5 {
+		t.Fatalf("Row = %d, want 5", got)
+	}
+	if got := Row(lines, 3, 0); got != 3 {
+		t.Fatalf("Row without a width = %d, want 3", got)
+	}
+}
+
+func TestCaptureAllBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	targets := []string{"%1", "%2", "%3", "%4", "%5"}
+	got := CaptureAll(targets, 2, func(target string) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if target == "%3" {
+			return "", errors.New("gone")
+		}
+		return "line of " + target + "\n", nil
+	})
+	if p := peak.Load(); p > 2 {
+		t.Fatalf("peak concurrency %d, want at most 2", p)
+	}
+	for i, c := range got {
+		if c.Target != targets[i] {
+			t.Fatalf("result %d is %s, want %s", i, c.Target, targets[i])
+		}
+	}
+	if got[2].Err == nil || !slices.Equal(got[4].Lines, []string{"line of %5"}) {
+		t.Fatalf("unexpected captures %#v", got)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	if got := Highlight("a panic here", 2, 7, "[", "]"); got != "a [panic] here" {
+		t.Fatalf("Highlight = %q", got)
+	}
+	if got := Highlight("short", 3, 9, "[", "]"); got != "short" {
+		t.Fatalf("out of range Highlight = %q", got)
+	}
+}
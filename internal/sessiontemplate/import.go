@@ -0,0 +1,300 @@
+package sessiontemplate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Format names a foreign project file format that can be imported.
+type Format string
+
+const (
+	FormatTmuxinator Format = "tmuxinator"
+	FormatTmuxp      Format = "tmuxp"
+)
+
+// Import is a foreign project file mapped onto a template. Warnings name
+// every key that had no equivalent and was dropped.
+type Import struct {
+	Format   Format
+	Path     string
+	Template Template
+	Warnings []string
+}
+
+// ImportFile reads and maps the project file at path.
+func ImportFile(format Format, path string) (Import, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Import{}, err
+	}
+	id := importID(path)
+	var (
+		t        Template
+		warnings []string
+	)
+	switch format {
+	case FormatTmuxinator:
+		t, warnings, err = ImportTmuxinator(id, data)
+	case FormatTmuxp:
+		t, warnings, err = ImportTmuxp(id, data)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return Import{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return Import{Format: format, Path: path, Template: t, Warnings: warnings}, nil
+}
+
+// importID turns a file name into a template ID.
+func importID(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.Map(func(r rune) rune {
+		if r == ':' || unicode.IsSpace(r) {
+			return '-'
+		}
+		return r
+	}, base)
+}
+
+// ImportDirs returns where each format keeps its project files, in the
+// order the tools themselves look.
+func ImportDirs(getenv func(string) string) map[Format][]string {
+	home := strings.TrimSpace(getenv("HOME"))
+	config := strings.TrimSpace(getenv("XDG_CONFIG_HOME"))
+	if config == "" && home != "" {
+		config = filepath.Join(home, ".config")
+	}
+	var tmuxinator, tmuxp []string
+	add := func(list *[]string, dir string) {
+		if dir != "" && !slices.Contains(*list, dir) {
+			*list = append(*list, dir)
+		}
+	}
+	add(&tmuxinator, strings.TrimSpace(getenv("TMUXINATOR_CONFIG")))
+	add(&tmuxp, strings.TrimSpace(getenv("TMUXP_CONFIGDIR")))
+	if config != "" {
+		add(&tmuxinator, filepath.Join(config, "tmuxinator"))
+		add(&tmuxp, filepath.Join(config, "tmuxp"))
+	}
+	if home != "" {
+		add(&tmuxinator, filepath.Join(home, ".tmuxinator"))
+		add(&tmuxp, filepath.Join(home, ".tmuxp"))
+	}
+	return map[Format][]string{FormatTmuxinator: tmuxinator, FormatTmuxp: tmuxp}
+}
+
+var importExts = map[Format][]string{
+	FormatTmuxinator: {".yml", ".yaml"},
+	FormatTmuxp:      {".yml", ".yaml", ".json"},
+}
+
+// LoadImports maps every project file found in dirs, sorted by format then
+// ID. Missing dirs are skipped; a broken file is reported in the error
+// without dropping the others.
+func LoadImports(dirs map[Format][]string) ([]Import, error) {
+	var (
+		imports []Import
+		errs    []error
+	)
+	for _, format := range []Format{FormatTmuxinator, FormatTmuxp} {
+		for _, dir := range dirs[format] {
+			entries, err := os.ReadDir(dir)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, entry := range entries {
+				name := entry.Name()
+				if entry.IsDir() || strings.HasPrefix(name, ".") || !slices.Contains(importExts[format], filepath.Ext(name)) {
+					continue
+				}
+				imp, err := ImportFile(format, filepath.Join(dir, name))
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				imports = append(imports, imp)
+			}
+		}
+	}
+	slices.SortStableFunc(imports, func(a, b Import) int {
+		if a.Format != b.Format {
+			return strings.Compare(string(a.Format), string(b.Format))
+		}
+		return strings.Compare(a.Template.ID, b.Template.ID)
+	})
+	return imports, errors.Join(errs...)
+}
+
+// FindImport returns the import of the file at path from dirs.
+func FindImport(dirs map[Format][]string, path string) (Import, error) {
+	for format, list := range dirs {
+		if slices.Contains(list, filepath.Dir(path)) {
+			return ImportFile(format, path)
+		}
+	}
+	return Import{}, fmt.Errorf("%s is not in a tmuxinator or tmuxp directory", path)
+}
+
+// importer collects warnings while mapping a YAML document.
+type importer struct {
+	warnings []string
+}
+
+func (im *importer) warnf(format string, args ...any) {
+	im.warnings = append(im.warnings, fmt.Sprintf(format, args...))
+}
+
+func (im *importer) unsupported(path, key string) {
+	if path != "" {
+		key = path + "." + key
+	}
+	im.warnf("unsupported key %q", key)
+}
+
+// parseDocument decodes data into its root node, which must be a mapping.
+func parseDocument(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+	root := resolve(&doc)
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil, errors.New("project file must be a mapping")
+	}
+	return root, nil
+}
+
+// resolve unwraps documents and aliases.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			if len(n.Content) == 0 {
+				return nil
+			}
+			n = n.Content[0]
+		case yaml.AliasNode:
+			n = n.Alias
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+type pair struct {
+	key   string
+	value *yaml.Node
+}
+
+// pairs returns a mapping's entries in order.
+func pairs(n *yaml.Node) []pair {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	out := make([]pair, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		out = append(out, pair{key: resolve(n.Content[i]).Value, value: resolve(n.Content[i+1])})
+	}
+	return out
+}
+
+func isNull(n *yaml.Node) bool {
+	return n == nil || (n.Kind == yaml.ScalarNode && n.Tag == "!!null")
+}
+
+func scalar(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode || isNull(n) {
+		return ""
+	}
+	return n.Value
+}
+
+func boolean(n *yaml.Node) bool {
+	v, _ := strconv.ParseBool(scalar(n))
+	return v
+}
+
+// commands reads a command that may be one string or a list of strings.
+func commands(n *yaml.Node) []string {
+	switch {
+	case isNull(n):
+		return nil
+	case n.Kind == yaml.SequenceNode:
+		var out []string
+		for _, item := range n.Content {
+			if cmd := scalar(resolve(item)); cmd != "" {
+				out = append(out, cmd)
+			}
+		}
+		return out
+	default:
+		if cmd := scalar(n); cmd != "" {
+			return []string{cmd}
+		}
+		return nil
+	}
+}
+
+// joinCommands chains commands the way typing them one per line would run
+// them: each regardless of how the previous one exited.
+func joinCommands(parts ...[]string) string {
+	var all []string
+	for _, p := range parts {
+		for _, cmd := range p {
+			if strings.TrimSpace(cmd) != "" {
+				all = append(all, cmd)
+			}
+		}
+	}
+	return strings.Join(all, "; ")
+}
+
+// importName maps a foreign session name. It stays the template's project,
+// so {project} in roots and commands keeps the dots, but the session itself
+// is named after SessionName and the user is told so.
+func (im *importer) importName(where, name string) string {
+	if session := SessionName(name); session != name {
+		im.warnf("%s %q names the session %q, as tmux does not allow . or : in session names", where, name, session)
+	}
+	return name
+}
+
+// importRoot maps a directory from a foreign file: $HOME becomes ~ so the
+// template stays portable, other variables are left for the shell to see
+// and reported.
+func (im *importer) importRoot(where, dir string) string {
+	for _, prefix := range []string{"$HOME", "${HOME}"} {
+		if rest, ok := strings.CutPrefix(dir, prefix); ok && (rest == "" || rest[0] == '/') {
+			dir = "~" + rest
+		}
+	}
+	if strings.Contains(dir, "$") {
+		im.warnf("%s %q uses environment variables, which are not expanded", where, dir)
+	}
+	return dir
+}
+
+// finish normalizes t once the importer has filled it in.
+func (im *importer) finish(t Template) (Template, []string, error) {
+	t, err := Normalize(t)
+	if err != nil {
+		return Template{}, nil, err
+	}
+	return t, im.warnings, nil
+}
@@ -0,0 +1,252 @@
+package sessiontemplate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tmuxinatorProject = `
+name: shop
+root: $HOME/src/shop
+pre_window: nvm use
+on_project_start: docker compose up -d
+startup_window: logs
+startup_pane: 1
+tmux_options: -f ~/.tmux.shop.conf
+windows:
+  - editor:
+      layout: main-vertical
+      panes:
+        - vim
+        - guard
+  - server: bundle exec rails s
+  - logs:
+      root: log
+      panes:
+        - tail -f development.log
+        - named:
+            - cd ..
+            - git status
+  - shell:
+`
+
+func TestImportTmuxinator(t *testing.T) {
+	tmpl, warnings, err := ImportTmuxinator("shop", []byte(tmuxinatorProject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Project != "shop" || tmpl.Root != "~/src/shop" || tmpl.Name != "{project}" {
+		t.Fatalf("unexpected session fields %#v", tmpl)
+	}
+	if !reflect.DeepEqual(tmpl.Pre, []string{"docker compose up -d"}) {
+		t.Fatalf("pre = %q", tmpl.Pre)
+	}
+	if len(tmpl.Windows) != 4 {
+		t.Fatalf("expected 4 windows, got %#v", tmpl.Windows)
+	}
+	editor := tmpl.Windows[0]
+	if editor.Layout != "main-vertical" || len(editor.Panes) != 2 || editor.Panes[1].Command != "nvm use; guard" {
+		t.Fatalf("editor = %#v", editor)
+	}
+	if got := tmpl.Windows[1].Panes[0].Command; got != "nvm use; bundle exec rails s" {
+		t.Fatalf("server command = %q", got)
+	}
+	logs := tmpl.Windows[2]
+	if !logs.Focus || logs.Root != "log" || !logs.Panes[1].Focus || logs.Panes[1].Command != "nvm use; cd ..; git status" {
+		t.Fatalf("logs = %#v", logs)
+	}
+	if got := tmpl.Windows[3].Panes[0].Command; got != "nvm use" {
+		t.Fatalf("empty window should only run pre_window, got %q", got)
+	}
+	if !reflect.DeepEqual(warnings, []string{`unsupported key "tmux_options"`}) {
+		t.Fatalf("warnings = %q", warnings)
+	}
+}
+
+func TestImportTmuxinatorWarnings(t *testing.T) {
+	_, warnings, err := ImportTmuxinator("x", []byte(`
+root: <%= ENV["SRC"] %>/x
+startup_window: missing
+windows:
+  - one:
+      synchronize: true
+      panes: [top]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(warnings, "\n")
+	for _, want := range []string{"ERB tags", `"windows[0].synchronize"`, `startup_window "missing"`} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing warning %q in %q", want, warnings)
+		}
+	}
+}
+
+const tmuxpProject = `
+session_name: api
+start_directory: ~/src/api
+environment:
+  PORT: "8080"
+shell_command_before:
+  - source .venv/bin/activate
+before_script: ./bootstrap
+windows:
+  - window_name: dev
+    layout: tiled
+    focus: true
+    panes:
+      - shell_command:
+          - cmd: make run
+        focus: true
+      - blank
+      - null
+  - window_name: db
+    start_directory: ./db
+    options:
+      automatic-rename: on
+    panes:
+      - psql
+`
+
+func TestImportTmuxp(t *testing.T) {
+	tmpl, warnings, err := ImportTmuxp("api", []byte(tmuxpProject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Project != "api" || tmpl.Root != "~/src/api" || tmpl.Env["PORT"] != "8080" {
+		t.Fatalf("unexpected session fields %#v", tmpl)
+	}
+	if !reflect.DeepEqual(tmpl.Pre, []string{"./bootstrap"}) {
+		t.Fatalf("pre = %q", tmpl.Pre)
+	}
+	dev := tmpl.Windows[0]
+	if !dev.Focus || dev.Layout != "tiled" || len(dev.Panes) != 3 {
+		t.Fatalf("dev = %#v", dev)
+	}
+	if !dev.Panes[0].Focus || dev.Panes[0].Command != "source .venv/bin/activate; make run" {
+		t.Fatalf("first pane = %#v", dev.Panes[0])
+	}
+	for _, p := range dev.Panes[1:] {
+		if p.Command != "source .venv/bin/activate" {
+			t.Fatalf("blank panes should only run shell_command_before, got %q", p.Command)
+		}
+	}
+	if db := tmpl.Windows[1]; db.Root != "./db" || db.Panes[0].Command != "source .venv/bin/activate; psql" {
+		t.Fatalf("db = %#v", db)
+	}
+	if !reflect.DeepEqual(warnings, []string{`unsupported key "windows[1].options"`}) {
+		t.Fatalf("warnings = %q", warnings)
+	}
+}
+
+func TestImportTmuxpJSON(t *testing.T) {
+	tmpl, _, err := ImportTmuxp("j", []byte(`{"session_name": "j", "windows": [{"window_name": "w", "panes": ["htop"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tmpl.Windows) != 1 || tmpl.Windows[0].Panes[0].Command != "htop" {
+		t.Fatalf("unexpected template %#v", tmpl)
+	}
+}
+
+func TestImportDottedName(t *testing.T) {
+	tmpl, warnings, err := ImportTmuxinator("site", []byte("name: example.com\nroot: ~/src/{project}\nwindows:\n  - editor: vim\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"example_com"`) {
+		t.Fatalf("warnings = %q", warnings)
+	}
+	expanded := tmpl.Expand(Vars{Project: tmpl.Project, Root: tmpl.Root}, "/home/me")
+	if expanded.Name != "example_com" || expanded.Root != "/home/me/src/example.com" {
+		t.Fatalf("expected a sanitised session in the dotted root, got %q in %q", expanded.Name, expanded.Root)
+	}
+
+	tmpl, warnings, err = ImportTmuxp("api", []byte("session_name: api.v2\nwindows:\n  - panes: [make]\n"))
+	if err != nil || len(warnings) != 1 || tmpl.Expand(Vars{Project: tmpl.Project}, "/home/me").Name != "api_v2" {
+		t.Fatalf("tmuxp dotted name: %#v %q %v", tmpl, warnings, err)
+	}
+}
+
+func TestImportRejectsNonMapping(t *testing.T) {
+	if _, _, err := ImportTmuxp("x", []byte("- a\n- b\n")); err == nil {
+		t.Fatal("expected an error for a list document")
+	}
+}
+
+func TestImportDirs(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me", "TMUXP_CONFIGDIR": "/etc/tmuxp"}
+	dirs := ImportDirs(func(key string) string { return env[key] })
+	want := map[Format][]string{
+		FormatTmuxinator: {"/home/me/.config/tmuxinator", "/home/me/.tmuxinator"},
+		FormatTmuxp:      {"/etc/tmuxp", "/home/me/.config/tmuxp", "/home/me/.tmuxp"},
+	}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Fatalf("dirs = %q", dirs)
+	}
+}
+
+func TestLoadAndFindImports(t *testing.T) {
+	base := t.TempDir()
+	tmuxinator := filepath.Join(base, "tmuxinator")
+	tmuxp := filepath.Join(base, "tmuxp")
+	for path, body := range map[string]string{
+		filepath.Join(tmuxinator, "shop.yml"):       tmuxinatorProject,
+		filepath.Join(tmuxinator, "notes.txt"):      "ignored",
+		filepath.Join(tmuxp, "api.yaml"):            tmuxpProject,
+		filepath.Join(tmuxp, "broken session.json"): "{",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirs := map[Format][]string{FormatTmuxinator: {tmuxinator}, FormatTmuxp: {tmuxp, filepath.Join(base, "missing")}}
+
+	imports, err := LoadImports(dirs)
+	if err == nil || !strings.Contains(err.Error(), "broken session.json") {
+		t.Fatalf("expected the broken file to be reported, got %v", err)
+	}
+	if len(imports) != 2 || imports[0].Template.ID != "shop" || imports[1].Format != FormatTmuxp {
+		t.Fatalf("imports = %#v", imports)
+	}
+
+	imp, err := FindImport(dirs, filepath.Join(tmuxp, "api.yaml"))
+	if err != nil || imp.Template.Project != "api" {
+		t.Fatalf("FindImport = %#v, %v", imp, err)
+	}
+	if _, err := FindImport(dirs, "/tmp/elsewhere.yml"); err == nil {
+		t.Fatal("expected files outside the import dirs to be refused")
+	}
+	if got := importID("broken session.json"); got != "broken-session" {
+		t.Fatalf("importID = %q", got)
+	}
+}
+
+func TestSaveRefusesToOverwrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+	tmpl, _, err := ImportTmuxp("api", []byte(tmuxpProject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := Save(dir, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Find(dir, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, tmpl) {
+		t.Fatalf("round trip mismatch:\n%#v\n%#v", loaded, tmpl)
+	}
+	if _, err := Save(dir, tmpl); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected %s not to be overwritten, got %v", path, err)
+	}
+}
@@ -0,0 +1,398 @@
+// Package sessiontemplate parses tmuxinator-style project layouts: one JSON
+// file per template in a templates directory, declaring the session's
+// windows, panes, layouts, working directories, startup commands,
+// environment and pre/post hooks. it only parses, expands and describes
+// templates: building the session is the menu package's job, so there are no
+// tmux, bubbletea, or menu imports here.
+package sessiontemplate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/atomicstack/tmux-popup-control/internal/shquote"
+)
+
+// Ext is the file extension of a template; the rest of the file name is the
+// template ID unless the file sets one.
+const Ext = ".json"
+
+// Template is one project layout. Name is the session name and defaults to
+// "{project}"; Project is the project name the form offers, defaulting to
+// the ID. Strings support the placeholders {project} (the project name
+// entered in the form) and {root} (the project root); in Pre, Post and pane
+// commands the values are shell-quoted for you.
+type Template struct {
+	ID          string            `json:"id,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Project     string            `json:"project,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Root        string            `json:"root,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Pre         []string          `json:"pre,omitempty"`
+	Post        []string          `json:"post,omitempty"`
+	Windows     []Window          `json:"windows,omitempty"`
+}
+
+// Window is one window of a template. A relative Root is resolved against
+// the template root; Layout is any layout select-layout accepts.
+type Window struct {
+	Name   string `json:"name,omitempty"`
+	Root   string `json:"root,omitempty"`
+	Layout string `json:"layout,omitempty"`
+	Focus  bool   `json:"focus,omitempty"`
+	Panes  []Pane `json:"panes,omitempty"`
+}
+
+// Pane is one pane of a window. A relative Root is resolved against the
+// window root; Command runs in the pane and drops back to the shell when it
+// exits. A pane without a command is just a shell.
+type Pane struct {
+	Root    string `json:"root,omitempty"`
+	Command string `json:"command,omitempty"`
+	Focus   bool   `json:"focus,omitempty"`
+}
+
+// Parse reads one template file body. id is used when the file sets none.
+func Parse(id string, data []byte) (Template, error) {
+	var t Template
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Template{}, fmt.Errorf("parse template: %w", err)
+	}
+	if t.ID == "" {
+		t.ID = id
+	}
+	return Normalize(t)
+}
+
+// Normalize validates t and fills in the defaults: a template without
+// windows gets one, a window without panes gets one shell pane, and an
+// unnamed window is named after its first pane's program.
+func Normalize(t Template) (Template, error) {
+	if t.ID == "" {
+		return Template{}, errors.New("template needs an id")
+	}
+	if strings.ContainsFunc(t.ID, func(r rune) bool { return r == ':' || unicode.IsSpace(r) }) {
+		return Template{}, fmt.Errorf("template id %q must not contain ':' or whitespace", t.ID)
+	}
+	if t.Name == "" {
+		t.Name = "{project}"
+	}
+	if t.Project == "" {
+		t.Project = t.ID
+	}
+	for key := range t.Env {
+		if key == "" || strings.ContainsAny(key, "= ") {
+			return Template{}, fmt.Errorf("template %q: invalid env name %q", t.ID, key)
+		}
+	}
+	if len(t.Windows) == 0 {
+		t.Windows = []Window{{}}
+	}
+	windows := make([]Window, len(t.Windows))
+	for i, w := range t.Windows {
+		if len(w.Panes) == 0 {
+			w.Panes = []Pane{{}}
+		}
+		w.Panes = slices.Clone(w.Panes)
+		if w.Name == "" {
+			w.Name = defaultWindowName(w.Panes[0])
+		}
+		windows[i] = w
+	}
+	t.Windows = windows
+	return t, nil
+}
+
+func defaultWindowName(p Pane) string {
+	fields := strings.Fields(p.Command)
+	if len(fields) == 0 {
+		return "shell"
+	}
+	return filepath.Base(fields[0])
+}
+
+// Load reads every template in dir, sorted by ID. A missing dir is not an
+// error: templates are optional. A broken file is reported in the error
+// without dropping the others.
+func Load(dir string) ([]Template, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var (
+		templates []Template
+		errs      []error
+	)
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != Ext || strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t, err := Parse(strings.TrimSuffix(name, Ext), data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		if prev, ok := seen[t.ID]; ok {
+			errs = append(errs, fmt.Errorf("%s: template id %q already used by %s", path, t.ID, prev))
+			continue
+		}
+		seen[t.ID] = path
+		templates = append(templates, t)
+	}
+	slices.SortFunc(templates, func(a, b Template) int { return strings.Compare(a.ID, b.ID) })
+	return templates, errors.Join(errs...)
+}
+
+// Find loads dir and returns the template with the given ID. Broken
+// siblings do not stop a healthy template from being found.
+func Find(dir, id string) (Template, error) {
+	templates, err := Load(dir)
+	for _, t := range templates {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{}, fmt.Errorf("template %q not found", id)
+}
+
+// Save writes t to dir as <id>.json, creating dir if needed. An existing
+// template of the same ID is never overwritten.
+func Save(dir string, t Template) (string, error) {
+	if _, err := Normalize(t); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	id := t.ID
+	t.ID = ""
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal template: %w", err)
+	}
+	path := filepath.Join(dir, id+Ext)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("template %q already exists in %s", id, dir)
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// Vars are the values the form fills in. Root may itself use {project}.
+type Vars struct {
+	Project string
+	Root    string
+}
+
+// Expand returns t with every placeholder substituted, "~" expanded to home
+// and each window and pane root made absolute. v.Root replaces the
+// template's root; an empty root falls back to home.
+func (t Template) Expand(v Vars, home string) Template {
+	project := strings.TrimSpace(v.Project)
+	root := expandHome(strings.ReplaceAll(strings.TrimSpace(v.Root), "{project}", project), home)
+	if root == "" {
+		root = home
+	}
+	raw := strings.NewReplacer("{project}", project, "{root}", root)
+	quoted := strings.NewReplacer("{project}", shquote.Quote(project), "{root}", shquote.Quote(root))
+
+	out := Template{
+		ID:          t.ID,
+		Description: t.Description,
+		Project:     project,
+		Name:        SessionName(raw.Replace(t.Name)),
+		Root:        root,
+	}
+	if len(t.Env) > 0 {
+		out.Env = make(map[string]string, len(t.Env))
+		for key, value := range t.Env {
+			out.Env[key] = raw.Replace(value)
+		}
+	}
+	for _, hook := range t.Pre {
+		out.Pre = append(out.Pre, quoted.Replace(hook))
+	}
+	for _, hook := range t.Post {
+		out.Post = append(out.Post, quoted.Replace(hook))
+	}
+	for _, w := range t.Windows {
+		ew := Window{
+			Name:   raw.Replace(w.Name),
+			Root:   resolveDir(root, raw.Replace(w.Root), home),
+			Layout: w.Layout,
+			Focus:  w.Focus,
+		}
+		for _, p := range w.Panes {
+			ew.Panes = append(ew.Panes, Pane{
+				Root:    resolveDir(ew.Root, raw.Replace(p.Root), home),
+				Command: quoted.Replace(p.Command),
+				Focus:   p.Focus,
+			})
+		}
+		out.Windows = append(out.Windows, ew)
+	}
+	return out
+}
+
+// SessionName returns name the way tmux stores it: new-session turns "." and
+// ":" into "_", so a name that kept them would no longer find its session.
+func SessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(strings.TrimSpace(name))
+}
+
+// EnvList returns t.Env as sorted KEY=value pairs.
+func (t Template) EnvList() []string {
+	env := make([]string, 0, len(t.Env))
+	for key, value := range t.Env {
+		env = append(env, key+"="+value)
+	}
+	slices.Sort(env)
+	return env
+}
+
+func expandHome(p, home string) string {
+	if p == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return p
+}
+
+func resolveDir(base, p, home string) string {
+	p = expandHome(p, home)
+	if p == "" {
+		return base
+	}
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(base, p)
+}
+
+// Tree describes t for the picker's preview: its root, environment and
+// hooks, then one branch per window with a leaf per pane. Placeholders are
+// shown as written.
+func Tree(t Template) []string {
+	var lines []string
+	if t.Description != "" {
+		lines = append(lines, t.Description, "")
+	}
+	lines = append(lines, "session "+t.Name)
+	if t.Root != "" {
+		lines = append(lines, "root    "+t.Root)
+	}
+	for _, kv := range t.EnvList() {
+		lines = append(lines, "env     "+kv)
+	}
+	for _, hook := range t.Pre {
+		lines = append(lines, "pre     "+hook)
+	}
+	lines = append(lines, "")
+	for i, w := range t.Windows {
+		branch, indent := "├─ ", "│  "
+		if i == len(t.Windows)-1 {
+			branch, indent = "└─ ", "   "
+		}
+		lines = append(lines, branch+windowLabel(w))
+		for j, p := range w.Panes {
+			leaf := "├─ "
+			if j == len(w.Panes)-1 {
+				leaf = "└─ "
+			}
+			lines = append(lines, indent+leaf+paneLabel(p))
+		}
+	}
+	if len(t.Post) > 0 {
+		lines = append(lines, "")
+		for _, hook := range t.Post {
+			lines = append(lines, "post    "+hook)
+		}
+	}
+	return lines
+}
+
+func windowLabel(w Window) string {
+	parts := []string{w.Name}
+	if w.Layout != "" {
+		parts = append(parts, "["+w.Layout+"]")
+	}
+	if w.Root != "" {
+		parts = append(parts, "in "+w.Root)
+	}
+	if w.Focus {
+		parts = append(parts, "*")
+	}
+	return strings.Join(parts, " ")
+}
+
+func paneLabel(p Pane) string {
+	parts := []string{p.Command}
+	if p.Command == "" {
+		parts[0] = "(shell)"
+	}
+	if p.Root != "" {
+		parts = append(parts, "in "+p.Root)
+	}
+	if p.Focus {
+		parts = append(parts, "*")
+	}
+	return strings.Join(parts, " ")
+}
+
+const (
+	envDir = "TMUX_POPUP_CONTROL_TEMPLATES_DIR"
+	optDir = "@tmux-popup-control-templates-dir"
+)
+
+// ResolveDir returns the templates directory from the environment, then the
+// tmux option, then $XDG_CONFIG_HOME/tmux-popup-control/templates.
+func ResolveDir(getenv, option func(string) string) string {
+	if v := strings.TrimSpace(getenv(envDir)); v != "" {
+		return os.Expand(v, getenv)
+	}
+	if v := strings.TrimSpace(option(optDir)); v != "" {
+		return os.Expand(v, getenv)
+	}
+	base := strings.TrimSpace(getenv("XDG_CONFIG_HOME"))
+	if base == "" {
+		home := strings.TrimSpace(getenv("HOME"))
+		if home == "" {
+			return ""
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "tmux-popup-control", "templates")
+}
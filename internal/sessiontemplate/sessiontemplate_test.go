@@ -0,0 +1,172 @@
+package sessiontemplate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const webapp = `{
+	"description": "web app",
+	"root": "~/src/{project}",
+	"env": {"APP_ENV": "dev", "APP_NAME": "{project}"},
+	"pre": ["git -C {root} pull"],
+	"windows": [
+		{"name": "editor", "layout": "main-vertical", "panes": [
+			{"command": "nvim ."},
+			{"root": "cmd", "command": "go test ./... && echo {project}", "focus": true}
+		]},
+		{"name": "logs", "root": "/var/log"}
+	]
+}`
+
+func TestParseFillsDefaults(t *testing.T) {
+	tmpl, err := Parse("webapp", []byte(webapp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.ID != "webapp" || tmpl.Name != "{project}" {
+		t.Fatalf("unexpected id/name %q %q", tmpl.ID, tmpl.Name)
+	}
+	if len(tmpl.Windows[1].Panes) != 1 {
+		t.Fatalf("expected a default pane, got %#v", tmpl.Windows[1].Panes)
+	}
+	named, _ := Parse("named", []byte(`{"windows": [{"panes": [{"command": "/usr/bin/htop -d 5"}]}]}`))
+	if named.Windows[0].Name != "htop" {
+		t.Fatalf("expected window named after its program, got %q", named.Windows[0].Name)
+	}
+
+	empty, err := Parse("blank", []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(empty.Windows) != 1 || len(empty.Windows[0].Panes) != 1 || empty.Windows[0].Name != "shell" {
+		t.Fatalf("expected one window with one pane, got %#v", empty.Windows)
+	}
+}
+
+func TestParseRejectsInvalidTemplates(t *testing.T) {
+	cases := map[string]string{
+		"colon in id": `{"id": "a:b"}`,
+		"bad env":     `{"env": {"A=B": "x"}}`,
+		"not json":    `{`,
+	}
+	for name, body := range cases {
+		if _, err := Parse("ok", []byte(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestExpandResolvesPlaceholdersAndRoots(t *testing.T) {
+	tmpl, err := Parse("webapp", []byte(webapp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := tmpl.Expand(Vars{Project: "my app", Root: tmpl.Root}, "/home/me")
+
+	if got.Name != "my app" || got.Root != "/home/me/src/my app" {
+		t.Fatalf("unexpected name/root %q %q", got.Name, got.Root)
+	}
+	if got.Env["APP_NAME"] != "my app" {
+		t.Fatalf("env not expanded: %#v", got.Env)
+	}
+	if got.Pre[0] != "git -C '/home/me/src/my app' pull" {
+		t.Fatalf("pre hook not quoted: %q", got.Pre[0])
+	}
+	editor := got.Windows[0]
+	if editor.Root != "/home/me/src/my app" || editor.Panes[1].Root != "/home/me/src/my app/cmd" {
+		t.Fatalf("unexpected editor roots %#v", editor)
+	}
+	if editor.Panes[1].Command != "go test ./... && echo 'my app'" {
+		t.Fatalf("pane command not quoted: %q", editor.Panes[1].Command)
+	}
+	if got.Windows[1].Panes[0].Root != "/var/log" {
+		t.Fatalf("absolute window root should win, got %#v", got.Windows[1])
+	}
+	if !reflect.DeepEqual(got.EnvList(), []string{"APP_ENV=dev", "APP_NAME=my app"}) {
+		t.Fatalf("unexpected env list %q", got.EnvList())
+	}
+}
+
+func TestExpandEmptyRootFallsBackToHome(t *testing.T) {
+	tmpl, _ := Parse("blank", []byte(`{"root": "/srv/{project}"}`))
+	got := tmpl.Expand(Vars{Project: "x"}, "/home/me")
+	if got.Root != "/home/me" || got.Windows[0].Panes[0].Root != "/home/me" {
+		t.Fatalf("expected home root, got %#v", got)
+	}
+}
+
+func TestLoadSkipsBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"webapp.json": webapp,
+		"broken.json": `{`,
+		"notes.txt":   `ignored`,
+		"api.json":    `{"description": "api"}`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates, err := Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Fatalf("expected an error naming broken.json, got %v", err)
+	}
+	if len(templates) != 2 || templates[0].ID != "api" || templates[1].ID != "webapp" {
+		t.Fatalf("unexpected templates %#v", templates)
+	}
+	found, err := Find(dir, "webapp")
+	if err != nil || found.Description != "web app" {
+		t.Fatalf("Find: %#v %v", found, err)
+	}
+	if _, err := Find(dir, "missing"); err == nil {
+		t.Fatal("expected an error for a missing template")
+	}
+}
+
+func TestLoadMissingDirIsEmpty(t *testing.T) {
+	templates, err := Load(filepath.Join(t.TempDir(), "nope"))
+	if err != nil || templates != nil {
+		t.Fatalf("expected nothing, got %#v %v", templates, err)
+	}
+}
+
+func TestTreeDescribesLayout(t *testing.T) {
+	tmpl, _ := Parse("webapp", []byte(webapp))
+	tree := strings.Join(Tree(tmpl), "\n")
+	for _, want := range []string{
+		"root    ~/src/{project}",
+		"env     APP_ENV=dev",
+		"pre     git -C {root} pull",
+		"├─ editor [main-vertical]",
+		"│  ├─ nvim .",
+		"│  └─ go test ./... && echo {project} in cmd *",
+		"└─ logs in /var/log",
+		"   └─ (shell)",
+	} {
+		if !strings.Contains(tree, want) {
+			t.Errorf("tree missing %q:\n%s", want, tree)
+		}
+	}
+}
+
+func TestResolveDir(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me"}
+	getenv := func(k string) string { return env[k] }
+	none := func(string) string { return "" }
+	if got := ResolveDir(getenv, none); got != "/home/me/.config/tmux-popup-control/templates" {
+		t.Fatalf("default dir = %q", got)
+	}
+	option := func(string) string { return "$HOME/layouts" }
+	if got := ResolveDir(getenv, option); got != "/home/me/layouts" {
+		t.Fatalf("option dir = %q", got)
+	}
+	env[envDir] = "/etc/templates"
+	if got := ResolveDir(getenv, option); got != "/etc/templates" {
+		t.Fatalf("env dir = %q", got)
+	}
+}
@@ -0,0 +1,135 @@
+package sessiontemplate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ImportTmuxinator maps a tmuxinator project file onto a template. Windows
+// are one-key mappings whose value is a command, a list of commands, or a
+// mapping with layout, root, pre and panes; pre_window is prefixed to every
+// pane's commands. ERB tags are left as they are.
+func ImportTmuxinator(id string, data []byte) (Template, []string, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return Template{}, nil, err
+	}
+	im := &importer{}
+	if strings.Contains(string(data), "<%") {
+		im.warnf("ERB tags are not evaluated")
+	}
+	t := Template{ID: id, Name: "{project}"}
+	var (
+		preWindow     []string
+		startupWindow string
+		startupPane   string
+		windows       *yaml.Node
+	)
+	for _, p := range pairs(root) {
+		switch p.key {
+		case "name", "project_name":
+			t.Project = im.importName(p.key, scalar(p.value))
+		case "root", "project_root":
+			t.Root = im.importRoot("root", scalar(p.value))
+		case "pre_window", "pre_tab":
+			preWindow = commands(p.value)
+		case "on_project_start", "on_project_first_start", "pre":
+			t.Pre = append(t.Pre, commands(p.value)...)
+		case "post":
+			t.Post = append(t.Post, commands(p.value)...)
+		case "startup_window":
+			startupWindow = scalar(p.value)
+		case "startup_pane":
+			startupPane = scalar(p.value)
+		case "windows", "tabs":
+			windows = p.value
+		default:
+			im.unsupported("", p.key)
+		}
+	}
+	if windows != nil && windows.Kind == yaml.SequenceNode {
+		for i, item := range windows.Content {
+			for _, p := range pairs(item) {
+				t.Windows = append(t.Windows, im.tmuxinatorWindow(fmt.Sprintf("windows[%d]", i), p.key, p.value, preWindow))
+			}
+		}
+	}
+	focusWindow := findWindow(t.Windows, startupWindow)
+	if startupWindow != "" && focusWindow < 0 {
+		im.warnf("startup_window %q matches no window", startupWindow)
+	}
+	if focusWindow >= 0 {
+		t.Windows[focusWindow].Focus = true
+		if n, err := strconv.Atoi(startupPane); err == nil && n >= 0 && n < len(t.Windows[focusWindow].Panes) {
+			t.Windows[focusWindow].Panes[n].Focus = true
+		} else if startupPane != "" {
+			im.warnf("startup_pane %q matches no pane", startupPane)
+		}
+	}
+	return im.finish(t)
+}
+
+func (im *importer) tmuxinatorWindow(path, name string, value *yaml.Node, preWindow []string) Window {
+	w := Window{Name: name}
+	if value == nil || value.Kind != yaml.MappingNode {
+		// a bare value is the commands of the window's only pane.
+		w.Panes = []Pane{{Command: joinCommands(preWindow, commands(value))}}
+		return w
+	}
+	var (
+		pre   []string
+		panes *yaml.Node
+	)
+	for _, p := range pairs(value) {
+		switch p.key {
+		case "layout":
+			w.Layout = scalar(p.value)
+		case "root":
+			w.Root = im.importRoot(path+".root", scalar(p.value))
+		case "pre":
+			pre = commands(p.value)
+		case "panes":
+			panes = p.value
+		default:
+			im.unsupported(path, p.key)
+		}
+	}
+	if panes == nil || panes.Kind != yaml.SequenceNode || len(panes.Content) == 0 {
+		w.Panes = []Pane{{Command: joinCommands(preWindow, pre)}}
+		return w
+	}
+	for _, item := range panes.Content {
+		item = resolve(item)
+		var cmds []string
+		if item != nil && item.Kind == yaml.MappingNode {
+			// a named pane: the name is only a label in tmuxinator.
+			for _, p := range pairs(item) {
+				cmds = append(cmds, commands(p.value)...)
+			}
+		} else {
+			cmds = commands(item)
+		}
+		w.Panes = append(w.Panes, Pane{Command: joinCommands(preWindow, pre, cmds)})
+	}
+	return w
+}
+
+// findWindow returns the window named ref, else the one at index ref
+// counted from tmux's default base-index of 0, else -1.
+func findWindow(windows []Window, ref string) int {
+	if ref == "" {
+		return -1
+	}
+	for i, w := range windows {
+		if w.Name == ref {
+			return i
+		}
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 0 && n < len(windows) {
+		return n
+	}
+	return -1
+}
@@ -0,0 +1,166 @@
+package sessiontemplate
+
+import (
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// tmuxpIgnored are tmuxp keys that only tune how tmuxp itself types the
+// commands; the template runs them directly, so they are dropped silently.
+var tmuxpIgnored = map[string]bool{
+	"suppress_history": true,
+	"sleep_before":     true,
+	"sleep_after":      true,
+	"enter":            true,
+}
+
+// ImportTmuxp maps a tmuxp session file (YAML or JSON) onto a template.
+// shell_command_before at session and window level is prefixed to every
+// pane's shell_command, and before_script becomes a pre hook.
+func ImportTmuxp(id string, data []byte) (Template, []string, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return Template{}, nil, err
+	}
+	im := &importer{}
+	t := Template{ID: id, Name: "{project}"}
+	var (
+		before  []string
+		windows *yaml.Node
+	)
+	for _, p := range pairs(root) {
+		switch p.key {
+		case "session_name":
+			t.Project = im.importName("session_name", scalar(p.value))
+		case "start_directory":
+			t.Root = im.importRoot("start_directory", scalar(p.value))
+		case "environment":
+			t.Env = im.tmuxpEnv("environment", p.value)
+		case "shell_command_before":
+			before = im.tmuxpCommands("shell_command_before", p.value)
+		case "before_script":
+			if script := scalar(p.value); script != "" {
+				t.Pre = append(t.Pre, script)
+			}
+		case "windows":
+			windows = p.value
+		default:
+			if !tmuxpIgnored[p.key] {
+				im.unsupported("", p.key)
+			}
+		}
+	}
+	if windows != nil && windows.Kind == yaml.SequenceNode {
+		for i, item := range windows.Content {
+			t.Windows = append(t.Windows, im.tmuxpWindow(fmt.Sprintf("windows[%d]", i), resolve(item), before))
+		}
+	}
+	return im.finish(t)
+}
+
+func (im *importer) tmuxpWindow(path string, value *yaml.Node, sessionBefore []string) Window {
+	var (
+		w      Window
+		before []string
+		panes  *yaml.Node
+	)
+	for _, p := range pairs(value) {
+		switch p.key {
+		case "window_name":
+			w.Name = scalar(p.value)
+		case "layout":
+			w.Layout = scalar(p.value)
+		case "start_directory":
+			w.Root = im.importRoot(path+".start_directory", scalar(p.value))
+		case "shell_command_before":
+			before = im.tmuxpCommands(path+".shell_command_before", p.value)
+		case "focus":
+			w.Focus = boolean(p.value)
+		case "panes":
+			panes = p.value
+		default:
+			if !tmuxpIgnored[p.key] {
+				im.unsupported(path, p.key)
+			}
+		}
+	}
+	if panes == nil || panes.Kind != yaml.SequenceNode {
+		w.Panes = []Pane{{Command: joinCommands(sessionBefore, before)}}
+		return w
+	}
+	for j, item := range panes.Content {
+		pane := im.tmuxpPane(fmt.Sprintf("%s.panes[%d]", path, j), resolve(item))
+		pane.Command = joinCommands(sessionBefore, before, []string{pane.Command})
+		w.Panes = append(w.Panes, pane)
+	}
+	return w
+}
+
+func (im *importer) tmuxpPane(path string, value *yaml.Node) Pane {
+	if value == nil || value.Kind != yaml.MappingNode {
+		cmd := joinCommands(im.tmuxpCommands(path, value))
+		// tmuxp spells an empty pane as null, "blank" or "pane".
+		if cmd == "blank" || cmd == "pane" {
+			cmd = ""
+		}
+		return Pane{Command: cmd}
+	}
+	var p Pane
+	for _, kv := range pairs(value) {
+		switch kv.key {
+		case "shell_command":
+			p.Command = joinCommands(im.tmuxpCommands(path+".shell_command", kv.value))
+		case "start_directory":
+			p.Root = im.importRoot(path+".start_directory", scalar(kv.value))
+		case "focus":
+			p.Focus = boolean(kv.value)
+		default:
+			if !tmuxpIgnored[kv.key] {
+				im.unsupported(path, kv.key)
+			}
+		}
+	}
+	return p
+}
+
+// tmuxpCommands reads a command list whose items are strings or, in newer
+// tmuxp files, mappings with a cmd key.
+func (im *importer) tmuxpCommands(path string, value *yaml.Node) []string {
+	if value == nil || value.Kind != yaml.SequenceNode {
+		return commands(value)
+	}
+	var out []string
+	for i, item := range value.Content {
+		item = resolve(item)
+		if item.Kind != yaml.MappingNode {
+			out = append(out, commands(item)...)
+			continue
+		}
+		for _, p := range pairs(item) {
+			switch {
+			case p.key == "cmd":
+				out = append(out, commands(p.value)...)
+			case !tmuxpIgnored[p.key]:
+				im.unsupported(fmt.Sprintf("%s[%d]", path, i), p.key)
+			}
+		}
+	}
+	return out
+}
+
+func (im *importer) tmuxpEnv(path string, value *yaml.Node) map[string]string {
+	entries := pairs(value)
+	if len(entries) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(entries))
+	for _, p := range entries {
+		if p.value != nil && p.value.Kind != yaml.ScalarNode {
+			im.warnf("%s.%s is not a plain value", path, p.key)
+			continue
+		}
+		env[p.key] = scalar(p.value)
+	}
+	return env
+}
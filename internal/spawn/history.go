@@ -0,0 +1,90 @@
+package spawn
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/atomicstack/tmux-popup-control/internal/statefile"
+)
+
+// maxHistory caps the commands remembered; the oldest are dropped first.
+const maxHistory = 100
+
+func historyPath(stateDir string) string {
+	return filepath.Join(stateDir, ".spawn-history")
+}
+
+func historyLockPath(stateDir string) string {
+	return filepath.Join(stateDir, ".spawn-history.lock")
+}
+
+// LoadHistory returns the commands run in new panes, newest first.
+func LoadHistory(stateDir string) ([]string, error) {
+	return statefile.Load[[]string](historyPath(stateDir), "spawn history")
+}
+
+// RecordHistory moves command to the front of the history.
+func RecordHistory(stateDir, command string) error {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil
+	}
+	return statefile.Update(historyLockPath(stateDir), historyPath(stateDir), "spawn history", func(history *[]string) {
+		h := slices.DeleteFunc(*history, func(c string) bool { return c == command })
+		h = append([]string{command}, h...)
+		if len(h) > maxHistory {
+			h = h[:maxHistory]
+		}
+		*history = h
+	})
+}
+
+// History steps through past commands from a draft, shell style: only the
+// entries starting with the draft are visited, newest first.
+type History struct {
+	entries []string
+	matches []string
+	draft   string
+	pos     int // index into matches; -1 is the draft itself
+}
+
+// NewHistory returns a History over entries, newest first.
+func NewHistory(entries []string) *History {
+	return &History{entries: entries, pos: -1}
+}
+
+// Older returns the next older entry matching the draft, starting a new
+// walk from current when not already walking. ok is false when there is
+// nothing older.
+func (h *History) Older(current string) (string, bool) {
+	if h.pos == -1 {
+		h.draft = current
+		h.matches = slices.DeleteFunc(slices.Clone(h.entries), func(e string) bool {
+			return !strings.HasPrefix(e, current) || e == current
+		})
+	}
+	if h.pos+1 >= len(h.matches) {
+		return "", false
+	}
+	h.pos++
+	return h.matches[h.pos], true
+}
+
+// Newer returns the next newer entry, or the draft once past the newest.
+// ok is false when not walking.
+func (h *History) Newer() (string, bool) {
+	if h.pos == -1 {
+		return "", false
+	}
+	h.pos--
+	if h.pos == -1 {
+		return h.draft, true
+	}
+	return h.matches[h.pos], true
+}
+
+// Reset ends the walk, so the next Older starts from a fresh draft.
+func (h *History) Reset() {
+	h.pos = -1
+}
@@ -0,0 +1,54 @@
+package spawn
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRecordHistory(t *testing.T) {
+	dir := t.TempDir()
+	for _, c := range []string{"make", "htop", " make ", ""} {
+		if err := RecordHistory(dir, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := LoadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"make", "htop"}; !slices.Equal(got, want) {
+		t.Fatalf("history = %q, want %q", got, want)
+	}
+	for i := range maxHistory + 5 {
+		if err := RecordHistory(dir, string(rune('a'+i%26))+string(rune('a'+i/26))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := LoadHistory(dir); len(got) != maxHistory {
+		t.Fatalf("expected the history capped at %d, got %d", maxHistory, len(got))
+	}
+}
+
+func TestHistoryWalk(t *testing.T) {
+	h := NewHistory([]string{"make test", "htop", "make", "make lint"})
+	var seen []string
+	for {
+		entry, ok := h.Older("make")
+		if !ok {
+			break
+		}
+		seen = append(seen, entry)
+	}
+	if want := []string{"make test", "make lint"}; !slices.Equal(seen, want) {
+		t.Fatalf("walk = %q, want %q", seen, want)
+	}
+	if entry, _ := h.Newer(); entry != "make test" {
+		t.Fatalf("Newer = %q", entry)
+	}
+	if entry, _ := h.Newer(); entry != "make" {
+		t.Fatalf("expected the draft back, got %q", entry)
+	}
+	if _, ok := h.Newer(); ok {
+		t.Fatal("expected nothing newer than the draft")
+	}
+}
@@ -0,0 +1,184 @@
+// Package spawn covers starting a process in a new or respawned pane: the
+// split-window and respawn-pane arguments, start directory completion, and
+// the history of commands run this way. Running tmux is the menu package's
+// job, so there are no tmux, bubbletea, or menu imports here.
+package spawn
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// Split is where a new pane goes relative to the one it splits.
+type Split struct {
+	// Horizontal puts the panes side by side (-h); otherwise they are
+	// stacked (-v).
+	Horizontal bool
+	// Before puts the new pane left of or above the target (-b).
+	Before bool
+	// Full makes the new pane span the whole window (-f).
+	Full bool
+}
+
+// Placement is a named Split, as offered by the split menu.
+type Placement struct {
+	Name  string
+	Split Split
+}
+
+// Placements lists the splits in the order the menu offers them, the most
+// common first.
+var Placements = []Placement{
+	{"right", Split{Horizontal: true}},
+	{"below", Split{}},
+	{"left", Split{Horizontal: true, Before: true}},
+	{"above", Split{Before: true}},
+	{"right-full", Split{Horizontal: true, Full: true}},
+	{"below-full", Split{Full: true}},
+	{"left-full", Split{Horizontal: true, Before: true, Full: true}},
+	{"above-full", Split{Before: true, Full: true}},
+}
+
+// FindPlacement returns the placement called name.
+func FindPlacement(name string) (Split, bool) {
+	for _, p := range Placements {
+		if p.Name == name {
+			return p.Split, true
+		}
+	}
+	return Split{}, false
+}
+
+// Flags returns the split-window flags for s.
+func (s Split) Flags() []string {
+	flags := []string{"-v"}
+	if s.Horizontal {
+		flags[0] = "-h"
+	}
+	if s.Before {
+		flags = append(flags, "-b")
+	}
+	if s.Full {
+		flags = append(flags, "-f")
+	}
+	return flags
+}
+
+// ParsePercent parses a new pane's size in percent. Blank means tmux's
+// default, returned as 0.
+func ParsePercent(s string) (int, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "%")
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 99 {
+		return 0, fmt.Errorf("size must be a percentage from 1 to 99")
+	}
+	return n, nil
+}
+
+// SplitArgs returns the split-window invocation splitting target. Zero
+// percent, an empty dir, or an empty command leave tmux's defaults.
+func SplitArgs(target string, s Split, percent int, dir, command string) []string {
+	args := append([]string{"split-window", "-t", target}, s.Flags()...)
+	if percent > 0 {
+		args = append(args, "-l", strconv.Itoa(percent)+"%")
+	}
+	return appendDirAndCommand(args, dir, command)
+}
+
+// RespawnArgs returns the respawn-pane invocation for target; kill adds -k
+// so a pane whose process is still running is respawned anyway.
+func RespawnArgs(target string, kill bool, dir, command string) []string {
+	args := []string{"respawn-pane", "-t", target}
+	if kill {
+		args = append(args, "-k")
+	}
+	return appendDirAndCommand(args, dir, command)
+}
+
+func appendDirAndCommand(args []string, dir, command string) []string {
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	if command = strings.TrimSpace(command); command != "" {
+		args = append(args, command)
+	}
+	return args
+}
+
+// ExpandHome replaces a leading ~ in path with home.
+func ExpandHome(path, home string) string {
+	if path == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
+
+// CompleteDir completes the last element of input to a directory, the way
+// a shell's Tab does: a unique match gets a trailing slash, several extend
+// input to their longest common prefix. Names matching by prefix win; when
+// none do, a fuzzy match is used instead. Relative input is resolved
+// against cwd and ~ against home, though input keeps its form. It returns
+// the completed input and the names that matched.
+func CompleteDir(input, cwd, home string) (string, []string) {
+	base, partial := "", input
+	if i := strings.LastIndexByte(input, '/'); i >= 0 {
+		base, partial = input[:i+1], input[i+1:]
+	} else if input == "~" {
+		return "~/", nil
+	}
+	dir := ExpandHome(base, home)
+	if dir == "" {
+		dir = cwd
+	} else if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cwd, dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return input, nil
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(partial, ".") {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			names = append(names, name)
+		}
+	}
+	matches := slices.DeleteFunc(slices.Clone(names), func(n string) bool { return !strings.HasPrefix(n, partial) })
+	if len(matches) == 0 {
+		matches = fuzzy.FindFold(partial, names)
+		if len(matches) > 1 {
+			// fuzzy matches share no prefix worth extending to.
+			return input, matches
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return input, nil
+	case 1:
+		return base + matches[0] + "/", matches
+	}
+	prefix := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return base + prefix, matches
+}
@@ -0,0 +1,77 @@
+package spawn
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	split, ok := FindPlacement("left-full")
+	if !ok {
+		t.Fatal("expected a left-full placement")
+	}
+	got := SplitArgs("work:0.1", split, 30, "/srv/app", " make test ")
+	want := []string{"split-window", "-t", "work:0.1", "-h", "-b", "-f", "-l", "30%", "-c", "/srv/app", "make test"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("SplitArgs = %q, want %q", got, want)
+	}
+	got = SplitArgs("work:0.1", Split{}, 0, "", "")
+	if want := []string{"split-window", "-t", "work:0.1", "-v"}; !slices.Equal(got, want) {
+		t.Fatalf("SplitArgs = %q, want %q", got, want)
+	}
+}
+
+func TestRespawnArgs(t *testing.T) {
+	got := RespawnArgs("%3", true, "/tmp", "top")
+	if want := []string{"respawn-pane", "-t", "%3", "-k", "-c", "/tmp", "top"}; !slices.Equal(got, want) {
+		t.Fatalf("RespawnArgs = %q, want %q", got, want)
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	for in, want := range map[string]int{"": 0, " 25 ": 25, "40%": 40} {
+		if got, err := ParsePercent(in); err != nil || got != want {
+			t.Errorf("ParsePercent(%q) = %d, %v", in, got, err)
+		}
+	}
+	for _, bad := range []string{"0", "100", "half"} {
+		if _, err := ParsePercent(bad); err == nil {
+			t.Errorf("ParsePercent(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestCompleteDir(t *testing.T) {
+	home := t.TempDir()
+	for _, d := range []string{"src/project-alpha", "src/project-beta", "src/tools", "src/.cache", "docs"} {
+		if err := os.MkdirAll(filepath.Join(home, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(home, "src", "notes"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		input, want string
+		matches     int
+	}{
+		{"~/sr", "~/src/", 1},
+		{"~/src/pro", "~/src/project-", 2},
+		{"~/src/project-a", "~/src/project-alpha/", 1},
+		{"~/src/n", "~/src/n", 0},
+		{"~/src/.c", "~/src/.cache/", 1},
+		{"~/src/tls", "~/src/tools/", 1},
+		{"do", "docs/", 1},
+		{home + "/d", home + "/docs/", 1},
+		{"~", "~/", 0},
+	}
+	for _, c := range cases {
+		got, matches := CompleteDir(c.input, home, home)
+		if got != c.want || len(matches) != c.matches {
+			t.Errorf("CompleteDir(%q) = %q %q, want %q with %d matches", c.input, got, matches, c.want, c.matches)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// Package sshconfig lists the hosts a user can ssh to: the literal Host
+// aliases of ~/.ssh/config (following Include directives, skipping wildcard
+// and negated patterns) and, optionally, the unhashed entries of
+// known_hosts. it resolves each alias the way ssh does, first value wins
+// across every matching block, so previews can show where a host really
+// goes. opening connections is the menu package's job, so there are no
+// tmux, bubbletea, or menu imports here.
+package sshconfig
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/atomicstack/tmux-popup-control/internal/shquote"
+)
+
+// Source says where a host was found.
+type Source string
+
+const (
+	SourceConfig     Source = "config"
+	SourceKnownHosts Source = "known_hosts"
+)
+
+// Host is one entry of the launcher with its resolved connection details.
+type Host struct {
+	Alias        string
+	HostName     string
+	User         string
+	Port         string
+	ProxyJump    string
+	IdentityFile string
+	Source       Source
+	// File is where the alias was declared.
+	File string
+}
+
+// ID identifies the host in menus: the alias, plus the port for a
+// known_hosts entry on a non-standard one.
+func (h Host) ID() string {
+	if h.Source == SourceKnownHosts && h.Port != "" {
+		return h.Alias + ":" + h.Port
+	}
+	return h.Alias
+}
+
+// Command is the shell command that connects to the host. Config aliases
+// are left for ssh to resolve; known_hosts entries carry their port.
+func (h Host) Command() string {
+	if h.Source == SourceKnownHosts && h.Port != "" {
+		return "ssh -p " + shquote.Quote(h.Port) + " " + shquote.Quote(h.Alias)
+	}
+	return "ssh " + shquote.Quote(h.Alias)
+}
+
+// maxIncludeDepth matches ssh's own limit on nested Include directives.
+const maxIncludeDepth = 16
+
+type block struct {
+	patterns []string
+	// match blocks depend on runtime conditions; they are skipped.
+	match   bool
+	options [][2]string
+}
+
+// Config is a parsed ssh client config.
+type Config struct {
+	blocks  []block
+	aliases []string
+	files   map[string]string
+}
+
+// ParseConfig reads the config at path. Include paths that are relative
+// resolve against ~/.ssh, as they do for a user config. A missing file
+// yields an empty config.
+func ParseConfig(path, home string) (*Config, error) {
+	c := &Config{files: make(map[string]string)}
+	// options before the first Host line apply to every host.
+	c.blocks = append(c.blocks, block{patterns: []string{"*"}})
+	if err := c.parseFile(path, home, 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return c, err
+	}
+	return c, nil
+}
+
+func (c *Config) parseFile(path, home string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("%s: too many nested includes", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, args := splitLine(scanner.Text())
+		if key == "" {
+			continue
+		}
+		switch key {
+		case "host":
+			c.blocks = append(c.blocks, block{patterns: args})
+			for _, p := range args {
+				if isLiteral(p) && !slices.Contains(c.aliases, p) {
+					c.aliases = append(c.aliases, p)
+					c.files[p] = path
+				}
+			}
+		case "match":
+			c.blocks = append(c.blocks, block{match: true})
+		case "include":
+			for _, pattern := range args {
+				pattern = expandTilde(pattern, home)
+				if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(home, ".ssh", pattern)
+				}
+				matches, _ := filepath.Glob(pattern)
+				slices.Sort(matches)
+				for _, m := range matches {
+					if err := c.parseFile(m, home, depth+1); err != nil {
+						errs = append(errs, err)
+					}
+				}
+			}
+		default:
+			if len(args) > 0 {
+				last := &c.blocks[len(c.blocks)-1]
+				last.options = append(last.options, [2]string{key, strings.Join(args, " ")})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// splitLine returns a config line's lowercased keyword and its arguments.
+// Keywords and arguments may be separated by spaces or one "=", and
+// arguments may be double-quoted.
+func splitLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil
+	}
+	key := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+	var args []string
+	for rest != "" {
+		if rest[0] == '"' {
+			if i := strings.IndexByte(rest[1:], '"'); i >= 0 {
+				args = append(args, rest[1:i+1])
+				rest = strings.TrimLeft(rest[i+2:], " \t")
+				continue
+			}
+		}
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		args = append(args, rest[:end])
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+	return key, args
+}
+
+// isLiteral reports whether a Host pattern names one host rather than a
+// wildcard or negation.
+func isLiteral(p string) bool {
+	return p != "" && !strings.ContainsAny(p, "*?!")
+}
+
+// Hosts returns every literal alias, in declaration order, resolved.
+func (c *Config) Hosts() []Host {
+	out := make([]Host, 0, len(c.aliases))
+	for _, alias := range c.aliases {
+		out = append(out, c.Resolve(alias))
+	}
+	return out
+}
+
+// Resolve applies every block matching alias in order, keeping the first
+// value of each option.
+func (c *Config) Resolve(alias string) Host {
+	h := Host{Alias: alias, Source: SourceConfig, File: c.files[alias]}
+	seen := make(map[string]bool)
+	for _, b := range c.blocks {
+		if b.match || !matchPatterns(b.patterns, alias) {
+			continue
+		}
+		for _, opt := range b.options {
+			if seen[opt[0]] {
+				continue
+			}
+			seen[opt[0]] = true
+			switch opt[0] {
+			case "hostname":
+				h.HostName = strings.ReplaceAll(opt[1], "%h", alias)
+			case "user":
+				h.User = opt[1]
+			case "port":
+				h.Port = opt[1]
+			case "proxyjump":
+				h.ProxyJump = opt[1]
+			case "identityfile":
+				h.IdentityFile = opt[1]
+			}
+		}
+	}
+	if h.HostName == "" {
+		h.HostName = alias
+	}
+	return h
+}
+
+// matchPatterns applies a Host line: any matching negated pattern rejects
+// the host, otherwise any matching pattern accepts it.
+func matchPatterns(patterns []string, host string) bool {
+	matched := false
+	for _, p := range patterns {
+		if negated, ok := strings.CutPrefix(p, "!"); ok {
+			if wildcard(negated, host) {
+				return false
+			}
+			continue
+		}
+		if wildcard(p, host) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// wildcard matches ssh patterns, where * is any run of characters and ? any
+// one, case-insensitively.
+func wildcard(pattern, s string) bool {
+	pattern, s = strings.ToLower(pattern), strings.ToLower(s)
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if wildcard(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
+
+// ParseKnownHosts returns the hosts named in known_hosts data. Hashed
+// entries can't be listed and are skipped, as are @revoked lines and
+// wildcard patterns; [host]:port entries keep their port.
+func ParseKnownHosts(data []byte) []Host {
+	var out []Host
+	seen := make(map[string]bool)
+	for line := range strings.SplitSeq(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "@") {
+			if fields[0] == "@revoked" || len(fields) < 2 {
+				continue
+			}
+			fields = fields[1:]
+		}
+		for name := range strings.SplitSeq(fields[0], ",") {
+			if name == "" || strings.HasPrefix(name, "|") || !isLiteral(name) {
+				continue
+			}
+			h := Host{Alias: name, Source: SourceKnownHosts}
+			if rest, ok := strings.CutPrefix(name, "["); ok {
+				host, port, found := strings.Cut(rest, "]:")
+				if !found {
+					continue
+				}
+				if _, err := strconv.Atoi(port); err != nil {
+					continue
+				}
+				h.Alias = host
+				if port != "22" {
+					h.Port = port
+				}
+			}
+			h.HostName = h.Alias
+			if !seen[h.ID()] {
+				seen[h.ID()] = true
+				out = append(out, h)
+			}
+		}
+	}
+	return out
+}
+
+// Settings configure where hosts are read from.
+type Settings struct {
+	Config string
+	// KnownHosts is the known_hosts file to list; empty when off.
+	KnownHosts string
+}
+
+const (
+	envConfig     = "TMUX_POPUP_CONTROL_SSH_CONFIG"
+	envKnownHosts = "TMUX_POPUP_CONTROL_SSH_KNOWN_HOSTS"
+	optConfig     = "@tmux-popup-control-ssh-config"
+	optKnownHosts = "@tmux-popup-control-ssh-known-hosts"
+)
+
+// ResolveSettings reads each setting from the environment first, then the
+// tmux option, then the default.
+func ResolveSettings(getenv, option func(string) string) Settings {
+	lookup := func(envKey, optKey string) string {
+		if v := strings.TrimSpace(getenv(envKey)); v != "" {
+			return v
+		}
+		return strings.TrimSpace(option(optKey))
+	}
+	home := strings.TrimSpace(getenv("HOME"))
+	s := Settings{Config: filepath.Join(home, ".ssh", "config")}
+	if v := lookup(envConfig, optConfig); v != "" {
+		s.Config = expandTilde(os.Expand(v, getenv), home)
+	}
+	switch strings.ToLower(lookup(envKnownHosts, optKnownHosts)) {
+	case "1", "true", "yes", "on":
+		s.KnownHosts = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	return s
+}
+
+// Load returns the config's hosts followed by the known_hosts ones not
+// already declared. Errors from either file are joined without dropping
+// what the other produced.
+func Load(s Settings, home string) ([]Host, error) {
+	var errs []error
+	cfg, err := ParseConfig(s.Config, home)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	hosts := cfg.Hosts()
+	if s.KnownHosts != "" {
+		data, err := os.ReadFile(s.KnownHosts)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		declared := make(map[string]bool, len(hosts))
+		for _, h := range hosts {
+			declared[h.Alias] = true
+			declared[h.HostName] = true
+		}
+		for _, h := range ParseKnownHosts(data) {
+			if !declared[h.Alias] {
+				hosts = append(hosts, h)
+			}
+		}
+	}
+	return hosts, errors.Join(errs...)
+}
+
+func expandTilde(p, home string) string {
+	if home == "" {
+		return p
+	}
+	if p == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return p
+}
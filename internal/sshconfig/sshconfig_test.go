@@ -0,0 +1,139 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	home := t.TempDir()
+	config := filepath.Join(home, ".ssh", "config")
+	writeFile(t, config, `# global
+ServerAliveInterval 30
+
+Include conf.d/*
+
+Host web1 web2
+    HostName %h.example.com
+    User deploy
+
+Host db
+    HostName=10.0.0.5
+    Port 2222
+    ProxyJump bastion
+    IdentityFile "~/.ssh/id db"
+
+Host *.internal !skip.internal
+    User ops
+
+Host * !db
+    User nobody
+    Port 22
+
+Match host web1
+    User matched
+`)
+	writeFile(t, filepath.Join(home, ".ssh", "conf.d", "work"), `Host bastion
+  HostName bastion.example.com
+  user jump
+`)
+
+	cfg, err := ParseConfig(config, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cfg.Hosts()
+	work := filepath.Join(home, ".ssh", "conf.d", "work")
+	want := []Host{
+		{Alias: "bastion", HostName: "bastion.example.com", User: "jump", Port: "22", Source: SourceConfig, File: work},
+		{Alias: "web1", HostName: "web1.example.com", User: "deploy", Port: "22", Source: SourceConfig, File: config},
+		{Alias: "web2", HostName: "web2.example.com", User: "deploy", Port: "22", Source: SourceConfig, File: config},
+		{Alias: "db", HostName: "10.0.0.5", Port: "2222", ProxyJump: "bastion", IdentityFile: "~/.ssh/id db", Source: SourceConfig, File: config},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Hosts =\n%#v\nwant\n%#v", got, want)
+	}
+	if h := cfg.Resolve("a.internal"); h.User != "ops" || h.HostName != "a.internal" {
+		t.Fatalf("Resolve(a.internal) = %#v", h)
+	}
+	if h := cfg.Resolve("skip.internal"); h.User != "nobody" {
+		t.Fatalf("negated pattern should not match, got %#v", h)
+	}
+}
+
+func TestParseConfigMissingFile(t *testing.T) {
+	cfg, err := ParseConfig(filepath.Join(t.TempDir(), "config"), "")
+	if err != nil || len(cfg.Hosts()) != 0 {
+		t.Fatalf("expected an empty config, got %v %v", cfg.Hosts(), err)
+	}
+}
+
+func TestParseKnownHosts(t *testing.T) {
+	got := ParseKnownHosts([]byte(`github.com,140.82.121.4 ssh-ed25519 AAAA
+|1|c2FsdA==|aGFzaA== ssh-ed25519 AAAA
+[git.example.com]:2222 ssh-rsa AAAA
+[plain.example.com]:22 ssh-rsa AAAA
+*.wild.example.com ssh-rsa AAAA
+@cert-authority ca.example.com ssh-rsa AAAA
+@revoked bad.example.com ssh-rsa AAAA
+# comment
+github.com ecdsa-sha2-nistp256 AAAA
+`))
+	var ids []string
+	for _, h := range got {
+		ids = append(ids, h.ID())
+	}
+	want := []string{"github.com", "140.82.121.4", "git.example.com:2222", "plain.example.com", "ca.example.com"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("ids = %q, want %q", ids, want)
+	}
+	if cmd := got[2].Command(); cmd != "ssh -p '2222' 'git.example.com'" {
+		t.Fatalf("Command = %q", cmd)
+	}
+}
+
+func TestResolveSettings(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me"}
+	opts := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+	option := func(k string) string { return opts[k] }
+
+	if s := ResolveSettings(getenv, option); s != (Settings{Config: "/home/me/.ssh/config"}) {
+		t.Fatalf("defaults = %#v", s)
+	}
+	opts[optKnownHosts] = "on"
+	opts[optConfig] = "~/alt/config"
+	if s := ResolveSettings(getenv, option); s != (Settings{Config: "/home/me/alt/config", KnownHosts: "/home/me/.ssh/known_hosts"}) {
+		t.Fatalf("options = %#v", s)
+	}
+	env[envKnownHosts] = "off"
+	if s := ResolveSettings(getenv, option); s.KnownHosts != "" {
+		t.Fatalf("env should win, got %#v", s)
+	}
+}
+
+func TestLoadSkipsKnownHostsAlreadyDeclared(t *testing.T) {
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".ssh", "config"), "Host gh\n  HostName github.com\n")
+	writeFile(t, filepath.Join(home, ".ssh", "known_hosts"), "github.com ssh-ed25519 AAAA\nother.example.com ssh-ed25519 AAAA\n")
+	s := Settings{Config: filepath.Join(home, ".ssh", "config"), KnownHosts: filepath.Join(home, ".ssh", "known_hosts")}
+	hosts, err := Load(s, home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0].Alias != "gh" || hosts[1].Alias != "other.example.com" || hosts[1].Source != SourceKnownHosts {
+		t.Fatalf("Load = %#v", hosts)
+	}
+}
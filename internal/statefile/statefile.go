@@ -0,0 +1,86 @@
+// Package statefile reads and updates the small JSON files kept in the state
+// directory, serialising writers with an flock so concurrent popups and
+// status-line jobs never lose each other's changes.
+package statefile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// ErrLocked is returned by TryLock when another process holds the lock.
+var ErrLocked = errors.New("lock busy")
+
+// Lock takes an exclusive lock on path, creating it if needed, and returns
+// the func that releases it. what names the guarded state in errors.
+func Lock(path, what string) (func(), error) {
+	return lock(path, what, syscall.LOCK_EX)
+}
+
+// TryLock is Lock without waiting: it fails with ErrLocked while another
+// process holds the lock.
+func TryLock(path, what string) (func(), error) {
+	return lock(path, what, syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+func lock(path, what string, how int) (func(), error) {
+	lockFile, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s lock: %w", what, err)
+	}
+	if err := syscall.Flock(int(lockFile.Fd()), how); err != nil {
+		lockFile.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) || errors.Is(err, syscall.EAGAIN) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("locking %s: %w", what, err)
+	}
+	return func() {
+		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+		lockFile.Close()
+	}, nil
+}
+
+// Load decodes the JSON file at path. A missing file is the zero value.
+func Load[T any](path, what string) (T, error) {
+	var v T
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return v, nil
+	}
+	if err != nil {
+		return v, fmt.Errorf("read %s: %w", what, err)
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("parse %s: %w", what, err)
+	}
+	return v, nil
+}
+
+// Update loads the file at path, applies fn and writes the result back, all
+// under the lock at lockPath. Files are owner-only: some hold whatever was
+// copied or typed, secrets included.
+func Update[T any](lockPath, path, what string, fn func(*T)) error {
+	unlock, err := Lock(lockPath, what)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	v, err := Load[T](path, what)
+	if err != nil {
+		return err
+	}
+	fn(&v)
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", what, err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", what, err)
+	}
+	return nil
+}
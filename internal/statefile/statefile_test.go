@@ -0,0 +1,90 @@
+package statefile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUpdateStartsFromZeroValue(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "counts")
+	lockPath := path + ".lock"
+
+	for range 2 {
+		err := Update(lockPath, path, "counts", func(m *map[string]int) {
+			if *m == nil {
+				*m = map[string]int{}
+			}
+			(*m)["picks"]++
+		})
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	got, err := Load[map[string]int](path, "counts")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got["picks"] != 2 {
+		t.Fatalf("picks = %d, want 2", got["picks"])
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("mode = %o, want 600", perm)
+	}
+}
+
+func TestConcurrentUpdatesKeepEveryWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "list")
+	lockPath := path + ".lock"
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			if err := Update(lockPath, path, "list", func(l *[]int) { *l = append(*l, 1) }); err != nil {
+				t.Errorf("Update: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	got, _ := Load[[]int](path, "list")
+	if len(got) != 20 {
+		t.Fatalf("len = %d, want 20", len(got))
+	}
+}
+
+func TestLoadReportsParseErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load[map[string]int](path, "bad state"); err == nil {
+		t.Fatal("Load accepted malformed JSON")
+	}
+}
+
+func TestTryLockFailsWhileHeld(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "x.lock")
+	unlock, err := Lock(lockPath, "x")
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	// flock locks belong to the open file description, so a second open in
+	// the same process contends like another popup would.
+	if _, err := TryLock(lockPath, "x"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("TryLock while held = %v, want ErrLocked", err)
+	}
+	unlock()
+	release, err := TryLock(lockPath, "x")
+	if err != nil {
+		t.Fatalf("TryLock after unlock: %v", err)
+	}
+	release()
+}
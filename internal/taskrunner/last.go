@@ -0,0 +1,64 @@
+package taskrunner
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/statefile"
+)
+
+// Last is the task most recently run from a directory, kept so it can be
+// rerun without picking it again.
+type Last struct {
+	ID      string    `json:"id"`
+	Command string    `json:"command"`
+	Place   string    `json:"place"`
+	At      time.Time `json:"at"`
+}
+
+// maxLast caps the directories remembered; the oldest are dropped first.
+const maxLast = 200
+
+func lastPath(stateDir string) string {
+	return filepath.Join(stateDir, ".tasks")
+}
+
+func lastLockPath(stateDir string) string {
+	return filepath.Join(stateDir, ".tasks.lock")
+}
+
+func loadAll(stateDir string) (map[string]Last, error) {
+	return statefile.Load[map[string]Last](lastPath(stateDir), "last tasks")
+}
+
+// LoadLast returns the last task run from dir, if any.
+func LoadLast(stateDir, dir string) (Last, bool, error) {
+	all, err := loadAll(stateDir)
+	if err != nil {
+		return Last{}, false, err
+	}
+	last, ok := all[filepath.Clean(dir)]
+	return last, ok, nil
+}
+
+// SaveLast records last as dir's last task.
+func SaveLast(stateDir, dir string, last Last) error {
+	return statefile.Update(lastLockPath(stateDir), lastPath(stateDir), "last tasks", func(all *map[string]Last) {
+		if *all == nil {
+			*all = map[string]Last{}
+		}
+		m := *all
+		m[filepath.Clean(dir)] = last
+		if len(m) > maxLast {
+			dirs := make([]string, 0, len(m))
+			for d := range m {
+				dirs = append(dirs, d)
+			}
+			slices.SortFunc(dirs, func(a, b string) int { return m[b].At.Compare(m[a].At) })
+			for _, d := range dirs[maxLast:] {
+				delete(m, d)
+			}
+		}
+	})
+}
@@ -0,0 +1,367 @@
+// Package taskrunner finds the tasks a directory defines: Makefile rules,
+// justfile recipes, package.json scripts, Taskfile tasks, and the
+// conventional cargo and go commands. each task carries the shell command
+// that runs it and the text behind it for previews. running tasks is the
+// menu package's job, so there are no exec, tmux, bubbletea, or menu imports
+// here.
+package taskrunner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/atomicstack/tmux-popup-control/internal/shquote"
+)
+
+// Task is one runnable target.
+type Task struct {
+	Runner  string // make, just, npm, pnpm, yarn, bun, task, cargo or go
+	Name    string
+	Command string
+	// Body is the recipe behind the task: the rule and its commands, the
+	// script, or the Taskfile description and cmds.
+	Body []string
+	File string
+}
+
+// ID identifies the task in menus, e.g. "make build" or "npm test".
+func (t Task) ID() string {
+	return t.Runner + " " + t.Name
+}
+
+// Discover returns dir's tasks, grouped by file in a fixed order. A file
+// that fails to parse is reported without dropping the others' tasks.
+func Discover(dir string) ([]Task, error) {
+	var (
+		tasks []Task
+		errs  []error
+	)
+	for _, find := range []func(string) ([]Task, error){
+		findMake, findJust, findPackageJSON, findTaskfile, findCargo, findGo,
+	} {
+		found, err := find(dir)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		tasks = append(tasks, found...)
+	}
+	return tasks, errors.Join(errs...)
+}
+
+// firstFile returns the first of names present in dir.
+func firstFile(dir string, names ...string) (string, []byte, error) {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return path, data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return path, nil, err
+		}
+	}
+	return "", nil, nil
+}
+
+var makeRule = regexp.MustCompile(`^([^\s:=#][^:=#]*?)\s*::?(?:[^=]|$)`)
+
+func findMake(dir string) ([]Task, error) {
+	path, data, err := firstFile(dir, "GNUmakefile", "makefile", "Makefile")
+	if path == "" || err != nil {
+		return nil, err
+	}
+	var (
+		tasks   []Task
+		current []int
+	)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			for _, i := range current {
+				tasks[i].Body = append(tasks[i].Body, line[1:])
+			}
+			continue
+		}
+		current = nil
+		if strings.Contains(line, ":=") {
+			continue
+		}
+		m := makeRule.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		for _, name := range strings.Fields(m[1]) {
+			if strings.HasPrefix(name, ".") || strings.ContainsAny(name, "%$") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			current = append(current, len(tasks))
+			tasks = append(tasks, Task{
+				Runner:  "make",
+				Name:    name,
+				Command: "make " + shquote.Quote(name),
+				Body:    []string{line},
+				File:    path,
+			})
+		}
+	}
+	return tasks, nil
+}
+
+var justRecipe = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)[^:]*:(?:[^=]|$)`)
+
+func findJust(dir string) ([]Task, error) {
+	path, data, err := firstFile(dir, "justfile", "Justfile", ".justfile")
+	if path == "" || err != nil {
+		return nil, err
+	}
+	var (
+		tasks   []Task
+		current = -1
+		private bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			if current >= 0 {
+				tasks[current].Body = append(tasks[current].Body, strings.TrimSpace(line))
+			}
+			continue
+		}
+		current = -1
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			private = private || strings.Contains(trimmed, "private")
+			continue
+		}
+		m := justRecipe.FindStringSubmatch(line)
+		if m == nil {
+			private = false
+			continue
+		}
+		if !private && !strings.HasPrefix(m[1], "_") {
+			current = len(tasks)
+			tasks = append(tasks, Task{
+				Runner:  "just",
+				Name:    m[1],
+				Command: "just " + shquote.Quote(m[1]),
+				Body:    []string{line},
+				File:    path,
+			})
+		}
+		private = false
+	}
+	return tasks, nil
+}
+
+func findPackageJSON(dir string) ([]Task, error) {
+	path, data, err := firstFile(dir, "package.json")
+	if path == "" || err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Scripts json.RawMessage `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(pkg.Scripts) == 0 {
+		return nil, nil
+	}
+	scripts, err := orderedStrings(pkg.Scripts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: scripts: %w", path, err)
+	}
+	runner := packageManager(dir)
+	tasks := make([]Task, 0, len(scripts))
+	for _, s := range scripts {
+		tasks = append(tasks, Task{
+			Runner:  runner,
+			Name:    s[0],
+			Command: runner + " run " + shquote.Quote(s[0]),
+			Body:    []string{s[1]},
+			File:    path,
+		})
+	}
+	return tasks, nil
+}
+
+// orderedStrings decodes a JSON object of strings keeping its key order,
+// which is the order package authors list their scripts in.
+func orderedStrings(raw json.RawMessage) ([][2]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("not an object")
+	}
+	var out [][2]string
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value string
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		out = append(out, [2]string{key.(string), value})
+	}
+	return out, nil
+}
+
+// packageManager picks the runner whose lockfile is present, npm otherwise.
+func packageManager(dir string) string {
+	for _, lock := range []struct{ file, runner string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lock", "bun"},
+		{"bun.lockb", "bun"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lock.file)); err == nil {
+			return lock.runner
+		}
+	}
+	return "npm"
+}
+
+func findTaskfile(dir string) ([]Task, error) {
+	path, data, err := firstFile(dir, "Taskfile.yml", "taskfile.yml", "Taskfile.yaml", "taskfile.yaml")
+	if path == "" || err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if doc.Tasks.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var tasks []Task
+	for i := 0; i+1 < len(doc.Tasks.Content); i += 2 {
+		name := doc.Tasks.Content[i].Value
+		body, internal := taskfileBody(doc.Tasks.Content[i+1])
+		if internal {
+			continue
+		}
+		tasks = append(tasks, Task{
+			Runner:  "task",
+			Name:    name,
+			Command: "task " + shquote.Quote(name),
+			Body:    body,
+			File:    path,
+		})
+	}
+	return tasks, nil
+}
+
+// taskfileBody renders a task's desc and cmds. A task may be a single
+// command, a list of them, or a mapping with desc/cmd/cmds/internal.
+func taskfileBody(node *yaml.Node) ([]string, bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}, false
+	case yaml.SequenceNode:
+		return taskfileCmds(node), false
+	case yaml.MappingNode:
+		var (
+			body     []string
+			internal bool
+		)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := node.Content[i+1]
+			switch node.Content[i].Value {
+			case "desc":
+				body = append([]string{"# " + value.Value}, body...)
+			case "cmd":
+				body = append(body, value.Value)
+			case "cmds":
+				body = append(body, taskfileCmds(value)...)
+			case "internal":
+				internal = value.Value == "true"
+			}
+		}
+		return body, internal
+	}
+	return nil, false
+}
+
+func taskfileCmds(node *yaml.Node) []string {
+	var out []string
+	for _, c := range node.Content {
+		switch c.Kind {
+		case yaml.ScalarNode:
+			out = append(out, c.Value)
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(c.Content); i += 2 {
+				switch c.Content[i].Value {
+				case "cmd":
+					out = append(out, c.Content[i+1].Value)
+				case "task":
+					out = append(out, "task "+c.Content[i+1].Value)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// conventional lists the commands every project of a kind supports.
+func conventional(dir, file, runner string, commands [][2]string) []Task {
+	path := filepath.Join(dir, file)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	tasks := make([]Task, 0, len(commands))
+	for _, c := range commands {
+		tasks = append(tasks, Task{
+			Runner:  runner,
+			Name:    c[0],
+			Command: c[1],
+			Body:    []string{c[1]},
+			File:    path,
+		})
+	}
+	return tasks
+}
+
+func findCargo(dir string) ([]Task, error) {
+	return conventional(dir, "Cargo.toml", "cargo", [][2]string{
+		{"build", "cargo build"},
+		{"test", "cargo test"},
+		{"run", "cargo run"},
+		{"check", "cargo check"},
+		{"clippy", "cargo clippy"},
+	}), nil
+}
+
+func findGo(dir string) ([]Task, error) {
+	return conventional(dir, "go.mod", "go", [][2]string{
+		{"build", "go build ./..."},
+		{"test", "go test ./..."},
+		{"vet", "go vet ./..."},
+		{"run", "go run ."},
+	}), nil
+}
+
+// Find returns the task with the given ID.
+func Find(tasks []Task, id string) (Task, bool) {
+	i := slices.IndexFunc(tasks, func(t Task) bool { return t.ID() == id })
+	if i < 0 {
+		return Task{}, false
+	}
+	return tasks[i], true
+}
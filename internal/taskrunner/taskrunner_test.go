@@ -0,0 +1,128 @@
+package taskrunner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func ids(tasks []Task) []string {
+	out := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, t.ID())
+	}
+	return out
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Makefile": `VERSION := 1.0
+CC = cc
+.PHONY: build test
+
+build test: deps
+	go build ./...
+%.o: %.c
+	$(CC) -c $<
+clean:
+	rm -rf out
+`,
+		"justfile": `set shell := ["bash", "-c"]
+alias b := build
+
+# build it
+build target="all":
+    cargo build
+
+_helper:
+    echo hidden
+
+[private]
+secret:
+    echo hidden
+`,
+		"package.json": `{"name": "x", "scripts": {"dev": "vite", "build": "vite build"}}`,
+		"yarn.lock":    "",
+		"Taskfile.yml": `version: '3'
+tasks:
+  lint:
+    desc: run the linters
+    cmds:
+      - golangci-lint run
+      - task: fmt
+  fmt: gofmt -w .
+  hidden:
+    internal: true
+    cmds: [echo]
+`,
+		"go.mod": "module x\n",
+	})
+
+	tasks, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"make build", "make test", "make clean",
+		"just build",
+		"yarn dev", "yarn build",
+		"task lint", "task fmt",
+		"go build", "go test", "go vet", "go run",
+	}
+	if got := ids(tasks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ids = %q, want %q", got, want)
+	}
+	build, _ := Find(tasks, "make build")
+	if !reflect.DeepEqual(build.Body, []string{"build test: deps", "go build ./..."}) || build.Command != "make 'build'" {
+		t.Fatalf("make build = %#v", build)
+	}
+	lint, _ := Find(tasks, "task lint")
+	if !reflect.DeepEqual(lint.Body, []string{"# run the linters", "golangci-lint run", "task fmt"}) {
+		t.Fatalf("task lint body = %q", lint.Body)
+	}
+	if dev, _ := Find(tasks, "yarn dev"); dev.Command != "yarn run 'dev'" || dev.Body[0] != "vite" {
+		t.Fatalf("yarn dev = %#v", dev)
+	}
+}
+
+func TestDiscoverReportsBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json": "{",
+		"Makefile":     "all:\n\techo\n",
+	})
+	tasks, err := Discover(dir)
+	if err == nil {
+		t.Fatal("expected a package.json error")
+	}
+	if got := ids(tasks); !reflect.DeepEqual(got, []string{"make all"}) {
+		t.Fatalf("ids = %q", got)
+	}
+}
+
+func TestLastRoundTrip(t *testing.T) {
+	state := t.TempDir()
+	if _, ok, err := LoadLast(state, "/src/app"); ok || err != nil {
+		t.Fatalf("expected nothing yet, got %v %v", ok, err)
+	}
+	last := Last{ID: "make test", Command: "make 'test'", Place: "split", At: time.Unix(100, 0).UTC()}
+	if err := SaveLast(state, "/src/app/", last); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := LoadLast(state, "/src/app")
+	if err != nil || !ok || got != last {
+		t.Fatalf("LoadLast = %#v %v %v", got, ok, err)
+	}
+}
@@ -0,0 +1,172 @@
+package tmux
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// Buffer describes a tmux paste buffer as reported by list-buffers.
+type Buffer struct {
+	Name    string
+	Size    int
+	Created time.Time
+	Sample  string
+}
+
+const bufferListFormat = "#{buffer_name}\t#{buffer_size}\t#{buffer_created}\t#{buffer_sample}"
+
+// ListBuffers returns the server's paste buffers, most recent first (the
+// order tmux reports them in).
+func ListBuffers(socketPath string) ([]Buffer, error) {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	output, err := client.Command("list-buffers", "-F", bufferListFormat)
+	if err != nil {
+		return nil, err
+	}
+	return parseBufferList(output), nil
+}
+
+// parseBufferList parses list-buffers rows produced by bufferListFormat. The
+// sample is the last field so tabs inside it survive the split.
+func parseBufferList(output string) []Buffer {
+	var buffers []Buffer
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) < 3 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		buf := Buffer{
+			Name: strings.TrimSpace(parts[0]),
+			Size: atoiOr0(parts[1]),
+		}
+		if secs := atoiOr0(parts[2]); secs > 0 {
+			buf.Created = time.Unix(int64(secs), 0)
+		}
+		if len(parts) == 4 {
+			buf.Sample = parts[3]
+		}
+		buffers = append(buffers, buf)
+	}
+	return buffers
+}
+
+// ShowBuffer returns the content of the named paste buffer.
+func ShowBuffer(socketPath, name string) (string, error) {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return "", err
+	}
+	return client.Command("show-buffer", "-b", name)
+}
+
+// PasteBuffer pastes the named buffer into target, using bracketed paste when
+// the application in the pane has requested it (-p).
+func PasteBuffer(socketPath, name, target string) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	args := []string{"paste-buffer", "-p", "-b", name}
+	if strings.TrimSpace(target) != "" {
+		args = append(args, "-t", target)
+	}
+	_, err = client.Command(args...)
+	return err
+}
+
+// DeleteBuffers deletes each named buffer, stopping at the first failure.
+func DeleteBuffers(socketPath string, names []string) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := client.Command("delete-buffer", "-b", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameBuffer gives the named buffer a new name.
+func RenameBuffer(socketPath, name, newName string) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	_, err = client.Command("set-buffer", "-b", name, "-n", newName)
+	return err
+}
+
+// SaveBuffer writes the named buffer to path. tmux resolves a relative path
+// against the client's working directory, so callers should pass an absolute
+// one.
+func SaveBuffer(socketPath, name, path string) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	_, err = client.Command("save-buffer", "-b", name, path)
+	return err
+}
+
+// LoadBuffer reads path into a paste buffer. An empty name lets tmux pick
+// the next automatic buffer name; otherwise the named buffer is created or
+// replaced.
+func LoadBuffer(socketPath, name, path string) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	args := []string{"load-buffer"}
+	if strings.TrimSpace(name) != "" {
+		args = append(args, "-b", name)
+	}
+	args = append(args, path)
+	_, err = client.Command(args...)
+	return err
+}
+
+// PasteTextBuffer is the scratch buffer PasteText loads text into; it is
+// deleted again by the paste, so buffer watchers should ignore it.
+const PasteTextBuffer = "tmux-popup-control-paste"
+
+// PasteText pastes text into target byte-for-byte. Control-mode arguments
+// cannot carry newlines, so unlike InsertText the text travels through a
+// temporary file and load-buffer. bracketed uses bracketed paste when the
+// application in the pane has requested it (-p).
+func PasteText(socketPath, target, text string, bracketed bool) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp("", "tmux-popup-control-paste-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if _, err := client.Command("load-buffer", "-b", PasteTextBuffer, file.Name()); err != nil {
+		return err
+	}
+	args := []string{"paste-buffer", "-d"}
+	if bracketed {
+		args = append(args, "-p")
+	}
+	args = append(args, "-b", PasteTextBuffer)
+	if strings.TrimSpace(target) != "" {
+		args = append(args, "-t", target)
+	}
+	_, err = client.Command(args...)
+	return err
+}
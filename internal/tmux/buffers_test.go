@@ -0,0 +1,118 @@
+package tmux
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestListBuffersParsesRows(t *testing.T) {
+	fake := &fakeClient{commandOutput: "buffer1\t12\t1700000000\thello\tworld\nnamed\t3\t0\tabc\n\n"}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	buffers, err := ListBuffers("/sock")
+	if err != nil {
+		t.Fatalf("ListBuffers: %v", err)
+	}
+	want := [][]string{{"list-buffers", "-F", bufferListFormat}}
+	if !reflect.DeepEqual(fake.commandCalls, want) {
+		t.Fatalf("commandCalls = %#v, want %#v", fake.commandCalls, want)
+	}
+	if len(buffers) != 2 {
+		t.Fatalf("expected 2 buffers, got %#v", buffers)
+	}
+	if buffers[0].Name != "buffer1" || buffers[0].Size != 12 || buffers[0].Sample != "hello\tworld" {
+		t.Fatalf("unexpected first buffer %#v", buffers[0])
+	}
+	if buffers[0].Created.Unix() != 1700000000 {
+		t.Fatalf("unexpected created time %v", buffers[0].Created)
+	}
+	if !buffers[1].Created.IsZero() {
+		t.Fatalf("expected zero created time for 0, got %v", buffers[1].Created)
+	}
+}
+
+func TestBufferCommands(t *testing.T) {
+	fake := &fakeClient{}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	if err := PasteBuffer("/sock", "buffer0", "%3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RenameBuffer("/sock", "buffer0", "keep"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveBuffer("/sock", "keep", "/tmp/out.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadBuffer("/sock", "", "/tmp/in.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadBuffer("/sock", "named", "/tmp/in.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteBuffers("/sock", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"paste-buffer", "-p", "-b", "buffer0", "-t", "%3"},
+		{"set-buffer", "-b", "buffer0", "-n", "keep"},
+		{"save-buffer", "-b", "keep", "/tmp/out.txt"},
+		{"load-buffer", "/tmp/in.txt"},
+		{"load-buffer", "-b", "named", "/tmp/in.txt"},
+		{"delete-buffer", "-b", "a"},
+		{"delete-buffer", "-b", "b"},
+	}
+	if !reflect.DeepEqual(fake.commandCalls, want) {
+		t.Fatalf("commandCalls = %#v, want %#v", fake.commandCalls, want)
+	}
+}
+
+func TestDeleteBuffersStopsOnError(t *testing.T) {
+	fake := &fakeClient{commandErr: errors.New("no buffer a")}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	if err := DeleteBuffers("/sock", []string{"a", "b"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(fake.commandCalls) != 1 {
+		t.Fatalf("expected a single delete attempt, got %#v", fake.commandCalls)
+	}
+}
+
+func TestPasteTextLoadsThroughTempFile(t *testing.T) {
+	fake := &fakeClient{}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	if err := PasteText("/sock", "%2", "line one\nline two\n", true); err != nil {
+		t.Fatalf("PasteText: %v", err)
+	}
+	if len(fake.commandCalls) != 2 {
+		t.Fatalf("commandCalls = %#v", fake.commandCalls)
+	}
+	load := fake.commandCalls[0]
+	if len(load) != 4 || load[0] != "load-buffer" || load[2] != PasteTextBuffer {
+		t.Fatalf("unexpected load call %#v", load)
+	}
+	if _, err := os.Stat(load[3]); !os.IsNotExist(err) {
+		t.Fatalf("expected temp file %s to be removed", load[3])
+	}
+	want := []string{"paste-buffer", "-d", "-p", "-b", PasteTextBuffer, "-t", "%2"}
+	if !reflect.DeepEqual(fake.commandCalls[1], want) {
+		t.Fatalf("paste call = %#v, want %#v", fake.commandCalls[1], want)
+	}
+}
+
+func TestPasteTextUnbracketed(t *testing.T) {
+	fake := &fakeClient{}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	if err := PasteText("/sock", "", "text", false); err != nil {
+		t.Fatalf("PasteText: %v", err)
+	}
+	want := []string{"paste-buffer", "-d", "-b", PasteTextBuffer}
+	if len(fake.commandCalls) != 2 || !reflect.DeepEqual(fake.commandCalls[1], want) {
+		t.Fatalf("paste call = %#v, want %#v", fake.commandCalls, want)
+	}
+}
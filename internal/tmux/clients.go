@@ -0,0 +1,55 @@
+package tmux
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Client describes an attached terminal client as reported by list-clients.
+type Client struct {
+	Name       string
+	Tty        string
+	Session    string
+	Termname   string
+	Width      int
+	Height     int
+	Activity   time.Time
+	ReadOnly   bool
+	IgnoreSize bool
+}
+
+// ListClients returns the attached terminal clients, leaving out
+// control-mode connections such as our own.
+func ListClients(socketPath string) ([]Client, error) {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := client.ListClients()
+	if err != nil {
+		return nil, err
+	}
+	var clients []Client
+	for _, c := range raw {
+		if c == nil || c.ControlMode || !isValidClientName(strings.TrimSpace(c.Name)) {
+			continue
+		}
+		flags := strings.Split(c.Flags, ",")
+		entry := Client{
+			Name:       strings.TrimSpace(c.Name),
+			Tty:        strings.TrimSpace(c.Tty),
+			Session:    strings.TrimSpace(c.Session),
+			Termname:   strings.TrimSpace(c.Termname),
+			Width:      c.Width,
+			Height:     c.Height,
+			ReadOnly:   c.Readonly || slices.Contains(flags, "read-only"),
+			IgnoreSize: slices.Contains(flags, "ignore-size"),
+		}
+		if secs := atoiOr0(c.Activity); secs > 0 {
+			entry.Activity = time.Unix(int64(secs), 0)
+		}
+		clients = append(clients, entry)
+	}
+	return clients, nil
+}
@@ -0,0 +1,36 @@
+package tmux
+
+import (
+	"testing"
+	"time"
+
+	gotmux "github.com/atomicstack/gotmuxcc/gotmuxcc"
+)
+
+func TestListClientsSkipsControlMode(t *testing.T) {
+	fake := &fakeClient{clients: []*gotmux.Client{
+		{Name: "client-4242", ControlMode: true},
+		{
+			Name: "/dev/pts/3", Tty: "/dev/pts/3", Session: "work", Termname: "xterm-256color",
+			Width: 200, Height: 50, Activity: "1700000000", Flags: "attached,focused,ignore-size,UTF-8",
+		},
+		{Name: "/dev/pts/7", Tty: "/dev/pts/7", Session: "ops", Readonly: true, Flags: "attached,read-only"},
+	}}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	clients, err := ListClients("/sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 2 {
+		t.Fatalf("expected 2 terminal clients, got %#v", clients)
+	}
+	first := clients[0]
+	if first.Name != "/dev/pts/3" || first.Session != "work" || first.Width != 200 || first.Height != 50 ||
+		!first.IgnoreSize || first.ReadOnly || !first.Activity.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected first client %#v", first)
+	}
+	if !clients[1].ReadOnly || clients[1].IgnoreSize || !clients[1].Activity.IsZero() {
+		t.Fatalf("unexpected second client %#v", clients[1])
+	}
+}
@@ -1,6 +1,8 @@
 package tmux
 
 import (
+	"fmt"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -103,3 +105,46 @@ func CopyText(socketPath, text string) error {
 	_, err = client.Command("set-buffer", "--", text)
 	return err
 }
+
+// ClientTerminal returns the tty and terminal name of the client named by
+// TMUX_POPUP_CONTROL_CLIENT (set by main.sh to #{client_tty}), falling back to
+// the first terminal client. OSC 52 writes go straight to that tty.
+func ClientTerminal(socketPath string) (tty, termname string, err error) {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return "", "", err
+	}
+	clients, err := client.ListClients()
+	if err != nil {
+		return "", "", err
+	}
+	preferred := strings.TrimSpace(os.Getenv("TMUX_POPUP_CONTROL_CLIENT"))
+	var fallback *gotmux.Client
+	for _, c := range clients {
+		if c == nil || c.ControlMode {
+			continue
+		}
+		if preferred != "" && (c.Tty == preferred || c.Name == preferred) {
+			return c.Tty, c.Termname, nil
+		}
+		if fallback == nil {
+			fallback = c
+		}
+	}
+	if fallback == nil {
+		return "", "", fmt.Errorf("no terminal client found")
+	}
+	return fallback.Tty, fallback.Termname, nil
+}
+
+// ForwardToClipboard stores text in a paste buffer with -w, which makes tmux
+// itself send it to the client terminals' clipboard via OSC 52. It only
+// reaches the terminal when set-clipboard is not off.
+func ForwardToClipboard(socketPath, text string) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	_, err = client.Command("set-buffer", "-w", "--", text)
+	return err
+}
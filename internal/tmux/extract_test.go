@@ -80,3 +80,36 @@ func TestCaptureVisible(t *testing.T) {
 		t.Fatalf("CapturePane options requested scrollback (StartLine=%q EndLine=%q), want visible screen only", gotOp.StartLine, gotOp.EndLine)
 	}
 }
+
+func TestClientTerminalPrefersPopupClient(t *testing.T) {
+	fake := &fakeClient{clients: []*gotmux.Client{
+		{Name: "control", Tty: "", ControlMode: true},
+		{Name: "/dev/pts/1", Tty: "/dev/pts/1", Termname: "xterm-256color"},
+		{Name: "/dev/pts/7", Tty: "/dev/pts/7", Termname: "tmux-256color"},
+	}}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	t.Setenv("TMUX_POPUP_CONTROL_CLIENT", "/dev/pts/7")
+	tty, termname, err := ClientTerminal("/sock")
+	if err != nil || tty != "/dev/pts/7" || termname != "tmux-256color" {
+		t.Fatalf("ClientTerminal = %q, %q, %v", tty, termname, err)
+	}
+
+	t.Setenv("TMUX_POPUP_CONTROL_CLIENT", "")
+	if tty, _, _ := ClientTerminal("/sock"); tty != "/dev/pts/1" {
+		t.Fatalf("expected first terminal client, got %q", tty)
+	}
+}
+
+func TestForwardToClipboardUsesSetBufferW(t *testing.T) {
+	fake := &fakeClient{}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	if err := ForwardToClipboard("/sock", "world"); err != nil {
+		t.Fatalf("ForwardToClipboard: %v", err)
+	}
+	want := [][]string{{"set-buffer", "-w", "--", "world"}}
+	if !reflect.DeepEqual(fake.commandCalls, want) {
+		t.Fatalf("commandCalls = %#v, want %#v", fake.commandCalls, want)
+	}
+}
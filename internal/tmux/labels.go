@@ -47,5 +47,8 @@ func defaultLabelForSession(s *gotmux.Session) string {
 	if s.Attached > 0 {
 		label += " (attached)"
 	}
+	if s.Group != "" {
+		label += fmt.Sprintf(" (group %s)", s.Group)
+	}
 	return label
 }
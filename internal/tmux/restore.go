@@ -11,11 +11,15 @@ import (
 	gotmux "github.com/atomicstack/gotmuxcc/gotmuxcc"
 )
 
+// SessionSpec describes a session to create. Env on the specs holds
+// KEY=value pairs passed with -e, so they reach the pane's first process as
+// well as any shell it execs.
 type SessionSpec struct {
 	SocketPath string
 	Name       string
 	Dir        string
 	Command    string
+	Env        []string
 }
 
 type WindowSpec struct {
@@ -25,6 +29,7 @@ type WindowSpec struct {
 	Name       string
 	Dir        string
 	Command    string
+	Env        []string
 }
 
 type PaneSpec struct {
@@ -32,6 +37,16 @@ type PaneSpec struct {
 	Target     string
 	Dir        string
 	Command    string
+	Env        []string
+}
+
+// envArgs turns KEY=value pairs into repeated -e flags.
+func envArgs(env []string) []string {
+	args := make([]string, 0, 2*len(env))
+	for _, kv := range env {
+		args = append(args, "-e", kv)
+	}
+	return args
 }
 
 // CreateSession creates a new tmux session with the given name, starting
@@ -41,15 +56,40 @@ func CreateSession(spec SessionSpec) error {
 	if err != nil {
 		return err
 	}
+	_, err = client.Command(newSessionArgs(spec)...)
+	return err
+}
+
+// CreateSessionID creates a session like CreateSession and returns its ID, which
+// stays a valid target when tmux rewrites the name (a "." or ":" in it
+// becomes "_").
+func CreateSessionID(spec SessionSpec) (string, error) {
+	client, err := newTmux(spec.SocketPath)
+	if err != nil {
+		return "", err
+	}
+	args := append([]string{"new-session", "-P", "-F", "#{session_id}"}, newSessionArgs(spec)[1:]...)
+	out, err := client.Command(args...)
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(out)
+	if id == "" {
+		return "", fmt.Errorf("new-session %s printed no session id", spec.Name)
+	}
+	return id, nil
+}
+
+func newSessionArgs(spec SessionSpec) []string {
 	args := []string{"new-session", "-d", "-s", spec.Name}
 	if spec.Dir != "" {
 		args = append(args, "-c", spec.Dir)
 	}
+	args = append(args, envArgs(spec.Env)...)
 	if spec.Command != "" {
 		args = append(args, spec.Command)
 	}
-	_, err = client.Command(args...)
-	return err
+	return args
 }
 
 // CreateWindow creates a new window at the given index within a session.
@@ -62,6 +102,7 @@ func CreateWindow(spec WindowSpec) error {
 	}
 	target := fmt.Sprintf("%s:%d", spec.Session, spec.Index)
 	args := []string{"new-window", "-t", target, "-n", spec.Name, "-c", spec.Dir, "-d"}
+	args = append(args, envArgs(spec.Env)...)
 	if spec.Command != "" {
 		args = append(args, spec.Command)
 	}
@@ -81,6 +122,7 @@ func SplitPane(spec PaneSpec) error {
 	if spec.Dir != "" {
 		args = append(args, "-c", spec.Dir)
 	}
+	args = append(args, envArgs(spec.Env)...)
 	if spec.Command != "" {
 		args = append(args, spec.Command)
 	}
@@ -100,6 +142,7 @@ func RespawnPane(spec PaneSpec) error {
 	if spec.Dir != "" {
 		args = append(args, "-c", spec.Dir)
 	}
+	args = append(args, envArgs(spec.Env)...)
 	if spec.Command != "" {
 		args = append(args, spec.Command)
 	}
@@ -62,6 +62,36 @@ func TestCreateSessionClientError(t *testing.T) {
 	}
 }
 
+func TestCreateSessionWithEnv(t *testing.T) {
+	fake := &fakeClient{}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	spec := SessionSpec{Name: "app", Dir: "/src/app", Command: "make run", Env: []string{"APP_ENV=dev", "PORT=8080"}}
+	if err := CreateSession(spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := fmt.Sprintf("%v", fake.commandCalls[0])
+	want := "[new-session -d -s app -c /src/app -e APP_ENV=dev -e PORT=8080 make run]"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestCreateSessionIDReturnsID(t *testing.T) {
+	fake := &fakeClient{commandOutput: "$7\n"}
+	withStubTmux(t, func(string) (tmuxClient, error) { return fake, nil })
+
+	id, err := CreateSessionID(SessionSpec{Name: "my.app", Dir: "/src/app"})
+	if err != nil || id != "$7" {
+		t.Fatalf("CreateSessionID = %q, %v", id, err)
+	}
+	got := fmt.Sprintf("%v", fake.commandCalls[0])
+	want := "[new-session -P -F #{session_id} -d -s my.app -c /src/app]"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
 // --- CreateWindow ---
 
 func TestCreateWindowSuccess(t *testing.T) {
@@ -24,6 +24,17 @@ func NewSession(socketPath, name string) error {
 	return err
 }
 
+// NewGroupedSession creates name in target's session group (new-session -t),
+// sharing its windows while keeping its own current window and size.
+func NewGroupedSession(socketPath, target, name string) error {
+	client, err := newTmux(socketPath)
+	if err != nil {
+		return err
+	}
+	_, err = client.Command("new-session", "-d", "-t", target, "-s", name)
+	return err
+}
+
 func RenameSession(socketPath, target, newName string) error {
 	client, err := newTmux(socketPath)
 	if err != nil {
@@ -61,6 +61,7 @@ func FetchSessions(socketPath string) (SessionSnapshot, error) {
 			Clients:  clients,
 			Current:  s.Name == currentName,
 			Windows:  s.Windows,
+			Group:    s.Group,
 		}
 		out = append(out, entry)
 	}
@@ -195,6 +196,7 @@ func FetchPanes(socketPath string) (PaneSnapshot, error) {
 			Path:      pane.CurrentPath,
 			Width:     pane.Width,
 			Height:    pane.Height,
+			PID:       int(pane.Pid),
 			Active:    pane.Active,
 			Label:     line.label,
 			Current:   current,
@@ -239,7 +241,7 @@ func FetchPanes(socketPath string) (PaneSnapshot, error) {
 // race at startup). It is intentionally kept as a direct tmux invocation
 // so it still works if the control-mode transport is misbehaving.
 func fetchSessionsFallback(socketPath string) ([]*gotmux.Session, error) {
-	format := "#{session_name}\t#{session_windows}\t#{session_attached}"
+	format := "#{session_name}\t#{session_windows}\t#{session_attached}\t#{session_group}"
 	args := make([]string, 0, 6)
 	if socketPath != "" {
 		args = append(args, "-S", socketPath)
@@ -256,15 +258,20 @@ func fetchSessionsFallback(socketPath string) ([]*gotmux.Session, error) {
 	lines := strings.Split(text, "\n")
 	sessions := make([]*gotmux.Session, 0, len(lines))
 	for _, line := range lines {
-		parts := splitTabLine(line, 3)
+		parts := splitTabLine(line, 4)
 		if len(parts) < 3 {
 			continue
 		}
-		sessions = append(sessions, &gotmux.Session{
+		session := &gotmux.Session{
 			Name:     parts[0],
 			Windows:  atoiOr0(parts[1]),
 			Attached: atoiOr0(parts[2]),
-		})
+		}
+		if len(parts) > 3 {
+			session.Group = parts[3]
+			session.Grouped = parts[3] != ""
+		}
+		sessions = append(sessions, session)
 	}
 	return sessions, nil
 }
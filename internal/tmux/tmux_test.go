@@ -452,6 +452,10 @@ func TestDefaultLabelForSession(t *testing.T) {
 	if got := defaultLabelForSession(session); got != "dev: 3 windows (attached)" {
 		t.Fatalf("unexpected label for plural %q", got)
 	}
+	session.Group = "dev"
+	if got := defaultLabelForSession(session); got != "dev: 3 windows (attached) (group dev)" {
+		t.Fatalf("unexpected label for grouped %q", got)
+	}
 }
 
 func TestResolveSocketPath(t *testing.T) {
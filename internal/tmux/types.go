@@ -33,6 +33,7 @@ type Pane struct {
 	Path      string
 	Width     int
 	Height    int
+	PID       int
 	Active    bool
 	Label     string
 	Current   bool
@@ -54,6 +55,9 @@ type Session struct {
 	Clients  []string
 	Current  bool
 	Windows  int
+	// Group is #{session_group}: the group the session shares its windows
+	// with, empty when it is not grouped.
+	Group string
 }
 
 type SessionSnapshot struct {
@@ -84,7 +88,7 @@ type sessionHandle interface {
 }
 
 var (
-	defaultSessionFormat = "#S: #{session_windows} windows#{?session_attached, (attached),}"
+	defaultSessionFormat = "#S: #{session_windows} windows#{?session_attached, (attached),}#{?session_grouped, (group #{session_group}),}"
 
 	clientMu     sync.Mutex
 	cachedClient tmuxClient
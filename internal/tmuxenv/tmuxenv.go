@@ -0,0 +1,124 @@
+// Package tmuxenv reads `tmux show-environment` output and works out which
+// panes carry an outdated copy of a variable. tmux hands a pane the global
+// environment overlaid with its session's only when the pane starts, so a
+// later set-environment, or a reattach that refreshes the update-environment
+// variables, leaves the panes already running behind. reading pane
+// environments and running tmux is the menu package's job, so there are no
+// exec, tmux, bubbletea, or menu imports here.
+package tmuxenv
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/atomicstack/tmux-popup-control/internal/shquote"
+)
+
+// Var is one line of show-environment. A removed variable, printed as
+// "-NAME", is kept out of new panes even when the global environment sets
+// it.
+type Var struct {
+	Name    string
+	Value   string
+	Removed bool
+}
+
+// Parse reads show-environment output, in the order tmux prints it.
+func Parse(out string) []Var {
+	var vars []Var
+	for line := range strings.SplitSeq(out, "\n") {
+		if line == "" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "-"); ok {
+			if !strings.Contains(name, "=") {
+				vars = append(vars, Var{Name: name, Removed: true})
+			}
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || name == "" {
+			continue
+		}
+		vars = append(vars, Var{Name: name, Value: value})
+	}
+	return vars
+}
+
+// Lookup returns the variable called name.
+func Lookup(vars []Var, name string) (Var, bool) {
+	i := slices.IndexFunc(vars, func(v Var) bool { return v.Name == name })
+	if i < 0 {
+		return Var{}, false
+	}
+	return vars[i], true
+}
+
+// Effective returns the value a pane started now in the session gets: the
+// session's own entry when it has one, the global one otherwise. set is
+// false when neither scope sets it or the deciding entry removes it.
+func Effective(session, global []Var, name string) (value string, set bool) {
+	v, ok := Lookup(session, name)
+	if !ok {
+		v, ok = Lookup(global, name)
+	}
+	if !ok || v.Removed {
+		return "", false
+	}
+	return v.Value, true
+}
+
+// Stale reports whether env, the environment a pane started with, disagrees
+// with the value, or absence, new panes would get.
+func Stale(env map[string]string, name, value string, set bool) bool {
+	had, ok := env[name]
+	if ok != set {
+		return true
+	}
+	return set && had != value
+}
+
+// ParseUpdateList reads the update-environment option, whose values tmux
+// prints one per line.
+func ParseUpdateList(out string) []string {
+	return strings.Fields(out)
+}
+
+// CheckName rejects names tmux or a shell would not take as a variable.
+func CheckName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("variable name is empty")
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("variable name %q starts with '-'", name)
+	case strings.ContainsAny(name, "= \t\n"):
+		return fmt.Errorf("variable name %q contains '=' or whitespace", name)
+	}
+	return nil
+}
+
+// shells are the pane commands ShellCommand knows how to address.
+var shells = []string{"bash", "zsh", "fish", "sh", "dash", "ksh", "mksh", "ash"}
+
+// IsShell reports whether command, a pane's #{pane_current_command}, is a
+// shell ShellCommand can update.
+func IsShell(command string) bool {
+	return slices.Contains(shells, strings.TrimPrefix(command, "-"))
+}
+
+// ShellCommand is the line to type into shell to bring name up to date.
+func ShellCommand(shell, name, value string, set bool) string {
+	fish := strings.TrimPrefix(shell, "-") == "fish"
+	switch {
+	case !set && fish:
+		return "set -e " + name
+	case !set:
+		return "unset " + name
+	case fish:
+		return "set -gx " + name + " " + shquote.Quote(value)
+	default:
+		return "export " + name + "=" + shquote.Quote(value)
+	}
+}
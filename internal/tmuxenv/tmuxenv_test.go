@@ -0,0 +1,99 @@
+package tmuxenv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	out := "DISPLAY=:1\n-SSH_AGENT_PID\nSSH_AUTH_SOCK=/tmp/ssh-new/agent\nEQ=a=b\nEMPTY=\n\n=broken\n"
+	got := Parse(out)
+	want := []Var{
+		{Name: "DISPLAY", Value: ":1"},
+		{Name: "SSH_AGENT_PID", Removed: true},
+		{Name: "SSH_AUTH_SOCK", Value: "/tmp/ssh-new/agent"},
+		{Name: "EQ", Value: "a=b"},
+		{Name: "EMPTY"},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Parse = %#v, want %#v", got, want)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	session := Parse("SSH_AUTH_SOCK=/tmp/new\n-DISPLAY\n")
+	global := Parse("SSH_AUTH_SOCK=/tmp/old\nDISPLAY=:0\nTERM=xterm\n-EDITOR\n")
+	cases := []struct {
+		name  string
+		value string
+		set   bool
+	}{
+		{"SSH_AUTH_SOCK", "/tmp/new", true},
+		{"DISPLAY", "", false},
+		{"TERM", "xterm", true},
+		{"EDITOR", "", false},
+		{"MISSING", "", false},
+	}
+	for _, c := range cases {
+		value, set := Effective(session, global, c.name)
+		if value != c.value || set != c.set {
+			t.Errorf("Effective(%s) = %q %v, want %q %v", c.name, value, set, c.value, c.set)
+		}
+	}
+}
+
+func TestStale(t *testing.T) {
+	env := map[string]string{"SSH_AUTH_SOCK": "/tmp/old", "EMPTY": ""}
+	cases := []struct {
+		name  string
+		value string
+		set   bool
+		stale bool
+	}{
+		{"SSH_AUTH_SOCK", "/tmp/old", true, false},
+		{"SSH_AUTH_SOCK", "/tmp/new", true, true},
+		{"SSH_AUTH_SOCK", "", false, true},
+		{"EMPTY", "", true, false},
+		{"DISPLAY", ":0", true, true},
+		{"DISPLAY", "", false, false},
+	}
+	for _, c := range cases {
+		if got := Stale(env, c.name, c.value, c.set); got != c.stale {
+			t.Errorf("Stale(%s=%q set=%v) = %v, want %v", c.name, c.value, c.set, got, c.stale)
+		}
+	}
+}
+
+func TestCheckName(t *testing.T) {
+	for _, name := range []string{"FOO", "foo_bar", "A1"} {
+		if err := CheckName(name); err != nil {
+			t.Errorf("CheckName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-FOO", "A=B", "A B"} {
+		if err := CheckName(name); err == nil {
+			t.Errorf("CheckName(%q) accepted", name)
+		}
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	cases := []struct {
+		shell, value string
+		set          bool
+		want         string
+	}{
+		{"zsh", "/tmp/a b", true, "export SSH_AUTH_SOCK='/tmp/a b'"},
+		{"-bash", "", false, "unset SSH_AUTH_SOCK"},
+		{"fish", "/tmp/a", true, "set -gx SSH_AUTH_SOCK '/tmp/a'"},
+		{"fish", "", false, "set -e SSH_AUTH_SOCK"},
+	}
+	for _, c := range cases {
+		if got := ShellCommand(c.shell, "SSH_AUTH_SOCK", c.value, c.set); got != c.want {
+			t.Errorf("ShellCommand(%s) = %q, want %q", c.shell, got, c.want)
+		}
+	}
+	if !IsShell("-zsh") || IsShell("vim") {
+		t.Fatal("IsShell misclassified a command")
+	}
+}
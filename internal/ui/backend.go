@@ -152,6 +152,8 @@ func (m *Model) applyBackendEvent(evt backend.Event) tea.Cmd {
 		}
 		m.applySimpleLevelUpdates(ctx, []levelUpdate{
 			{"session:rename", func(c menu.Context) []menu.Item { return menu.SessionRenameItems(c.Sessions) }},
+			{"session:new-grouped", func(c menu.Context) []menu.Item { return menu.SessionSourceItems(c.Sessions) }},
+			{"session:clone", func(c menu.Context) []menu.Item { return menu.SessionSourceItems(c.Sessions) }},
 			{"session:detach", func(c menu.Context) []menu.Item { return menu.SessionEntriesToItems(c.Sessions) }},
 			{"session:kill", func(c menu.Context) []menu.Item { return menu.SessionEntriesToItems(c.Sessions) }},
 			{"window:push-to-session", windowPushToSessionItems},
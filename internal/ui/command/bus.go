@@ -17,12 +17,25 @@ type Request struct {
 	Item    menu.Item
 }
 
+// Hooks observe the requests the bus runs, off the UI goroutine. ctx is the
+// context the request was queued with, so it still describes tmux as it was
+// before the action ran.
+type Hooks struct {
+	// Before sees every request that has a handler, before it runs.
+	Before func(ctx menu.Context, req Request)
+	// After sees every request whose command produced a message, with that
+	// message.
+	After func(ctx menu.Context, req Request, msg tea.Msg)
+}
+
 // Bus coordinates the execution of menu actions.
-type Bus struct{}
+type Bus struct {
+	hooks Hooks
+}
 
 // New initialises a command bus instance.
-func New() *Bus {
-	return &Bus{}
+func New(hooks Hooks) *Bus {
+	return &Bus{hooks: hooks}
 }
 
 // Execute wraps a menu action into a Bubble Tea command while emitting trace logs.
@@ -40,6 +53,9 @@ func (b *Bus) Execute(ctx menu.Context, req Request) tea.Cmd {
 			span.EndWithOutcome("skip", nil)
 			return nil
 		}
+		if b.hooks.Before != nil {
+			b.hooks.Before(ctx, req)
+		}
 		cmd := req.Handler(ctx, req.Item)
 		if cmd == nil {
 			events.Command.NoOp(req.ID, req.Label)
@@ -47,6 +63,9 @@ func (b *Bus) Execute(ctx menu.Context, req Request) tea.Cmd {
 			return nil
 		}
 		msg := cmd()
+		if b.hooks.After != nil && msg != nil {
+			b.hooks.After(ctx, req, msg)
+		}
 		span.AddAttr("msg_type", fmt.Sprintf("%T", msg))
 		events.Command.Result(req.ID, req.Label, fmt.Sprintf("%T", msg))
 		span.End(nil)
@@ -13,7 +13,7 @@ type testMsg struct {
 }
 
 func TestExecuteSkipsNilHandler(t *testing.T) {
-	bus := New()
+	bus := New(Hooks{})
 
 	cmd := bus.Execute(menu.Context{}, Request{ID: "pane:kill", Label: "kill"})
 	if got := cmd(); got != nil {
@@ -22,7 +22,7 @@ func TestExecuteSkipsNilHandler(t *testing.T) {
 }
 
 func TestExecuteSkipsNilCommand(t *testing.T) {
-	bus := New()
+	bus := New(Hooks{})
 
 	cmd := bus.Execute(menu.Context{}, Request{
 		ID:    "pane:kill",
@@ -37,7 +37,7 @@ func TestExecuteSkipsNilCommand(t *testing.T) {
 }
 
 func TestExecuteReturnsHandlerMessage(t *testing.T) {
-	bus := New()
+	bus := New(Hooks{})
 	want := testMsg{Value: "ok"}
 
 	cmd := bus.Execute(menu.Context{}, Request{
@@ -52,3 +52,31 @@ func TestExecuteReturnsHandlerMessage(t *testing.T) {
 		t.Fatalf("got %#v, want %#v", got, want)
 	}
 }
+
+func TestExecuteRunsHooksAroundHandlers(t *testing.T) {
+	var recorded []string
+	bus := New(Hooks{
+		Before: func(_ menu.Context, req Request) { recorded = append(recorded, "before "+req.ID+" "+req.Item.ID) },
+		After: func(_ menu.Context, req Request, msg tea.Msg) {
+			recorded = append(recorded, "after "+req.ID+" "+msg.(testMsg).Value)
+		},
+	})
+	bus.Execute(menu.Context{}, Request{ID: "pane:kill", Label: "kill"})()
+	bus.Execute(menu.Context{}, Request{
+		ID:      "window:kill",
+		Label:   "kill",
+		Handler: func(menu.Context, menu.Item) tea.Cmd { return nil },
+	})()
+	bus.Execute(menu.Context{}, Request{
+		ID:    "session:switch",
+		Label: "dev",
+		Item:  menu.Item{ID: "dev", Label: "dev"},
+		Handler: func(menu.Context, menu.Item) tea.Cmd {
+			return func() tea.Msg { return testMsg{Value: "ok"} }
+		},
+	})()
+	want := []string{"before window:kill ", "before session:switch dev", "after session:switch ok"}
+	if !reflect.DeepEqual(recorded, want) {
+		t.Fatalf("recorded = %q, want %q", recorded, want)
+	}
+}
@@ -243,6 +243,9 @@ func (m *Model) menuContext() menu.Context {
 		PaneIncludeCurrent:   m.panes.IncludeCurrent(),
 		ExtractCategory:      m.extractCategory,
 		ExtractGrabArea:      m.extractGrabArea,
+		SearchScope:          m.searchScope,
+		SearchQuery:          m.searchQuery,
+		SearchRegex:          m.searchRegex,
 	}
 	for _, w := range ctx.Windows {
 		if w.Current {
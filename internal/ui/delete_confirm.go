@@ -14,10 +14,13 @@ import (
 const deleteSavedLevelID = "resurrect:delete-saved"
 
 // deleteConfirmState carries the in-flight y/n confirmation prompt that
-// replaces the filter input on the resurrect delete-saved page.
+// replaces the filter input on the resurrect delete-saved page. The process
+// signal submenus reuse it with their own prompt and pending label.
 type deleteConfirmState struct {
 	levelID           string
 	item              menu.Item
+	prompt            string
+	label             string
 	savedFilter       string
 	savedFilterCursor int
 }
@@ -35,6 +38,14 @@ type deleteSavedReloadedMsg struct {
 // startDeleteConfirm enters the confirmation state on the current
 // resurrect:delete-saved level.
 func (m *Model) startDeleteConfirm(item menu.Item) {
+	name := deleteSaveDisplayName(item)
+	m.startConfirm(item, fmt.Sprintf("delete save '%s'?", name), name)
+}
+
+// startConfirm enters the y/n confirmation state on the current level. prompt
+// is the question shown ahead of the [y/n] marker; label becomes the pending
+// label once the action is dispatched.
+func (m *Model) startConfirm(item menu.Item, prompt, label string) {
 	current := m.currentLevel()
 	if current == nil {
 		return
@@ -42,6 +53,8 @@ func (m *Model) startDeleteConfirm(item menu.Item) {
 	m.confirmState = &deleteConfirmState{
 		levelID:           current.ID,
 		item:              item,
+		prompt:            prompt,
+		label:             label,
 		savedFilter:       current.Filter,
 		savedFilterCursor: current.FilterCursorPos(),
 	}
@@ -63,9 +76,9 @@ func (m *Model) cancelDeleteConfirm() {
 	}
 }
 
-// commitDeleteConfirm dispatches the underlying delete action. The saved
-// filter state is stashed on the model so the post-delete reload can restore
-// it.
+// commitDeleteConfirm dispatches the underlying action. For delete-saved the
+// saved filter state is stashed on the model so the post-delete reload can
+// restore it; multi-select levels drop their marks once the action is sent.
 func (m *Model) commitDeleteConfirm() tea.Cmd {
 	if m.confirmState == nil {
 		return nil
@@ -79,17 +92,21 @@ func (m *Model) commitDeleteConfirm() tea.Cmd {
 		}
 		return nil
 	}
-	label := deleteSaveDisplayName(cs.item)
 	m.loading = true
 	m.pendingID = cs.levelID
-	m.pendingLabel = label
-	m.pendingDeleteFilter = cs.savedFilter
-	m.pendingDeleteFilterCursor = cs.savedFilterCursor
+	m.pendingLabel = cs.label
+	if cs.levelID == deleteSavedLevelID {
+		m.pendingDeleteFilter = cs.savedFilter
+		m.pendingDeleteFilterCursor = cs.savedFilterCursor
+	}
+	if cur := m.currentLevel(); cur != nil && cur.MultiSelect {
+		cur.ClearSelection()
+	}
 	m.errMsg = ""
 	m.forceClearInfo()
 	return m.bus.Execute(m.menuContext(), command.Request{
 		ID:      cs.levelID,
-		Label:   label,
+		Label:   cs.label,
 		Handler: node.Action,
 		Item:    cs.item,
 	})
@@ -119,8 +136,7 @@ func (m *Model) renderDeleteConfirmPrompt() string {
 	if m.confirmState == nil {
 		return ""
 	}
-	name := deleteSaveDisplayName(m.confirmState.item)
-	return renderYNPrompt(fmt.Sprintf("delete save '%s'? %s", name, YNPromptMarker))
+	return renderYNPrompt(m.confirmState.prompt + " " + YNPromptMarker)
 }
 
 // handleDeleteSavedActionResult finishes the post-confirm flow: surface a
@@ -275,3 +275,83 @@ func TestEnterKeyOnNonDeleteSavedLevelStillFlowsThrough(t *testing.T) {
 		t.Fatal("expected confirm state to remain nil on non-delete-saved level")
 	}
 }
+
+func TestProcessSignalLevelConfirmsBeforeDispatch(t *testing.T) {
+	m := NewModel(ModelConfig{Width: 100, Height: 30})
+	node, ok := m.registry.Find("process:kill")
+	if !ok || node == nil {
+		t.Fatal("registry missing process:kill node")
+	}
+	items := []menu.Item{
+		{Label: "pid  state  pane  command", Header: true},
+		{ID: "111", Label: "111  R  s:0.0  cc1 a.c"},
+		{ID: "112", Label: "112  R  s:0.0  cc1 b.c"},
+	}
+	lvl := newLevel("process:kill", "kill", items, node)
+	lvl.Cursor = 1
+	m.applyNodeSettings(lvl)
+	m.stack = []*level{lvl}
+	lvl.ToggleSelection("111")
+	lvl.ToggleSelection("112")
+
+	if cmd := m.handleEnterKey(); cmd != nil {
+		t.Fatalf("expected Enter to open the confirm prompt, got %T", cmd())
+	}
+	if m.confirmState == nil {
+		t.Fatal("expected confirmState to be set on a process signal level")
+	}
+	if got := m.confirmState.item.ID; got != "111\n112" {
+		t.Fatalf("expected joined selection, got %q", got)
+	}
+	if prompt := m.renderDeleteConfirmPrompt(); !strings.Contains(prompt, "SIGKILL to 2 processes") {
+		t.Fatalf("expected signal prompt, got %q", prompt)
+	}
+
+	m.handleDeleteConfirmKey(tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if len(lvl.SelectedItems()) != 2 {
+		t.Fatal("expected marks to survive a declined confirmation")
+	}
+
+	m.handleEnterKey()
+	if cmd := m.handleDeleteConfirmKey(tea.KeyPressMsg{Code: 'y', Text: "y"}); cmd == nil {
+		t.Fatal("expected confirm-yes to dispatch the signal action")
+	}
+	if m.pendingID != "process:kill" || m.pendingLabel != "SIGKILL → 2 processes" {
+		t.Fatalf("unexpected pending state %q / %q", m.pendingID, m.pendingLabel)
+	}
+	if m.pendingDeleteFilter != "" {
+		t.Fatalf("expected delete filter untouched, got %q", m.pendingDeleteFilter)
+	}
+	if len(lvl.SelectedItems()) != 0 {
+		t.Fatal("expected marks cleared once the action is dispatched")
+	}
+}
+
+func TestWorktreeRemoveConfirmsBeforeDispatch(t *testing.T) {
+	m := NewModel(ModelConfig{Width: 100, Height: 30})
+	node, ok := m.registry.Find(menu.WorktreeRemoveID)
+	if !ok || node == nil {
+		t.Fatal("registry missing worktree:remove node")
+	}
+	items := []menu.Item{
+		{Label: "worktree  path  state", Header: true},
+		{ID: "/src/app-feature-x", Label: "feature/x  ~/src/app-feature-x"},
+	}
+	lvl := newLevel(menu.WorktreeRemoveID, "remove", items, node)
+	lvl.Cursor = 1
+	m.applyNodeSettings(lvl)
+	m.stack = []*level{lvl}
+
+	if cmd := m.handleEnterKey(); cmd != nil {
+		t.Fatalf("expected Enter to open the confirm prompt, got %T", cmd())
+	}
+	if prompt := m.renderDeleteConfirmPrompt(); !strings.Contains(prompt, "remove worktree app-feature-x?") {
+		t.Fatalf("expected worktree prompt, got %q", prompt)
+	}
+	if cmd := m.handleDeleteConfirmKey(tea.KeyPressMsg{Code: 'y', Text: "y"}); cmd == nil {
+		t.Fatal("expected confirm-yes to dispatch the removal")
+	}
+	if m.pendingID != menu.WorktreeRemoveID || m.pendingLabel != "remove app-feature-x" {
+		t.Fatalf("unexpected pending state %q %q", m.pendingID, m.pendingLabel)
+	}
+}
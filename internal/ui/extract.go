@@ -4,7 +4,7 @@ import (
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
-	"github.com/atomicstack/tmux-popup-control/internal/clipboard"
+	"github.com/atomicstack/tmux-popup-control/internal/cliphistory"
 	"github.com/atomicstack/tmux-popup-control/internal/extract"
 	"github.com/atomicstack/tmux-popup-control/internal/logging"
 	"github.com/atomicstack/tmux-popup-control/internal/menu"
@@ -19,10 +19,13 @@ const extractLevelID = "extract"
 var (
 	extractInsertFn = tmux.InsertText
 	extractCopyFn   = tmux.CopyText
-	// extractClipboardFn copies to the host's native system clipboard. a
-	// failure here is logged but never blocks the copy (the tmux buffer is
-	// the source of truth).
-	extractClipboardFn = clipboard.Copy
+	// extractClipboardFn copies to the system clipboard (native tool and/or
+	// OSC 52). a failure here is logged but never blocks the copy (the tmux
+	// buffer is the source of truth).
+	extractClipboardFn = menu.CopyToSystemClipboard
+	// extractHistoryFn records the copy in the clipboard history; like the
+	// system clipboard it is best-effort.
+	extractHistoryFn = menu.RecordClipboardHistory
 )
 
 // extractDoneMsg carries the result of an extractInsert/extractCopy action.
@@ -168,8 +171,8 @@ func (m *Model) extractInsert() tea.Cmd {
 	return func() tea.Msg { return extractDoneMsg{err: extractInsertFn(sock, target, text)} }
 }
 
-// extractCopy stores the selected token(s) in the tmux paste buffer and the
-// system clipboard, then quits on success. The tmux buffer is the source of
+// extractCopy stores the selected token(s) in the tmux paste buffer, the
+// system clipboard and the clipboard history, then quits on success. The tmux buffer is the source of
 // truth: a system-clipboard failure is logged but never blocks the copy.
 func (m *Model) extractCopy() tea.Cmd {
 	text, ok := m.extractSelectedText()
@@ -183,7 +186,10 @@ func (m *Model) extractCopy() tea.Cmd {
 		if err := extractCopyFn(sock, text); err != nil {
 			return extractDoneMsg{err: err}
 		}
-		if err := extractClipboardFn(text); err != nil {
+		if err := extractClipboardFn(sock, text); err != nil {
+			logging.Error(err)
+		}
+		if err := extractHistoryFn(sock, text, cliphistory.SourceExtract); err != nil {
 			logging.Error(err)
 		}
 		return extractDoneMsg{err: nil}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/atomicstack/tmux-popup-control/internal/cliphistory"
 	"github.com/atomicstack/tmux-popup-control/internal/extract"
 	"github.com/atomicstack/tmux-popup-control/internal/menu"
 	"github.com/charmbracelet/x/ansi"
@@ -953,12 +954,20 @@ func TestExtractCopyAlsoCopiesToSystemClipboard(t *testing.T) {
 
 	origClipboard := extractClipboardFn
 	var clipboardCopied string
-	extractClipboardFn = func(text string) error {
+	extractClipboardFn = func(_, text string) error {
 		clipboardCopied = text
 		return nil
 	}
 	defer func() { extractClipboardFn = origClipboard }()
 
+	origHistory := extractHistoryFn
+	var historyRecorded, historySource string
+	extractHistoryFn = func(_, text, source string) error {
+		historyRecorded, historySource = text, source
+		return nil
+	}
+	defer func() { extractHistoryFn = origHistory }()
+
 	m := NewModel(ModelConfig{Width: 80, Height: 24, RootMenu: "extract", SocketPath: "test.sock"})
 	h := NewHarness(m)
 
@@ -987,6 +996,9 @@ func TestExtractCopyAlsoCopiesToSystemClipboard(t *testing.T) {
 	if clipboardCopied != "please" {
 		t.Fatalf("clipboard copied = %q, want please", clipboardCopied)
 	}
+	if historyRecorded != "please" || historySource != cliphistory.SourceExtract {
+		t.Fatalf("history recorded %q from %q, want please from extract", historyRecorded, historySource)
+	}
 
 	_, cmd2 := h.Model().Update(msg)
 	if cmd2 == nil {
@@ -1012,7 +1024,7 @@ func TestExtractClipboardErrorStillQuits(t *testing.T) {
 	defer func() { extractCopyFn = origCopy }()
 
 	origClipboard := extractClipboardFn
-	extractClipboardFn = func(text string) error { return errors.New("no clipboard tool") }
+	extractClipboardFn = func(_, text string) error { return errors.New("no clipboard tool") }
 	defer func() { extractClipboardFn = origClipboard }()
 
 	m := NewModel(ModelConfig{Width: 80, Height: 24, RootMenu: "extract", SocketPath: "test.sock"})
@@ -1066,7 +1078,7 @@ func TestExtractBufferErrorDoesNotQuit(t *testing.T) {
 
 	origClipboard := extractClipboardFn
 	clipboardCalled := false
-	extractClipboardFn = func(text string) error {
+	extractClipboardFn = func(_, text string) error {
 		clipboardCalled = true
 		return nil
 	}
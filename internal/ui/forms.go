@@ -8,6 +8,7 @@ import (
 
 	"github.com/atomicstack/tmux-popup-control/internal/menu"
 	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
+	"github.com/atomicstack/tmux-popup-control/internal/ui/command"
 )
 
 func (m *Model) handlePaneForm(msg tea.Msg) (bool, tea.Cmd) {
@@ -17,6 +18,51 @@ func (m *Model) handlePaneForm(msg tea.Msg) (bool, tea.Cmd) {
 	return m.handleRenameForm(msg, m.paneForm, false, func() { m.paneForm = nil })
 }
 
+func (m *Model) handlePaneBroadcastForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.paneBroadcastForm == nil {
+		return false, nil
+	}
+	return m.handleRenameForm(msg, m.paneBroadcastForm, false, func() { m.paneBroadcastForm = nil })
+}
+
+// handlePaneResizeMode takes keys and redrawn layouts while the resize mode
+// is up; everything else goes on to the usual handlers.
+func (m *Model) handlePaneResizeMode(msg tea.Msg) (bool, tea.Cmd) {
+	if m.paneResizeMode == nil {
+		return false, nil
+	}
+	switch msg := msg.(type) {
+	case menu.PaneResizeLayoutMsg:
+		m.paneResizeMode.SetLayout(msg)
+		return true, nil
+	case tea.KeyPressMsg:
+		cmd, done := m.paneResizeMode.Update(msg)
+		if done {
+			m.paneResizeMode = nil
+			m.mode = ModeMenu
+			if m.rootMenuID == "pane:resize" {
+				return true, tea.Quit
+			}
+		}
+		return true, cmd
+	}
+	return false, nil
+}
+
+func (m *Model) handlePaneSpawnForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.paneSpawnForm == nil {
+		return false, nil
+	}
+	return m.handleRenameForm(msg, m.paneSpawnForm, false, func() { m.paneSpawnForm = nil })
+}
+
+func (m *Model) handlePaneLogForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.paneLogForm == nil {
+		return false, nil
+	}
+	return m.handleRenameForm(msg, m.paneLogForm, false, func() { m.paneLogForm = nil })
+}
+
 func (m *Model) handleWindowForm(msg tea.Msg) (bool, tea.Cmd) {
 	if m.windowForm == nil {
 		return false, nil
@@ -24,6 +70,62 @@ func (m *Model) handleWindowForm(msg tea.Msg) (bool, tea.Cmd) {
 	return m.handleRenameForm(msg, m.windowForm, m.rootMenuID == "window:rename", func() { m.windowForm = nil })
 }
 
+func (m *Model) handleBufferForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.bufferForm == nil {
+		return false, nil
+	}
+	return m.handleRenameForm(msg, m.bufferForm, false, func() { m.bufferForm = nil })
+}
+
+func (m *Model) handleWorktreeForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.worktreeForm == nil {
+		return false, nil
+	}
+	return m.handleRenameForm(msg, m.worktreeForm, false, func() { m.worktreeForm = nil })
+}
+
+func (m *Model) handleSessionEnvForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.sessionEnvForm == nil {
+		return false, nil
+	}
+	return m.handleRenameForm(msg, m.sessionEnvForm, false, func() { m.sessionEnvForm = nil })
+}
+
+func (m *Model) handleServerKillForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.serverKillForm == nil {
+		return false, nil
+	}
+	return m.handleRenameForm(msg, m.serverKillForm, false, func() { m.serverKillForm = nil })
+}
+
+// handleSearchForm keeps the submitted query on the model, where
+// menuContext hands it to the results loader, and opens the results.
+func (m *Model) handleSearchForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.searchForm == nil {
+		return false, nil
+	}
+	cmd, done, cancel := m.searchForm.Update(msg)
+	if cancel {
+		m.searchForm = nil
+		m.mode = ModeMenu
+		return true, cmd
+	}
+	if done {
+		form := m.searchForm
+		m.searchForm = nil
+		m.mode = ModeMenu
+		node, ok := m.registry.Find(form.ActionID())
+		if !ok {
+			return true, nil
+		}
+		m.searchScope = form.Scope()
+		m.searchQuery = form.Value()
+		m.searchRegex = form.Regex()
+		return true, m.openNode(m.currentLevel(), node, form.PendingLabel())
+	}
+	return true, cmd
+}
+
 func (m *Model) handleSessionForm(msg tea.Msg) (bool, tea.Cmd) {
 	if m.sessionForm == nil {
 		return false, nil
@@ -49,7 +151,7 @@ func (m *Model) handleSessionForm(msg tea.Msg) (bool, tea.Cmd) {
 		if cmd == nil {
 			cmd = menu.SessionCommandForAction(req)
 		}
-		return true, cmd
+		return true, m.formRequest(req.Context, actionID, pendingLabel, req.Target, req.Value, cmd)
 	}
 	if cmd != nil {
 		return true, cmd
@@ -57,6 +159,55 @@ func (m *Model) handleSessionForm(msg tea.Msg) (bool, tea.Cmd) {
 	return true, nil
 }
 
+func (m *Model) handleTemplateForm(msg tea.Msg) (bool, tea.Cmd) {
+	if m.templateForm == nil {
+		return false, nil
+	}
+	cmd, done, cancel := m.templateForm.Update(msg)
+	if cancel {
+		m.templateForm = nil
+		m.mode = ModeMenu
+		return true, cmd
+	}
+	if done {
+		form := m.templateForm
+		actionID := form.ActionID()
+		pendingLabel := form.PendingLabel()
+		m.templateForm = nil
+		m.mode = ModeMenu
+		m.loading = true
+		m.pendingID = actionID
+		m.pendingLabel = pendingLabel
+		return true, m.formRequest(form.Context(), actionID, pendingLabel, form.TemplateID(), form.Project(), cmd)
+	}
+	return true, cmd
+}
+
+func (m *Model) startTemplateForm(prompt menu.SessionTemplatePrompt) tea.Cmd {
+	m.templateForm = menu.NewSessionTemplateForm(prompt)
+	m.mode = ModeTemplateForm
+	return m.templateForm.FocusCmd()
+}
+
+// viewTemplateForm renders the template form: both inputs, then what enter
+// would create. The returned row is the focused input's.
+func (m *Model) viewTemplateForm(header string) (string, int) {
+	f := m.templateForm
+	title := f.Title()
+	if header != "" {
+		title = header + menuHeaderSeparator + title
+	}
+	lines := []string{title, ""}
+	inputRow := len(lines) + f.Focus()
+	lines = append(lines, f.InputViews()...)
+	lines = append(lines, "", lipgloss.NewStyle().Faint(true).Render(f.Summary()))
+	if err := f.Error(); err != "" {
+		lines = append(lines, "", styles.Error.Render(err))
+	}
+	lines = append(lines, "", f.Help())
+	return strings.Join(lines, "\n"), inputRow
+}
+
 func (m *Model) startSessionForm(prompt menu.SessionPrompt) tea.Cmd {
 	m.sessionForm = menu.NewSessionForm(prompt)
 	m.mode = ModeSessionForm
@@ -75,12 +226,84 @@ func (m *Model) startPaneForm(prompt menu.PanePrompt) tea.Cmd {
 	return m.paneForm.FocusCmd()
 }
 
+func (m *Model) startPaneBroadcastForm(prompt menu.PaneBroadcastPrompt) tea.Cmd {
+	m.paneBroadcastForm = menu.NewPaneBroadcastForm(prompt)
+	m.mode = ModePaneBroadcastForm
+	return m.paneBroadcastForm.FocusCmd()
+}
+
+func (m *Model) startPaneResizeMode(prompt menu.PaneResizePrompt) tea.Cmd {
+	m.paneResizeMode = menu.NewPaneResizeMode(prompt)
+	m.mode = ModePaneResize
+	return m.paneResizeMode.RefreshCmd()
+}
+
+func (m *Model) startPaneSpawnForm(prompt menu.PaneSpawnPrompt) tea.Cmd {
+	m.paneSpawnForm = menu.NewPaneSpawnForm(prompt)
+	m.mode = ModePaneSpawnForm
+	return m.paneSpawnForm.FocusCmd()
+}
+
+func (m *Model) startPaneLogForm(prompt menu.PaneLogPrompt) tea.Cmd {
+	m.paneLogForm = menu.NewPaneLogForm(prompt)
+	m.mode = ModePaneLogForm
+	return m.paneLogForm.FocusCmd()
+}
+
+func (m *Model) startBufferForm(prompt menu.BufferPrompt) tea.Cmd {
+	m.bufferForm = menu.NewBufferForm(prompt)
+	m.mode = ModeBufferForm
+	return m.bufferForm.FocusCmd()
+}
+
+func (m *Model) startWorktreeForm(prompt menu.WorktreePrompt) tea.Cmd {
+	m.worktreeForm = menu.NewWorktreeForm(prompt)
+	m.mode = ModeWorktreeForm
+	return m.worktreeForm.FocusCmd()
+}
+
+func (m *Model) startSessionEnvForm(prompt menu.SessionEnvPrompt) tea.Cmd {
+	m.sessionEnvForm = menu.NewSessionEnvForm(prompt)
+	m.mode = ModeSessionEnvForm
+	return m.sessionEnvForm.FocusCmd()
+}
+
+func (m *Model) startSearchForm(prompt menu.SearchPrompt) tea.Cmd {
+	m.searchForm = menu.NewSearchForm(prompt)
+	m.mode = ModeSearchForm
+	return m.searchForm.FocusCmd()
+}
+
+func (m *Model) startServerKillForm(prompt menu.ServerKillPrompt) tea.Cmd {
+	m.serverKillForm = menu.NewServerKillForm(prompt)
+	m.mode = ModeServerKillForm
+	return m.serverKillForm.FocusCmd()
+}
+
 type renameForm interface {
 	Update(tea.Msg) (tea.Cmd, bool, bool)
+	Context() menu.Context
+	Target() string
+	Value() string
 	ActionID() string
 	PendingLabel() string
 }
 
+// formRequest routes a submitted form's command through the bus, so the
+// bus hooks see it like any menu action: the item carries the form's target
+// as ID and the entered value as Label.
+func (m *Model) formRequest(ctx menu.Context, actionID, label, target, value string, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return m.bus.Execute(ctx, command.Request{
+		ID:      actionID,
+		Label:   label,
+		Handler: func(menu.Context, menu.Item) tea.Cmd { return cmd },
+		Item:    menu.Item{ID: target, Label: value},
+	})
+}
+
 func (m *Model) handleRenameForm(msg tea.Msg, form renameForm, quitOnCancel bool, clear func()) (bool, tea.Cmd) {
 	cmd, done, cancel := form.Update(msg)
 	if cancel {
@@ -99,7 +322,7 @@ func (m *Model) handleRenameForm(msg tea.Msg, form renameForm, quitOnCancel bool
 		m.loading = true
 		m.pendingID = actionID
 		m.pendingLabel = pendingLabel
-		return true, cmd
+		return true, m.formRequest(form.Context(), actionID, pendingLabel, form.Target(), form.Value(), cmd)
 	}
 	if cmd != nil {
 		return true, cmd
@@ -115,10 +338,61 @@ func (m *Model) viewPaneFormWithHeader(header string) (string, int) {
 	return m.viewFormWithHeader(m.paneForm.Title(), m.paneForm.InputView(), m.paneForm.Help(), header)
 }
 
+func (m *Model) viewPaneBroadcastFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.paneBroadcastForm.Title(), m.paneBroadcastForm.InputView(), m.paneBroadcastForm.Help(), header)
+}
+
+// viewPaneResizeModeWithHeader draws the window's geometry in the preview
+// panel beside the resize form, or under the form when the popup is too
+// narrow to split.
+func (m *Model) viewPaneResizeModeWithHeader(header string) (string, int) {
+	mode := m.paneResizeMode
+	form, inputRow := m.viewFormWithHeader(mode.Title(), mode.View(), mode.Help(), header)
+	prevW := m.previewPanelWidth()
+	if prevW == 0 {
+		if diagram := mode.Diagram(m.width, 0); len(diagram) > 0 {
+			form += "\n\n" + strings.Join(diagram, "\n")
+		}
+		return form, inputRow
+	}
+	height := max(m.height, len(strings.Split(form, "\n")), 3)
+	left := lipgloss.NewStyle().Width(m.width - prevW).Height(height).MaxHeight(height).Render(form)
+	preview := &previewData{label: "layout", lines: mode.Diagram(prevW-2, height-2)}
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, m.renderPreviewPanel(preview, prevW, height)), inputRow
+}
+
+func (m *Model) viewPaneSpawnFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.paneSpawnForm.Title(), m.paneSpawnForm.InputView(), m.paneSpawnForm.Help(), header)
+}
+
+func (m *Model) viewPaneLogFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.paneLogForm.Title(), m.paneLogForm.InputView(), m.paneLogForm.Help(), header)
+}
+
 func (m *Model) viewWindowFormWithHeader(header string) (string, int) {
 	return m.viewFormWithHeader(m.windowForm.Title(), m.windowForm.InputView(), m.windowForm.Help(), header)
 }
 
+func (m *Model) viewBufferFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.bufferForm.Title(), m.bufferForm.InputView(), m.bufferForm.Help(), header)
+}
+
+func (m *Model) viewWorktreeFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.worktreeForm.Title(), m.worktreeForm.InputView(), m.worktreeForm.Help(), header)
+}
+
+func (m *Model) viewSessionEnvFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.sessionEnvForm.Title(), m.sessionEnvForm.InputView(), m.sessionEnvForm.Help(), header)
+}
+
+func (m *Model) viewServerKillFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.serverKillForm.Title(), m.serverKillForm.InputView(), m.serverKillForm.Help(), header)
+}
+
+func (m *Model) viewSearchFormWithHeader(header string) (string, int) {
+	return m.viewFormWithHeader(m.searchForm.Title(), m.searchForm.InputView(), m.searchForm.Help(), header)
+}
+
 func (m *Model) viewSessionFormWithHeader(header string) (string, int) {
 	lines := []string{}
 	title := m.sessionForm.Title()
@@ -58,3 +58,20 @@ func TestPaneFormCursorMovesOnBackspace(t *testing.T) {
 		t.Fatalf("cursor X did not decrement on backspace: before=%d after=%d", beforeX, afterX)
 	}
 }
+
+func TestBufferPromptOpensBufferForm(t *testing.T) {
+	m := NewModel(ModelConfig{Width: 80, Height: 16})
+	m.handleBufferPromptMsg(menu.BufferPrompt{Action: "clipboard:buffer:rename", Buffer: "buffer0", Initial: "buffer0"})
+	if m.mode != ModeBufferForm || m.bufferForm == nil {
+		t.Fatalf("expected buffer form mode, got %s", m.mode)
+	}
+	v := m.View()
+	if v.Cursor == nil {
+		t.Fatal("expected cursor visible when buffer form is focused")
+	}
+	h := NewHarness(m)
+	h.Send(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.mode != ModeMenu || m.bufferForm != nil {
+		t.Fatalf("expected escape to close the form, got %s", m.mode)
+	}
+}
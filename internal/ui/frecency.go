@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/frecency"
+	"github.com/atomicstack/tmux-popup-control/internal/logging"
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+	"github.com/atomicstack/tmux-popup-control/internal/ui/command"
+)
+
+// recordFrecencyFn persists a pick; it is a package var so tests never write
+// to the developer's real frecency store.
+var recordFrecencyFn = menu.RecordFrecency
+
+// frecencyRecorder returns the command bus hook that counts every executed
+// action. it runs off the UI goroutine and only touches the on-disk store:
+// the in-memory scores stay as loaded for the life of the popup.
+func frecencyRecorder(socketPath string) func(menu.Context, command.Request) {
+	return func(_ menu.Context, req command.Request) {
+		recordPick(socketPath, req.ID, strings.Split(req.Item.ID, "\n"))
+	}
+}
+
+// recordPick logs rather than surfaces failures: ranking is best-effort and
+// must never block the action itself.
+func recordPick(socketPath, nodeID string, itemIDs []string) {
+	if err := recordFrecencyFn(socketPath, nodeID, itemIDs); err != nil {
+		logging.Error(err)
+	}
+}
+
+// applyFrecency hands a freshly built level its scores. Levels ranked by
+// frecency also start with the cursor on the top (most frecent) item rather
+// than the last one.
+func (m *Model) applyFrecency(l *level) {
+	if l == nil || l.Node == nil {
+		return
+	}
+	scores := m.frecency.Scores(l.ID, time.Now())
+	sorted := m.sortMode == frecency.SortFrecency && l.Node.Frecency
+	l.SetFrecency(scores, sorted)
+	if sorted && len(scores) > 0 && len(l.Items) > 0 {
+		l.Cursor = 0
+		l.SkipHeaders(1)
+	}
+}
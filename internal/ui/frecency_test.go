@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/frecency"
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+	"github.com/atomicstack/tmux-popup-control/internal/ui/command"
+)
+
+func frecencyStore(node string, picks ...string) frecency.Store {
+	var s frecency.Store
+	now := time.Now()
+	for _, item := range picks {
+		s.Bump(node, item, now)
+	}
+	return s
+}
+
+func loadSessionSwitch(m *Model) *level {
+	m.pendingID = "session:switch"
+	m.handleCategoryLoadedMsg(categoryLoadedMsg{
+		id:    "session:switch",
+		title: "switch",
+		items: []menu.Item{{ID: "main", Label: "main"}, {ID: "misc", Label: "misc"}, {ID: "work", Label: "work"}},
+	})
+	return m.currentLevel()
+}
+
+func levelIDs(l *level) []string {
+	ids := make([]string, 0, len(l.Items))
+	for _, item := range l.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestFrecencySortPutsMostUsedFirst(t *testing.T) {
+	m := NewModel(ModelConfig{
+		Frecency: frecencyStore("session:switch", "work", "work", "misc"),
+		SortMode: frecency.SortFrecency,
+	})
+	lvl := loadSessionSwitch(m)
+	if got, want := levelIDs(lvl), []string{"work", "misc", "main"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("items = %v, want %v", got, want)
+	}
+	if lvl.Cursor != 0 {
+		t.Fatalf("expected cursor on the most frecent item, got %d", lvl.Cursor)
+	}
+}
+
+func TestFrecencyKeepsTmuxOrderByDefault(t *testing.T) {
+	m := NewModel(ModelConfig{Frecency: frecencyStore("session:switch", "misc")})
+	lvl := loadSessionSwitch(m)
+	if got, want := levelIDs(lvl), []string{"main", "misc", "work"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("items = %v, want %v", got, want)
+	}
+	lvl.SetFilter("m", 1)
+	if got := lvl.Items[lvl.Cursor].ID; got != "misc" {
+		t.Fatalf("expected filter tie broken toward misc, got %q", got)
+	}
+}
+
+func TestBusRecordsPicks(t *testing.T) {
+	var gotNode string
+	var gotItems []string
+	orig := recordFrecencyFn
+	recordFrecencyFn = func(_, node string, items []string) error {
+		gotNode, gotItems = node, items
+		return nil
+	}
+	defer func() { recordFrecencyFn = orig }()
+
+	bus := command.New(command.Hooks{Before: frecencyRecorder("")})
+	cmd := bus.Execute(menu.Context{}, command.Request{
+		ID:      "pane:kill",
+		Handler: func(menu.Context, menu.Item) tea.Cmd { return nil },
+		Item:    menu.Item{ID: "%1\n%2"},
+	})
+	cmd()
+	if gotNode != "pane:kill" || !reflect.DeepEqual(gotItems, []string{"%1", "%2"}) {
+		t.Fatalf("recorded %q %v", gotNode, gotItems)
+	}
+}
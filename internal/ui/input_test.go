@@ -90,6 +90,17 @@ func TestAutoCompleteGhostUsesSelectedRootMenuItem(t *testing.T) {
 	}
 }
 
+func TestRootFilterPrefixesPickTheirMenus(t *testing.T) {
+	m := NewModel(ModelConfig{})
+	current := m.currentLevel()
+	for query, want := range map[string]string{"se": "session", "sea": "search", "ser": "server"} {
+		current.SetFilter(query, len(query))
+		if got := current.Items[current.Cursor].ID; got != want {
+			t.Errorf("filter %q selected %q, want %q", query, got, want)
+		}
+	}
+}
+
 func TestAutoCompleteGhostRequiresCursorAtEndOfFilter(t *testing.T) {
 	m := NewModel(ModelConfig{})
 	current := m.currentLevel()
@@ -4,10 +4,19 @@ import (
 	"os"
 	"testing"
 
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
 	"github.com/atomicstack/tmux-popup-control/internal/testutil"
 )
 
 func TestMain(m *testing.M) {
+	// extract copies must never write to the developer's real clipboard,
+	// terminal or clipboard history, nor picks to the frecency store or
+	// inverses to the undo journal; tests
+	// that care stub these themselves.
+	extractClipboardFn = func(string, string) error { return nil }
+	extractHistoryFn = func(string, string, string) error { return nil }
+	recordFrecencyFn = func(string, string, []string) error { return nil }
+	recordUndoFn = func(menu.Context, string, menu.Item) error { return nil }
 	code := m.Run()
 	testutil.ShutdownSharedServer()
 	os.Exit(code)
@@ -14,12 +14,14 @@ import (
 	"github.com/atomicstack/tmux-popup-control/internal/cmdparse"
 	"github.com/atomicstack/tmux-popup-control/internal/data/dispatcher"
 	"github.com/atomicstack/tmux-popup-control/internal/extract"
+	"github.com/atomicstack/tmux-popup-control/internal/frecency"
 	"github.com/atomicstack/tmux-popup-control/internal/logging"
 	"github.com/atomicstack/tmux-popup-control/internal/menu"
 	"github.com/atomicstack/tmux-popup-control/internal/state"
 	"github.com/atomicstack/tmux-popup-control/internal/theme"
 	"github.com/atomicstack/tmux-popup-control/internal/ui/command"
 	uistate "github.com/atomicstack/tmux-popup-control/internal/ui/state"
+	"github.com/atomicstack/tmux-popup-control/internal/usermenu"
 )
 
 type level = uistate.Level
@@ -36,6 +38,16 @@ const (
 	ModeSessionSaveForm
 	ModePaneCaptureForm
 	ModeCommandOutput
+	ModeBufferForm
+	ModeTemplateForm
+	ModeWorktreeForm
+	ModeSessionEnvForm
+	ModeServerKillForm
+	ModeSearchForm
+	ModePaneBroadcastForm
+	ModePaneLogForm
+	ModePaneResize
+	ModePaneSpawnForm
 )
 
 const menuHeaderSeparator = "→"
@@ -66,6 +78,26 @@ func (m Mode) String() string {
 		return "pane_capture_form"
 	case ModeCommandOutput:
 		return "command_output"
+	case ModeBufferForm:
+		return "buffer_form"
+	case ModeTemplateForm:
+		return "template_form"
+	case ModeWorktreeForm:
+		return "worktree_form"
+	case ModeSessionEnvForm:
+		return "session_env_form"
+	case ModeServerKillForm:
+		return "server_kill_form"
+	case ModeSearchForm:
+		return "search_form"
+	case ModePaneBroadcastForm:
+		return "pane_broadcast_form"
+	case ModePaneLogForm:
+		return "pane_log_form"
+	case ModePaneResize:
+		return "pane_resize"
+	case ModePaneSpawnForm:
+		return "pane_spawn_form"
 	default:
 		return "unknown"
 	}
@@ -100,7 +132,18 @@ type Model struct {
 	paneForm                   *menu.PaneRenameForm
 	saveForm                   *menu.SaveForm
 	paneCaptureForm            *menu.PaneCaptureForm
+	bufferForm                 *menu.BufferForm
+	templateForm               *menu.SessionTemplateForm
+	worktreeForm               *menu.WorktreeForm
+	sessionEnvForm             *menu.SessionEnvForm
+	serverKillForm             *menu.ServerKillForm
+	searchForm                 *menu.SearchForm
+	paneBroadcastForm          *menu.PaneBroadcastForm
+	paneLogForm                *menu.PaneLogForm
+	paneResizeMode             *menu.PaneResizeMode
+	paneSpawnForm              *menu.PaneSpawnForm
 	pendingWindowSwap          *menu.Item
+	pendingClientSwitch        *menu.Item
 	pendingPaneSwap            *menu.Item
 	commandItemsCache          []menu.Item
 	commandSchemas             map[string]*cmdparse.CommandSchema
@@ -122,6 +165,9 @@ type Model struct {
 	extractModeSeq             int
 	extractAreaPopup           *completionState
 	extractAreaPrePopup        extract.GrabArea
+	searchScope                string
+	searchQuery                string
+	searchRegex                bool
 
 	handlers map[reflect.Type]msgHandler
 
@@ -151,6 +197,8 @@ type Model struct {
 	initCmd            tea.Cmd
 	deferredAction     *menu.Node
 	deferredRename     *menu.Node
+	frecency           frecency.Store
+	sortMode           frecency.SortMode
 
 	confirmState              *deleteConfirmState
 	pendingDeleteFilter       string
@@ -170,23 +218,29 @@ type ModelConfig struct {
 	MenuArgs    string
 	ClientID    string
 	SessionName string
+	UserMenus   []usermenu.Def
+	Frecency    frecency.Store
+	SortMode    frecency.SortMode
 }
 
 // NewModel initialises the UI state with the root menu and configuration.
 func NewModel(cfg ModelConfig) *Model {
-	registry := menu.BuildRegistry()
+	registry := menu.BuildRegistry(cfg.UserMenus...)
 	sessions := state.NewSessionStore()
 	sessions.SetIncludeCurrent(true)
 	windows := state.NewWindowStore()
 	windows.SetIncludeCurrent(true)
 	panes := state.NewPaneStore()
 	panes.SetIncludeCurrent(true)
-	rootItems := menu.RootItems()
+	rootItems := registry.RootItems()
 	root := newLevel("root", "Main Menu", rootItems, registry.Root())
 	m := &Model{
-		stack:        []*level{root},
-		registry:     registry,
-		bus:          command.New(),
+		stack:    []*level{root},
+		registry: registry,
+		bus: command.New(command.Hooks{
+			Before: frecencyRecorder(cfg.SocketPath),
+			After:  undoRecorder,
+		}),
 		backend:      cfg.Watcher,
 		backendState: map[backend.Kind]error{},
 		showFooter:   cfg.ShowFooter,
@@ -204,6 +258,8 @@ func NewModel(cfg ModelConfig) *Model {
 		dispatcher:   dispatcher.New(sessions, windows, panes),
 		preview:      make(map[string]*previewData),
 		commandHelp:  cmdhelp.Commands,
+		frecency:     cfg.Frecency,
+		sortMode:     cfg.SortMode,
 	}
 	m.applyNodeSettings(root)
 	m.syncViewport(root)
@@ -309,6 +365,26 @@ func (m *Model) handleActiveForm(msg tea.Msg) (bool, tea.Cmd) {
 		return m.handleSaveForm(msg)
 	case ModePaneCaptureForm:
 		return m.handlePaneCaptureForm(msg)
+	case ModeBufferForm:
+		return m.handleBufferForm(msg)
+	case ModeTemplateForm:
+		return m.handleTemplateForm(msg)
+	case ModeWorktreeForm:
+		return m.handleWorktreeForm(msg)
+	case ModeSessionEnvForm:
+		return m.handleSessionEnvForm(msg)
+	case ModeServerKillForm:
+		return m.handleServerKillForm(msg)
+	case ModeSearchForm:
+		return m.handleSearchForm(msg)
+	case ModePaneBroadcastForm:
+		return m.handlePaneBroadcastForm(msg)
+	case ModePaneLogForm:
+		return m.handlePaneLogForm(msg)
+	case ModePaneResize:
+		return m.handlePaneResizeMode(msg)
+	case ModePaneSpawnForm:
+		return m.handlePaneSpawnForm(msg)
 	default:
 		return false, nil
 	}
@@ -347,6 +423,17 @@ func (m *Model) registerHandlers() {
 		reflect.TypeFor[menu.SaveAsPrompt]():          m.handleSaveAsPromptMsg,
 		reflect.TypeFor[menu.PaneCapturePrompt]():     m.handlePaneCapturePromptMsg,
 		reflect.TypeFor[menu.PaneCapturePreviewMsg](): m.handlePaneCapturePreviewMsg,
+		reflect.TypeFor[menu.BufferPrompt]():          m.handleBufferPromptMsg,
+		reflect.TypeFor[menu.SessionTemplatePrompt](): m.handleSessionTemplatePromptMsg,
+		reflect.TypeFor[menu.WorktreePrompt]():        m.handleWorktreePromptMsg,
+		reflect.TypeFor[menu.SessionEnvPrompt]():      m.handleSessionEnvPromptMsg,
+		reflect.TypeFor[menu.ClientSwitchPrompt]():    m.handleClientSwitchPromptMsg,
+		reflect.TypeFor[menu.ServerKillPrompt]():      m.handleServerKillPromptMsg,
+		reflect.TypeFor[menu.SearchPrompt]():          m.handleSearchPromptMsg,
+		reflect.TypeFor[menu.PaneBroadcastPrompt]():   m.handlePaneBroadcastPromptMsg,
+		reflect.TypeFor[menu.PaneLogPrompt]():         m.handlePaneLogPromptMsg,
+		reflect.TypeFor[menu.PaneResizePrompt]():      m.handlePaneResizePromptMsg,
+		reflect.TypeFor[menu.PaneSpawnPrompt]():       m.handlePaneSpawnPromptMsg,
 		reflect.TypeFor[deleteSavedReloadedMsg]():     m.handleDeleteSavedReloadedMsg,
 		reflect.TypeFor[extractReloadMsg]():           m.handleExtractReloadMsg,
 		reflect.TypeFor[extractDoneMsg]():             m.handleExtractDoneMsg,
@@ -2,9 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
 	"github.com/atomicstack/tmux-popup-control/internal/menu"
+	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
 )
 
 func TestSessionSwitchItemsFiltersCurrent(t *testing.T) {
@@ -209,3 +214,76 @@ func TestStartPaneSwapAddsLevel(t *testing.T) {
 		t.Fatalf("unexpected items %#v", lvl.Items)
 	}
 }
+
+func TestStartClientSwitchSkipsCurrentSession(t *testing.T) {
+	m := NewModel(ModelConfig{})
+	m.sessions.SetEntries([]menu.SessionEntry{{Name: "work", Label: "work"}, {Name: "ops", Label: "ops"}})
+	m.startClientSwitch(menu.ClientSwitchPrompt{Client: menu.Item{ID: "/dev/pts/3", Label: "/dev/pts/3"}, Session: "work"})
+	lvl := m.stack[len(m.stack)-1]
+	if lvl.ID != "client:switch-target" || len(lvl.Items) != 1 || lvl.Items[0].ID != "ops" {
+		t.Fatalf("unexpected switch level %s %#v", lvl.ID, lvl.Items)
+	}
+	if m.pendingClientSwitch == nil || m.pendingClientSwitch.ID != "/dev/pts/3" {
+		t.Fatalf("expected pending client, got %#v", m.pendingClientSwitch)
+	}
+	if m.handleEscapeKey(); m.pendingClientSwitch != nil {
+		t.Fatal("expected escape to clear the pending client")
+	}
+}
+
+func TestSearchFormOpensResultsWithQuery(t *testing.T) {
+	m := NewModel(ModelConfig{})
+	m.startSearchForm(menu.SearchPrompt{Scope: "server"})
+	if m.mode != ModeSearchForm {
+		t.Fatalf("mode = %s", m.mode)
+	}
+	m.handleSearchForm(tea.KeyPressMsg{Code: 'x', Text: "x"})
+	m.handleSearchForm(tea.KeyPressMsg{Code: tea.KeyTab})
+	_, cmd := m.handleSearchForm(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil || m.mode != ModeMenu || m.pendingID != "search:results" {
+		t.Fatalf("expected the results to load, mode %s pending %q", m.mode, m.pendingID)
+	}
+	ctx := m.menuContext()
+	if ctx.SearchScope != "server" || ctx.SearchQuery != "x" || !ctx.SearchRegex {
+		t.Fatalf("unexpected search context %q %q %v", ctx.SearchScope, ctx.SearchQuery, ctx.SearchRegex)
+	}
+}
+
+func TestPaneResizeModeKeepsOtherMessagesFlowing(t *testing.T) {
+	m := NewModel(ModelConfig{})
+	m.startPaneResizeMode(menu.PaneResizePrompt{Target: "work:0.0"})
+	if m.mode != ModePaneResize {
+		t.Fatalf("mode = %s", m.mode)
+	}
+	if handled, _ := m.handlePaneResizeMode(tea.WindowSizeMsg{Width: 80, Height: 24}); handled {
+		t.Fatal("expected non-key messages to reach the usual handlers")
+	}
+	if handled, _ := m.handlePaneResizeMode(tea.KeyPressMsg{Code: tea.KeyEscape}); !handled || m.mode != ModeMenu || m.paneResizeMode != nil {
+		t.Fatalf("expected esc to leave the mode, mode %s", m.mode)
+	}
+}
+
+func TestPaneResizeModeDrawsLayoutInPreviewPanel(t *testing.T) {
+	m := NewModel(ModelConfig{})
+	m.width, m.height = 100, 24
+	m.startPaneResizeMode(menu.PaneResizePrompt{Target: "%2"})
+	layout, err := resurrect.ParseLayout("159x48,0,0{79x48,0,0,1,79x48,80,0[79x24,80,0,2,79x23,80,25,3]}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.handlePaneResizeMode(menu.PaneResizeLayoutMsg{Layout: layout, PaneID: 2, Seq: 1})
+
+	content := ansi.Strip(m.View().Content)
+	lines := strings.Split(content, "\n")
+	if !strings.Contains(lines[0], "Preview: layout") {
+		t.Fatalf("expected the preview panel beside the form:\n%s", content)
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "*%2 79x24") && !strings.Contains(line, "│") {
+			t.Fatalf("expected the diagram inside the panel:\n%s", content)
+		}
+	}
+	if !strings.Contains(content, "*%2 79x24") || !strings.Contains(content, "%2 is 79x24 of 159x48") {
+		t.Fatalf("expected the diagram and the pane size:\n%s", content)
+	}
+}
@@ -15,6 +15,13 @@ import (
 	"github.com/atomicstack/tmux-popup-control/internal/ui/command"
 )
 
+// the swap commands are package vars so tests can drive a swap through the
+// bus without touching a real tmux server.
+var (
+	windowSwapFn = menu.WindowSwapCommand
+	paneSwapFn   = menu.PaneSwapCommand
+)
+
 func (m *Model) handleEscapeKey() tea.Cmd {
 	current := m.currentLevel()
 	if current == nil {
@@ -29,6 +36,9 @@ func (m *Model) handleEscapeKey() tea.Cmd {
 	if current.ID == "pane:swap-target" {
 		m.pendingPaneSwap = nil
 	}
+	if current.ID == "client:switch-target" {
+		m.pendingClientSwitch = nil
+	}
 	if current.ID == "resurrect:restore-from" {
 		m.stopRestoreRefresh()
 	}
@@ -89,7 +99,13 @@ func (m *Model) handleEnterKey() tea.Cmd {
 		m.pendingLabel = filterText
 		m.errMsg = ""
 		m.forceClearInfo()
-		return menu.RunCommand(m.socketPath, filterText)
+		run := menu.RunCommand(m.socketPath, filterText)
+		socketPath := m.socketPath
+		name := strings.Fields(filterText)[0]
+		return func() tea.Msg {
+			recordPick(socketPath, "command", []string{name})
+			return run()
+		}
 	}
 	if current == nil || len(current.Items) == 0 {
 		return nil
@@ -119,6 +135,27 @@ func (m *Model) handleEnterKey() tea.Cmd {
 		m.startDeleteConfirm(item)
 		return nil
 	}
+	if current.ID == menu.PaletteID {
+		return m.paletteEnter(ctx, current, item)
+	}
+	if menu.IsProcessSignalLevel(current.ID) {
+		// Marks survive until the confirmation is accepted so a "n" leaves
+		// the selection intact.
+		if joined, ok := joinedSelection(current); ok {
+			item = joined
+		}
+		prompt, label := menu.ProcessSignalPrompt(current.ID, item)
+		m.startConfirm(item, prompt, label)
+		return nil
+	}
+	if current.ID == menu.WorktreeRemoveID {
+		if joined, ok := joinedSelection(current); ok {
+			item = joined
+		}
+		prompt, label := menu.WorktreeRemovePrompt(item)
+		m.startConfirm(item, prompt, label)
+		return nil
+	}
 	beforeCursor := current.FilterCursorPos()
 	current.SetFilter("", 0)
 	m.kickPreviewBlinkOnFilterChange(current, beforeCursor)
@@ -131,7 +168,16 @@ func (m *Model) handleEnterKey() tea.Cmd {
 		m.pendingLabel = fmt.Sprintf("%s ↔ %s", first.Label, item.Label)
 		m.errMsg = ""
 		m.forceClearInfo()
-		return menu.WindowSwapCommand(ctx, first, item)
+		// the pair travels as one joined item so the undo hook can swap
+		// the same two positions back.
+		return m.bus.Execute(ctx, command.Request{
+			ID:    "window:swap",
+			Label: m.pendingLabel,
+			Handler: func(ctx menu.Context, _ menu.Item) tea.Cmd {
+				return windowSwapFn(ctx, first, item)
+			},
+			Item: menu.Item{ID: first.ID + "\n" + item.ID, Label: m.pendingLabel},
+		})
 	}
 	if current.ID == "pane:swap-target" && m.pendingPaneSwap != nil {
 		first := *m.pendingPaneSwap
@@ -142,77 +188,123 @@ func (m *Model) handleEnterKey() tea.Cmd {
 		m.pendingLabel = fmt.Sprintf("%s ↔ %s", first.Label, item.Label)
 		m.errMsg = ""
 		m.forceClearInfo()
-		return menu.PaneSwapCommand(ctx, first, item)
+		// the pair travels as one joined item so the undo hook can swap
+		// the same two positions back.
+		return m.bus.Execute(ctx, command.Request{
+			ID:    "pane:swap",
+			Label: m.pendingLabel,
+			Handler: func(ctx menu.Context, _ menu.Item) tea.Cmd {
+				return paneSwapFn(ctx, first, item)
+			},
+			Item: menu.Item{ID: first.ID + "\n" + item.ID, Label: m.pendingLabel},
+		})
+	}
+	if current.ID == "client:switch-target" && m.pendingClientSwitch != nil {
+		client := *m.pendingClientSwitch
+		m.pendingClientSwitch = nil
+		m.stack = m.stack[:len(m.stack)-1]
+		m.loading = true
+		m.pendingID = "client:switch"
+		m.pendingLabel = fmt.Sprintf("%s → %s", client.Label, item.ID)
+		m.errMsg = ""
+		m.forceClearInfo()
+		return m.bus.Execute(ctx, command.Request{
+			ID:    "client:switch",
+			Label: m.pendingLabel,
+			Handler: func(ctx menu.Context, _ menu.Item) tea.Cmd {
+				return menu.ClientSwitchCommand(ctx, client, item)
+			},
+			Item: menu.Item{ID: client.ID, Label: m.pendingLabel},
+		})
 	}
 	node := current.Node
 	if node == nil {
 		node, _ = m.registry.Find(current.ID)
 	}
 	if current.MultiSelect {
-		if selected := current.SelectedItems(); len(selected) > 0 {
-			ids := make([]string, 0, len(selected))
-			labels := make([]string, 0, len(selected))
-			for _, sel := range selected {
-				ids = append(ids, sel.ID)
-				labels = append(labels, sel.Label)
-			}
-			item = menu.Item{ID: strings.Join(ids, "\n"), Label: strings.Join(labels, ", ")}
+		if joined, ok := joinedSelection(current); ok {
+			item = joined
 			current.ClearSelection()
 		}
 	}
 	if node != nil {
 		if child, ok := node.Children[item.ID]; ok {
 			if child.Loader != nil {
-				if child.FilterCommand && m.commandItemsCache != nil {
-					current.LastCursor = current.Cursor
-					m.errMsg = ""
-					m.forceClearInfo()
-					lvl := newLevel(child.ID, item.Label, m.commandItemsCache, child)
-					m.applyNodeSettings(lvl)
-					m.syncViewport(lvl)
-					m.stack = append(m.stack, lvl)
-					return nil
-				}
-				current.LastCursor = current.Cursor
-				if child.ID == extractLevelID {
-					// Reset the active category before the loader is
-					// dispatched so the async load reads the reset value,
-					// not a stale category from a previous visit. Also bump
-					// extractSeq so any ctrl-f reload still in flight from a
-					// prior visit is invalidated (see handleExtractReloadMsg).
-					m.extractCategory = extract.DefaultCategory
-					m.extractGrabArea = extract.DefaultGrabArea
-					m.extractSeq++
-				}
-				m.loading = true
-				m.pendingID = child.ID
-				m.pendingLabel = item.Label
-				m.errMsg = ""
-				m.forceClearInfo()
-				return m.loadMenuCmd(child.ID, item.Label, child.Loader)
+				return m.openNode(current, child, item.Label)
 			}
 			if child.Action != nil {
-				m.loading = true
-				m.pendingID = child.ID
-				m.pendingLabel = item.Label
-				m.errMsg = ""
-				m.forceClearInfo()
-				return m.bus.Execute(ctx, command.Request{ID: child.ID, Label: item.Label, Handler: child.Action, Item: item})
+				return m.runNodeAction(ctx, child, item)
 			}
 		}
 		if node.Action != nil {
-			m.loading = true
-			m.pendingID = node.ID
-			m.pendingLabel = item.Label
-			m.errMsg = ""
-			m.forceClearInfo()
-			return m.bus.Execute(ctx, command.Request{ID: node.ID, Label: item.Label, Handler: node.Action, Item: item})
+			return m.runNodeAction(ctx, node, item)
 		}
 	}
 	m.setInfo(fmt.Sprintf("Selected %s (no action defined yet)", item.Label))
 	return nil
 }
 
+// openNode pushes node's level above current, titled label. The command
+// browser opens synchronously from its preloaded cache; every other node
+// loads asynchronously.
+func (m *Model) openNode(current *level, node *menu.Node, label string) tea.Cmd {
+	current.LastCursor = current.Cursor
+	if node.FilterCommand && m.commandItemsCache != nil {
+		m.errMsg = ""
+		m.forceClearInfo()
+		lvl := newLevel(node.ID, label, m.commandItemsCache, node)
+		m.applyNodeSettings(lvl)
+		m.applyFrecency(lvl)
+		m.syncViewport(lvl)
+		m.stack = append(m.stack, lvl)
+		return nil
+	}
+	if node.ID == extractLevelID {
+		// Reset the active category before the loader is dispatched so the
+		// async load reads the reset value, not a stale category from a
+		// previous visit. Also bump extractSeq so any ctrl-f reload still in
+		// flight from a prior visit is invalidated (see
+		// handleExtractReloadMsg).
+		m.extractCategory = extract.DefaultCategory
+		m.extractGrabArea = extract.DefaultGrabArea
+		m.extractSeq++
+	}
+	m.loading = true
+	m.pendingID = node.ID
+	m.pendingLabel = label
+	m.errMsg = ""
+	m.forceClearInfo()
+	return m.loadMenuCmd(node.ID, label, node.Loader)
+}
+
+// runNodeAction dispatches node's action for item through the command bus.
+func (m *Model) runNodeAction(ctx menu.Context, node *menu.Node, item menu.Item) tea.Cmd {
+	m.loading = true
+	m.pendingID = node.ID
+	m.pendingLabel = item.Label
+	m.errMsg = ""
+	m.forceClearInfo()
+	return m.bus.Execute(ctx, command.Request{ID: node.ID, Label: item.Label, Handler: node.Action, Item: item})
+}
+
+// joinedSelection folds the marked items of a multi-select level into a single
+// item whose ID joins the selected IDs with "\n" (split again by the menu
+// actions) and whose label joins the labels with ", ". It reports false when
+// nothing is marked.
+func joinedSelection(current *level) (menu.Item, bool) {
+	selected := current.SelectedItems()
+	if len(selected) == 0 {
+		return menu.Item{}, false
+	}
+	ids := make([]string, 0, len(selected))
+	labels := make([]string, 0, len(selected))
+	for _, sel := range selected {
+		ids = append(ids, sel.ID)
+		labels = append(labels, sel.Label)
+	}
+	return menu.Item{ID: strings.Join(ids, "\n"), Label: strings.Join(labels, ", ")}, true
+}
+
 // moveCursor runs the supplied Level cursor-movement function against the
 // current level, emitting the cursor event and syncing the viewport when the
 // cursor moves. When the cursor does not move, the viewport is re-synced only if
@@ -509,6 +601,7 @@ func (m *Model) handleCategoryLoadedMsg(msg tea.Msg) tea.Cmd {
 		level.Subtitle = extractSubtitle(m.extractCategory, m.extractGrabArea)
 	}
 	m.applyNodeSettings(level)
+	m.applyFrecency(level)
 	m.syncViewport(level)
 	m.stack = append(m.stack, level)
 	cmd := m.ensurePreviewForLevel(level)
@@ -657,6 +750,7 @@ func (m *Model) applyRootMenuOverride(requested string) {
 		root.Subtitle = extractSubtitle(m.extractCategory, m.extractGrabArea)
 	}
 	m.applyNodeSettings(root)
+	m.applyFrecency(root)
 	m.syncViewport(root)
 	m.stack = []*level{root}
 	m.rootMenuID = node.ID
@@ -700,6 +794,30 @@ func (m *Model) startWindowSwap(prompt menu.WindowSwapPrompt) {
 	m.stack = append(m.stack, level)
 }
 
+// startClientSwitch lists the sessions the client can move to, leaving out
+// the one it is already on.
+func (m *Model) startClientSwitch(prompt menu.ClientSwitchPrompt) {
+	parent := m.currentLevel()
+	entries := m.sessions.Entries()
+	items := make([]menu.Item, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Name == prompt.Session {
+			continue
+		}
+		items = append(items, menu.Item{ID: entry.Name, Label: entry.Label})
+	}
+	if len(items) == 0 {
+		m.setInfo("No other sessions to switch to.")
+		return
+	}
+	level := newLevel("client:switch-target", fmt.Sprintf("Switch %s to…", prompt.Client.Label), items, nil)
+	if parent != nil {
+		parent.LastCursor = parent.Cursor
+	}
+	m.pendingClientSwitch = &prompt.Client
+	m.stack = append(m.stack, level)
+}
+
 func (m *Model) startPaneSwap(prompt menu.PaneSwapPrompt) {
 	parent := m.currentLevel()
 	label := prompt.First.Label
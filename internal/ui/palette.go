@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+)
+
+// paletteEnter resolves a palette pick to its registry node. A live entry
+// runs the node's action on its item, a node with a loader opens its submenu
+// above the palette (so Escape comes back to the search), and any other node
+// runs its action as if picked from its parent menu.
+func (m *Model) paletteEnter(ctx menu.Context, current *level, item menu.Item) tea.Cmd {
+	nodeID, itemID := menu.PaletteTarget(item.ID)
+	node, ok := m.registry.Find(nodeID)
+	if !ok {
+		m.setInfo(fmt.Sprintf("Selected %s (no action defined yet)", item.Label))
+		return nil
+	}
+	beforeCursor := current.FilterCursorPos()
+	current.SetFilter("", 0)
+	m.kickPreviewBlinkOnFilterChange(current, beforeCursor)
+	key := nodeID[strings.LastIndex(nodeID, ":")+1:]
+	var cmd tea.Cmd
+	switch {
+	case itemID != "" && node.Action != nil:
+		cmd = m.runNodeAction(ctx, node, menu.Item{ID: itemID, Label: menu.PaletteLabel(item.Label)})
+	case node.Loader != nil:
+		cmd = m.openNode(current, node, key)
+	case node.Action != nil:
+		cmd = m.runNodeAction(ctx, node, menu.Item{ID: key, Label: key})
+	default:
+		m.setInfo(fmt.Sprintf("Selected %s (no action defined yet)", item.Label))
+		return nil
+	}
+	socketPath := m.socketPath
+	record := func() tea.Msg {
+		recordPick(socketPath, menu.PaletteID, []string{item.ID})
+		return nil
+	}
+	if cmd == nil {
+		return record
+	}
+	return tea.Batch(record, cmd)
+}
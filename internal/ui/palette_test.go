@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+)
+
+func TestPaletteOpensLoaderNodeAbovePalette(t *testing.T) {
+	m := NewModel(ModelConfig{RootMenu: menu.PaletteID})
+	palette := m.currentLevel()
+	if palette == nil || palette.ID != menu.PaletteID {
+		t.Fatalf("expected palette root level, got %#v", palette)
+	}
+	palette.SetFilter("resize left", len("resize left"))
+	if got := palette.Items[palette.Cursor].ID; got != "pane:resize:left" {
+		t.Fatalf("expected pane:resize:left highlighted, got %q", got)
+	}
+	if cmd := m.handleEnterKey(); cmd == nil {
+		t.Fatal("expected a load command")
+	}
+	if m.pendingID != "pane:resize:left" || !m.loading {
+		t.Fatalf("expected pane:resize:left loading, got %q loading=%v", m.pendingID, m.loading)
+	}
+	m.handleCategoryLoadedMsg(categoryLoadedMsg{id: "pane:resize:left", title: "left", items: []menu.Item{{ID: "1", Label: "1"}}})
+	if len(m.stack) != 2 || m.stack[0].ID != menu.PaletteID {
+		t.Fatalf("expected submenu pushed above the palette, got %d levels", len(m.stack))
+	}
+}
+
+func TestPaletteLiveEntryRunsNodeAction(t *testing.T) {
+	m := NewModel(ModelConfig{RootMenu: menu.PaletteID})
+	palette := m.currentLevel()
+	var got menu.Item
+	node, _ := m.registry.Find("session:switch")
+	orig := node.Action
+	node.Action = func(_ menu.Context, item menu.Item) tea.Cmd {
+		got = item
+		return nil
+	}
+	defer func() { node.Action = orig }()
+	palette.UpdateItems([]menu.Item{{ID: "session:switch\twork", Label: "session → switch → work"}})
+	palette.Cursor = 0
+
+	cmd := m.handleEnterKey()
+	if cmd == nil || m.pendingID != "session:switch" {
+		t.Fatalf("expected session:switch dispatched, got %q", m.pendingID)
+	}
+	runBatch(cmd)
+	if got.ID != "work" || got.Label != "work" {
+		t.Fatalf("action got %#v", got)
+	}
+}
+
+// runBatch runs cmd and, for a batch, every command inside it.
+func runBatch(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			runBatch(c)
+		}
+	}
+}
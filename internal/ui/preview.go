@@ -69,6 +69,12 @@ var (
 	panePreviewFn          = tmux.PanePreview
 	layoutPreviewFn        = tmux.SelectLayout
 	fetchPreviewTopologyFn = tmux.FetchPreviewTopology
+	processPreviewFn       = menu.ProcessPreviewLines
+	bufferPreviewFn        = menu.BufferPreviewLines
+	historyPreviewFn       = menu.ClipboardHistoryPreviewLines
+	systemClipPreviewFn    = menu.SystemClipboardPreviewLines
+	clientPaneFn           = menu.ClientPaneID
+	searchPreviewFn        = menu.SearchPreviewLines
 )
 
 type layoutAppliedMsg struct {
@@ -110,7 +116,7 @@ func (m *Model) ensurePreviewForLevel(level *level) tea.Cmd {
 	if level == nil {
 		return nil
 	}
-	kind := previewKindForLevel(level.ID)
+	kind := previewKindFor(level)
 	if kind == previewKindNone {
 		m.clearPreview(level.ID)
 		return nil
@@ -183,6 +189,53 @@ func (m *Model) ensurePreviewForLevel(level *level) tea.Cmd {
 		return m.treePreviewCmd(levelID, target, seq, socket)
 	case previewKindPane:
 		return capturePaneCmd(levelID, kind, target, target, seq, socket)
+	case previewKindProcess:
+		ctx := m.menuContext()
+		return func() tea.Msg {
+			lines, err := processPreviewFn(ctx, target)
+			return previewLoadedMsg{levelID: levelID, kind: kind, target: target, seq: seq, lines: lines, err: err}
+		}
+	case previewKindBuffer:
+		ctx := m.menuContext()
+		return func() tea.Msg {
+			lines, err := bufferPreviewFn(ctx, target)
+			return previewLoadedMsg{levelID: levelID, kind: kind, target: target, seq: seq, lines: lines, err: err}
+		}
+	case previewKindHistory:
+		ctx := m.menuContext()
+		return func() tea.Msg {
+			lines, err := historyPreviewFn(ctx, target)
+			return previewLoadedMsg{levelID: levelID, kind: kind, target: target, seq: seq, lines: lines, err: err}
+		}
+	case previewKindSystemClipboard:
+		ctx := m.menuContext()
+		return func() tea.Msg {
+			lines, err := systemClipPreviewFn(ctx, target)
+			return previewLoadedMsg{levelID: levelID, kind: kind, target: target, seq: seq, lines: lines, err: err}
+		}
+	case previewKindNode:
+		ctx := m.menuContext()
+		preview := level.Node.Preview
+		item := level.Items[level.Cursor]
+		return func() tea.Msg {
+			lines, err := preview(ctx, item)
+			return previewLoadedMsg{levelID: levelID, kind: kind, target: target, seq: seq, lines: lines, err: err}
+		}
+	case previewKindSearch:
+		ctx := m.menuContext()
+		return func() tea.Msg {
+			lines, err := searchPreviewFn(ctx, target)
+			return previewLoadedMsg{levelID: levelID, kind: kind, target: target, seq: seq, lines: lines, err: err, rawANSI: true}
+		}
+	case previewKindClient:
+		ctx := m.menuContext()
+		return func() tea.Msg {
+			paneID, err := clientPaneFn(ctx, target)
+			if err != nil {
+				return previewLoadedMsg{levelID: levelID, kind: kind, target: target, seq: seq, err: err}
+			}
+			return capturePaneCmd(levelID, kind, target, paneID, seq, socket)()
+		}
 	case previewKindSession:
 		paneID := m.previewPaneIDForSession(level, target)
 		if paneID == "" {
@@ -236,6 +289,12 @@ func (m *Model) refreshPreviewForLevel(level *level) tea.Cmd {
 	// will issue a new fetch, but do NOT delete the entry — its lines remain
 	// visible until the fresh data arrives.
 	if existing, ok := m.preview[level.ID]; ok {
+		// user preview commands may be slow or costly (kubectl, curl), and
+		// reading the system clipboard runs an external tool, so these only
+		// run when the highlighted item changes.
+		if existing.kind == previewKindNode || existing.kind == previewKindSystemClipboard {
+			return nil
+		}
 		existing.loading = false
 	}
 	return m.ensurePreviewForLevel(level)
@@ -390,13 +449,48 @@ const previewKindTree previewKind = 10
 const previewKindLayout previewKind = 11
 const previewKindPlugin previewKind = 12
 
+// previewKindProcess renders the owning pane's process tree.
+const previewKindProcess previewKind = 13
+
+// previewKindBuffer renders the full content of a tmux paste buffer.
+const previewKindBuffer previewKind = 14
+
+// previewKindHistory renders the full text of a clipboard history entry.
+const previewKindHistory previewKind = 15
+
+// previewKindSystemClipboard renders what a system clipboard paste mode
+// would send to the pane.
+const previewKindSystemClipboard previewKind = 16
+
+// previewKindNode runs the level node's own Preview func (user menus,
+// session templates).
+const previewKindNode previewKind = 17
+
+// previewKindClient captures the active pane of the highlighted client.
+const previewKindClient previewKind = 18
+
+// previewKindSearch shows the lines around a scrollback search match.
+const previewKindSearch previewKind = 19
+
+// previewKindFor returns the preview kind for l: the built-in kind for its
+// ID, else previewKindNode when its registry node brings a Preview func.
+func previewKindFor(l *level) previewKind {
+	if kind := previewKindForLevel(l.ID); kind != previewKindNone {
+		return kind
+	}
+	if l.Node != nil && l.Node.Preview != nil {
+		return previewKindNode
+	}
+	return previewKindNone
+}
+
 func previewKindForLevel(id string) previewKind {
 	switch id {
 	case "session:switch":
 		return previewKindSession
 	case "window:switch":
 		return previewKindWindow
-	case "pane:switch", "pane:join":
+	case "pane:switch", "pane:join", "pane:broadcast", "pane:respawn":
 		return previewKindPane
 	case "session:tree", "window:pull-from-session":
 		return previewKindTree
@@ -404,6 +498,23 @@ func previewKindForLevel(id string) previewKind {
 		return previewKindLayout
 	case "plugins":
 		return previewKindPlugin
+	case "process:display", "process:tree", "process:terminate", "process:kill",
+		"process:interrupt", "process:continue", "process:stop", "process:quit",
+		"process:hangup":
+		return previewKindProcess
+	case "clipboard:buffer:paste", "clipboard:buffer:rename", "clipboard:buffer:delete",
+		"clipboard:buffer:save", "clipboard:buffer:edit":
+		return previewKindBuffer
+	case "clipboard:history:insert", "clipboard:history:copy", "clipboard:history:pin",
+		"clipboard:history:delete":
+		return previewKindHistory
+	case "clipboard:system":
+		return previewKindSystemClipboard
+	case "client:detach", "client:detach-kill", "client:switch", "client:read-only",
+		"client:refresh", "client:lock":
+		return previewKindClient
+	case "search:results":
+		return previewKindSearch
 	default:
 		return previewKindNone
 	}
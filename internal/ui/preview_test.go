@@ -6,6 +6,7 @@ import (
 
 	"github.com/atomicstack/tmux-popup-control/internal/menu"
 	"github.com/atomicstack/tmux-popup-control/internal/tmux"
+	"github.com/atomicstack/tmux-popup-control/internal/usermenu"
 )
 
 // TestEnsurePreviewForLevelFallsBackToWindowList covers the session preview
@@ -131,6 +132,140 @@ func TestWindowPreviewUsesPaneCaptureWhenPanesAvailable(t *testing.T) {
 	}
 }
 
+func TestProcessPreviewRendersOwningPaneTree(t *testing.T) {
+	lvl := newLevel("process:terminate", "terminate", []menu.Item{
+		{Label: "pid  state  pane  command", Header: true},
+		{ID: "111", Label: "111  R  s:0.0  cc1"},
+	}, nil)
+	lvl.Cursor = 1
+	m := NewModel(ModelConfig{})
+	m.stack = []*level{lvl}
+	m.preview = make(map[string]*previewData)
+
+	gotPID := ""
+	old := processPreviewFn
+	processPreviewFn = func(_ menu.Context, pid string) ([]string, error) {
+		gotPID = pid
+		return []string{"s:0.0 (make)", "", "* 111  R  cc1"}, nil
+	}
+	defer func() { processPreviewFn = old }()
+
+	cmd := m.ensurePreviewForLevel(lvl)
+	if cmd == nil {
+		t.Fatal("expected preview command")
+	}
+	m.handlePreviewLoadedMsg(cmd())
+	if gotPID != "111" {
+		t.Fatalf("expected preview for pid 111, got %q", gotPID)
+	}
+	data := m.preview[lvl.ID]
+	if data == nil || len(data.lines) != 3 || data.kind != previewKindProcess {
+		t.Fatalf("unexpected preview data %#v", data)
+	}
+}
+
+func TestBufferPreviewShowsBufferContent(t *testing.T) {
+	lvl := newLevel("clipboard:buffer:paste", "paste", []menu.Item{
+		{Label: "name  size  sample", Header: true},
+		{ID: "notes", Label: "notes  5 B  hello"},
+	}, nil)
+	lvl.Cursor = 1
+	m := NewModel(ModelConfig{})
+	m.stack = []*level{lvl}
+	m.preview = make(map[string]*previewData)
+
+	gotName := ""
+	old := bufferPreviewFn
+	bufferPreviewFn = func(_ menu.Context, name string) ([]string, error) {
+		gotName = name
+		return []string{"notes (5 B)", "", "hello"}, nil
+	}
+	defer func() { bufferPreviewFn = old }()
+
+	cmd := m.ensurePreviewForLevel(lvl)
+	if cmd == nil {
+		t.Fatal("expected preview command")
+	}
+	m.handlePreviewLoadedMsg(cmd())
+	if gotName != "notes" {
+		t.Fatalf("expected preview for buffer notes, got %q", gotName)
+	}
+	data := m.preview[lvl.ID]
+	if data == nil || len(data.lines) != 3 || data.kind != previewKindBuffer {
+		t.Fatalf("unexpected preview data %#v", data)
+	}
+}
+
+func TestHistoryPreviewShowsEntryText(t *testing.T) {
+	lvl := newLevel("clipboard:history:insert", "insert", []menu.Item{
+		{Label: "  age  source  size  sample", Header: true},
+		{ID: "abc123", Label: "  just now  extract  5 B  hello"},
+	}, nil)
+	lvl.Cursor = 1
+	m := NewModel(ModelConfig{})
+	m.stack = []*level{lvl}
+	m.preview = make(map[string]*previewData)
+
+	gotID := ""
+	old := historyPreviewFn
+	historyPreviewFn = func(_ menu.Context, id string) ([]string, error) {
+		gotID = id
+		return []string{"extract, just now (5 B)", "", "hello"}, nil
+	}
+	defer func() { historyPreviewFn = old }()
+
+	cmd := m.ensurePreviewForLevel(lvl)
+	if cmd == nil {
+		t.Fatal("expected preview command")
+	}
+	m.handlePreviewLoadedMsg(cmd())
+	if gotID != "abc123" {
+		t.Fatalf("expected preview for entry abc123, got %q", gotID)
+	}
+	data := m.preview[lvl.ID]
+	if data == nil || len(data.lines) != 3 || data.kind != previewKindHistory {
+		t.Fatalf("unexpected preview data %#v", data)
+	}
+}
+
+func TestSystemClipboardPreviewFollowsHighlightedMode(t *testing.T) {
+	lvl := newLevel("clipboard:system", "System Clipboard", []menu.Item{
+		{ID: "paste", Label: "paste"},
+		{ID: "trimmed", Label: "paste without trailing newline"},
+	}, nil)
+	lvl.Cursor = 1
+	m := NewModel(ModelConfig{})
+	m.stack = []*level{lvl}
+	m.preview = make(map[string]*previewData)
+
+	gotMode := ""
+	old := systemClipPreviewFn
+	systemClipPreviewFn = func(_ menu.Context, id string) ([]string, error) {
+		gotMode = id
+		return []string{"2 B, bracketed paste", "", "hi"}, nil
+	}
+	defer func() { systemClipPreviewFn = old }()
+
+	cmd := m.ensurePreviewForLevel(lvl)
+	if cmd == nil {
+		t.Fatal("expected preview command")
+	}
+	m.handlePreviewLoadedMsg(cmd())
+	if gotMode != "trimmed" {
+		t.Fatalf("expected preview for mode trimmed, got %q", gotMode)
+	}
+	if data := m.preview[lvl.ID]; data == nil || data.kind != previewKindSystemClipboard {
+		t.Fatalf("unexpected preview data %#v", data)
+	}
+	if cmd := m.refreshPreviewForLevel(lvl); cmd != nil {
+		t.Fatal("the clipboard tool must not re-run on the refresh tick")
+	}
+	lvl.Cursor = 0
+	if cmd := m.ensurePreviewForLevel(lvl); cmd == nil {
+		t.Fatal("expected moving the cursor to read the clipboard again")
+	}
+}
+
 func TestSessionPreviewUsesSessionActivePaneFromTopology(t *testing.T) {
 	lvl := newLevel("session:switch", "Sessions", []menu.Item{{ID: "dev", Label: "Dev"}}, nil)
 	m := NewModel(ModelConfig{})
@@ -615,3 +750,39 @@ func TestMaxVisibleItemsRespectsNoPreview(t *testing.T) {
 		t.Fatalf("expected maxVisibleItems >= 15 when noPreview=true, got %d", got)
 	}
 }
+
+func TestUserMenuPreviewUsesNodePreview(t *testing.T) {
+	var got menu.Item
+	node := &menu.Node{ID: "k8s:contexts", Preview: func(_ menu.Context, item menu.Item) ([]string, error) {
+		got = item
+		return []string{"cluster: prod"}, nil
+	}}
+	lvl := newLevel("k8s:contexts", "contexts", []menu.Item{{ID: "prod", Label: "Production"}}, node)
+	m := NewModel(ModelConfig{})
+	m.stack = []*level{lvl}
+	m.preview = make(map[string]*previewData)
+
+	cmd := m.ensurePreviewForLevel(lvl)
+	if cmd == nil {
+		t.Fatal("expected preview command")
+	}
+	m.handlePreviewLoadedMsg(cmd())
+	if got.ID != "prod" || got.Label != "Production" {
+		t.Fatalf("preview ran for %#v", got)
+	}
+	data := m.preview[lvl.ID]
+	if data == nil || data.kind != previewKindNode || len(data.lines) != 1 {
+		t.Fatalf("unexpected preview data %#v", data)
+	}
+	if cmd := m.refreshPreviewForLevel(lvl); cmd != nil {
+		t.Fatal("user previews must not re-run on the refresh tick")
+	}
+}
+
+func TestNewModelListsUserMenusInRoot(t *testing.T) {
+	m := NewModel(ModelConfig{UserMenus: []usermenu.Def{{ID: "deploy", Label: "Deploy", Shell: "make deploy"}}})
+	root := m.currentLevel()
+	if root.IndexOf("deploy") < 0 {
+		t.Fatalf("expected deploy in the root menu, got %#v", root.Items)
+	}
+}
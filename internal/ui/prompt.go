@@ -55,6 +55,106 @@ func (m *Model) handlePanePromptMsg(msg tea.Msg) tea.Cmd {
 	})
 }
 
+func (m *Model) handlePaneBroadcastPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.PaneBroadcastPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startPaneBroadcastForm(prompt)}
+	})
+}
+
+func (m *Model) handlePaneResizePromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.PaneResizePrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startPaneResizeMode(prompt)}
+	})
+}
+
+func (m *Model) handlePaneSpawnPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.PaneSpawnPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startPaneSpawnForm(prompt)}
+	})
+}
+
+func (m *Model) handlePaneLogPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.PaneLogPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startPaneLogForm(prompt)}
+	})
+}
+
+func (m *Model) handleBufferPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.BufferPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startBufferForm(prompt)}
+	})
+}
+
+func (m *Model) handleSessionTemplatePromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.SessionTemplatePrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startTemplateForm(prompt)}
+	})
+}
+
+func (m *Model) handleWorktreePromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.WorktreePrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startWorktreeForm(prompt)}
+	})
+}
+
+func (m *Model) handleSessionEnvPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.SessionEnvPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startSessionEnvForm(prompt)}
+	})
+}
+
+func (m *Model) handleServerKillPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.ServerKillPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startServerKillForm(prompt)}
+	})
+}
+
+func (m *Model) handleSearchPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.SearchPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		return promptResult{Cmd: m.startSearchForm(prompt)}
+	})
+}
+
 func (m *Model) handleWindowSwapPromptMsg(msg tea.Msg) tea.Cmd {
 	prompt, ok := msg.(menu.WindowSwapPrompt)
 	if !ok {
@@ -66,6 +166,17 @@ func (m *Model) handleWindowSwapPromptMsg(msg tea.Msg) tea.Cmd {
 	})
 }
 
+func (m *Model) handleClientSwitchPromptMsg(msg tea.Msg) tea.Cmd {
+	prompt, ok := msg.(menu.ClientSwitchPrompt)
+	if !ok {
+		return nil
+	}
+	return m.withPrompt(func() promptResult {
+		m.startClientSwitch(prompt)
+		return promptResult{}
+	})
+}
+
 func (m *Model) handlePaneSwapPromptMsg(msg tea.Msg) tea.Cmd {
 	prompt, ok := msg.(menu.PaneSwapPrompt)
 	if !ok {
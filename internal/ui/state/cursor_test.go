@@ -1,6 +1,7 @@
 package state
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/atomicstack/tmux-popup-control/internal/menu"
@@ -171,3 +172,25 @@ func TestEnsureCursorVisibleWithAnchorAdjustsViewport(t *testing.T) {
 		t.Fatalf("expected anchored offset clamped to 0, got %d", l.ViewportOffset)
 	}
 }
+
+func TestSetFrecencySortsBelowHeaders(t *testing.T) {
+	l := NewLevel("session:switch", "switch", []menu.Item{
+		{Label: "name", Header: true},
+		{ID: "a", Label: "a"},
+		{ID: "b", Label: "b"},
+		{ID: "c", Label: "c"},
+	}, nil)
+	l.SetFrecency(map[string]float64{"c": 3, "b": 1}, true)
+	var got []string
+	for _, item := range l.Items {
+		got = append(got, item.ID)
+	}
+	if want := []string{"", "c", "b", "a"}; !slices.Equal(got, want) {
+		t.Fatalf("order = %q, want %q", got, want)
+	}
+
+	l.UpdateItems([]menu.Item{{ID: "a", Label: "a"}, {ID: "b", Label: "b"}})
+	if l.Items[0].ID != "b" {
+		t.Fatalf("refreshed items must stay sorted, got %#v", l.Items)
+	}
+}
@@ -27,7 +27,7 @@ func (l *Level) SetFilter(query string, cursor int) {
 	}
 	l.applyFilter()
 	if trimmed != "" && len(l.Items) > 0 {
-		if idx := BestMatchIndex(l.Items, l.filterQuery()); idx >= 0 {
+		if idx := BestMatchIndex(l.Items, l.filterQuery(), l.Frecency); idx >= 0 {
 			l.Cursor = idx
 		}
 	}
@@ -269,8 +269,11 @@ func FilterItems(items []menu.Item, query string) []menu.Item {
 	return CloneItems(filtered)
 }
 
-// BestMatchIndex returns the best index for the query among the provided items.
-func BestMatchIndex(items []menu.Item, query string) int {
+// BestMatchIndex returns the best index for the query among the provided
+// items. Matches are tried in tiers (exact, label prefix, ID prefix, ID
+// substring, label substring, fuzzy); within a tier the item with the highest
+// score wins, then the earliest. scores may be nil.
+func BestMatchIndex(items []menu.Item, query string, scores map[string]float64) int {
 	trimmed := strings.TrimSpace(query)
 	if trimmed == "" {
 		if len(items) == 0 {
@@ -279,29 +282,18 @@ func BestMatchIndex(items []menu.Item, query string) int {
 		return 0
 	}
 	lower := strings.ToLower(trimmed)
-	for i, item := range items {
-		if strings.EqualFold(item.Label, trimmed) || strings.EqualFold(item.ID, trimmed) {
-			return i
-		}
-	}
-	for i, item := range items {
-		if strings.HasPrefix(strings.ToLower(item.Label), lower) {
-			return i
-		}
-	}
-	for i, item := range items {
-		if strings.HasPrefix(strings.ToLower(item.ID), lower) {
-			return i
-		}
-	}
-	for i, item := range items {
-		if strings.Contains(strings.ToLower(item.ID), lower) {
-			return i
-		}
-	}
-	for i, item := range items {
-		if strings.Contains(strings.ToLower(item.Label), lower) {
-			return i
+	tiers := []func(menu.Item) bool{
+		func(item menu.Item) bool {
+			return strings.EqualFold(item.Label, trimmed) || strings.EqualFold(item.ID, trimmed)
+		},
+		func(item menu.Item) bool { return strings.HasPrefix(strings.ToLower(item.Label), lower) },
+		func(item menu.Item) bool { return strings.HasPrefix(strings.ToLower(item.ID), lower) },
+		func(item menu.Item) bool { return strings.Contains(strings.ToLower(item.ID), lower) },
+		func(item menu.Item) bool { return strings.Contains(strings.ToLower(item.Label), lower) },
+	}
+	for _, match := range tiers {
+		if idx := bestScored(items, match, scores); idx >= 0 {
+			return idx
 		}
 	}
 	labels := make([]string, len(items))
@@ -321,7 +313,11 @@ func BestMatchIndex(items []menu.Item, query string) int {
 			best = rank
 			continue
 		}
-		if rank.Distance == best.Distance && rank.OriginalIndex < best.OriginalIndex {
+		if rank.Distance != best.Distance {
+			continue
+		}
+		rankScore, bestScore := scores[items[rank.OriginalIndex].ID], scores[items[best.OriginalIndex].ID]
+		if rankScore > bestScore || (rankScore == bestScore && rank.OriginalIndex < best.OriginalIndex) {
 			best = rank
 		}
 	}
@@ -333,3 +329,18 @@ func BestMatchIndex(items []menu.Item, query string) int {
 	}
 	return best.OriginalIndex
 }
+
+// bestScored returns the index of the highest-scored item satisfying match,
+// the earliest on ties, or -1 when none does.
+func bestScored(items []menu.Item, match func(menu.Item) bool, scores map[string]float64) int {
+	best := -1
+	for i, item := range items {
+		if !match(item) {
+			continue
+		}
+		if best < 0 || scores[item.ID] > scores[items[best].ID] {
+			best = i
+		}
+	}
+	return best
+}
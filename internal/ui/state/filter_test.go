@@ -149,23 +149,41 @@ func TestBestMatchIndex(t *testing.T) {
 		{ID: "three", Label: "Third"},
 	}
 
-	if idx := BestMatchIndex(items, "Second"); idx != 1 {
+	if idx := BestMatchIndex(items, "Second", nil); idx != 1 {
 		t.Fatalf("expected exact label match index 1, got %d", idx)
 	}
-	if idx := BestMatchIndex(items, "two"); idx != 1 {
+	if idx := BestMatchIndex(items, "two", nil); idx != 1 {
 		t.Fatalf("expected ID match index 1, got %d", idx)
 	}
-	if idx := BestMatchIndex(items, "th"); idx != 2 {
+	if idx := BestMatchIndex(items, "th", nil); idx != 2 {
 		t.Fatalf("expected prefix match index 2, got %d", idx)
 	}
-	if idx := BestMatchIndex(items, "zzz"); idx != 0 {
+	if idx := BestMatchIndex(items, "zzz", nil); idx != 0 {
 		t.Fatalf("expected fallback index 0, got %d", idx)
 	}
-	if idx := BestMatchIndex(nil, "anything"); idx != -1 {
+	if idx := BestMatchIndex(nil, "anything", nil); idx != -1 {
 		t.Fatalf("expected -1 for empty slice, got %d", idx)
 	}
 }
 
+func TestBestMatchIndexBreaksTiesByScore(t *testing.T) {
+	items := []menu.Item{
+		{ID: "dev-api", Label: "dev-api"},
+		{ID: "dev-web", Label: "dev-web"},
+		{ID: "docs", Label: "docs"},
+	}
+	scores := map[string]float64{"dev-web": 12.5, "docs": 40}
+	if idx := BestMatchIndex(items, "dev", scores); idx != 1 {
+		t.Fatalf("expected the frecent prefix match 1, got %d", idx)
+	}
+	if idx := BestMatchIndex(items, "dev-api", scores); idx != 0 {
+		t.Fatalf("an exact match must beat a higher score, got %d", idx)
+	}
+	if idx := BestMatchIndex(items, "dw", scores); idx != 1 {
+		t.Fatalf("expected fuzzy match 1, got %d", idx)
+	}
+}
+
 func TestSetFilterSelectsFuzzyMatch(t *testing.T) {
 	items := []menu.Item{{ID: "1", Label: "Alpha"}, {ID: "2", Label: "Beta"}}
 	level := NewLevel("id", "title", items, nil)
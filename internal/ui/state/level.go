@@ -1,6 +1,7 @@
 package state
 
 import (
+	"cmp"
 	"slices"
 	"strings"
 
@@ -23,6 +24,10 @@ type Level struct {
 	LastCursor     int
 	Node           *menu.Node
 	ViewportOffset int
+	// Frecency scores item IDs by past picks. It breaks ties when the filter
+	// picks a best match, and orders Full when SortByFrecency is set.
+	Frecency       map[string]float64
+	SortByFrecency bool
 }
 
 // NewLevel constructs a Level using the provided items and menu node.
@@ -60,6 +65,9 @@ func (l *Level) IndexOf(id string) int {
 func (l *Level) UpdateItems(items []menu.Item) {
 	prevOffset := l.ViewportOffset
 	l.Full = CloneItems(items)
+	if l.SortByFrecency {
+		sortByFrecency(l.Full, l.Frecency)
+	}
 	l.CleanupSelections()
 	l.applyFilter()
 	if len(l.Items) == 0 {
@@ -73,3 +81,28 @@ func (l *Level) UpdateItems(items []menu.Item) {
 	}
 	l.ViewportOffset = prevOffset
 }
+
+// SetFrecency installs item scores and re-orders the items when sortItems
+// is set.
+func (l *Level) SetFrecency(scores map[string]float64, sortItems bool) {
+	l.Frecency = scores
+	l.SortByFrecency = sortItems
+	if sortItems {
+		l.UpdateItems(l.Full)
+	}
+}
+
+// sortByFrecency orders items by score, highest first. Leading header rows
+// stay on top and equal scores keep their original (tmux) order.
+func sortByFrecency(items []menu.Item, scores map[string]float64) {
+	if len(scores) == 0 {
+		return
+	}
+	start := 0
+	for start < len(items) && items[start].Header {
+		start++
+	}
+	slices.SortStableFunc(items[start:], func(a, b menu.Item) int {
+		return cmp.Compare(scores[b.ID], scores[a.ID])
+	})
+}
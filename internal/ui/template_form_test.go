@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+	"github.com/atomicstack/tmux-popup-control/internal/sessiontemplate"
+)
+
+func newTemplatePrompt(t *testing.T) menu.SessionTemplatePrompt {
+	t.Helper()
+	tmpl, err := sessiontemplate.Parse("webapp", []byte(`{"root": "/src/{project}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return menu.SessionTemplatePrompt{Context: menu.Context{SocketPath: "sock"}, Template: tmpl}
+}
+
+func TestSessionTemplatePromptSwitchesToForm(t *testing.T) {
+	h := NewHarness(NewModel(ModelConfig{Width: 80, Height: 24}))
+	h.Send(newTemplatePrompt(t))
+	m := h.Model()
+	if m.mode != ModeTemplateForm || m.templateForm == nil {
+		t.Fatalf("mode = %v, want ModeTemplateForm", m.mode)
+	}
+	view := ansi.Strip(m.View().Content)
+	for _, want := range []string{"new from webapp", "project » webapp", "session webapp in /src/webapp"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestSessionTemplateFormEscReturnsToMenu(t *testing.T) {
+	h := NewHarness(NewModel(ModelConfig{Width: 80, Height: 24}))
+	h.Send(newTemplatePrompt(t))
+	h.Send(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m := h.Model(); m.mode != ModeMenu || m.templateForm != nil {
+		t.Fatalf("mode = %v, form = %v", m.mode, m.templateForm)
+	}
+}
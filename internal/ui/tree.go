@@ -60,8 +60,11 @@ func buildTree(
 		indicator := treeExpandIndicator(state, sid)
 		wc := windowCounts[sess.Name]
 		sessionSuffix := ""
+		if sess.Group != "" {
+			sessionSuffix = fmt.Sprintf(" [group %s]", sess.Group)
+		}
 		if sess.Current {
-			sessionSuffix = " (current)"
+			sessionSuffix += " (current)"
 		}
 		label := fmt.Sprintf("%s%s (%d windows)%s", indicator, sess.Name, wc, sessionSuffix)
 
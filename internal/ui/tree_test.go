@@ -768,3 +768,22 @@ func TestBuildTreeDFSOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestTreeMarksSessionGroup(t *testing.T) {
+	sessions := []menu.SessionEntry{
+		{Name: "alpha", Windows: 1, Group: "alpha"},
+		{Name: "alpha-2", Windows: 1, Group: "alpha"},
+		{Name: "beta", Windows: 1},
+	}
+	windows := []menu.WindowEntry{{ID: "0", Label: "0:bash", Session: "alpha", Index: 0}}
+
+	m := testTreeModelWithSize(sessions, windows, nil, false, 160, 24)
+	view := m.View().Content
+
+	if strings.Count(view, "[group alpha]") != 2 {
+		t.Fatalf("expected both grouped sessions marked, got:\n%s", view)
+	}
+	if strings.Contains(view, "beta (0 windows) [group") {
+		t.Fatalf("did not expect an ungrouped session marked, got:\n%s", view)
+	}
+}
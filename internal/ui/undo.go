@@ -0,0 +1,27 @@
+package ui
+
+import (
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/logging"
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+	"github.com/atomicstack/tmux-popup-control/internal/ui/command"
+)
+
+// recordUndoFn journals an inverse; it is a package var so tests never write
+// to the developer's real undo journal.
+var recordUndoFn = menu.RecordUndo
+
+// undoRecorder is the command bus hook that journals the inverse of every
+// action that succeeded. ctx predates the action, which is what the inverse
+// needs to restore. failures are logged: a missing undo entry must never
+// fail the action itself.
+func undoRecorder(ctx menu.Context, req command.Request, msg tea.Msg) {
+	result, ok := msg.(menu.ActionResult)
+	if !ok || result.Err != nil {
+		return
+	}
+	if err := recordUndoFn(ctx, req.ID, req.Item); err != nil {
+		logging.Error(err)
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+	"github.com/atomicstack/tmux-popup-control/internal/ui/command"
+)
+
+func TestUndoRecorderSkipsFailures(t *testing.T) {
+	var recorded []string
+	orig := recordUndoFn
+	recordUndoFn = func(_ menu.Context, action string, _ menu.Item) error {
+		recorded = append(recorded, action)
+		return nil
+	}
+	defer func() { recordUndoFn = orig }()
+
+	undoRecorder(menu.Context{}, command.Request{ID: "window:rename"}, menu.ActionResult{Info: "Renamed"})
+	undoRecorder(menu.Context{}, command.Request{ID: "pane:rename"}, menu.ActionResult{Err: errors.New("boom")})
+	undoRecorder(menu.Context{}, command.Request{ID: "pane:swap"}, "not a result")
+	if !reflect.DeepEqual(recorded, []string{"window:rename"}) {
+		t.Fatalf("recorded %v", recorded)
+	}
+}
+
+func TestWindowSwapRunsThroughBus(t *testing.T) {
+	var gotNode string
+	var gotItems []string
+	orig := recordFrecencyFn
+	recordFrecencyFn = func(_, node string, items []string) error {
+		gotNode, gotItems = node, items
+		return nil
+	}
+	defer func() { recordFrecencyFn = orig }()
+	var swapped [2]string
+	origSwap := windowSwapFn
+	windowSwapFn = func(_ menu.Context, first, second menu.Item) tea.Cmd {
+		swapped = [2]string{first.ID, second.ID}
+		return func() tea.Msg { return menu.ActionResult{Info: "Swapped"} }
+	}
+	defer func() { windowSwapFn = origSwap }()
+
+	m := NewModel(ModelConfig{})
+	swap := newLevel("window:swap-target", "Swap", []menu.Item{{ID: "main:2", Label: "two"}}, nil)
+	m.stack = append(m.stack, swap)
+	m.pendingWindowSwap = &menu.Item{ID: "main:1", Label: "one"}
+
+	cmd := m.handleEnterKey()
+	if cmd == nil || m.pendingID != "window:swap" {
+		t.Fatalf("expected window:swap dispatched, got %q", m.pendingID)
+	}
+	cmd()
+	if gotNode != "window:swap" || !reflect.DeepEqual(gotItems, []string{"main:1", "main:2"}) {
+		t.Fatalf("bus saw %q %v", gotNode, gotItems)
+	}
+	if swapped != [2]string{"main:1", "main:2"} {
+		t.Fatalf("swapped %q", swapped)
+	}
+}
@@ -42,7 +42,7 @@ func (m *Model) hasSidePreview() bool {
 	if current == nil {
 		return false
 	}
-	kind := previewKindForLevel(current.ID)
+	kind := previewKindFor(current)
 	if kind == previewKindNone || kind == previewKindLayout {
 		return false
 	}
@@ -116,6 +116,74 @@ func (m *Model) View() (view tea.View) {
 			attachFormCursor(&v, m.windowForm.Cursor(), inputRow)
 			return v
 		}
+	case ModeBufferForm:
+		if m.bufferForm != nil {
+			content, inputRow := m.viewBufferFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.bufferForm.Cursor(), inputRow)
+			return v
+		}
+	case ModeWorktreeForm:
+		if m.worktreeForm != nil {
+			content, inputRow := m.viewWorktreeFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.worktreeForm.Cursor(), inputRow)
+			return v
+		}
+	case ModeSessionEnvForm:
+		if m.sessionEnvForm != nil {
+			content, inputRow := m.viewSessionEnvFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.sessionEnvForm.Cursor(), inputRow)
+			return v
+		}
+	case ModeServerKillForm:
+		if m.serverKillForm != nil {
+			content, inputRow := m.viewServerKillFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.serverKillForm.Cursor(), inputRow)
+			return v
+		}
+	case ModePaneBroadcastForm:
+		if m.paneBroadcastForm != nil {
+			content, inputRow := m.viewPaneBroadcastFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.paneBroadcastForm.Cursor(), inputRow)
+			return v
+		}
+	case ModePaneResize:
+		if m.paneResizeMode != nil {
+			content, _ := m.viewPaneResizeModeWithHeader(header)
+			return m.wrapView(content)
+		}
+	case ModePaneSpawnForm:
+		if m.paneSpawnForm != nil {
+			content, inputRow := m.viewPaneSpawnFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.paneSpawnForm.Cursor(), inputRow)
+			return v
+		}
+	case ModePaneLogForm:
+		if m.paneLogForm != nil {
+			content, inputRow := m.viewPaneLogFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.paneLogForm.Cursor(), inputRow)
+			return v
+		}
+	case ModeSearchForm:
+		if m.searchForm != nil {
+			content, inputRow := m.viewSearchFormWithHeader(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.searchForm.Cursor(), inputRow)
+			return v
+		}
+	case ModeTemplateForm:
+		if m.templateForm != nil {
+			content, inputRow := m.viewTemplateForm(header)
+			v := m.wrapView(content)
+			attachFormCursor(&v, m.templateForm.Cursor(), inputRow)
+			return v
+		}
 	case ModeSessionForm:
 		if m.sessionForm != nil {
 			content, inputRow := m.viewSessionFormWithHeader(header)
@@ -1113,7 +1181,7 @@ func (m *Model) maxVisibleItems() int {
 				used += len(displayLines)
 			}
 		} else if current := m.currentLevel(); current != nil {
-			kind := previewKindForLevel(current.ID)
+			kind := previewKindFor(current)
 			if kind != previewKindNone && kind != previewKindLayout {
 				// Reserve space for the preview that is about to load.
 				used += 3 // blank + title + "Loading preview…"
@@ -0,0 +1,112 @@
+// Package undo keeps a per-server journal of inverse operations, so a rename,
+// swap, move or layout change made from the popup can be reversed later. it
+// only stores and hands out entries: computing an inverse and replaying it
+// is the menu package's job, so there are no tmux, bubbletea, or menu imports.
+package undo
+
+import (
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/atomicstack/tmux-popup-control/internal/statefile"
+)
+
+// MaxEntries caps each server's journal; the oldest entries go first.
+const MaxEntries = 50
+
+// maxAge is how long a server's journal survives without a new entry. tmux
+// reuses sockets, so a journal left behind by a dead server is dropped
+// rather than kept forever.
+const maxAge = 7 * 24 * time.Hour
+
+// Entry is one reversible action. Commands are the tmux commands that
+// reverse it, run in order; Requires lists the tmux targets that must still
+// exist for the replay to make sense.
+type Entry struct {
+	ID       string     `json:"id"`
+	Action   string     `json:"action"`
+	Label    string     `json:"label"`
+	Commands [][]string `json:"commands"`
+	Requires []string   `json:"requires,omitempty"`
+	Created  time.Time  `json:"created"`
+}
+
+// journalFile maps a server key to its entries, newest first.
+type journalFile struct {
+	Servers map[string][]Entry `json:"servers"`
+}
+
+// nowFn is stubbed in tests to make ordering and expiry deterministic.
+var nowFn = time.Now
+
+func journalPath(dir string) string {
+	return filepath.Join(dir, ".undo")
+}
+
+func journalLockPath(dir string) string {
+	return filepath.Join(dir, ".undo.lock")
+}
+
+func load(dir string) (journalFile, error) {
+	return statefile.Load[journalFile](journalPath(dir), "undo journal")
+}
+
+// update runs fn on the journal and saves the result.
+func update(dir string, fn func(*journalFile)) error {
+	return statefile.Update(journalLockPath(dir), journalPath(dir), "undo journal", func(f *journalFile) {
+		if f.Servers == nil {
+			f.Servers = make(map[string][]Entry)
+		}
+		fn(f)
+	})
+}
+
+// Push records e as server's newest entry, filling in its ID and creation
+// time.
+func Push(dir, server string, e Entry) error {
+	now := nowFn()
+	e.Created = now
+	e.ID = strconv.FormatInt(now.UnixNano(), 36)
+	return update(dir, func(f *journalFile) {
+		for key, entries := range f.Servers {
+			if key != server && (len(entries) == 0 || now.Sub(entries[0].Created) > maxAge) {
+				delete(f.Servers, key)
+			}
+		}
+		entries := append([]Entry{e}, f.Servers[server]...)
+		if len(entries) > MaxEntries {
+			entries = entries[:MaxEntries]
+		}
+		f.Servers[server] = entries
+	})
+}
+
+// List returns server's entries, newest first.
+func List(dir, server string) ([]Entry, error) {
+	f, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return f.Servers[server], nil
+}
+
+// Take removes and returns server's entry with the given ID, or its newest
+// entry when id is empty. ok is false when there is no such entry.
+func Take(dir, server, id string) (entry Entry, ok bool, err error) {
+	err = update(dir, func(f *journalFile) {
+		entries := f.Servers[server]
+		for i, e := range entries {
+			if id != "" && e.ID != id {
+				continue
+			}
+			entry, ok = e, true
+			f.Servers[server] = append(entries[:i:i], entries[i+1:]...)
+			if len(f.Servers[server]) == 0 {
+				delete(f.Servers, server)
+			}
+			return
+		}
+	})
+	return entry, ok, err
+}
@@ -0,0 +1,91 @@
+package undo
+
+import (
+	"testing"
+	"time"
+)
+
+// withClock makes every Push land one second after the last, starting at
+// start, so entry IDs and ages are deterministic.
+func withClock(t *testing.T, start time.Time) {
+	t.Helper()
+	now := start
+	orig := nowFn
+	nowFn = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	t.Cleanup(func() { nowFn = orig })
+}
+
+func TestPushListTakeNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	withClock(t, time.Unix(1_700_000_000, 0))
+	for _, label := range []string{"first", "second", "third"} {
+		if err := Push(dir, "srv", Entry{Action: "window:rename", Label: label}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := List(dir, "srv")
+	if err != nil || len(entries) != 3 || entries[0].Label != "third" {
+		t.Fatalf("unexpected entries %#v %v", entries, err)
+	}
+
+	newest, ok, err := Take(dir, "srv", "")
+	if err != nil || !ok || newest.Label != "third" {
+		t.Fatalf("take newest = %#v %v %v", newest, ok, err)
+	}
+	picked, ok, err := Take(dir, "srv", entries[2].ID)
+	if err != nil || !ok || picked.Label != "first" {
+		t.Fatalf("take by id = %#v %v %v", picked, ok, err)
+	}
+	if _, ok, _ := Take(dir, "srv", entries[2].ID); ok {
+		t.Fatal("expected a taken entry to be gone")
+	}
+	left, _ := List(dir, "srv")
+	if len(left) != 1 || left[0].Label != "second" {
+		t.Fatalf("left = %#v", left)
+	}
+}
+
+func TestPushCapsEntries(t *testing.T) {
+	dir := t.TempDir()
+	withClock(t, time.Unix(1_700_000_000, 0))
+	for range MaxEntries + 5 {
+		if err := Push(dir, "srv", Entry{Action: "pane:swap"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := List(dir, "srv")
+	if err != nil || len(entries) != MaxEntries {
+		t.Fatalf("expected %d entries, got %d %v", MaxEntries, len(entries), err)
+	}
+}
+
+func TestPushDropsStaleServers(t *testing.T) {
+	dir := t.TempDir()
+	withClock(t, time.Unix(1_700_000_000, 0))
+	if err := Push(dir, "old", Entry{Action: "window:rename"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Push(dir, "recent", Entry{Action: "window:rename"}); err != nil {
+		t.Fatal(err)
+	}
+	withClock(t, time.Unix(1_700_000_000, 0).Add(maxAge+time.Hour))
+	if err := Push(dir, "recent", Entry{Action: "pane:rename"}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := List(dir, "old"); len(entries) != 0 {
+		t.Fatalf("expected the stale server dropped, got %#v", entries)
+	}
+	if entries, _ := List(dir, "recent"); len(entries) != 2 {
+		t.Fatalf("expected the pushing server kept, got %#v", entries)
+	}
+}
+
+func TestListMissingJournal(t *testing.T) {
+	entries, err := List(t.TempDir(), "srv")
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty journal, got %#v %v", entries, err)
+	}
+}
@@ -0,0 +1,282 @@
+// Package usermenu parses user-defined menu trees from JSON, either a config
+// file holding a list of menus or one @tmux-popup-control-menu-<id> option per
+// menu. it only validates and expands definitions: running the commands and
+// registering the nodes is the menu package's job, so there are no tmux,
+// bubbletea, or menu imports here.
+package usermenu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/atomicstack/tmux-popup-control/internal/shquote"
+)
+
+// OptionPrefix is the tmux option prefix that defines one menu per option;
+// the rest of the option name becomes the menu ID.
+const OptionPrefix = "@tmux-popup-control-menu-"
+
+// Def is one menu node. A node with Items is a static submenu. A node with
+// Loader is a dynamic submenu: the loader's output lines become items, and
+// picking one runs the node's Tmux or Shell command. Any other node is a leaf
+// that runs its Tmux or Shell command directly.
+//
+// Commands support tmux formats (#{pane_current_path}) expanded against the
+// originating pane, plus the placeholders {item} (the picked item ID),
+// {label} (its label) and {items} (every marked item, for MultiSelect).
+type Def struct {
+	ID          string `json:"id,omitempty"`
+	Label       string `json:"label,omitempty"`
+	Tmux        string `json:"tmux,omitempty"`
+	Shell       string `json:"shell,omitempty"`
+	Loader      string `json:"loader,omitempty"`
+	Preview     string `json:"preview,omitempty"`
+	MultiSelect bool   `json:"multi_select,omitempty"`
+	Items       []Def  `json:"items,omitempty"`
+}
+
+// Parse reads a config file body: a JSON array of top-level menus.
+func Parse(data []byte) ([]Def, error) {
+	var defs []Def
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("parse menus: %w", err)
+	}
+	return Normalize(defs)
+}
+
+// ParseOption reads the value of a menu option: a single JSON menu whose ID
+// defaults to the option name without OptionPrefix.
+func ParseOption(name, value string) (Def, error) {
+	var def Def
+	if err := json.Unmarshal([]byte(value), &def); err != nil {
+		return Def{}, fmt.Errorf("parse %s: %w", name, err)
+	}
+	if def.ID == "" {
+		def.ID = strings.TrimPrefix(name, OptionPrefix)
+	}
+	defs, err := Normalize([]Def{def})
+	if err != nil {
+		return Def{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return defs[0], nil
+}
+
+// Load reads and parses the config file at path. A missing file is not an
+// error: the file is optional.
+func Load(path string) ([]Def, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defs, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return defs, nil
+}
+
+// Merge appends extra to base; an extra menu whose ID is already in base
+// replaces it in place, so an option overrides the file's menu of that ID.
+func Merge(base, extra []Def) []Def {
+	merged := append([]Def(nil), base...)
+	for _, def := range extra {
+		replaced := false
+		for i := range merged {
+			if merged[i].ID == def.ID {
+				merged[i], replaced = def, true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, def)
+		}
+	}
+	return merged
+}
+
+// Normalize fills in missing IDs and labels and validates the tree. IDs
+// default to the label in kebab-case and must be unique among siblings.
+func Normalize(defs []Def) ([]Def, error) {
+	return normalize(defs, "")
+}
+
+func normalize(defs []Def, parent string) ([]Def, error) {
+	out := make([]Def, 0, len(defs))
+	seen := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if def.ID == "" {
+			def.ID = slug(def.Label)
+		}
+		path := def.ID
+		if parent != "" {
+			path = parent + ":" + def.ID
+		}
+		if def.ID == "" {
+			return nil, fmt.Errorf("menu under %q needs an id or label", parent)
+		}
+		if strings.ContainsFunc(def.ID, func(r rune) bool { return r == ':' || unicode.IsSpace(r) }) {
+			return nil, fmt.Errorf("menu id %q must not contain ':' or whitespace", path)
+		}
+		if seen[def.ID] {
+			return nil, fmt.Errorf("duplicate menu id %q", path)
+		}
+		seen[def.ID] = true
+		if def.Label == "" {
+			def.Label = def.ID
+		}
+		if err := validate(def, path); err != nil {
+			return nil, err
+		}
+		if len(def.Items) > 0 {
+			items, err := normalize(def.Items, path)
+			if err != nil {
+				return nil, err
+			}
+			def.Items = items
+		}
+		out = append(out, def)
+	}
+	return out, nil
+}
+
+func validate(def Def, path string) error {
+	hasCommand := def.Tmux != "" || def.Shell != ""
+	switch {
+	case def.Tmux != "" && def.Shell != "":
+		return fmt.Errorf("menu %q: set tmux or shell, not both", path)
+	case len(def.Items) > 0 && (hasCommand || def.Loader != ""):
+		return fmt.Errorf("menu %q: items cannot be combined with a command or loader", path)
+	case len(def.Items) == 0 && !hasCommand:
+		return fmt.Errorf("menu %q: needs items, or a tmux or shell command", path)
+	case def.MultiSelect && def.Loader == "":
+		return fmt.Errorf("menu %q: multi_select needs a loader", path)
+	}
+	return nil
+}
+
+// slug turns a label into a kebab-case ID.
+func slug(label string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(label)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// Item is one loaded entry.
+type Item struct {
+	ID    string
+	Label string
+}
+
+// ParseItems turns loader output into items: one per non-blank line, with an
+// optional tab separating the item ID from its label.
+func ParseItems(output string) []Item {
+	var items []Item
+	for line := range strings.SplitSeq(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		id, label, ok := strings.Cut(line, "\t")
+		if !ok {
+			label = id
+		}
+		items = append(items, Item{ID: id, Label: label})
+	}
+	return items
+}
+
+// Selection is what a command's placeholders expand to.
+type Selection struct {
+	IDs   []string
+	Label string
+}
+
+// Batched reports whether command takes every marked item at once through
+// {items}; otherwise it runs once per item.
+func Batched(command string) bool {
+	return strings.Contains(command, "{items}")
+}
+
+// ExpandShell substitutes placeholders in a shell command, quoting each value
+// so an item can never inject shell syntax.
+func ExpandShell(command string, sel Selection) string {
+	return expand(command, sel, shquote.Quote, func(ids []string) string {
+		return shquote.JoinCommand(ids...)
+	})
+}
+
+// ExpandArgs substitutes placeholders in each argument of a tmux command
+// already split with shquote.Fields. An argument that is exactly {items}
+// becomes one argument per marked item.
+func ExpandArgs(fields []string, sel Selection) []string {
+	args := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field == "{items}" {
+			args = append(args, sel.IDs...)
+			continue
+		}
+		args = append(args, expand(field, sel, nil, func(ids []string) string {
+			return strings.Join(ids, " ")
+		}))
+	}
+	return args
+}
+
+func expand(s string, sel Selection, quote func(string) string, join func([]string) string) string {
+	if quote == nil {
+		quote = func(v string) string { return v }
+	}
+	item := ""
+	if len(sel.IDs) > 0 {
+		item = sel.IDs[0]
+	}
+	return strings.NewReplacer(
+		"{item}", quote(item),
+		"{label}", quote(sel.Label),
+		"{items}", join(sel.IDs),
+	).Replace(s)
+}
+
+const (
+	envFile = "TMUX_POPUP_CONTROL_MENUS_FILE"
+	optFile = "@tmux-popup-control-menus-file"
+)
+
+// ResolvePath returns the config file path from the environment, then the
+// tmux option, then $XDG_CONFIG_HOME/tmux-popup-control/menus.json.
+func ResolvePath(getenv, option func(string) string) string {
+	if v := strings.TrimSpace(getenv(envFile)); v != "" {
+		return os.Expand(v, getenv)
+	}
+	if v := strings.TrimSpace(option(optFile)); v != "" {
+		return os.Expand(v, getenv)
+	}
+	base := strings.TrimSpace(getenv("XDG_CONFIG_HOME"))
+	if base == "" {
+		home := strings.TrimSpace(getenv("HOME"))
+		if home == "" {
+			return ""
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "tmux-popup-control", "menus.json")
+}
@@ -0,0 +1,129 @@
+package usermenu
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFillsIDsAndLabels(t *testing.T) {
+	defs, err := Parse([]byte(`[
+		{"label": "K8s Contexts", "loader": "kubectl config get-contexts -o name",
+		 "shell": "kubectl config use-context {item}"},
+		{"id": "logs", "items": [{"label": "App Log", "tmux": "split-window tail -f app.log"}]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defs[0].ID != "k8s-contexts" || defs[0].Label != "K8s Contexts" {
+		t.Fatalf("unexpected first menu %#v", defs[0])
+	}
+	if defs[1].Label != "logs" || defs[1].Items[0].ID != "app-log" {
+		t.Fatalf("unexpected second menu %#v", defs[1])
+	}
+}
+
+func TestParseRejectsInvalidDefinitions(t *testing.T) {
+	cases := map[string]string{
+		"no command":       `[{"id": "a"}]`,
+		"both commands":    `[{"id": "a", "tmux": "x", "shell": "y"}]`,
+		"items and action": `[{"id": "a", "tmux": "x", "items": [{"id": "b", "tmux": "y"}]}]`,
+		"colon in id":      `[{"id": "a:b", "tmux": "x"}]`,
+		"duplicate child":  `[{"id": "a", "items": [{"id": "b", "tmux": "x"}, {"id": "b", "tmux": "y"}]}]`,
+		"multi no loader":  `[{"id": "a", "tmux": "x", "multi_select": true}]`,
+		"not json":         `{`,
+	}
+	for name, body := range cases {
+		if _, err := Parse([]byte(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseOptionTakesIDFromName(t *testing.T) {
+	def, err := ParseOption(OptionPrefix+"deploy", `{"label": "Deploy", "shell": "make deploy"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.ID != "deploy" || def.Label != "Deploy" {
+		t.Fatalf("unexpected def %#v", def)
+	}
+}
+
+func TestMergeReplacesByID(t *testing.T) {
+	base := []Def{{ID: "a", Label: "file a"}, {ID: "b"}}
+	got := Merge(base, []Def{{ID: "a", Label: "option a"}, {ID: "c"}})
+	want := []Def{{ID: "a", Label: "option a"}, {ID: "b"}, {ID: "c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Merge = %#v, want %#v", got, want)
+	}
+	if base[0].Label != "file a" {
+		t.Fatal("Merge must not modify base")
+	}
+}
+
+func TestLoadMissingFileIsEmpty(t *testing.T) {
+	defs, err := Load(filepath.Join(t.TempDir(), "menus.json"))
+	if err != nil || defs != nil {
+		t.Fatalf("got %#v, %v", defs, err)
+	}
+}
+
+func TestLoadReportsPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "menus.json")
+	if err := os.WriteFile(path, []byte(`[{"id": "x"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected error naming %s, got %v", path, err)
+	}
+}
+
+func TestParseItemsSplitsTabs(t *testing.T) {
+	got := ParseItems("prod\tProduction cluster\n\nstaging\r\n")
+	want := []Item{{ID: "prod", Label: "Production cluster"}, {ID: "staging", Label: "staging"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseItems = %#v, want %#v", got, want)
+	}
+}
+
+func TestExpandShellQuotesValues(t *testing.T) {
+	sel := Selection{IDs: []string{"it's", "b c"}, Label: "x; rm -rf ~"}
+	got := ExpandShell("echo {item} {label} -- {items}", sel)
+	want := `echo 'it'\''s' 'x; rm -rf ~' -- 'it'\''s' 'b c'`
+	if got != want {
+		t.Fatalf("ExpandShell = %q, want %q", got, want)
+	}
+}
+
+func TestExpandArgsSpreadsItems(t *testing.T) {
+	sel := Selection{IDs: []string{"%1", "%2"}, Label: "two panes"}
+	got := ExpandArgs([]string{"kill-pane", "-t", "{item}", "{items}", "name={label}"}, sel)
+	want := []string{"kill-pane", "-t", "%1", "%1", "%2", "name=two panes"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ExpandArgs = %q, want %q", got, want)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	env := map[string]string{"HOME": "/home/me"}
+	getenv := func(k string) string { return env[k] }
+	none := func(string) string { return "" }
+	if got := ResolvePath(getenv, none); got != "/home/me/.config/tmux-popup-control/menus.json" {
+		t.Fatalf("default path = %q", got)
+	}
+	env["XDG_CONFIG_HOME"] = "/xdg"
+	if got := ResolvePath(getenv, none); got != "/xdg/tmux-popup-control/menus.json" {
+		t.Fatalf("xdg path = %q", got)
+	}
+	option := func(string) string { return "$HOME/menus.json" }
+	if got := ResolvePath(getenv, option); got != "/home/me/menus.json" {
+		t.Fatalf("option path = %q", got)
+	}
+	env[envFile] = "/etc/menus.json"
+	if got := ResolvePath(getenv, option); got != "/etc/menus.json" {
+		t.Fatalf("env path = %q", got)
+	}
+}
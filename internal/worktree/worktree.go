@@ -0,0 +1,192 @@
+// Package worktree reads git worktrees: it parses `git worktree list
+// --porcelain` and `git status --porcelain=v2 --branch`, and derives where a
+// new worktree for a branch should live. it never runs git itself: the menu
+// package does, so there are no exec, tmux, bubbletea, or menu imports here.
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Worktree is one entry of `git worktree list --porcelain`.
+type Worktree struct {
+	Path     string
+	Head     string
+	Branch   string // short name; empty when detached or bare
+	Bare     bool
+	Detached bool
+	Locked   bool
+	// Prunable is set when git considers the worktree stale, usually
+	// because its directory is gone; Reason says why.
+	Prunable bool
+	Reason   string
+}
+
+// Name returns the worktree's branch, else the short HEAD, else its
+// directory name.
+func (w Worktree) Name() string {
+	switch {
+	case w.Branch != "":
+		return w.Branch
+	case w.Head != "":
+		return shortHash(w.Head)
+	default:
+		return filepath.Base(w.Path)
+	}
+}
+
+// Parse reads the porcelain listing. The first entry is the main worktree.
+func Parse(data []byte) []Worktree {
+	var (
+		out []Worktree
+		cur *Worktree
+	)
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		key, value, _ := strings.Cut(line, " ")
+		if key == "worktree" {
+			out = append(out, Worktree{Path: value})
+			cur = &out[len(out)-1]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch key {
+		case "HEAD":
+			cur.Head = value
+		case "branch":
+			cur.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			cur.Bare = true
+		case "detached":
+			cur.Detached = true
+		case "locked":
+			cur.Locked = true
+		case "prunable":
+			cur.Prunable = true
+			cur.Reason = value
+		}
+	}
+	return out
+}
+
+// Status summarises a work tree's state.
+type Status struct {
+	Branch     string // "(detached)" when HEAD is detached
+	Upstream   string
+	Ahead      int
+	Behind     int
+	Staged     int
+	Modified   int
+	Untracked  int
+	Conflicted int
+}
+
+// Dirty reports whether anything is uncommitted.
+func (s Status) Dirty() bool {
+	return s.Staged+s.Modified+s.Untracked+s.Conflicted > 0
+}
+
+// String renders the status the way a prompt would, e.g. "clean" or
+// "2 staged, 1 modified".
+func (s Status) String() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{
+		{s.Conflicted, "conflicted"},
+		{s.Staged, "staged"},
+		{s.Modified, "modified"},
+		{s.Untracked, "untracked"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	if len(parts) == 0 {
+		return "clean"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseStatus reads `git status --porcelain=v2 --branch`.
+func ParseStatus(data []byte) Status {
+	var s Status
+	for line := range strings.SplitSeq(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "#":
+			if len(fields) >= 3 && fields[1] == "branch.head" {
+				s.Branch = fields[2]
+			}
+			if len(fields) >= 3 && fields[1] == "branch.upstream" {
+				s.Upstream = fields[2]
+			}
+			if len(fields) >= 4 && fields[1] == "branch.ab" {
+				s.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+				s.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+			}
+		case "1", "2":
+			// fields[1] is XY: index then work tree state, "." when
+			// unchanged.
+			if len(fields) >= 2 && len(fields[1]) == 2 {
+				if fields[1][0] != '.' {
+					s.Staged++
+				}
+				if fields[1][1] != '.' {
+					s.Modified++
+				}
+			}
+		case "u":
+			s.Conflicted++
+		case "?":
+			s.Untracked++
+		}
+	}
+	return s
+}
+
+// CheckBranch rejects names git would refuse as a branch, so the form can
+// say so before anything runs. It is a quick subset of check-ref-format.
+func CheckBranch(name string) error {
+	switch {
+	case name == "":
+		return errors.New("branch name is empty")
+	case strings.HasPrefix(name, "-"):
+		return errors.New("branch name cannot start with -")
+	case strings.HasPrefix(name, "/"), strings.HasSuffix(name, "/"), strings.HasSuffix(name, "."):
+		return errors.New("branch name cannot start or end with / or end with .")
+	case strings.Contains(name, ".."), strings.Contains(name, "//"), strings.Contains(name, "@{"):
+		return errors.New("branch name cannot contain .., // or @{")
+	case strings.HasSuffix(name, ".lock"):
+		return errors.New("branch name cannot end with .lock")
+	case strings.ContainsFunc(name, func(r rune) bool {
+		return r <= ' ' || r == 0x7f || strings.ContainsRune(`~^:?*[\`, r)
+	}):
+		return errors.New(`branch name cannot contain spaces or any of ~^:?*[\`)
+	}
+	return nil
+}
+
+// DefaultPath returns where a worktree for branch goes: next to the main
+// worktree, named after it and the branch, e.g. ~/src/app-feature-x.
+func DefaultPath(main, branch string) string {
+	main = filepath.Clean(main)
+	return filepath.Join(filepath.Dir(main), filepath.Base(main)+"-"+strings.ReplaceAll(branch, "/", "-"))
+}
+
+func shortHash(head string) string {
+	if len(head) > 7 {
+		return head[:7]
+	}
+	return head
+}
@@ -0,0 +1,83 @@
+package worktree
+
+import (
+	"reflect"
+	"testing"
+)
+
+const porcelain = `worktree /src/app
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /src/app-feature-x
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/x
+locked
+
+worktree /src/app-detached
+HEAD 3333333333333333333333333333333333333333
+detached
+
+worktree /tmp/app-gone
+HEAD 4444444444444444444444444444444444444444
+branch refs/heads/old
+prunable gitdir file points to non-existent location
+`
+
+func TestParse(t *testing.T) {
+	got := Parse([]byte(porcelain))
+	want := []Worktree{
+		{Path: "/src/app", Head: "1111111111111111111111111111111111111111", Branch: "main"},
+		{Path: "/src/app-feature-x", Head: "2222222222222222222222222222222222222222", Branch: "feature/x", Locked: true},
+		{Path: "/src/app-detached", Head: "3333333333333333333333333333333333333333", Detached: true},
+		{Path: "/tmp/app-gone", Head: "4444444444444444444444444444444444444444", Branch: "old", Prunable: true, Reason: "gitdir file points to non-existent location"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse = %#v", got)
+	}
+	if got[2].Name() != "3333333" || got[1].Name() != "feature/x" {
+		t.Fatalf("names = %q %q", got[2].Name(), got[1].Name())
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	s := ParseStatus([]byte(`# branch.oid 1111111111111111111111111111111111111111
+# branch.head main
+# branch.upstream origin/main
+# branch.ab +2 -1
+1 M. N... 100644 100644 100644 a b go.mod
+1 .M N... 100644 100644 100644 a b main.go
+1 MM N... 100644 100644 100644 a b README.md
+u UU N... 100644 100644 100644 100644 a b c conflict.go
+? notes.txt
+`))
+	want := Status{Branch: "main", Upstream: "origin/main", Ahead: 2, Behind: 1, Staged: 2, Modified: 2, Untracked: 1, Conflicted: 1}
+	if s != want {
+		t.Fatalf("ParseStatus = %#v", s)
+	}
+	if !s.Dirty() || s.String() != "1 conflicted, 2 staged, 2 modified, 1 untracked" {
+		t.Fatalf("String = %q", s.String())
+	}
+	if clean := ParseStatus([]byte("# branch.head main\n")); clean.Dirty() || clean.String() != "clean" {
+		t.Fatalf("expected clean, got %#v", clean)
+	}
+}
+
+func TestCheckBranch(t *testing.T) {
+	for _, ok := range []string{"feature/x", "fix-123", "release/v1.2"} {
+		if err := CheckBranch(ok); err != nil {
+			t.Errorf("CheckBranch(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"", "-x", "a..b", "a b", "x.lock", "feat/", "a:b", "x@{1}"} {
+		if err := CheckBranch(bad); err == nil {
+			t.Errorf("CheckBranch(%q) should fail", bad)
+		}
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	if got := DefaultPath("/src/app/", "feature/x"); got != "/src/app-feature-x" {
+		t.Fatalf("DefaultPath = %q", got)
+	}
+}
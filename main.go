@@ -18,6 +18,8 @@ import (
 	"github.com/atomicstack/tmux-popup-control/internal/config"
 	"github.com/atomicstack/tmux-popup-control/internal/logging"
 	"github.com/atomicstack/tmux-popup-control/internal/logging/events"
+	"github.com/atomicstack/tmux-popup-control/internal/menu"
+	"github.com/atomicstack/tmux-popup-control/internal/panelog"
 	"github.com/atomicstack/tmux-popup-control/internal/plugin"
 	"github.com/atomicstack/tmux-popup-control/internal/resurrect"
 	"github.com/atomicstack/tmux-popup-control/internal/shquote"
@@ -181,6 +183,18 @@ func commandHandlers() map[string]commandHandler {
 				return runDeferredInstall(cfg)
 			},
 		},
+		"clipboard-history-poll": {
+			ErrorLabel: "clipboard-history-poll",
+			Run: func(cfg config.Config, _ MainDeps) error {
+				return runClipboardHistoryPoll(cfg)
+			},
+		},
+		panelog.FilterCommand: {
+			ErrorLabel: panelog.FilterCommand,
+			Run: func(config.Config, MainDeps) error {
+				return panelog.Filter(os.Stdin, os.Stdout)
+			},
+		},
 	}
 }
 
@@ -484,6 +498,24 @@ func runRestoreSessions(cfg config.Config) error {
 	return showPopup(socketPath, clientName, args...)
 }
 
+// runClipboardHistoryPoll handles the "clipboard-history-poll" subcommand:
+// it imports tmux paste buffers created since the last poll into the
+// clipboard history. main.tmux runs it from the paste-buffer-changed hook
+// when @tmux-popup-control-clipboard-history-poll is on.
+func runClipboardHistoryPoll(cfg config.Config) error {
+	fs := flag.NewFlagSet("clipboard-history-poll", flag.ContinueOnError)
+	socket := fs.String("socket", cfg.App.SocketPath, "tmux socket path")
+	if err := fs.Parse(subcommandArgs(cfg)); err != nil {
+		return err
+	}
+	socketPath, err := tmux.ResolveSocketPath(*socket)
+	if err != nil {
+		return fmt.Errorf("resolving socket: %w", err)
+	}
+	_, err = menu.PollClipboardBuffers(socketPath)
+	return err
+}
+
 func runAutosave(cfg config.Config, deps MainDeps) error {
 	autoSaveCfg, err := buildAutoSaveConfig(cfg, deps)
 	if err != nil {
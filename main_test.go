@@ -186,3 +186,17 @@ func TestCommandHandlersIncludesAutosaveAlias(t *testing.T) {
 		t.Fatalf("expected autosave labels, got %q and %q", autosave.ErrorLabel, autosaveStatus.ErrorLabel)
 	}
 }
+
+func TestCommandHandlersIncludesClipboardHistoryPoll(t *testing.T) {
+	handler, ok := commandHandlers()["clipboard-history-poll"]
+	if !ok || handler.ErrorLabel != "clipboard-history-poll" {
+		t.Fatalf("expected clipboard-history-poll handler, got %#v", handler)
+	}
+}
+
+func TestCommandHandlersIncludesLogFilter(t *testing.T) {
+	handler, ok := commandHandlers()["log-filter"]
+	if !ok || handler.ErrorLabel != "log-filter" {
+		t.Fatalf("expected log-filter handler, got %#v", handler)
+	}
+}